@@ -2,6 +2,8 @@
 
 package v1alpha1
 
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 // Host Session represent the leg between the router and the host.
 // A BGP session is established over this leg.
 type HostSession struct {
@@ -26,6 +28,68 @@ type HostSession struct {
 	// +required
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="LocalCIDR can't be changed"
 	LocalCIDR LocalCIDRConfig `json:"localcidr"`
+
+	// NextHopSelf makes the router set itself as next hop for the routes
+	// advertised to the host, so that the host does not need to resolve
+	// the nexthops learned from the fabric.
+	// Defaults to false.
+	// +optional
+	NextHopSelf *bool `json:"nexthopself,omitempty"`
+
+	// NextHopSelfForce extends NextHopSelf to reflected routes too, by
+	// emitting the "force" variant of next-hop-self.
+	// It can be set only if NextHopSelf is true.
+	// +optional
+	NextHopSelfForce *bool `json:"nexthopselfforce,omitempty"`
+
+	// DynamicPeers makes the router accept BGP sessions from any host
+	// in the LocalCIDR through a listen range, instead of peering with
+	// the host side of the veth only.
+	// It requires HostASN to be set.
+	// +optional
+	DynamicPeers bool `json:"dynamicpeers,omitempty"`
+
+	// StripCommunitiesOnImport removes the BGP communities and large communities
+	// from the routes advertised to the host, so that the communities used
+	// internally by the fabric are not leaked to the host.
+	// +optional
+	StripCommunitiesOnImport bool `json:"stripcommunitiesonimport,omitempty"`
+
+	// SendCommunity tells which communities are sent to the host:
+	// only the standard, extended or large ones, all of them, or none.
+	// Defaults to all.
+	// +kubebuilder:validation:Enum=standard;extended;large;all;none
+	// +optional
+	SendCommunity *string `json:"sendcommunity,omitempty"`
+
+	// Description is a free text identifying the session with the host
+	// in the output of vtysh. Only printable ASCII characters are allowed.
+	// +kubebuilder:validation:MaxLength=80
+	// +kubebuilder:validation:Pattern=`^[ -~]*$`
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// ConnectTime is the requested BGP connect time, controls how long BGP
+	// waits between connection attempts to the host.
+	// +kubebuilder:validation:XValidation:message="connect time should be between 1 seconds to 65535",rule="duration(self).getSeconds() >= 1 && duration(self).getSeconds() <= 65535"
+	// +kubebuilder:validation:XValidation:message="connect time should contain a whole number of seconds",rule="duration(self).getMilliseconds() % 1000 == 0"
+	// +optional
+	ConnectTime *metav1.Duration `json:"connecttime,omitempty"`
+
+	// HostAdvertise is the list of the prefixes advertised to the host.
+	// When set, only the routes matching exactly one of them are
+	// advertised to the host, instead of all the routes of the VRF.
+	// +optional
+	HostAdvertise []string `json:"hostadvertise,omitempty"`
+
+	// AllowASIn makes the router accept the routes received from the host
+	// with its own AS number in the AS path, up to the given number of
+	// times. It is required in hub-and-spoke topologies where the same AS
+	// number is used on both the ends of the fabric.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	AllowASIn *uint8 `json:"allowasin,omitempty"`
 }
 
 type LocalCIDRConfig struct {
@@ -25,6 +25,16 @@ const (
 	OVSBridge   = "ovs-bridge"
 )
 
+const (
+	OVSDatapathSystem = "system"
+	OVSDatapathNetdev = "netdev"
+)
+
+const (
+	AnycastGateway     = "anycast"
+	CentralizedGateway = "centralized"
+)
+
 // L2VNISpec defines the desired state of VNI.
 type L2VNISpec struct {
 	// VRF is the name of the linux VRF to be used inside the PERouter namespace.
@@ -54,10 +64,131 @@ type L2VNISpec struct {
 	// bridge the veths are enslaved to will be configured with these IP addresses, effectively
 	// acting as a distributed gateway for the VNI. This allows for dual-stack (IPv4 and IPv6) support.
 	// Maximum of 2 addresses are allowed. If 2 addresses are provided, one must be IPv4 and one must be IPv6.
+	// Each address must be a host address of its subnet, and not its network or broadcast address.
 	// +optional
 	// +kubebuilder:validation:MaxItems=2
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="L2GatewayIPs cannot be changed"
 	L2GatewayIPs []string `json:"l2gatewayips,omitempty"`
+
+	// GatewayMode tells which nodes assign the L2GatewayIPs. In anycast mode
+	// the gateway is distributed and assigned on every node, while in centralized
+	// mode it is assigned only on the node with the lowest index among the
+	// ready nodes that are not cordoned or in maintenance.
+	// Defaults to anycast.
+	// +kubebuilder:validation:Enum=anycast;centralized
+	// +optional
+	GatewayMode string `json:"gatewaymode,omitempty"`
+
+	// SuppressRA disables the IPv6 router advertisements sent by the router
+	// on the L2 gateway. It is meaningful only if an IPv6 L2GatewayIP is set.
+	// Defaults to true.
+	// +optional
+	SuppressRA *bool `json:"suppressra,omitempty"`
+
+	// ManagePolicyRouting makes the router install on the host, and in the pods
+	// attached to the HostMaster, source based routing rules for the L2GatewayIPs
+	// subnets, so that the traffic originated from the overlay is routed via the
+	// L2 gateway while the default route of the host and of the pods is preserved.
+	// It requires L2GatewayIPs to be set and a linux-bridge HostMaster.
+	// +optional
+	ManagePolicyRouting bool `json:"managepolicyrouting,omitempty"`
+
+	// Learning enables MAC learning on the VXLan interface, to interoperate with
+	// flood-and-learn endpoints not using EVPN. When enabled, one of MulticastGroup
+	// and StaticVTEPs must be set.
+	// Defaults to false, where MAC addresses are learned via BGP EVPN.
+	// +optional
+	Learning *bool `json:"learning,omitempty"`
+
+	// MulticastGroup is the multicast group the VXLan interface joins to flood
+	// the broadcast, unknown unicast and multicast traffic. It requires Learning to be enabled.
+	// +optional
+	MulticastGroup *string `json:"multicastgroup,omitempty"`
+
+	// StaticVTEPs is the list of the IPs of the remote VTEPs the VXLan interface
+	// floods the broadcast, unknown unicast and multicast traffic to, using head-end
+	// replication. It requires Learning to be enabled, as the flooding is not
+	// learned via EVPN, and can't be set together with MulticastGroup.
+	// +optional
+	StaticVTEPs []string `json:"staticvteps,omitempty"`
+
+	// DSCP is the DSCP value set on the outer header of the VXLan encapsulated
+	// packets of this VNI. If not set, the one of the underlay EVPN configuration is used.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// +optional
+	DSCP *uint8 `json:"dscp,omitempty"`
+
+	// TXChecksum enables or disables the UDP checksum of the VXLan encapsulated
+	// packets of this VNI. If not set, the one of the underlay EVPN configuration is used.
+	// +optional
+	TXChecksum *bool `json:"txchecksum,omitempty"`
+
+	// GRO enables or disables the generic receive offload of the VXLan device
+	// of this VNI. If not set, the one of the underlay EVPN configuration is used.
+	// +optional
+	GRO *bool `json:"gro,omitempty"`
+
+	// GSO enables or disables the generic segmentation offload of the VXLan device
+	// of this VNI. If not set, the one of the underlay EVPN configuration is used.
+	// +optional
+	GSO *bool `json:"gso,omitempty"`
+
+	// EthernetSegment is the EVPN multihoming ethernet segment the
+	// router side of the VNI veth belongs to.
+	// +optional
+	EthernetSegment *EthernetSegment `json:"ethernetsegment,omitempty"`
+
+	// MACAgeingTime is the time a MAC address learned by the bridge of the
+	// VNI is kept without receiving traffic from it. A longer time avoids
+	// flooding quiet overlays. It must be between 10s and 1h.
+	// Defaults to 300s, the default of the kernel.
+	// +optional
+	MACAgeingTime *metav1.Duration `json:"macageingtime,omitempty"`
+
+	// AdvertiseHostRoutes makes the router learn the hosts of the VNI
+	// announcing themselves with unsolicited ARP and NA messages, and
+	// advertise them as MAC/IP routes, installed as /32 and /128 host routes
+	// in the VRF of the remote routers. It requires L2GatewayIPs to be set,
+	// and the VRF to be the one of a L3VNI.
+	// +optional
+	AdvertiseHostRoutes *bool `json:"advertisehostroutes,omitempty"`
+
+	// DisableConntrack disables the connection tracking, in the router
+	// namespace, of the traffic from and to the subnets of the L2GatewayIPs,
+	// to avoid its overhead on the overlay traffic. Stateful filtering and NAT
+	// rules won't apply to that traffic. It requires L2GatewayIPs to be set.
+	// +optional
+	DisableConntrack bool `json:"disableconntrack,omitempty"`
+}
+
+// EthernetSegment identifies an EVPN multihoming ethernet segment, and the
+// preference of the router in the designated forwarder election of the segment.
+type EthernetSegment struct {
+	// ID is the local discriminator of the ethernet segment. Together with
+	// SysMAC it forms the type-3 ESI shared by the routers attached to the segment.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16777215
+	// +optional
+	ID *uint32 `json:"id,omitempty"`
+
+	// SysMAC is the system MAC of the ethernet segment. It is required when ID is set.
+	// +optional
+	SysMAC *string `json:"sysmac,omitempty"`
+
+	// ESI is the whole 10 bytes type-0 ESI of the ethernet segment, in the
+	// form 00:11:22:33:44:55:66:77:88:99. It is an alternative to ID and SysMAC,
+	// for the segments whose ESI is assigned by the operator.
+	// +optional
+	ESI *string `json:"esi,omitempty"`
+
+	// DFPreference is the preference of the router in the designated forwarder
+	// election of the segment: the router with the highest preference forwards
+	// the BUM traffic to the segment. It requires ID or ESI to be set.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	DFPreference *uint16 `json:"dfpreference,omitempty"`
 }
 
 // +kubebuilder:validation:Required
@@ -78,12 +209,25 @@ type HostMaster struct {
 	// The name of the bridge is of the form br-hs-<VNI>.
 	// +kubebuilder:default:=false
 	AutoCreate bool `json:"autocreate,omitempty"`
+
+	// DatapathType is the datapath type of the OVS bridge, system for the
+	// kernel datapath or netdev for the userspace one, as used with DPDK.
+	// It can be set only on the ovs-bridge interfaces created automatically.
+	// If not set, the default of OVS is kept.
+	// +kubebuilder:validation:Enum=system;netdev
+	// +optional
+	DatapathType *string `json:"datapathtype,omitempty"`
 }
 
 // VNIStatus defines the observed state of VNI.
 type L2VNIStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Conditions are the conditions reported for the L2VNI. When the VNIs
+	// are configured in best effort mode, each node reports a
+	// <node>/Configured condition telling if the VNI was set up.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -42,17 +42,157 @@ type L3VNISpec struct {
 	// HostSession is the configuration for the host session.
 	// +optional
 	HostSession *HostSession `json:"hostsession,omitempty"`
+
+	// ImportVRFs is the list of the VRFs of other L3VNIs whose routes are
+	// imported into the VRF of this L3VNI, to share services across VRFs.
+	// +optional
+	ImportVRFs []string `json:"importvrfs,omitempty"`
+
+	// LeakToDefault is the list of prefixes of the VRF leaked into the default
+	// VRF of the router, to reach them from the default network, for example
+	// for management access. Leaking a prefix bypasses the isolation provided
+	// by the VRF for it, so it must be limited to the prefixes that require it.
+	// +optional
+	LeakToDefault []string `json:"leaktodefault,omitempty"`
+
+	// AdvertiseIPv4 tells if the IPv4 routes of the VRF are advertised
+	// as EVPN type-5 routes. Defaults to true.
+	// +optional
+	AdvertiseIPv4 *bool `json:"advertiseipv4,omitempty"`
+
+	// AdvertiseIPv6 tells if the IPv6 routes of the VRF are advertised
+	// as EVPN type-5 routes. Defaults to true.
+	// +optional
+	AdvertiseIPv6 *bool `json:"advertiseipv6,omitempty"`
+
+	// FabricAdvertise tells if the VRF is advertised into the EVPN fabric.
+	// When false, the VRF is set up on the node only and none of its routes
+	// are advertised, so AdvertiseIPv4 and AdvertiseIPv6 can't be set to true.
+	// Defaults to true.
+	// +optional
+	FabricAdvertise *bool `json:"fabricadvertise,omitempty"`
+
+	// DSCP is the DSCP value set on the outer header of the VXLan encapsulated
+	// packets of this VNI. If not set, the one of the underlay EVPN configuration is used.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// +optional
+	DSCP *uint8 `json:"dscp,omitempty"`
+
+	// DisableConntrack disables the connection tracking, in the router
+	// namespace, of the traffic from and to the subnets of the host session
+	// and the ones listed in ConntrackBypassCIDRs, to avoid its overhead on
+	// the overlay traffic. Stateful filtering and NAT rules won't apply to
+	// that traffic. The host session subnets cover the link to the hosts only,
+	// so it requires ConntrackBypassCIDRs to be set.
+	// +optional
+	DisableConntrack bool `json:"disableconntrack,omitempty"`
+
+	// ConntrackBypassCIDRs are the subnets routed through the VRF, in
+	// addition to the ones of the host session, as the ones of the pods
+	// advertised by the hosts, whose traffic is not tracked when
+	// DisableConntrack is set.
+	// +listType=set
+	// +optional
+	ConntrackBypassCIDRs []string `json:"conntrackbypasscidrs,omitempty"`
+
+	// RouteMaps are the route-maps available to filter the routes of the VRF.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	RouteMaps []RouteMapSpec `json:"routemaps,omitempty"`
+
+	// EVPNExportRouteMap is the name of the route-map, among RouteMaps,
+	// filtering the routes of the VRF advertised as EVPN type-5 routes.
+	// It can't be set when FabricAdvertise is false.
+	// +optional
+	EVPNExportRouteMap *string `json:"evpnexportroutemap,omitempty"`
+
+	// RouterSVIAddress is an address, in CIDR notation, assigned to the bridge
+	// of the VNI inside the router namespace, to reach the VRF of the router
+	// for diagnostics. The address is the same on all the nodes, and it is
+	// advertised to the host as a host route. It can't overlap the LocalCIDR
+	// of the host session.
+	// +optional
+	RouterSVIAddress *string `json:"routersviaddress,omitempty"`
+}
+
+const (
+	RouteMapPermit = "permit"
+	RouteMapDeny   = "deny"
+)
+
+// RouteMapSpec is a route-map made of rules evaluated in order: the first
+// rule matching a route tells if the route is permitted, and what is set on
+// it. The routes not matching any rule are denied.
+type RouteMapSpec struct {
+	// Name is the name the route-map is referenced with.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_-]*$`
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Rules are the rules of the route-map.
+	// +kubebuilder:validation:MinItems=1
+	Rules []RouteMapRule `json:"rules"`
+}
+
+// RouteMapRule is a rule of a route-map.
+type RouteMapRule struct {
+	// Action tells if the routes matched by the rule are permitted or denied.
+	// +kubebuilder:validation:Enum=permit;deny
+	Action string `json:"action"`
+
+	// Match selects the routes the rule applies to. If not set, the rule
+	// applies to all the routes.
+	// +optional
+	Match *RouteMapMatch `json:"match,omitempty"`
+
+	// Set is what is set on the routes permitted by the rule.
+	// It can't be set when Action is deny.
+	// +optional
+	Set *RouteMapSet `json:"set,omitempty"`
+}
+
+// RouteMapMatch selects the routes a route-map rule applies to.
+type RouteMapMatch struct {
+	// Prefixes is the list of the prefixes, in CIDR notation, matched
+	// exactly by the rule. A rule with only IPv4 prefixes does not apply
+	// to the IPv6 routes, and the other way around.
+	// +kubebuilder:validation:MinItems=1
+	Prefixes []string `json:"prefixes"`
+}
+
+// RouteMapSet is what a route-map rule sets on the routes it permits.
+type RouteMapSet struct {
+	// Communities are the standard communities, in the <AS>:<value> form,
+	// added to the routes.
+	// +optional
+	Communities []string `json:"communities,omitempty"`
+
+	// LocalPreference is the local preference set on the routes.
+	// +optional
+	LocalPreference *uint32 `json:"localpreference,omitempty"`
 }
 
 // L3VNIStatus defines the observed state of L3VNI.
 type L3VNIStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Conditions are the conditions reported for the L3VNI. When the data
+	// path self test is enabled, each node reports a <node>/DataPathHealthy
+	// condition telling if the host side of the session is reachable from the
+	// router. When the VNIs are configured in best effort mode, each node
+	// reports a <node>/Configured condition telling if the VNI was set up.
+	// When the frr-k8s interoperability check is enabled, a
+	// FRRConfigurationConflict condition tells if an FRRConfiguration peers
+	// with the host session using mismatched ASNs.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:webhook:verbs=create;update,path=/validate-openperouter-io-v1alpha1-l3vni,mutating=false,failurePolicy=fail,groups=openpe.openperouter.github.io,resources=l3vnis,versions=v1alpha1,name=l3vnivalidationwebhook.openperouter.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-openperouter-io-v1alpha1-l3vni,mutating=false,failurePolicy=fail,groups=openpe.openperouter.github.io,resources=l3vnis,versions=v1alpha1,name=l3vnivalidationwebhook.openperouter.io,sideEffects=None,admissionReviewVersions=v1
 
 // L3VNI represents a VXLan L3VNI to receive EVPN type 5 routes
 // from.
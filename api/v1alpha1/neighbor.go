@@ -4,6 +4,15 @@ package v1alpha1
 
 import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+// The communities sent to a BGP neighbor.
+const (
+	SendCommunityStandard = "standard"
+	SendCommunityExtended = "extended"
+	SendCommunityLarge    = "large"
+	SendCommunityAll      = "all"
+	SendCommunityNone     = "none"
+)
+
 // Neighbor represents a BGP Neighbor we want FRR to connect to.
 // +kubebuilder:validation:XValidation:rule="!has(self.hostasn) || self.hostasn != self.asn",message="hostASN must be different from asn for eBGP"
 type Neighbor struct {
@@ -62,9 +71,42 @@ type Neighbor struct {
 	// +optional
 	EBGPMultiHop bool `json:"ebgpMultiHop,omitempty"`
 
+	// EBGPMultiHopTTL is the maximum number of hops the eBGP neighbor is away.
+	// Setting it implies EBGPMultiHop. Only valid for eBGP neighbors.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=255
+	// +optional
+	EBGPMultiHopTTL *uint8 `json:"ebgpMultiHopTTL,omitempty"`
+
+	// ExtendedNextHop advertises the extended next hop capability to the
+	// neighbor, to exchange the IPv4 routes with IPv6 next hops over an
+	// IPv6 session. It can be set only on IPv6 neighbors, and activates
+	// the IPv4 unicast address family for them. Defaults to false.
+	// +optional
+	ExtendedNextHop *bool `json:"extendedNextHop,omitempty"`
+
 	// BFD defines the BFD configuration for the BGP session.
 	// +optional
 	BFD *BFDSettings `json:"bfd,omitempty"`
+
+	// PeerGroup is the name of the peer group of the underlay the neighbor
+	// belongs to. The neighbor inherits the ASN and the timers of the group.
+	// +optional
+	PeerGroup *string `json:"peerGroup,omitempty"`
+
+	// SendCommunity tells which communities are sent to the neighbor:
+	// only the standard, extended or large ones, all of them, or none.
+	// Defaults to all.
+	// +kubebuilder:validation:Enum=standard;extended;large;all;none
+	// +optional
+	SendCommunity *string `json:"sendCommunity,omitempty"`
+
+	// Description is a free text identifying the neighbor in the
+	// output of vtysh. Only printable ASCII characters are allowed.
+	// +kubebuilder:validation:MaxLength=80
+	// +kubebuilder:validation:Pattern=`^[ -~]*$`
+	// +optional
+	Description *string `json:"description,omitempty"`
 }
 
 // BFDSettings defines the BFD configuration for a BGP session.
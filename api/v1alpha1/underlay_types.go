@@ -37,6 +37,40 @@ type UnderlaySpec struct {
 	// +kubebuilder:validation:MinItems=1
 	Neighbors []Neighbor `json:"neighbors,omitempty"`
 
+	// PeerGroups is the list of peer groups the neighbors can be assigned to,
+	// sharing the configuration of the group.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	PeerGroups []PeerGroupSpec `json:"peergroups,omitempty"`
+
+	// BestPath contains the options influencing the BGP best path
+	// selection of the router.
+	// +optional
+	BestPath *BestPathConfig `json:"bestpath,omitempty"`
+
+	// UpdateDelay is the time the router waits for its neighbors to
+	// converge after starting, before sending its first updates. It must
+	// be a whole number of seconds, up to one hour. When unset or zero,
+	// the updates are sent without waiting.
+	// +optional
+	UpdateDelay *metav1.Duration `json:"updatedelay,omitempty"`
+
+	// CoalesceTime is the time the router waits for, after the sessions
+	// are established, to group the initial updates sent to its neighbors.
+	// It must be a whole number of milliseconds, up to one hour. When unset
+	// or zero, the default of the router is used.
+	// +optional
+	CoalesceTime *metav1.Duration `json:"coalescetime,omitempty"`
+
+	// ExpectedNeighbors is the number of neighbors the router of each node
+	// is expected to have an established session with. When set, each node
+	// reports a <node>/NeighborsIncomplete condition on the Underlay, true
+	// when fewer sessions with the neighbors are established.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpectedNeighbors *int `json:"expectedneighbors,omitempty"`
+
 	// Nics is the list of physical nics to move under the PERouter namespace to connect
 	// to external routers. This field is optional when using Multus networks for TOR connectivity.
 	// +kubebuilder:validation:items:Pattern=`^[a-zA-Z][a-zA-Z0-9._-]*$`
@@ -46,21 +80,175 @@ type UnderlaySpec struct {
 	EVPN *EVPNConfig `json:"evpn,omitempty"`
 }
 
+// BestPathConfig contains the options of the BGP best path selection.
+type BestPathConfig struct {
+	// ASPathMultipathRelax allows load sharing across the paths with an
+	// AS path of the same length, even if received from different ASes.
+	// +optional
+	ASPathMultipathRelax bool `json:"aspathmultipathrelax,omitempty"`
+
+	// ASPathIgnore ignores the AS path length in the best path selection.
+	// It can't be set together with ASPathMultipathRelax.
+	// +optional
+	ASPathIgnore bool `json:"aspathignore,omitempty"`
+
+	// CompareRouterID compares the router ids of the neighbors to
+	// break the ties between identical paths received from them.
+	// +optional
+	CompareRouterID bool `json:"comparerouterid,omitempty"`
+
+	// MEDMissingAsWorst treats a missing MED as the worst possible value
+	// instead of the best one.
+	// +optional
+	MEDMissingAsWorst bool `json:"medmissingasworst,omitempty"`
+}
+
+// PeerGroupSpec defines a BGP peer group, carrying the
+// configuration shared by all the neighbors assigned to it.
+type PeerGroupSpec struct {
+	// Name is the name of the peer group. The names hosts-ipv4 and
+	// hosts-ipv6 are reserved for the dynamic host peers.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_-]*$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// ASN is the AS number of the neighbors of the group. A neighbor of
+	// the group can omit its ASN, or must set the same one.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4294967295
+	// +optional
+	ASN *uint32 `json:"asn,omitempty"`
+
+	// HoldTime is the requested BGP hold time of the neighbors of the group,
+	// unless overridden by the neighbor.
+	// +optional
+	HoldTime *metav1.Duration `json:"holdTime,omitempty"`
+
+	// KeepaliveTime is the requested BGP keepalive time of the neighbors of
+	// the group, unless overridden by the neighbor.
+	// +optional
+	KeepaliveTime *metav1.Duration `json:"keepaliveTime,omitempty"`
+}
+
 type EVPNConfig struct {
 	// VTEPCIDR is CIDR to be used to assign IPs to the local VTEP on each node.
 	// +required
 	VTEPCIDR string `json:"vtepcidr,omitempty"`
+
+	// VTEPCIDRv6 is the ipv6 CIDR to be used to assign an additional ipv6 address
+	// to the local VTEP on each node, for dual stack VTEPs. When set, VTEPCIDR
+	// must be an ipv4 CIDR, and its address is used as the source of the VXLan
+	// encapsulated packets.
+	// +optional
+	VTEPCIDRv6 *string `json:"vtepcidrv6,omitempty"`
+
+	// DSCP is the DSCP value set on the outer header of the VXLan
+	// encapsulated packets of all the VNIs, unless overridden by the VNI.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	// +optional
+	DSCP *uint8 `json:"dscp,omitempty"`
+
+	// VTEPMAC is the base MAC address the MAC of the VXLan devices and of the
+	// L3VNI bridges is derived from on each node, by adding the index of the
+	// node to it, so that each node gets a stable and unique MAC. If not set,
+	// the MAC addresses are assigned by the kernel.
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`
+	// +optional
+	VTEPMAC *string `json:"vtepmac,omitempty"`
+
+	// AdvertiseAllVNI makes the router advertise the MAC/IP and multicast
+	// routes of all the VNIs it knows of. When false, they are advertised
+	// only for the VNIs listed in AdvertisedVNIs, while the other routes, as
+	// the IP prefix routes of the L3VNIs, are still advertised. Defaults to true.
+	// +optional
+	AdvertiseAllVNI *bool `json:"advertiseallvni,omitempty"`
+
+	// AdvertisedVNIs are the VNIs of the L2VNIs whose routes are advertised
+	// when AdvertiseAllVNI is false. The routes of the other L2VNIs are kept
+	// local to the node. It can be set only when AdvertiseAllVNI is false.
+	// +listType=set
+	// +optional
+	AdvertisedVNIs []uint32 `json:"advertisedvnis,omitempty"`
+
+	// ExpectedVNIs is the list of the VNIs expected to be set up on every
+	// node. Each node reports a <node>/MissingVNI condition on the Underlay,
+	// listing the expected VNIs that are not configured or failed to be set
+	// up on the node.
+	// +listType=set
+	// +optional
+	ExpectedVNIs []uint32 `json:"expectedvnis,omitempty"`
+
+	// RTAutoDeriveASN is the ASN the route targets of the VNIs are derived
+	// from, as <ASN>:<VNI>, instead of the local ASN of the router. Setting the
+	// same ASN on all the routers of a fabric spanning multiple ASNs makes
+	// them import each other's routes. When greater than 65535, the VNIs
+	// must not be greater than 65535.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4294967295
+	// +optional
+	RTAutoDeriveASN *uint32 `json:"rtautoderiveasn,omitempty"`
+
+	// TXChecksum enables or disables the UDP checksum of the VXLan
+	// encapsulated packets of all the VNIs, unless overridden by the VNI.
+	// Disabling it saves the checksum computation on the NICs that can't
+	// offload it. It can't be disabled on an IPv6 VTEP. If not set, the
+	// default of the kernel is kept.
+	// +optional
+	TXChecksum *bool `json:"txchecksum,omitempty"`
+
+	// GRO enables or disables the generic receive offload of the VXLan
+	// devices of all the VNIs, unless overridden by the VNI.
+	// If not set, the default of the kernel is kept.
+	// +optional
+	GRO *bool `json:"gro,omitempty"`
+
+	// GSO enables or disables the generic segmentation offload of the VXLan
+	// devices of all the VNIs, unless overridden by the VNI.
+	// If not set, the default of the kernel is kept.
+	// +optional
+	GSO *bool `json:"gso,omitempty"`
+
+	// DuplicateAddressDetection configures the detection of the MAC and IP
+	// addresses moving between the VTEPs too often, as it happens with
+	// misconfigured hosts causing mobility loops. If not set, the defaults
+	// of the router are kept.
+	// +optional
+	DuplicateAddressDetection *DADConfig `json:"duplicateaddressdetection,omitempty"`
+}
+
+// DADConfig configures the EVPN duplicate address detection: an address
+// moving more than MaxMoves times within DetectionTime is flagged as
+// duplicate, and its following moves are not advertised anymore.
+type DADConfig struct {
+	// MaxMoves is the number of moves after which an address is flagged
+	// as duplicate, between 2 and 1000.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=1000
+	MaxMoves uint32 `json:"maxmoves"`
+
+	// DetectionTime is the time window the moves are counted in. It must
+	// be a whole number of seconds, between 2s and 30m.
+	DetectionTime metav1.Duration `json:"detectiontime"`
 }
 
 // UnderlayStatus defines the observed state of Underlay.
 type UnderlayStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Conditions are the conditions reported for the Underlay. Each node
+	// reports a <node>/NodeIndexChanged condition telling if the index of
+	// the node, and so its VTEP IP, changed since its router was configured,
+	// and, when ExpectedVNIs is set, a <node>/MissingVNI condition. When
+	// ExpectedNeighbors is set, each node reports a <node>/NeighborsIncomplete
+	// condition.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:webhook:verbs=create;update,path=/validate-openperouter-io-v1alpha1-underlay,mutating=false,failurePolicy=fail,groups=openpe.openperouter.github.io,resources=underlays,versions=v1alpha1,name=underlayvalidationwebhook.openperouter.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-openperouter-io-v1alpha1-underlay,mutating=false,failurePolicy=fail,groups=openpe.openperouter.github.io,resources=underlays,versions=v1alpha1,name=underlayvalidationwebhook.openperouter.io,sideEffects=None,admissionReviewVersions=v1
 
 // Underlay is the Schema for the underlays API.
 type Underlay struct {
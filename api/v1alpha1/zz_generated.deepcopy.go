@@ -75,9 +75,95 @@ func (in *BFDSettings) DeepCopy() *BFDSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BestPathConfig) DeepCopyInto(out *BestPathConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BestPathConfig.
+func (in *BestPathConfig) DeepCopy() *BestPathConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BestPathConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DADConfig) DeepCopyInto(out *DADConfig) {
+	*out = *in
+	out.DetectionTime = in.DetectionTime
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DADConfig.
+func (in *DADConfig) DeepCopy() *DADConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DADConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EVPNConfig) DeepCopyInto(out *EVPNConfig) {
 	*out = *in
+	if in.VTEPCIDRv6 != nil {
+		in, out := &in.VTEPCIDRv6, &out.VTEPCIDRv6
+		*out = new(string)
+		**out = **in
+	}
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.VTEPMAC != nil {
+		in, out := &in.VTEPMAC, &out.VTEPMAC
+		*out = new(string)
+		**out = **in
+	}
+	if in.AdvertiseAllVNI != nil {
+		in, out := &in.AdvertiseAllVNI, &out.AdvertiseAllVNI
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdvertisedVNIs != nil {
+		in, out := &in.AdvertisedVNIs, &out.AdvertisedVNIs
+		*out = make([]uint32, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedVNIs != nil {
+		in, out := &in.ExpectedVNIs, &out.ExpectedVNIs
+		*out = make([]uint32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RTAutoDeriveASN != nil {
+		in, out := &in.RTAutoDeriveASN, &out.RTAutoDeriveASN
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.TXChecksum != nil {
+		in, out := &in.TXChecksum, &out.TXChecksum
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GRO != nil {
+		in, out := &in.GRO, &out.GRO
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GSO != nil {
+		in, out := &in.GSO, &out.GSO
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DuplicateAddressDetection != nil {
+		in, out := &in.DuplicateAddressDetection, &out.DuplicateAddressDetection
+		*out = new(DADConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EVPNConfig.
@@ -90,9 +176,49 @@ func (in *EVPNConfig) DeepCopy() *EVPNConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EthernetSegment) DeepCopyInto(out *EthernetSegment) {
+	*out = *in
+	if in.ID != nil {
+		in, out := &in.ID, &out.ID
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.SysMAC != nil {
+		in, out := &in.SysMAC, &out.SysMAC
+		*out = new(string)
+		**out = **in
+	}
+	if in.ESI != nil {
+		in, out := &in.ESI, &out.ESI
+		*out = new(string)
+		**out = **in
+	}
+	if in.DFPreference != nil {
+		in, out := &in.DFPreference, &out.DFPreference
+		*out = new(uint16)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EthernetSegment.
+func (in *EthernetSegment) DeepCopy() *EthernetSegment {
+	if in == nil {
+		return nil
+	}
+	out := new(EthernetSegment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostMaster) DeepCopyInto(out *HostMaster) {
 	*out = *in
+	if in.DatapathType != nil {
+		in, out := &in.DatapathType, &out.DatapathType
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostMaster.
@@ -109,6 +235,41 @@ func (in *HostMaster) DeepCopy() *HostMaster {
 func (in *HostSession) DeepCopyInto(out *HostSession) {
 	*out = *in
 	out.LocalCIDR = in.LocalCIDR
+	if in.NextHopSelf != nil {
+		in, out := &in.NextHopSelf, &out.NextHopSelf
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NextHopSelfForce != nil {
+		in, out := &in.NextHopSelfForce, &out.NextHopSelfForce
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SendCommunity != nil {
+		in, out := &in.SendCommunity, &out.SendCommunity
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConnectTime != nil {
+		in, out := &in.ConnectTime, &out.ConnectTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HostAdvertise != nil {
+		in, out := &in.HostAdvertise, &out.HostAdvertise
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowASIn != nil {
+		in, out := &in.AllowASIn, &out.AllowASIn
+		*out = new(uint8)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostSession.
@@ -127,7 +288,7 @@ func (in *L2VNI) DeepCopyInto(out *L2VNI) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L2VNI.
@@ -191,13 +352,68 @@ func (in *L2VNISpec) DeepCopyInto(out *L2VNISpec) {
 	if in.HostMaster != nil {
 		in, out := &in.HostMaster, &out.HostMaster
 		*out = new(HostMaster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.L2GatewayIPs != nil {
 		in, out := &in.L2GatewayIPs, &out.L2GatewayIPs
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SuppressRA != nil {
+		in, out := &in.SuppressRA, &out.SuppressRA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Learning != nil {
+		in, out := &in.Learning, &out.Learning
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MulticastGroup != nil {
+		in, out := &in.MulticastGroup, &out.MulticastGroup
+		*out = new(string)
+		**out = **in
+	}
+	if in.StaticVTEPs != nil {
+		in, out := &in.StaticVTEPs, &out.StaticVTEPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.TXChecksum != nil {
+		in, out := &in.TXChecksum, &out.TXChecksum
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GRO != nil {
+		in, out := &in.GRO, &out.GRO
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GSO != nil {
+		in, out := &in.GSO, &out.GSO
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EthernetSegment != nil {
+		in, out := &in.EthernetSegment, &out.EthernetSegment
+		*out = new(EthernetSegment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MACAgeingTime != nil {
+		in, out := &in.MACAgeingTime, &out.MACAgeingTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AdvertiseHostRoutes != nil {
+		in, out := &in.AdvertiseHostRoutes, &out.AdvertiseHostRoutes
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L2VNISpec.
@@ -213,6 +429,13 @@ func (in *L2VNISpec) DeepCopy() *L2VNISpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *L2VNIStatus) DeepCopyInto(out *L2VNIStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L2VNIStatus.
@@ -230,7 +453,7 @@ func (in *L3Passthrough) DeepCopyInto(out *L3Passthrough) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -287,7 +510,7 @@ func (in *L3PassthroughList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *L3PassthroughSpec) DeepCopyInto(out *L3PassthroughSpec) {
 	*out = *in
-	out.HostSession = in.HostSession
+	in.HostSession.DeepCopyInto(&out.HostSession)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L3PassthroughSpec.
@@ -321,7 +544,7 @@ func (in *L3VNI) DeepCopyInto(out *L3VNI) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L3VNI.
@@ -380,6 +603,58 @@ func (in *L3VNISpec) DeepCopyInto(out *L3VNISpec) {
 	if in.HostSession != nil {
 		in, out := &in.HostSession, &out.HostSession
 		*out = new(HostSession)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImportVRFs != nil {
+		in, out := &in.ImportVRFs, &out.ImportVRFs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LeakToDefault != nil {
+		in, out := &in.LeakToDefault, &out.LeakToDefault
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdvertiseIPv4 != nil {
+		in, out := &in.AdvertiseIPv4, &out.AdvertiseIPv4
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdvertiseIPv6 != nil {
+		in, out := &in.AdvertiseIPv6, &out.AdvertiseIPv6
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FabricAdvertise != nil {
+		in, out := &in.FabricAdvertise, &out.FabricAdvertise
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.ConntrackBypassCIDRs != nil {
+		in, out := &in.ConntrackBypassCIDRs, &out.ConntrackBypassCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RouteMaps != nil {
+		in, out := &in.RouteMaps, &out.RouteMaps
+		*out = make([]RouteMapSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EVPNExportRouteMap != nil {
+		in, out := &in.EVPNExportRouteMap, &out.EVPNExportRouteMap
+		*out = new(string)
+		**out = **in
+	}
+	if in.RouterSVIAddress != nil {
+		in, out := &in.RouterSVIAddress, &out.RouterSVIAddress
+		*out = new(string)
 		**out = **in
 	}
 }
@@ -397,6 +672,13 @@ func (in *L3VNISpec) DeepCopy() *L3VNISpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *L3VNIStatus) DeepCopyInto(out *L3VNIStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new L3VNIStatus.
@@ -452,11 +734,36 @@ func (in *Neighbor) DeepCopyInto(out *Neighbor) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.EBGPMultiHopTTL != nil {
+		in, out := &in.EBGPMultiHopTTL, &out.EBGPMultiHopTTL
+		*out = new(uint8)
+		**out = **in
+	}
+	if in.ExtendedNextHop != nil {
+		in, out := &in.ExtendedNextHop, &out.ExtendedNextHop
+		*out = new(bool)
+		**out = **in
+	}
 	if in.BFD != nil {
 		in, out := &in.BFD, &out.BFD
 		*out = new(BFDSettings)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PeerGroup != nil {
+		in, out := &in.PeerGroup, &out.PeerGroup
+		*out = new(string)
+		**out = **in
+	}
+	if in.SendCommunity != nil {
+		in, out := &in.SendCommunity, &out.SendCommunity
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Neighbor.
@@ -469,13 +776,135 @@ func (in *Neighbor) DeepCopy() *Neighbor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PeerGroupSpec) DeepCopyInto(out *PeerGroupSpec) {
+	*out = *in
+	if in.ASN != nil {
+		in, out := &in.ASN, &out.ASN
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.HoldTime != nil {
+		in, out := &in.HoldTime, &out.HoldTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KeepaliveTime != nil {
+		in, out := &in.KeepaliveTime, &out.KeepaliveTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeerGroupSpec.
+func (in *PeerGroupSpec) DeepCopy() *PeerGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PeerGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMapMatch) DeepCopyInto(out *RouteMapMatch) {
+	*out = *in
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapMatch.
+func (in *RouteMapMatch) DeepCopy() *RouteMapMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteMapMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMapRule) DeepCopyInto(out *RouteMapRule) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(RouteMapMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = new(RouteMapSet)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapRule.
+func (in *RouteMapRule) DeepCopy() *RouteMapRule {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteMapRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMapSet) DeepCopyInto(out *RouteMapSet) {
+	*out = *in
+	if in.Communities != nil {
+		in, out := &in.Communities, &out.Communities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalPreference != nil {
+		in, out := &in.LocalPreference, &out.LocalPreference
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapSet.
+func (in *RouteMapSet) DeepCopy() *RouteMapSet {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteMapSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMapSpec) DeepCopyInto(out *RouteMapSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]RouteMapRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapSpec.
+func (in *RouteMapSpec) DeepCopy() *RouteMapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteMapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Underlay) DeepCopyInto(out *Underlay) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Underlay.
@@ -538,6 +967,33 @@ func (in *UnderlaySpec) DeepCopyInto(out *UnderlaySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PeerGroups != nil {
+		in, out := &in.PeerGroups, &out.PeerGroups
+		*out = make([]PeerGroupSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BestPath != nil {
+		in, out := &in.BestPath, &out.BestPath
+		*out = new(BestPathConfig)
+		**out = **in
+	}
+	if in.UpdateDelay != nil {
+		in, out := &in.UpdateDelay, &out.UpdateDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CoalesceTime != nil {
+		in, out := &in.CoalesceTime, &out.CoalesceTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExpectedNeighbors != nil {
+		in, out := &in.ExpectedNeighbors, &out.ExpectedNeighbors
+		*out = new(int)
+		**out = **in
+	}
 	if in.Nics != nil {
 		in, out := &in.Nics, &out.Nics
 		*out = make([]string, len(*in))
@@ -546,7 +1002,7 @@ func (in *UnderlaySpec) DeepCopyInto(out *UnderlaySpec) {
 	if in.EVPN != nil {
 		in, out := &in.EVPN, &out.EVPN
 		*out = new(EVPNConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -563,6 +1019,13 @@ func (in *UnderlaySpec) DeepCopy() *UnderlaySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnderlayStatus) DeepCopyInto(out *UnderlayStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnderlayStatus.
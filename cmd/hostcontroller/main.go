@@ -22,10 +22,12 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"runtime/debug"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
@@ -37,15 +39,21 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/go-logr/logr"
+	"github.com/openperouter/openperouter/api/static"
 	periov1alpha1 "github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/audit"
 	"github.com/openperouter/openperouter/internal/controller/routerconfiguration"
+	"github.com/openperouter/openperouter/internal/frr"
 	"github.com/openperouter/openperouter/internal/hostnetwork"
 	"github.com/openperouter/openperouter/internal/logging"
 	"github.com/openperouter/openperouter/internal/pods"
+	"github.com/openperouter/openperouter/internal/probes"
 	"github.com/openperouter/openperouter/internal/staticconfiguration"
 	"github.com/openperouter/openperouter/internal/systemdctl"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	// +kubebuilder:scaffold:imports
 )
@@ -71,13 +79,19 @@ type hostModeParameters struct {
 	k8sWaitInterval      time.Duration
 	hostContainerPidPath string
 	configuration        string
+	configurationSet     bool
+	configMapName        string
+	configMapNamespace   string
 	systemdSocketPath    string
+	routerUnitName       string
 }
 
 type k8sModeParameters struct {
-	nodeName  string
-	namespace string
-	criSocket string
+	nodeName                  string
+	namespace                 string
+	criSocket                 string
+	routerPodDeletionCooldown time.Duration
+	routerPodSelection        string
 }
 
 func main() {
@@ -85,29 +99,94 @@ func main() {
 	k8sModeParams := k8sModeParameters{}
 
 	args := struct {
-		probeAddr          string
-		tlsOpts            []func(*tls.Config)
-		logLevel           string
-		frrConfigPath      string
-		reloaderSocket     string
-		mode               string
-		underlayFromMultus bool
-		ovsSocketPath      string
+		probeAddr           string
+		probeMetrics        bool
+		tlsOpts             []func(*tls.Config)
+		logLevel            string
+		frrLogLevel         string
+		frrConfigPath       string
+		reloaderSocket      string
+		mode                string
+		underlayFromMultus  bool
+		ovsSocketPath       string
+		reconcileWorkers    int
+		dataPathSelfTest    bool
+		vniFailureThreshold int
+		vniFailureHoldDown  time.Duration
+		auditSink           string
+		auditFile           string
+		hostConfigEndpoint  bool
+		bestEffortVNIs      bool
+		vniTeardownDelay    time.Duration
+		underlayMultusNet   string
+		frrConfigMode       string
+		frrBGPListenLimit   int
+		resyncPeriod        time.Duration
+		refuseIndexChange   bool
+		cleanupOnExit       bool
+		deviceNamePrefix    string
+		frrConfigExportPath string
+		debugAddr           string
 	}{}
 
-	flag.StringVar(&args.probeAddr, "health-probe-bind-address", ":9081", "The address the probe endpoint binds to.")
+	flag.StringVar(&args.probeAddr, "health-probe-bind-address", ":9081",
+		"The address the probe endpoint binds to. An address with an ipv4 host, such as 0.0.0.0:9081, binds only ipv4 and one with an ipv6 host, such as [::]:9081, only ipv6")
+	flag.BoolVar(&args.probeMetrics, "probe-metrics", false,
+		"Whether to serve the metrics on the probe endpoint, for the nodes where the metrics server is not running")
 	flag.StringVar(&args.logLevel, "loglevel", "info", "the verbosity of the process")
+	flag.StringVar(&args.frrLogLevel, "frr-loglevel", "",
+		"the verbosity of frr, one of the frr log levels. If not set, loglevel is used")
 	flag.StringVar(&args.frrConfigPath, "frrconfig", "/etc/perouter/frr/frr.conf",
 		"the location of the frr configuration file")
+	flag.StringVar(&args.frrConfigMode, "frr-config-mode", frr.ConfigModeIntegrated,
+		"the mode the frr configuration is written in, integrated or split. In split mode, the configuration of each daemon is written in the directory of frrconfig")
+	flag.IntVar(&args.frrBGPListenLimit, "frr-bgp-listen-limit", 0,
+		"the maximum number of dynamic bgp neighbors the router accepts. If not set, the frr default is used")
 	flag.BoolVar(&args.underlayFromMultus, "underlay-from-multus", false, "Whether underlay access is built with Multus")
+	flag.StringVar(&args.underlayMultusNet, "underlay-multus-network", "",
+		"the Multus network providing the underlay, in the form <namespace>/<name> or <name>. Required when underlay-from-multus is set")
 	flag.StringVar(&args.ovsSocketPath, "ovssocket", "unix:/var/run/openvswitch/db.sock",
 		"the OVS database socket path")
 
 	flag.StringVar(&args.mode, "mode", modeK8s, "the mode to run in (k8s or host)")
+	flag.IntVar(&args.reconcileWorkers, "reconcile-workers", 1,
+		"the maximum number of concurrent reconciles of the router configuration. Applying the configuration to the node is serialized across them, only the reporting of the outcome runs concurrently, so values above 1 do not make applying it faster")
+	flag.DurationVar(&args.resyncPeriod, "resync-period", routerconfiguration.DefaultResyncPeriod,
+		"the interval the router configuration is reconciled at even without changes to the resources, to catch up with the changes of the router itself. Zero disables it")
+	flag.BoolVar(&args.refuseIndexChange, "refuse-node-index-change", false,
+		"fail the reconcile instead of reconfiguring the node when its index changes after the configuration was applied. The applied index is persisted next to the frr configuration, removing the node-index file accepts the new index")
+	flag.BoolVar(&args.cleanupOnExit, "cleanup-on-exit", false,
+		"remove the devices created in the router namespace and move the underlay interface back to the host when the controller exits. Meant for uninstalling, as the traffic of the node is disrupted")
+	flag.StringVar(&args.deviceNamePrefix, "device-name-prefix", "",
+		"the prefix of the names of the vxlan and bridge devices created for each vni")
+	flag.BoolVar(&args.dataPathSelfTest, "datapath-selftest", false,
+		"ping the host side of the session of each L3VNI from the router and report the result as a condition of the L3VNI")
+	flag.IntVar(&args.vniFailureThreshold, "vni-failure-threshold", 1,
+		"the number of consecutive reconciles a failure to set up a VNI must happen in before being reported")
+	flag.DurationVar(&args.vniFailureHoldDown, "vni-failure-hold-down", 0,
+		"the time a failure to set up a VNI must last for before being reported, if reached before vni-failure-threshold")
+	flag.BoolVar(&args.bestEffortVNIs, "best-effort-vnis", false,
+		"set up each VNI independently, reporting the failures as a condition of the VNIs, instead of stopping at the first failing one")
+	flag.DurationVar(&args.vniTeardownDelay, "vni-teardown-delay", 0,
+		"the time the devices of a removed VNI are kept for, after applying the frr configuration withdrawing its routes, to avoid blackholing the traffic still in flight")
+	flag.StringVar(&args.auditSink, "audit-sink", audit.SinkNone,
+		"where to emit a record of each configuration applied to the node, when it differs from the one applied before (file or events). If not set, no record is emitted")
+	flag.BoolVar(&args.hostConfigEndpoint, "hostconfig-endpoint", false,
+		"serve on the debug address, under "+routerconfiguration.HostConfigPath+", the host configuration computed for the node, for debugging")
+	flag.StringVar(&args.frrConfigExportPath, "frr-config-export-path", "",
+		"serve on the debug address, under "+routerconfiguration.FRRConfigExportPath+", an endpoint writing to this path the FRR configuration computed for the node, without reloading FRR")
+	flag.StringVar(&args.debugAddr, "debug-bind-address", routerconfiguration.DefaultDebugAddr,
+		"the address the debugging endpoints bind to, when any is enabled. It must be a loopback address, as the endpoints are not authenticated")
+	flag.StringVar(&args.auditFile, "audit-file", "",
+		"the path of the file the audit records are appended to, when audit-sink is file")
 
 	flag.StringVar(&k8sModeParams.nodeName, "nodename", "", "The name of the node the controller runs on")
 	flag.StringVar(&k8sModeParams.namespace, "namespace", "", "The namespace the controller runs in")
 	flag.StringVar(&k8sModeParams.criSocket, "crisocket", "/containerd.sock", "the location of the cri socket")
+	flag.DurationVar(&k8sModeParams.routerPodDeletionCooldown, "router-pod-deletion-cooldown", time.Minute,
+		"the minimum interval between two deletions of the router pod on non recoverable errors")
+	flag.StringVar(&k8sModeParams.routerPodSelection, "router-pod-selection", routerconfiguration.RouterPodSelectionReady,
+		"how to choose the router pod when more than one is found for the node: ready or newest")
 
 	flag.DurationVar(&hostModeParams.k8sWaitInterval, "k8s-wait-timeout", time.Minute,
 		"K8s API server waiting interval time")
@@ -117,15 +196,72 @@ func main() {
 		"the path of socket to trigger frr reload in the router container")
 	flag.StringVar(&hostModeParams.configuration, "host-configuration",
 		"/etc/openperouter/config.yaml", "the path of host configuration")
+	flag.StringVar(&hostModeParams.configMapName, "host-configuration-configmap", "",
+		"the name of the configmap holding the host configuration, used instead of host-configuration")
+	flag.StringVar(&hostModeParams.configMapNamespace, "host-configuration-configmap-namespace", "",
+		"the namespace of the configmap holding the host configuration")
 	flag.StringVar(&hostModeParams.systemdSocketPath, "systemd-socket",
 		systemdctl.HostDBusSocket, "the path of systemd control socket")
+	flag.StringVar(&hostModeParams.routerUnitName, "router-unit-name",
+		routerconfiguration.DefaultRouterUnitName, "the name of the systemd unit running the router")
 
 	flag.Parse()
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "host-configuration" {
+			hostModeParams.configurationSet = true
+		}
+	})
 
 	if err := validateParameters(args.mode, hostModeParams, k8sModeParams); err != nil {
 		fmt.Printf("validation error: %v\n", err)
 		os.Exit(1)
 	}
+	if args.reconcileWorkers < 1 {
+		fmt.Printf("validation error: reconcile-workers must be at least 1, got %d\n", args.reconcileWorkers)
+		os.Exit(1)
+	}
+	if args.resyncPeriod < 0 {
+		fmt.Printf("validation error: resync-period can't be negative, got %s\n", args.resyncPeriod)
+		os.Exit(1)
+	}
+	if args.vniFailureThreshold < 1 {
+		fmt.Printf("validation error: vni-failure-threshold must be at least 1, got %d\n", args.vniFailureThreshold)
+		os.Exit(1)
+	}
+	if args.vniFailureHoldDown < 0 {
+		fmt.Printf("validation error: vni-failure-hold-down can't be negative, got %s\n", args.vniFailureHoldDown)
+		os.Exit(1)
+	}
+	if args.vniTeardownDelay < 0 {
+		fmt.Printf("validation error: vni-teardown-delay can't be negative, got %s\n", args.vniTeardownDelay)
+		os.Exit(1)
+	}
+	if err := audit.ValidateSink(args.auditSink, args.auditFile); err != nil {
+		fmt.Printf("validation error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := routerconfiguration.ValidateUnderlayMultusNetwork(args.underlayFromMultus, args.underlayMultusNet); err != nil {
+		fmt.Printf("validation error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := frr.ValidateConfigMode(args.frrConfigMode); err != nil {
+		fmt.Printf("validation error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := frr.ValidateBGPListenLimit(args.frrBGPListenLimit); err != nil {
+		fmt.Printf("validation error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := hostnetwork.ValidateDeviceNamePrefix(args.deviceNamePrefix); err != nil {
+		fmt.Printf("validation error: %v\n", err)
+		os.Exit(1)
+	}
+	if args.frrLogLevel != "" {
+		if err := frr.ValidateLogLevel(args.frrLogLevel); err != nil {
+			fmt.Printf("validation error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	flag.Parse()
 
@@ -155,9 +291,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	if _, err := probes.ListenNetwork(args.probeAddr); err != nil {
+		setupLog.Error(err, "invalid health probe bind address")
+		os.Exit(1)
+	}
 	mgr, err := ctrl.NewManager(k8sConfig, ctrl.Options{
-		Scheme:                 scheme,
-		HealthProbeBindAddress: args.probeAddr,
+		Scheme: scheme,
+		// The probes are served by the probes server, honoring the family of
+		// the address, see below.
+		HealthProbeBindAddress: "0",
 		Cache:                  cache.Options{},
 	})
 	if err != nil {
@@ -175,48 +317,114 @@ func main() {
 	switch args.mode {
 	case modeK8s:
 		routerProvider = &routerconfiguration.RouterPodProvider{
-			FRRConfigPath: args.frrConfigPath,
-			PodRuntime:    podRuntime,
-			Client:        mgr.GetClient(),
-			Node:          k8sModeParams.nodeName,
+			FRRConfigPath:    args.frrConfigPath,
+			PodRuntime:       podRuntime,
+			Client:           mgr.GetClient(),
+			Node:             k8sModeParams.nodeName,
+			DeletionCooldown: k8sModeParams.routerPodDeletionCooldown,
+			PodSelection:     k8sModeParams.routerPodSelection,
 		}
 	case modeHost:
-		hostConfig, err := staticconfiguration.ReadFromFile(hostModeParams.configuration)
+		hostConfig, err := readHostConfiguration(mgr.GetAPIReader(), hostModeParams)
 		if err != nil {
-			setupLog.Error(err, "failed to load the static configuration file")
+			setupLog.Error(err, "failed to load the static configuration")
 			os.Exit(1)
 		}
-		routerProvider = &routerconfiguration.RouterHostProvider{
+		hostProvider := &routerconfiguration.RouterHostProvider{
 			FRRConfigPath:     args.frrConfigPath,
 			RouterPidFilePath: hostModeParams.hostContainerPidPath,
 			CurrentNodeIndex:  hostConfig.NodeIndex,
 			SystemdSocketPath: hostModeParams.systemdSocketPath,
+			RouterUnitName:    hostModeParams.routerUnitName,
 		}
+		if err := hostProvider.ValidateUnit(context.Background()); err != nil {
+			setupLog.Error(err, "failed to validate the router unit")
+			os.Exit(1)
+		}
+		routerProvider = hostProvider
+	}
+
+	var auditSink audit.Sink
+	switch args.auditSink {
+	case audit.SinkFile:
+		auditSink = &audit.FileSink{Path: args.auditFile}
+	case audit.SinkEvents:
+		auditSink = &audit.EventSink{Recorder: mgr.GetEventRecorderFor("openperouter-controller")}
 	}
 
-	if err = (&routerconfiguration.PERouterReconciler{
-		Client:             mgr.GetClient(),
-		Scheme:             mgr.GetScheme(),
-		MyNode:             k8sModeParams.nodeName,
-		LogLevel:           args.logLevel,
-		Logger:             logger,
-		MyNamespace:        k8sModeParams.namespace,
-		FRRConfigPath:      args.frrConfigPath,
-		FRRReloadSocket:    args.reloaderSocket,
-		RouterProvider:     routerProvider,
-		UnderlayFromMultus: args.underlayFromMultus,
-	}).SetupWithManager(mgr); err != nil {
+	reconciler := &routerconfiguration.PERouterReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		MyNode:              k8sModeParams.nodeName,
+		LogLevel:            args.logLevel,
+		FRRLogLevel:         args.frrLogLevel,
+		Logger:              logger,
+		MyNamespace:         k8sModeParams.namespace,
+		FRRConfigPath:       args.frrConfigPath,
+		FRRReloadSocket:     args.reloaderSocket,
+		RouterProvider:      routerProvider,
+		UnderlayFromMultus:  args.underlayFromMultus,
+		ReconcileWorkers:    args.reconcileWorkers,
+		DataPathSelfTest:    args.dataPathSelfTest,
+		VNIFailureThreshold: args.vniFailureThreshold,
+		VNIFailureHoldDown:  args.vniFailureHoldDown,
+		AuditSink:           auditSink,
+		BestEffortVNIs:      args.bestEffortVNIs,
+		VNITeardownDelay:    args.vniTeardownDelay,
+
+		UnderlayMultusNetwork: args.underlayMultusNet,
+		FRRConfigMode:         args.frrConfigMode,
+		FRRBGPListenLimit:     uint32(args.frrBGPListenLimit),
+		ResyncPeriod:          args.resyncPeriod,
+		RefuseNodeIndexChange: args.refuseIndexChange,
+		DeviceNamePrefix:      args.deviceNamePrefix,
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Underlay")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
+	debugHandlers := map[string]http.Handler{}
+	if args.hostConfigEndpoint {
+		debugHandlers[routerconfiguration.HostConfigPath] = &routerconfiguration.HostConfigHandler{
+			Reconciler: reconciler,
+		}
+	}
+	if args.frrConfigExportPath != "" {
+		debugHandlers[routerconfiguration.FRRConfigExportPath] = &routerconfiguration.FRRConfigExportHandler{
+			Reconciler: reconciler,
+			Path:       args.frrConfigExportPath,
+		}
+	}
+	if len(debugHandlers) > 0 {
+		debugServer, err := routerconfiguration.NewDebugServer(args.debugAddr, debugHandlers)
+		if err != nil {
+			setupLog.Error(err, "unable to create the debug server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(debugServer); err != nil {
+			setupLog.Error(err, "unable to set up the debug server")
+			os.Exit(1)
+		}
+	}
+
+	var gatherer prometheus.Gatherer
+	if args.probeMetrics {
+		gatherer = metrics.Registry
+	}
+	server, err := probes.NewServer(args.probeAddr, gatherer, map[string]healthz.Checker{
+		routerconfiguration.RouterNamespaceCheck: (&routerconfiguration.RouterNamespaceChecker{
+			Client:         mgr.GetClient(),
+			RouterProvider: routerProvider,
+		}).Check,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create the probes server")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	if err := mgr.Add(server); err != nil {
+		setupLog.Error(err, "unable to set up the probes server")
 		os.Exit(1)
 	}
 
@@ -225,6 +433,34 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if args.cleanupOnExit {
+		setupLog.Info("cleaning up the router configuration")
+		if err := cleanupRouter(routerProvider, args.deviceNamePrefix); err != nil {
+			setupLog.Error(err, "failed to clean up the router configuration")
+			os.Exit(1)
+		}
+	}
+}
+
+// cleanupRouterTimeout is the maximum time the cleanup of the
+// router configuration is allowed to take on exit.
+const cleanupRouterTimeout = 30 * time.Second
+
+// cleanupRouter removes the configuration of the router from the host.
+func cleanupRouter(provider routerconfiguration.RouterProvider, deviceNamePrefix string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupRouterTimeout)
+	defer cancel()
+
+	router, err := provider.New(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get router instance: %w", err)
+	}
+	targetNS, err := router.TargetNS(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve target namespace: %w", err)
+	}
+	return routerconfiguration.Cleanup(ctx, targetNS, deviceNamePrefix)
 }
 
 func waitForKubernetes(ctx context.Context, waitInterval time.Duration) (*rest.Config, error) {
@@ -279,6 +515,11 @@ func validateParameters(mode string, hostModeParams hostModeParameters, k8sModeP
 		if k8sModeParams.namespace == "" {
 			return fmt.Errorf("namespace is required in %s mode", modeK8s)
 		}
+		if k8sModeParams.routerPodSelection != routerconfiguration.RouterPodSelectionReady &&
+			k8sModeParams.routerPodSelection != routerconfiguration.RouterPodSelectionNewest {
+			return fmt.Errorf("invalid router-pod-selection %q, must be '%s' or '%s'", k8sModeParams.routerPodSelection,
+				routerconfiguration.RouterPodSelectionReady, routerconfiguration.RouterPodSelectionNewest)
+		}
 	}
 
 	if mode == modeHost {
@@ -291,7 +532,29 @@ func validateParameters(mode string, hostModeParams hostModeParameters, k8sModeP
 		if hostModeParams.hostContainerPidPath == "" {
 			return fmt.Errorf("pid-path is required in %s mode", modeHost)
 		}
+		if hostModeParams.routerUnitName == "" {
+			return fmt.Errorf("router-unit-name is required in %s mode", modeHost)
+		}
+		if hostModeParams.configMapName != "" && hostModeParams.configurationSet {
+			return fmt.Errorf("host-configuration and host-configuration-configmap are mutually exclusive")
+		}
+		if hostModeParams.configMapName != "" && hostModeParams.configMapNamespace == "" {
+			return fmt.Errorf("host-configuration-configmap-namespace is required when host-configuration-configmap is set")
+		}
+		if hostModeParams.configMapName == "" && hostModeParams.configMapNamespace != "" {
+			return fmt.Errorf("host-configuration-configmap-namespace requires host-configuration-configmap")
+		}
 	}
 
 	return nil
 }
+
+// readHostConfiguration reads the static configuration from the configmap
+// when one is configured, falling back to the configuration file otherwise.
+func readHostConfiguration(cli client.Reader, hostModeParams hostModeParameters) (*static.PERouterConfig, error) {
+	if hostModeParams.configMapName == "" {
+		return staticconfiguration.ReadFromFile(hostModeParams.configuration)
+	}
+	return staticconfiguration.ReadFromConfigMap(context.Background(), cli,
+		hostModeParams.configMapNamespace, hostModeParams.configMapName)
+}
@@ -26,19 +26,23 @@ import (
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/go-logr/logr"
+	frrk8sapi "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/open-policy-agent/cert-controller/pkg/rotator"
 	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/controller/frrk8sinterop"
 	"github.com/openperouter/openperouter/internal/controller/nodeindex"
 	"github.com/openperouter/openperouter/internal/conversion"
 	"github.com/openperouter/openperouter/internal/logging"
@@ -63,6 +67,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(frrk8sapi.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -75,11 +80,15 @@ func main() {
 		namespace                     string
 		logLevel                      string
 		webhookMode                   string
+		webhookKinds                  string
 		webhookPort                   int
 		disableCertRotation           bool
 		restartOnRotatorSecretRefresh bool
 		certDir                       string
 		certServiceName               string
+		webhookHealthAddr             string
+		frrk8sInterop                 bool
+		validateNADSubnets            bool
 	}{}
 
 	flag.StringVar(&args.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -99,7 +108,15 @@ func main() {
 	flag.StringVar(&args.certServiceName, "cert-service-name", "openpe-webhook-service",
 		"The service name used to generate the TLS cert's hostname")
 	flag.IntVar(&args.webhookPort, "webhook-port", 9443, "the port of the webhook service")
+	flag.StringVar(&args.webhookHealthAddr, "webhook-health-bind-address", "",
+		"The address the webhook health probes bind to. Leave empty to serve them on the webhook server.")
 	flag.StringVar(&args.webhookMode, "webhookmode", WebhookModeEnabled, "webhook mode: disabled, enabled, or webhookonly")
+	flag.StringVar(&args.webhookKinds, "webhook-kinds", "",
+		"Comma separated list of the kinds to validate: underlay, l3vni, l2vni, l3passthrough. Leave empty to validate all of them.")
+	flag.BoolVar(&args.frrk8sInterop, "frrk8s-interop", false,
+		"If set, the FRRConfigurations of frr-k8s peering with the host sessions of the l3vnis with mismatched ASNs are reported as a condition of the l3vnis. Requires the frr-k8s CRDs.")
+	flag.BoolVar(&args.validateNADSubnets, "validate-nad-subnets", false,
+		"If set, the l2vni webhook rejects the l2gatewayips outside of the subnets of the NetworkAttachmentDefinitions attached to the host bridge of the l2vni. Requires the NetworkAttachmentDefinition CRD.")
 
 	flag.Parse()
 
@@ -111,6 +128,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	webhookKinds, err := webhooks.ParseKinds(args.webhookKinds)
+	if err != nil {
+		setupLog.Error(err, "invalid webhook kinds")
+		os.Exit(1)
+	}
+
+	if args.webhookHealthAddr != "" {
+		if err := webhooks.ValidateBindAddress(args.webhookHealthAddr); err != nil {
+			setupLog.Error(err, "invalid webhook health bind address")
+			os.Exit(1)
+		}
+	}
+
 	logger, err := logging.New(args.logLevel)
 	if err != nil {
 		fmt.Println("unable to init logger", err)
@@ -132,10 +162,19 @@ func main() {
 		TLSOpts:       args.tlsOpts,
 	}
 
+	cacheOptions := cache.Options{}
+	if args.namespace != "" {
+		// the only configmap read is the one of the node allocations
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {Namespaces: map[string]cache.Config{args.namespace: {}}},
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:  scheme,
-		Cache:   cache.Options{},
-		Metrics: metricsServerOptions,
+		Scheme:                 scheme,
+		Cache:                  cacheOptions,
+		Metrics:                metricsServerOptions,
+		HealthProbeBindAddress: args.webhookHealthAddr,
 		WebhookServer: webhook.NewServer(
 			webhook.Options{
 				Port:    args.webhookPort,
@@ -144,6 +183,19 @@ func main() {
 		),
 	})
 
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	webhooksEnabled := args.webhookMode == WebhookModeEnabled || args.webhookMode == WebhookModeWebhookOnly
+	if webhooksEnabled && args.webhookHealthAddr != "" {
+		if err := webhooks.SetupHealthProbes(mgr); err != nil {
+			setupLog.Error(err, "unable to set up the webhook health probes")
+			os.Exit(1)
+		}
+	}
+
 	startListeners := make(chan struct{})
 	if !args.disableCertRotation && args.webhookMode != WebhookModeDisabled {
 		setupLog.Info("Starting certs generator")
@@ -163,29 +215,41 @@ func main() {
 		if args.webhookMode != WebhookModeWebhookOnly {
 			setupLog.Info("Starting controllers")
 			if err = (&nodeindex.NodesReconciler{
-				Client:   mgr.GetClient(),
-				Scheme:   mgr.GetScheme(),
-				LogLevel: args.logLevel,
-				Logger:   logger,
+				Client:    mgr.GetClient(),
+				Scheme:    mgr.GetScheme(),
+				LogLevel:  args.logLevel,
+				Logger:    logger,
+				Namespace: args.namespace,
 			}).SetupWithManager(signalHandlerContext, mgr); err != nil {
 				setupLog.Error(err, "unable to create controller", "controller", "NodeReconciler")
 				os.Exit(1)
 			}
+			if args.frrk8sInterop {
+				if err = (&frrk8sinterop.Reconciler{
+					Client: mgr.GetClient(),
+					Logger: logger,
+				}).SetupWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create controller", "controller", "FRRK8sInteropReconciler")
+					os.Exit(1)
+				}
+			}
 			// +kubebuilder:scaffold:builder
 		}
 
-		if args.webhookMode == WebhookModeEnabled || args.webhookMode == WebhookModeWebhookOnly {
+		if webhooksEnabled {
 			setupLog.Info("Starting webhooks")
 			if err := v1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
 				logger.Error("unable to add v1alpha1 scheme", "error", err)
 			}
 
-			err := setupWebhook(mgr, logger)
+			err := setupWebhook(mgr, logger, webhookKinds, args.validateNADSubnets)
 			if err != nil {
 				setupLog.Error(err, "unable to create", "webhooks")
 				os.Exit(1)
 			}
-			webhooks.SetupHealth(mgr)
+			if args.webhookHealthAddr == "" {
+				webhooks.SetupHealth(mgr)
+			}
 		}
 	}()
 
@@ -238,30 +302,21 @@ func setupCertRotation(notifyFinished chan struct{}, mgr manager.Manager, logger
 	return nil
 }
 
-func setupWebhook(mgr manager.Manager, logger *slog.Logger) error {
-	logger.Info("webhooks enabled")
+func setupWebhook(mgr manager.Manager, logger *slog.Logger, kinds []string, validateNADSubnets bool) error {
+	logger.Info("webhooks enabled", "kinds", kinds)
 
 	webhooks.Logger = logger
 	webhooks.WebhookClient = mgr.GetAPIReader()
-	webhooks.ValidateL3VNIs = conversion.ValidateL3VNIs
-	webhooks.ValidateL2VNIs = conversion.ValidateL2VNIs
-	webhooks.ValidateUnderlays = conversion.ValidateUnderlays
-	webhooks.ValidateL3Passthroughs = conversion.ValidatePassthrough
-
-	if err := webhooks.SetupL3VNI(mgr); err != nil {
-		logger.Error("unable to create the webook", "error", err, "webhook", "L3VNIs")
-		return err
-	}
-	if err := webhooks.SetupL2VNI(mgr); err != nil {
-		logger.Error("unable to create the webook", "error", err, "webhook", "L2VNIs")
-		return err
-	}
-	if err := webhooks.SetupUnderlay(mgr); err != nil {
-		logger.Error("unable to create the webook", "error", err, "webhook", "Underlays")
-		return err
+	webhooks.ValidateAll = conversion.ValidateAllJoined
+	if validateNADSubnets {
+		lister := webhooks.NADSubnetLister{Client: mgr.GetAPIReader()}
+		webhooks.ValidateNADSubnets = func(l2vnis []v1alpha1.L2VNI) error {
+			return conversion.ValidateL2VNIGatewaysInNADSubnets(l2vnis, lister)
+		}
 	}
-	if err := webhooks.SetupL3Passthrough(mgr); err != nil {
-		logger.Error("unable to create the webook", "error", err, "webhook", "L3Passthroughs")
+
+	if err := webhooks.Setup(mgr, kinds); err != nil {
+		logger.Error("unable to create the webooks", "error", err)
 		return err
 	}
 	return nil
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/openperouter/openperouter/internal/frr"
 	"github.com/openperouter/openperouter/internal/frrconfig"
 )
 
@@ -24,37 +25,71 @@ func TestHandler(t *testing.T) {
 		name       string
 		reloadMock func(string) error
 		method     string
+		mode       string
+		target     string
 		httpStatus int
 	}{
 		{
 			"succeeds",
 			reloadSucceeds,
 			http.MethodPost,
+			frr.ConfigModeIntegrated,
+			"/",
 			200,
 		},
 		{
 			"wrong method",
 			reloadSucceeds,
 			http.MethodGet,
+			frr.ConfigModeIntegrated,
+			"/",
 			http.StatusBadRequest,
 		},
 		{
 			"reload fails",
 			reloadFails,
 			http.MethodPost,
+			frr.ConfigModeIntegrated,
+			"/",
 			http.StatusInternalServerError,
 		},
+		{
+			"split succeeds",
+			reloadSucceeds,
+			http.MethodPost,
+			frr.ConfigModeSplit,
+			"/?mode=split",
+			200,
+		},
+		{
+			"split requested to integrated reloader",
+			reloadSucceeds,
+			http.MethodPost,
+			frr.ConfigModeIntegrated,
+			"/?mode=split",
+			http.StatusConflict,
+		},
+		{
+			"integrated requested to split reloader",
+			reloadSucceeds,
+			http.MethodPost,
+			frr.ConfigModeSplit,
+			"/",
+			http.StatusConflict,
+		},
 	}
 
 	t.Cleanup(func() {
 		updateConfig = frrconfig.Update
+		updateSplitConfig = frrconfig.UpdateSplit
 	})
 	for _, tc := range tests {
 		updateConfig = tc.reloadMock
+		updateSplitConfig = tc.reloadMock
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
-			req, _ := http.NewRequest(tc.method, "/", nil)
-			handler := http.HandlerFunc(reloadHandler("/etc/frr/frr.conf"))
+			req, _ := http.NewRequest(tc.method, tc.target, nil)
+			handler := http.HandlerFunc(reloadHandler("/etc/frr/frr.conf", tc.mode))
 
 			handler.ServeHTTP(w, req)
 			res := w.Result()
@@ -67,3 +102,58 @@ func TestHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNeighborsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		show       func() (string, error)
+		method     string
+		httpStatus int
+		body       string
+	}{
+		{
+			"succeeds",
+			func() (string, error) { return `{"192.168.11.2":{}}`, nil },
+			http.MethodGet,
+			http.StatusOK,
+			`{"192.168.11.2":{}}`,
+		},
+		{
+			"wrong method",
+			func() (string, error) { return "", nil },
+			http.MethodPost,
+			http.StatusBadRequest,
+			"",
+		},
+		{
+			"vtysh fails",
+			func() (string, error) { return "", errors.New("failed") },
+			http.MethodGet,
+			http.StatusInternalServerError,
+			"",
+		},
+	}
+
+	t.Cleanup(func() {
+		showNeighbors = frrconfig.ShowNeighbors
+	})
+	for _, tc := range tests {
+		showNeighbors = tc.show
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, frrconfig.NeighborsPath, nil)
+
+			neighborsHandler(w, req)
+			res := w.Result()
+			if err := res.Body.Close(); err != nil {
+				t.Fatalf("Body.Close() failed: %s", err)
+			}
+			if res.StatusCode != tc.httpStatus {
+				t.Fatalf("expecting %d, got %d", tc.httpStatus, res.StatusCode)
+			}
+			if tc.body != "" && w.Body.String() != tc.body {
+				t.Fatalf("expecting body %q, got %q", tc.body, w.Body.String())
+			}
+		})
+	}
+}
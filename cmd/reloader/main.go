@@ -29,10 +29,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/debug"
 	"syscall"
 	"time"
 
+	"github.com/openperouter/openperouter/internal/frr"
 	"github.com/openperouter/openperouter/internal/frrconfig"
 	"github.com/openperouter/openperouter/internal/logging"
 )
@@ -41,6 +43,7 @@ type Args struct {
 	unixSocket    string
 	logLevel      string
 	frrConfigPath string
+	frrConfigMode string
 }
 
 func main() {
@@ -48,6 +51,7 @@ func main() {
 	flag.StringVar(&args.unixSocket, "unixsocket", "", "Unix socket path to listen on")
 	flag.StringVar(&args.logLevel, "loglevel", "info", "The log level of the process")
 	flag.StringVar(&args.frrConfigPath, "frrconfig", "/etc/frr/frr.conf", "The path the frr configuration is at")
+	flag.StringVar(&args.frrConfigMode, "frr-config-mode", frr.ConfigModeIntegrated, "The mode the frr configuration is written in, integrated or split. In split mode, the configuration of each daemon is in the directory of the frrconfig path")
 	flag.Parse()
 
 	_, err := logging.New(args.logLevel)
@@ -59,6 +63,10 @@ func main() {
 		fmt.Println("error: unixsocket parameter is required")
 		os.Exit(1)
 	}
+	if err := frr.ValidateConfigMode(args.frrConfigMode); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
 
 	build, _ := debug.ReadBuildInfo()
 	slog.Info("version", "version", build.Main.Version)
@@ -79,7 +87,8 @@ func serveReload(args Args) error {
 		return fmt.Errorf("failed to listen on unix socket %s: %w", args.unixSocket, err)
 	}
 
-	http.HandleFunc("/", reloadHandler(args.frrConfigPath))
+	http.HandleFunc("/", reloadHandler(args.frrConfigPath, args.frrConfigMode))
+	http.HandleFunc(frrconfig.NeighborsPath, neighborsHandler)
 
 	server := &http.Server{
 		ReadHeaderTimeout: 3 * time.Second,
@@ -118,16 +127,38 @@ func serveReload(args Args) error {
 	}
 }
 
-var updateConfig = frrconfig.Update
+var (
+	updateConfig      = frrconfig.Update
+	updateSplitConfig = frrconfig.UpdateSplit
+	showNeighbors     = frrconfig.ShowNeighbors
+)
 
-func reloadHandler(frrConfigPath string) func(w http.ResponseWriter, req *http.Request) {
+// reloadHandler reloads the frr configuration written in the given mode.
+// Requests for a different mode are refused, as the files they wrote are
+// not the ones that would be reloaded. A request with no mode is assumed
+// to be for the integrated one.
+func reloadHandler(frrConfigPath, mode string) func(w http.ResponseWriter, req *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != http.MethodPost {
 			http.Error(w, "invalid method", http.StatusBadRequest)
 			return
 		}
-		slog.Info("reload handler", "event", "received request")
-		err := updateConfig(frrConfigPath)
+		requestMode := req.URL.Query().Get("mode")
+		if requestMode == "" {
+			requestMode = frr.ConfigModeIntegrated
+		}
+		if requestMode != mode {
+			http.Error(w, fmt.Sprintf("requested a reload in %s mode, reloader is in %s mode", requestMode, mode), http.StatusConflict)
+			return
+		}
+		slog.Info("reload handler", "event", "received request", "mode", mode)
+		var err error
+		switch mode {
+		case frr.ConfigModeSplit:
+			err = updateSplitConfig(filepath.Dir(frrConfigPath))
+		default:
+			err = updateConfig(frrConfigPath)
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -136,3 +167,21 @@ func reloadHandler(frrConfigPath string) func(w http.ResponseWriter, req *http.R
 		slog.Info("reload handler", "event", "reload successful")
 	}
 }
+
+// neighborsHandler returns the state of the BGP neighbors of the router,
+// for the controller to report the sessions that are not established.
+func neighborsHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusBadRequest)
+		return
+	}
+	neighbors, err := showNeighbors()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write([]byte(neighbors)); err != nil {
+		slog.Error("neighbors handler", "event", "failed to write the response", "error", err)
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// verify is a read-only preflight checking that the configuration of the
+// whole cluster is consistent, exiting with 1 if it is not.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/verify"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	var timeout time.Duration
+	flag.DurationVar(&timeout, "timeout", 30*time.Second, "the time allowed to read the cluster")
+	flag.Parse()
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get the kubernetes configuration: %v\n", err)
+		os.Exit(2)
+	}
+	cli, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the kubernetes client: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = verify.Run(ctx, cli, os.Stdout)
+	if errors.Is(err, verify.ErrInconsistent) {
+		cancel()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to verify the cluster: %v\n", err)
+		cancel()
+		os.Exit(2)
+	}
+}
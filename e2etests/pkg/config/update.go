@@ -124,12 +124,14 @@ func (o Updater) Update(r Resources) error {
 }
 
 // CleanAll deletes all relevant resources in the namespace.
+// The underlays are deleted last, as their deletion is denied while
+// any VNI or passthrough still exists.
 func (o Updater) CleanAll() error {
-	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.Underlay{},
-		client.InNamespace(o.namespace)); err != nil {
+	if err := o.CleanButUnderlay(); err != nil {
 		return err
 	}
-	if err := o.CleanButUnderlay(); err != nil {
+	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.Underlay{},
+		client.InNamespace(o.namespace)); err != nil {
 		return err
 	}
 	return nil
@@ -139,11 +141,13 @@ func (o Updater) CleanAll() error {
 // This is needed as deleting underlays is a time consuming operation that
 // will cause the router pods to be recreated.
 func (o Updater) CleanButUnderlay() error {
-	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.L3VNI{},
+	// The l2vnis go first, as deleting an l3vni is denied while
+	// any l2vni is still attached to its vrf.
+	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.L2VNI{},
 		client.InNamespace(o.namespace)); err != nil {
 		return err
 	}
-	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.L2VNI{},
+	if err := o.cli.DeleteAllOf(context.Background(), &v1alpha1.L3VNI{},
 		client.InNamespace(o.namespace)); err != nil {
 		return err
 	}
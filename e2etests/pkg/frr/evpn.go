@@ -56,6 +56,37 @@ func (e *EVPNData) ContainsType5RouteForVNI(prefix string, vtep string, vni int)
 	return false
 }
 
+// ContainsType5RouteForPrefix tells if the given prefix is received
+// as type 5 route on the given vni, regardless of its next hop.
+func (e *EVPNData) ContainsType5RouteForPrefix(prefix string, vni int) bool {
+	for _, entry := range e.Entries {
+		for _, prefixEntry := range entry.Prefixes {
+			for _, path := range prefixEntry.Paths {
+				routePrefix := fmt.Sprintf("%s/%d", path.IP, path.IPLen)
+				if routePrefix == prefix && vniFromExtendedCommunity(path.ExtendedCommunity.String) == vni {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ContainsRouteTypeForVNI tells if any route of the given
+// type is received on the given vni.
+func (e *EVPNData) ContainsRouteTypeForVNI(routeType int, vni int) bool {
+	for _, entry := range e.Entries {
+		for _, prefixEntry := range entry.Prefixes {
+			for _, path := range prefixEntry.Paths {
+				if path.RouteType == routeType && vniFromExtendedCommunity(path.ExtendedCommunity.String) == vni {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 type RdEntry struct {
 	RD       string            `json:"rd"`
 	Prefixes map[string]Prefix `json:"-"` // handled manually
@@ -5,6 +5,7 @@ package frrk8s
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	frrk8sapi "github.com/metallb/frr-k8s/api/v1beta1"
 	"github.com/openperouter/openperouter/api/v1alpha1"
@@ -97,6 +98,47 @@ func AdvertisePrefixes(prefixes ...string) func(frrConfig *frrk8sapi.FRRConfigur
 	}
 }
 
+// PrependASPath prepends the given ASN to the AS path of the routes advertised
+// to the router, as if they were learned from another site using that ASN.
+func PrependASPath(asn uint32) func(frrConfig *frrk8sapi.FRRConfiguration) {
+	return func(frrConfig *frrk8sapi.FRRConfiguration) {
+		router := frrConfig.Spec.BGP.Routers[0]
+		neighbor := router.Neighbors[0].Address
+		family := "ipv4"
+		if strings.Contains(neighbor, ":") {
+			family = "ipv6"
+		}
+		frrConfig.Spec.Raw = frrk8sapi.RawConfig{
+			Priority: 10,
+			Config: fmt.Sprintf(`router bgp %[1]d
+ address-family %[2]s unicast
+  neighbor %[3]s route-map prepend-as-path out
+ exit-address-family
+exit
+route-map prepend-as-path permit 10
+ set as-path prepend %[4]d
+exit
+`, router.ASN, family, neighbor, asn),
+		}
+	}
+}
+
+// ExtendedNextHop makes the session with an IPv6 router carry the IPv4
+// routes too, with an IPv6 next hop.
+func ExtendedNextHop() func(frrConfig *frrk8sapi.FRRConfiguration) {
+	return func(frrConfig *frrk8sapi.FRRConfiguration) {
+		router := frrConfig.Spec.BGP.Routers[0]
+		frrConfig.Spec.BGP.Routers[0].Neighbors[0].DualStackAddressFamily = true
+		frrConfig.Spec.Raw = frrk8sapi.RawConfig{
+			Priority: 10,
+			Config: fmt.Sprintf(`router bgp %d
+ neighbor %s capability extended-nexthop
+exit
+`, router.ASN, router.Neighbors[0].Address),
+		}
+	}
+}
+
 func Pods(cs clientset.Interface) ([]*corev1.Pod, error) {
 	return k8s.PodsForLabel(cs, Namespace, frrk8sLabelSelector)
 }
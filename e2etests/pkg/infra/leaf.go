@@ -58,6 +58,8 @@ type Addresses struct {
 	RedistributeConnected bool
 	IPV4                  []string
 	IPV6                  []string
+	// Communities are the communities set on the advertised prefixes.
+	Communities []string
 }
 
 type Leaf struct {
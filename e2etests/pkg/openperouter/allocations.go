@@ -0,0 +1,39 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package openperouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const allocationsConfigMap = "openpe-node-indexes"
+
+// NodeAllocation is the allocation of a node, as published by the nodemarker.
+type NodeAllocation struct {
+	Index    int    `json:"index"`
+	VTEPIP   string `json:"vtepIP,omitempty"`
+	VTEPIPv6 string `json:"vtepIPv6,omitempty"`
+}
+
+// NodeAllocations returns the allocations published by the nodemarker,
+// keyed by node name.
+func NodeAllocations(cs clientset.Interface) (map[string]NodeAllocation, error) {
+	cm, err := cs.CoreV1().ConfigMaps(Namespace).Get(context.Background(), allocationsConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s: %w", allocationsConfigMap, err)
+	}
+	res := map[string]NodeAllocation{}
+	for node, data := range cm.Data {
+		var allocation NodeAllocation
+		if err := json.Unmarshal([]byte(data), &allocation); err != nil {
+			return nil, fmt.Errorf("failed to parse the allocation %q of node %s: %w", data, node, err)
+		}
+		res[node] = allocation
+	}
+	return res, nil
+}
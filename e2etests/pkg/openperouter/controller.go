@@ -0,0 +1,32 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package openperouter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/openperouter/openperouter/e2etests/pkg/k8s"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const controllerLabelSelector = "app=controller"
+
+// ControllerHasArg tells if the controller pods run with the given argument.
+func ControllerHasArg(cs clientset.Interface, arg string) (bool, error) {
+	pods, err := k8s.PodsForLabel(cs, Namespace, controllerLabelSelector)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve controller pods %w", err)
+	}
+	if len(pods) == 0 {
+		return false, nil
+	}
+	for _, p := range pods {
+		for _, c := range p.Spec.Containers {
+			if c.Name == "controller" && !slices.Contains(c.Args, arg) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	"github.com/openperouter/openperouter/e2etests/pkg/config"
 	"github.com/openperouter/openperouter/e2etests/pkg/executor"
+	"github.com/openperouter/openperouter/e2etests/pkg/frr"
 	"github.com/openperouter/openperouter/e2etests/pkg/infra"
 	"github.com/openperouter/openperouter/e2etests/pkg/ipfamily"
 	"github.com/openperouter/openperouter/e2etests/pkg/k8s"
@@ -330,6 +331,604 @@ var _ = Describe("Routes between bgp and the fabric", Ordered, func() {
 			},
 		}),
 	)
+
+	It("does not send router advertisements when suppressed", func() {
+		const (
+			gatewayIP = "fd00:10:245:3::1/64"
+			podIP     = "fd00:10:245:3::2/64"
+		)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedWithGateway := l2VniRed.DeepCopy()
+		l2VniRedWithGateway.Spec.L2GatewayIPs = []string{gatewayIP}
+		l2VniRedWithGateway.Spec.SuppressRA = ptr.To(true)
+
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedWithGateway,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8s.CreateNamespace(cs, testNamespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		nad, err = k8s.CreateMacvlanNad("110", testNamespace, "br-hs-110", []string{gatewayIP})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.DeleteNamespace(cs, testNamespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the pod")
+		firstPod, err = k8s.CreateAgnhostPod(cs, "pod1", testNamespace, k8s.WithNad(nad.Name, testNamespace, []string{podIP}), k8s.OnNode(nodes[0].Name))
+		Expect(err).NotTo(HaveOccurred())
+		podExecutor := executor.ForPod(firstPod.Namespace, firstPod.Name, "agnhost")
+
+		By("checking no routes are learned from router advertisements")
+		Consistently(func(g Gomega) {
+			res, err := podExecutor.Exec("ip", "-6", "route", "show", "proto", "ra")
+			g.Expect(err).NotTo(HaveOccurred(), res)
+			g.Expect(strings.TrimSpace(res)).To(BeEmpty())
+		}, 15*time.Second, time.Second).Should(Succeed())
+
+		By("checking the pod keeps its assigned ipv6 address")
+		res, err := podExecutor.Exec("ip", "-6", "address", "show")
+		Expect(err).NotTo(HaveOccurred(), res)
+		Expect(res).To(ContainSubstring(podIP))
+	})
+
+	It("toggles the vxlan learning mode", func() {
+		const multicastGroup = "239.1.1.110"
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		checkVXLanLearning := func(learning bool) {
+			GinkgoHelper()
+			Eventually(func() error {
+				for exec := range routers.GetExecutors() {
+					res, err := exec.Exec("ip", "-d", "link", "show", "vni110")
+					if err != nil {
+						return fmt.Errorf("failed to get vni110 on router %s: %s: %w", exec.Name(), res, err)
+					}
+					hasNoLearning := strings.Contains(res, "nolearning")
+					hasGroup := strings.Contains(res, "group "+multicastGroup)
+					if learning && (hasNoLearning || !hasGroup) {
+						return fmt.Errorf("expected learning with group %s on router %s, got %s", multicastGroup, exec.Name(), res)
+					}
+					if !learning && (!hasNoLearning || hasGroup) {
+						return fmt.Errorf("expected nolearning without group on router %s, got %s", exec.Name(), res)
+					}
+				}
+				return nil
+			}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+		}
+
+		By("enabling learning on the l2vni")
+		l2VniRedLearning := l2VniRed.DeepCopy()
+		l2VniRedLearning.Spec.Learning = ptr.To(true)
+		l2VniRedLearning.Spec.MulticastGroup = ptr.To(multicastGroup)
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedLearning,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		checkVXLanLearning(true)
+
+		By("disabling learning on the l2vni")
+		err = Updater.Update(config.Resources{
+			L2VNIs: []v1alpha1.L2VNI{
+				l2VniRed,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		checkVXLanLearning(false)
+	})
+
+	It("sets the datapath type of the autocreated ovs bridge", func() {
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		l2VniRedNetdev := l2VniRed.DeepCopy()
+		l2VniRedNetdev.Spec.HostMaster = &v1alpha1.HostMaster{
+			AutoCreate:   true,
+			Type:         "ovs-bridge",
+			DatapathType: ptr.To(v1alpha1.OVSDatapathNetdev),
+		}
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedNetdev,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		bridgeName := fmt.Sprintf("br-hs-%d", l2VniRed.Spec.VNI)
+		for _, nodeName := range []string{infra.KindControlPlane, infra.KindWorker} {
+			exec := executor.ForContainer(nodeName)
+			Eventually(func() error {
+				res, err := exec.Exec("ovs-vsctl", "get", "bridge", bridgeName, "datapath_type")
+				if err != nil {
+					return fmt.Errorf("failed to get the datapath type of %s on %s: %s: %w", bridgeName, nodeName, res, err)
+				}
+				if got := strings.Trim(strings.TrimSpace(res), `"`); got != v1alpha1.OVSDatapathNetdev {
+					return fmt.Errorf("expected datapath type %s for %s on %s, got %s", v1alpha1.OVSDatapathNetdev, bridgeName, nodeName, got)
+				}
+				return nil
+			}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+		}
+	})
+
+	It("connects two pods on different nodes in flood and learn mode", func() {
+		const (
+			multicastGroup = "239.1.1.110"
+			firstPodIP     = "192.171.25.2/24"
+			secondPodIP    = "192.171.25.3/24"
+		)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedLearning := l2VniRed.DeepCopy()
+		l2VniRedLearning.Spec.Learning = ptr.To(true)
+		l2VniRedLearning.Spec.MulticastGroup = ptr.To(multicastGroup)
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedLearning,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8s.CreateNamespace(cs, testNamespace)
+		Expect(err).NotTo(HaveOccurred())
+		learningNad, err := k8s.CreateMacvlanNad("110", testNamespace, "br-hs-110", nil)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.DeleteNamespace(cs, testNamespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		By("checking the vxlan interfaces join the group on the underlay interface")
+		underlayNic := infra.Underlay.Spec.Nics[0]
+		Eventually(func() error {
+			for exec := range routers.GetExecutors() {
+				res, err := exec.Exec("ip", "-d", "link", "show", "vni110")
+				if err != nil {
+					return fmt.Errorf("failed to get vni110 on router %s: %s: %w", exec.Name(), res, err)
+				}
+				if !strings.Contains(res, fmt.Sprintf("group %s dev %s", multicastGroup, underlayNic)) {
+					return fmt.Errorf("expected group %s on %s on router %s, got %s", multicastGroup, underlayNic, exec.Name(), res)
+				}
+			}
+			return nil
+		}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(nodes)).To(BeNumerically(">=", 2), "Expected at least 2 nodes, but got fewer")
+
+		By("creating the pods on different nodes")
+		pod1, err := k8s.CreateAgnhostPod(cs, "pod1", testNamespace, k8s.WithNad(learningNad.Name, testNamespace, []string{firstPodIP}), k8s.OnNode(nodes[0].Name))
+		Expect(err).NotTo(HaveOccurred())
+		pod2, err := k8s.CreateAgnhostPod(cs, "pod2", testNamespace, k8s.WithNad(learningNad.Name, testNamespace, []string{secondPodIP}), k8s.OnNode(nodes[1].Name))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the pods reach each other")
+		for _, p := range []struct {
+			pod *corev1.Pod
+			to  string
+		}{
+			{pod: pod1, to: secondPodIP},
+			{pod: pod2, to: firstPodIP},
+		} {
+			podExec := executor.ForPod(p.pod.Namespace, p.pod.Name, "agnhost")
+			Eventually(func() error {
+				res, err := podExec.Exec("ping", "-c", "1", "-W", "1", discardAddressLength(p.to))
+				if err != nil {
+					return fmt.Errorf("failed to ping %s from %s: %s: %w", p.to, p.pod.Name, res, err)
+				}
+				return nil
+			}, time.Minute, time.Second).Should(Succeed())
+		}
+	})
+
+	It("reaches the overlay from the host while keeping its default route when policy routing is managed", func() {
+		const (
+			gatewayIP = "192.171.24.1/24"
+			hostIP    = "192.171.24.10"
+		)
+
+		redistributeConnectedForLeaf(infra.LeafAConfig)
+		redistributeConnectedForLeaf(infra.LeafBConfig)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedWithPolicyRouting := l2VniRed.DeepCopy()
+		l2VniRedWithPolicyRouting.Spec.L2GatewayIPs = []string{gatewayIP}
+		l2VniRedWithPolicyRouting.Spec.ManagePolicyRouting = true
+
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedWithPolicyRouting,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+		nodeExec := executor.ForContainer(nodes[0].Name)
+
+		DeferCleanup(func() {
+			removeLeafPrefixes(infra.LeafAConfig)
+			removeLeafPrefixes(infra.LeafBConfig)
+			dumpIfFails(cs)
+			_, _ = nodeExec.Exec("ip", "address", "del", hostIP+"/24", "dev", "br-hs-110")
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		By("assigning an overlay address to the host bridge")
+		Eventually(func() error {
+			res, err := nodeExec.Exec("ip", "address", "replace", hostIP+"/24", "dev", "br-hs-110")
+			if err != nil {
+				return fmt.Errorf("failed to assign %s to br-hs-110: %s: %w", hostIP, res, err)
+			}
+			return nil
+		}, time.Minute, time.Second).Should(Succeed())
+
+		By("checking the policy routing rule is installed")
+		Eventually(func(g Gomega) {
+			res, err := nodeExec.Exec("ip", "rule", "show")
+			g.Expect(err).NotTo(HaveOccurred(), res)
+			g.Expect(res).To(ContainSubstring("from 192.171.24.0/24"))
+		}, time.Minute, time.Second).Should(Succeed())
+
+		By("checking the host default route is preserved")
+		res, err := nodeExec.Exec("ip", "route", "show", "default")
+		Expect(err).NotTo(HaveOccurred(), res)
+		Expect(strings.TrimSpace(res)).NotTo(BeEmpty())
+		Expect(res).NotTo(ContainSubstring("br-hs-110"))
+
+		By("reaching hostARed sourcing from the overlay address")
+		hostPort := net.JoinHostPort(infra.HostARedIPv4, "8090")
+		Eventually(func(g Gomega) string {
+			res, err := nodeExec.Exec("curl", "-sS", "--interface", hostIP, url.Format("http://%s/clientip", hostPort))
+			g.Expect(err).ToNot(HaveOccurred(), "curl %s failed: %s", hostPort, res)
+			clientIP, _, err := net.SplitHostPort(res)
+			g.Expect(err).ToNot(HaveOccurred())
+			return clientIP
+		}, 30*time.Second, time.Second).Should(Equal(hostIP))
+	})
+
+	It("reaches the overlay from the pods while keeping their default route when policy routing is managed", func() {
+		const (
+			gatewayIP = "192.171.24.1/24"
+			podIP     = "192.171.24.2"
+		)
+
+		redistributeConnectedForLeaf(infra.LeafAConfig)
+		redistributeConnectedForLeaf(infra.LeafBConfig)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedWithPolicyRouting := l2VniRed.DeepCopy()
+		l2VniRedWithPolicyRouting.Spec.L2GatewayIPs = []string{gatewayIP}
+		l2VniRedWithPolicyRouting.Spec.ManagePolicyRouting = true
+
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedWithPolicyRouting,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8s.CreateNamespace(cs, testNamespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		// no gateway is passed, so that the pod keeps its default route via the primary interface.
+		nad, err = k8s.CreateMacvlanNad("110", testNamespace, "br-hs-110", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			removeLeafPrefixes(infra.LeafAConfig)
+			removeLeafPrefixes(infra.LeafBConfig)
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.DeleteNamespace(cs, testNamespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the pod")
+		pod, err := k8s.CreateAgnhostPod(cs, "pod1", testNamespace, k8s.WithNad(nad.Name, testNamespace, []string{podIP + "/24"}), k8s.OnNode(nodes[0].Name))
+		Expect(err).NotTo(HaveOccurred())
+		podExecutor := executor.ForPod(pod.Namespace, pod.Name, "agnhost")
+
+		By("checking the policy routing rule is installed in the pod")
+		Eventually(func(g Gomega) {
+			res, err := podExecutor.Exec("ip", "rule", "show")
+			g.Expect(err).NotTo(HaveOccurred(), res)
+			g.Expect(res).To(ContainSubstring("from 192.171.24.0/24"))
+		}, time.Minute, time.Second).Should(Succeed())
+
+		By("checking the pod default route is preserved")
+		res, err := podExecutor.Exec("ip", "route", "show", "default")
+		Expect(err).NotTo(HaveOccurred(), res)
+		Expect(res).To(ContainSubstring("dev eth0"))
+
+		By("reaching hostARed from the pod sourcing from the overlay address")
+		hostPort := net.JoinHostPort(infra.HostARedIPv4, "8090")
+		Eventually(func(g Gomega) string {
+			res, err := podExecutor.Exec("curl", "-sS", "--interface", podIP, url.Format("http://%s/clientip", hostPort))
+			g.Expect(err).ToNot(HaveOccurred(), "curl %s failed: %s", hostPort, res)
+			clientIP, _, err := net.SplitHostPort(res)
+			g.Expect(err).ToNot(HaveOccurred())
+			return clientIP
+		}, 30*time.Second, time.Second).Should(Equal(podIP))
+
+		By("reaching the pod overlay address from hostARed")
+		hostARedExecutor := executor.ForContainer("clab-kind-hostA_red")
+		podPort := net.JoinHostPort(podIP, "8090")
+		Eventually(func(g Gomega) string {
+			res, err := hostARedExecutor.Exec("curl", "-sS", url.Format("http://%s/clientip", podPort))
+			g.Expect(err).ToNot(HaveOccurred(), "curl %s failed: %s", podPort, res)
+			clientIP, _, err := net.SplitHostPort(res)
+			g.Expect(err).ToNot(HaveOccurred())
+			return clientIP
+		}, 30*time.Second, time.Second).Should(Equal(infra.HostARedIPv4))
+	})
+
+	It("marks the vxlan encapsulated packets with the dscp of the vni", func() {
+		const (
+			gatewayIP = "192.171.24.1/24"
+			hostIP    = "192.171.24.10"
+			// DSCP 46 (expedited forwarding) is carried as tos 0xb8.
+			dscp = 46
+			tos  = "0xb8"
+		)
+
+		redistributeConnectedForLeaf(infra.LeafAConfig)
+		redistributeConnectedForLeaf(infra.LeafBConfig)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedWithDSCP := l2VniRed.DeepCopy()
+		l2VniRedWithDSCP.Spec.L2GatewayIPs = []string{gatewayIP}
+		l2VniRedWithDSCP.Spec.ManagePolicyRouting = true
+		l2VniRedWithDSCP.Spec.DSCP = ptr.To(uint8(dscp))
+
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedWithDSCP,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+		nodeExec := executor.ForContainer(nodes[0].Name)
+
+		DeferCleanup(func() {
+			removeLeafPrefixes(infra.LeafAConfig)
+			removeLeafPrefixes(infra.LeafBConfig)
+			dumpIfFails(cs)
+			_, _ = nodeExec.Exec("ip", "address", "del", hostIP+"/24", "dev", "br-hs-110")
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		By("checking the vxlan interface is created with the requested tos")
+		Eventually(func() error {
+			for exec := range routers.GetExecutors() {
+				res, err := exec.Exec("ip", "-d", "link", "show", "vni110")
+				if err != nil {
+					return fmt.Errorf("failed to get vni110 on router %s: %s: %w", exec.Name(), res, err)
+				}
+				if !strings.Contains(res, "tos "+tos) {
+					return fmt.Errorf("expected tos %s on router %s, got %s", tos, exec.Name(), res)
+				}
+			}
+			return nil
+		}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+
+		By("assigning an overlay address to the host bridge")
+		Eventually(func() error {
+			res, err := nodeExec.Exec("ip", "address", "replace", hostIP+"/24", "dev", "br-hs-110")
+			if err != nil {
+				return fmt.Errorf("failed to assign %s to br-hs-110: %s: %w", hostIP, res, err)
+			}
+			return nil
+		}, time.Minute, time.Second).Should(Succeed())
+
+		By("capturing the vxlan packets on the leaf while reaching hostARed")
+		leafExec := executor.ForContainer(infra.KindLeaf)
+		hostPort := net.JoinHostPort(infra.HostARedIPv4, "8090")
+		Eventually(func(g Gomega) {
+			captured := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				res, _ := leafExec.Exec("timeout", "10", "tcpdump", "-nn", "-v", "-i", "any", "-c", "5", "udp", "port", "4789")
+				captured <- res
+			}()
+			// Give tcpdump the time to start before generating the traffic.
+			time.Sleep(2 * time.Second)
+			res, err := nodeExec.Exec("curl", "-sS", "--interface", hostIP, url.Format("http://%s/clientip", hostPort))
+			g.Expect(err).ToNot(HaveOccurred(), "curl %s failed: %s", hostPort, res)
+
+			res = <-captured
+			g.Expect(res).To(ContainSubstring("tos "+tos), "vxlan packets not marked with dscp %d", dscp)
+		}, time.Minute, time.Second).Should(Succeed())
+	})
+
+	It("advertises the host routes of the pods to the fabric", func() {
+		const (
+			gatewayIP = "192.171.24.1/24"
+			podIP     = "192.171.24.2/24"
+			hostRoute = "192.171.24.2/32"
+		)
+
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+		l2VniRedWithHostRoutes := l2VniRed.DeepCopy()
+		l2VniRedWithHostRoutes.Spec.L2GatewayIPs = []string{gatewayIP}
+		l2VniRedWithHostRoutes.Spec.AdvertiseHostRoutes = ptr.To(true)
+
+		err = Updater.Update(config.Resources{
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				*l2VniRedWithHostRoutes,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = k8s.CreateNamespace(cs, testNamespace)
+		Expect(err).NotTo(HaveOccurred())
+		nad, err = k8s.CreateMacvlanNad("110", testNamespace, "br-hs-110", []string{gatewayIP})
+		Expect(err).NotTo(HaveOccurred())
+
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8s.DeleteNamespace(cs, testNamespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		nodes, err := k8s.GetNodes(cs)
+		Expect(err).NotTo(HaveOccurred())
+		By("creating the pod")
+		pod, err := k8s.CreateAgnhostPod(cs, "pod1", testNamespace, k8s.WithNad(nad.Name, testNamespace, []string{podIP}), k8s.OnNode(nodes[0].Name))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("making the pod announce itself to the gateway")
+		podExec := executor.ForPod(pod.Namespace, pod.Name, "agnhost")
+		Eventually(func() error {
+			res, err := podExec.Exec("ping", "-c", "1", "-W", "1", discardAddressLength(gatewayIP))
+			if err != nil {
+				return fmt.Errorf("failed to ping the gateway: %s: %w", res, err)
+			}
+			return nil
+		}, time.Minute, time.Second).Should(Succeed())
+
+		By("checking the host route of the pod is installed on the leaf")
+		leafExec := executor.ForContainer(infra.LeafA)
+		Eventually(func() error {
+			res, err := leafExec.Exec("ip", "route", "show", "vrf", "red", hostRoute)
+			if err != nil {
+				return fmt.Errorf("failed to get the routes of vrf red on %s: %s: %w", infra.LeafA, res, err)
+			}
+			if !strings.Contains(res, discardAddressLength(hostRoute)) {
+				return fmt.Errorf("host route %s not found on %s: %s", hostRoute, infra.LeafA, res)
+			}
+			return nil
+		}, 2*time.Minute, time.Second).Should(Succeed())
+	})
+
+	It("advertises to the fabric only the routes of the listed vnis", func() {
+		err := Updater.CleanButUnderlay()
+		Expect(err).NotTo(HaveOccurred())
+
+		l2VniUnlisted := l2VniRed.DeepCopy()
+		l2VniUnlisted.Name = "red120"
+		l2VniUnlisted.Spec.VNI = 120
+		underlay := infra.Underlay.DeepCopy()
+		underlay.Spec.EVPN.AdvertiseAllVNI = ptr.To(false)
+		underlay.Spec.EVPN.AdvertisedVNIs = []uint32{l2VniRed.Spec.VNI}
+
+		By("advertising only the first of two l2vnis")
+		err = Updater.Update(config.Resources{
+			Underlays: []v1alpha1.Underlay{*underlay},
+			L3VNIs: []v1alpha1.L3VNI{
+				vniRed,
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				l2VniRed,
+				*l2VniUnlisted,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			err = Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{infra.Underlay},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		leafExec := executor.ForContainer(infra.LeafA)
+		By("checking the leaf receives the routes of the listed vni")
+		Eventually(func() error {
+			evpn, err := frr.EVPNInfo(leafExec)
+			if err != nil {
+				return err
+			}
+			if !evpn.ContainsRouteTypeForVNI(3, int(l2VniRed.Spec.VNI)) {
+				return fmt.Errorf("type3 route for vni %d not found in leaf %s", l2VniRed.Spec.VNI, infra.LeafA)
+			}
+			return nil
+		}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+
+		By("checking the leaf does not receive the routes of the unlisted vni")
+		Consistently(func() error {
+			evpn, err := frr.EVPNInfo(leafExec)
+			if err != nil {
+				return err
+			}
+			if evpn.ContainsRouteTypeForVNI(3, int(l2VniUnlisted.Spec.VNI)) {
+				return fmt.Errorf("type3 route for vni %d found in leaf %s", l2VniUnlisted.Spec.VNI, infra.LeafA)
+			}
+			return nil
+		}, 30*time.Second, time.Second).ShouldNot(HaveOccurred())
+	})
 })
 
 func removeGatewayFromPod(pod *corev1.Pod) error {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"regexp"
 	"strings"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 )
 
 var (
@@ -189,6 +191,43 @@ var _ = Describe("Routes between bgp and the fabric", Ordered, func() {
 			checkRouteFromLeaf(infra.LeafBConfig, vniBlue, Contains, leafBVRFBluePrefixes)
 		})
 
+		It("receives type 5 routes over an ipv6 session with the extended next hop capability", func() {
+			underlay := infra.Underlay.DeepCopy()
+			underlay.Spec.Neighbors = []v1alpha1.Neighbor{
+				{
+					ASN:             64512,
+					Address:         "2001:db8:11::2",
+					ExtendedNextHop: ptr.To(true),
+				},
+			}
+			err := Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{*underlay},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() {
+				err := Updater.Update(config.Resources{
+					Underlays: []v1alpha1.Underlay{infra.Underlay},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			By("announcing type 5 routes on VNI 100 from leafA")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+			Eventually(func() error {
+				for exec := range routers.GetExecutors() {
+					evpn, err := frr.EVPNInfo(exec)
+					if err != nil {
+						return err
+					}
+					for _, prefix := range leafAVRFRedPrefixes {
+						if !evpn.ContainsType5RouteForVNI(prefix, infra.LeafAConfig.VTEPIP, int(vniRed.Spec.VNI)) {
+							return fmt.Errorf("type5 route for %s - %s not found in %v in router %s", prefix, infra.LeafAConfig.VTEPIP, evpn, exec.Name())
+						}
+					}
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
 	})
 
 	Context("with vnis and frr-k8s", func() {
@@ -268,6 +307,469 @@ var _ = Describe("Routes between bgp and the fabric", Ordered, func() {
 				checkBGPPrefixesForHostSession(frrk8s, *vniBlue.Spec.HostSession, leafBVRFBluePrefixes, !ShouldExist)
 			}
 		})
+
+		It("installs the ipv6 type 5 routes with a resolvable next hop", func() {
+			const (
+				ipv6Prefix = "2001:db8:20::/64"
+				ipv6Host   = "2001:db8:20::2"
+			)
+			bridge := fmt.Sprintf("br-pe-%d", vniRed.Spec.VNI)
+
+			By("advertising routes from leafA for VRF Red - VNI 100")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+
+			By("checking the ipv6 route is installed through the bridge of the vni")
+			Eventually(func() error {
+				for exec := range routers.GetExecutors() {
+					res, err := exec.Exec("ip", "-6", "route", "show", "vrf", vniRed.Spec.VRF, ipv6Prefix)
+					if err != nil {
+						return fmt.Errorf("failed to show the routes of router %s: %s: %w", exec.Name(), res, err)
+					}
+					nextHop := regexp.MustCompile(`via +([0-9a-fA-F:.]+) dev ` + bridge).FindStringSubmatch(res)
+					if len(nextHop) == 0 {
+						return fmt.Errorf("route to %s via %s not found in vrf %s of router %s: %s", ipv6Prefix, bridge, vniRed.Spec.VRF, exec.Name(), res)
+					}
+					if strings.HasPrefix(strings.ToLower(nextHop[1]), "fe80") {
+						return fmt.Errorf("route to %s has link local next hop %s in router %s", ipv6Prefix, nextHop[1], exec.Name())
+					}
+
+					res, err = exec.Exec("ip", "-6", "route", "get", ipv6Host, "vrf", vniRed.Spec.VRF)
+					if err != nil {
+						return fmt.Errorf("next hop of %s not resolvable in router %s: %s: %w", ipv6Host, exec.Name(), res, err)
+					}
+					if !strings.Contains(res, "dev "+bridge) {
+						return fmt.Errorf("%s not resolved via %s in router %s: %s", ipv6Host, bridge, exec.Name(), res)
+					}
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+
+		It("leaks the prefixes of VRF Red listed in leaktodefault into the default vrf", func() {
+			const leakedPrefix = "192.168.20.0/24"
+			checkDefaultRoute := func(mustContain bool) {
+				Eventually(func() error {
+					for exec := range routers.GetExecutors() {
+						res, err := exec.Exec("ip", "-4", "route", "show", leakedPrefix)
+						if err != nil {
+							return fmt.Errorf("failed to show the routes of router %s: %w", exec.Name(), err)
+						}
+						found := strings.Contains(res, leakedPrefix)
+						if mustContain && !found {
+							return fmt.Errorf("route to %s not found in the default vrf of router %s", leakedPrefix, exec.Name())
+						}
+						if !mustContain && found {
+							return fmt.Errorf("route to %s found in the default vrf of router %s: %s", leakedPrefix, exec.Name(), res)
+						}
+					}
+					return nil
+				}, 3*time.Minute, time.Second).WithOffset(1).ShouldNot(HaveOccurred())
+			}
+
+			By("advertising routes from leafA for VRF Red - VNI 100")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+
+			By("checking the prefix is not reachable from the default vrf")
+			checkDefaultRoute(false)
+
+			By("leaking the prefix into the default vrf")
+			vniRedLeaking := vniRed.DeepCopy()
+			vniRedLeaking.Spec.LeakToDefault = []string{leakedPrefix}
+			err := Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedLeaking,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the prefix is reachable from the default vrf")
+			checkDefaultRoute(true)
+
+			By("checking the prefix is not advertised to the fabric")
+			Consistently(func() error {
+				for _, leaf := range []string{infra.KindLeaf, infra.LeafA, infra.LeafB} {
+					ipv4Routes, _, err := frr.BGPRoutesFor(executor.ForContainer(leaf))
+					if err != nil {
+						return err
+					}
+					if _, ok := ipv4Routes[leakedPrefix]; ok {
+						return fmt.Errorf("leaked route to %s advertised to %s in the default vrf", leakedPrefix, leaf)
+					}
+				}
+				return nil
+			}, 30*time.Second, time.Second).ShouldNot(HaveOccurred())
+		})
+
+		It("advertises to the host only the prefixes listed in hostadvertise", func() {
+			By("advertising routes from the leaves for VRF Red - VNI 100")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+			changeLeafPrefixes(infra.LeafBConfig, emptyPrefixes, leafBVRFRedPrefixes, emptyPrefixes)
+
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafAVRFRedPrefixes, ShouldExist)
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafBVRFRedPrefixes, ShouldExist)
+			}
+
+			By("limiting the prefixes advertised to the host to the ones of leafA")
+			vniRedLimited := vniRed.DeepCopy()
+			vniRedLimited.Spec.HostSession.HostAdvertise = leafAVRFRedPrefixes
+			err := Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedLimited,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking only the prefixes of leafA are received by the host")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafAVRFRedPrefixes, ShouldExist)
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafBVRFRedPrefixes, !ShouldExist)
+			}
+		})
+
+		It("does not advertise to the fabric the families disabled on the l3vni", func() {
+			const (
+				ipv4Prefix = "192.168.100.0/24"
+				ipv6Prefix = "2001:db8:100::/64"
+			)
+			leafExec := executor.ForContainer(infra.LeafA)
+			checkType5Route := func(prefix string, mustContain bool) {
+				Eventually(func() error {
+					evpn, err := frr.EVPNInfo(leafExec)
+					if err != nil {
+						return err
+					}
+					found := evpn.ContainsType5RouteForPrefix(prefix, int(vniRed.Spec.VNI))
+					if mustContain && !found {
+						return fmt.Errorf("type5 route for %s not found in leaf %s", prefix, infra.LeafA)
+					}
+					if !mustContain && found {
+						return fmt.Errorf("type5 route for %s found in leaf %s", prefix, infra.LeafA)
+					}
+					return nil
+				}, 3*time.Minute, time.Second).WithOffset(1).ShouldNot(HaveOccurred())
+			}
+
+			By("advertising an ipv4 and an ipv6 prefix from the hosts on VRF Red, advertising only ipv4 to the fabric")
+			frrK8sConfigRedIPv4, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRed.Spec.HostSession, vniRed.Name, ipfamily.IPv4, frrk8s.AdvertisePrefixes(ipv4Prefix))
+			Expect(err).NotTo(HaveOccurred())
+			frrK8sConfigRedIPv6, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRed.Spec.HostSession, vniRed.Name, ipfamily.IPv6, frrk8s.AdvertisePrefixes(ipv6Prefix))
+			Expect(err).NotTo(HaveOccurred())
+
+			vniRedIPv4Only := vniRed.DeepCopy()
+			vniRedIPv4Only.Spec.AdvertiseIPv6 = ptr.To(false)
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedIPv4Only,
+					vniBlue,
+				},
+				FRRConfigurations: append([]frrk8sapi.FRRConfiguration{*frrK8sConfigRedIPv4, *frrK8sConfigRedIPv6}, frrK8sConfigBlue...),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking only the ipv4 prefix reaches the fabric")
+			checkType5Route(ipv4Prefix, true)
+			checkType5Route(ipv6Prefix, false)
+
+			By("advertising ipv6 too")
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					vniRed,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the ipv6 prefix reaches the fabric")
+			checkType5Route(ipv6Prefix, true)
+		})
+
+		It("does not advertise to the fabric the l3vni kept local to the node", func() {
+			const ipv4Prefix = "192.168.101.0/24"
+			leafExec := executor.ForContainer(infra.LeafA)
+			checkNoRoutes := func() {
+				Consistently(func() error {
+					evpn, err := frr.EVPNInfo(leafExec)
+					if err != nil {
+						return err
+					}
+					if evpn.ContainsType5RouteForPrefix(ipv4Prefix, int(vniRed.Spec.VNI)) {
+						return fmt.Errorf("type5 route for %s found in leaf %s", ipv4Prefix, infra.LeafA)
+					}
+					if evpn.ContainsRouteTypeForVNI(3, int(vniRed.Spec.VNI)) {
+						return fmt.Errorf("type3 route for vni %d found in leaf %s", vniRed.Spec.VNI, infra.LeafA)
+					}
+					return nil
+				}, 30*time.Second, time.Second).ShouldNot(HaveOccurred())
+			}
+
+			By("advertising a prefix from the hosts on VRF Red, kept local to the node")
+			frrK8sConfigRed, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRed.Spec.HostSession, vniRed.Name, ipfamily.IPv4, frrk8s.AdvertisePrefixes(ipv4Prefix))
+			Expect(err).NotTo(HaveOccurred())
+
+			vniRedLocal := vniRed.DeepCopy()
+			vniRedLocal.Spec.FabricAdvertise = ptr.To(false)
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedLocal,
+					vniBlue,
+				},
+				FRRConfigurations: []frrk8sapi.FRRConfiguration{*frrK8sConfigRed},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking no route of VRF Red leaves the node")
+			checkNoRoutes()
+
+			By("advertising VRF Red into the fabric")
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					vniRed,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the prefix reaches the fabric")
+			Eventually(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if !evpn.ContainsType5RouteForPrefix(ipv4Prefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s not found in leaf %s", ipv4Prefix, infra.LeafA)
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+
+		It("does not advertise to the fabric the prefixes denied by the evpn export route-map", func() {
+			const (
+				allowedPrefix = "192.168.102.0/24"
+				deniedPrefix  = "192.168.103.0/24"
+			)
+			leafExec := executor.ForContainer(infra.LeafA)
+
+			By("advertising two prefixes from the hosts on VRF Red, filtering one of them")
+			frrK8sConfigRed, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRed.Spec.HostSession, vniRed.Name, ipfamily.IPv4, frrk8s.AdvertisePrefixes(allowedPrefix, deniedPrefix))
+			Expect(err).NotTo(HaveOccurred())
+
+			vniRedFiltered := vniRed.DeepCopy()
+			vniRedFiltered.Spec.RouteMaps = []v1alpha1.RouteMapSpec{
+				{
+					Name: "export",
+					Rules: []v1alpha1.RouteMapRule{
+						{
+							Action: v1alpha1.RouteMapDeny,
+							Match:  &v1alpha1.RouteMapMatch{Prefixes: []string{deniedPrefix}},
+						},
+						{
+							Action: v1alpha1.RouteMapPermit,
+						},
+					},
+				},
+			}
+			vniRedFiltered.Spec.EVPNExportRouteMap = ptr.To("export")
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedFiltered,
+					vniBlue,
+				},
+				FRRConfigurations: []frrk8sapi.FRRConfiguration{*frrK8sConfigRed},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking only the allowed prefix reaches the fabric")
+			Eventually(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if !evpn.ContainsType5RouteForPrefix(allowedPrefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s not found in leaf %s", allowedPrefix, infra.LeafA)
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+			Consistently(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if evpn.ContainsType5RouteForPrefix(deniedPrefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s found in leaf %s", deniedPrefix, infra.LeafA)
+				}
+				return nil
+			}, 30*time.Second, time.Second).ShouldNot(HaveOccurred())
+		})
+
+		It("accepts the routes carrying the router asn only when allowas-in is set", func() {
+			const ipv4Prefix = "192.168.104.0/24"
+			leafExec := executor.ForContainer(infra.LeafA)
+
+			By("advertising a prefix from the hosts on VRF Red, as learned from a site sharing the router asn")
+			frrK8sConfigRed, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRed.Spec.HostSession, vniRed.Name, ipfamily.IPv4,
+				frrk8s.AdvertisePrefixes(ipv4Prefix),
+				frrk8s.PrependASPath(vniRed.Spec.HostSession.ASN))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					vniRed,
+					vniBlue,
+				},
+				FRRConfigurations: []frrk8sapi.FRRConfiguration{*frrK8sConfigRed},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the prefix is dropped by the router")
+			Consistently(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if evpn.ContainsType5RouteForPrefix(ipv4Prefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s found in leaf %s", ipv4Prefix, infra.LeafA)
+				}
+				return nil
+			}, 30*time.Second, time.Second).ShouldNot(HaveOccurred())
+
+			By("allowing the router asn in the routes received from the hosts")
+			vniRedAllowASIn := vniRed.DeepCopy()
+			vniRedAllowASIn.Spec.HostSession.AllowASIn = ptr.To[uint8](1)
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedAllowASIn,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the prefix reaches the fabric")
+			Eventually(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if !evpn.ContainsType5RouteForPrefix(ipv4Prefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s not found in leaf %s", ipv4Prefix, infra.LeafA)
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+
+		It("exchanges the ipv4 routes over an ipv6 only host session", func() {
+			const ipv4Prefix = "192.168.105.0/24"
+			leafExec := executor.ForContainer(infra.LeafA)
+
+			By("peering with the hosts on VRF Red over ipv6 only")
+			vniRedIPv6Only := vniRed.DeepCopy()
+			vniRedIPv6Only.Spec.HostSession.LocalCIDR.IPv4 = ""
+			frrK8sConfigRed, err := frrk8s.ConfigFromHostSessionForIPFamily(*vniRedIPv6Only.Spec.HostSession, vniRed.Name, ipfamily.IPv6,
+				frrk8s.AdvertisePrefixes(ipv4Prefix),
+				frrk8s.ExtendedNextHop())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedIPv6Only,
+					vniBlue,
+				},
+				FRRConfigurations: []frrk8sapi.FRRConfiguration{*frrK8sConfigRed},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the ipv4 prefix advertised by the hosts reaches the fabric")
+			Eventually(func() error {
+				evpn, err := frr.EVPNInfo(leafExec)
+				if err != nil {
+					return err
+				}
+				if !evpn.ContainsType5RouteForPrefix(ipv4Prefix, int(vniRed.Spec.VNI)) {
+					return fmt.Errorf("type5 route for %s not found in leaf %s", ipv4Prefix, infra.LeafA)
+				}
+				return nil
+			}, 3*time.Minute, time.Second).ShouldNot(HaveOccurred())
+
+			By("advertising ipv4 routes from leafA for VRF Red")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+
+			By("checking the ipv4 routes of the fabric reach the hosts with an ipv6 next hop")
+			ipv4Prefixes, _ := separateIPFamilies(leafAVRFRedPrefixes)
+			for _, pod := range frrk8sPods {
+				podExec := executor.ForPod(pod.Namespace, pod.Name, "frr")
+				Eventually(func() error {
+					ipv4Routes, _, err := frr.BGPRoutesFor(podExec)
+					if err != nil {
+						return err
+					}
+					return checkPrefixesForIPFamily(pod, ipv4Prefixes, vniRedIPv6Only.Spec.HostSession.LocalCIDR.IPv6, "IPv4", ShouldExist, ipv4Routes)
+				}, 4*time.Minute, time.Second).ShouldNot(HaveOccurred())
+			}
+		})
+
+		It("reaches the router svi address of VRF Red from the hosts", func() {
+			const sviIP = "192.168.250.1"
+
+			By("assigning a router svi address to VRF Red")
+			vniRedSVI := vniRed.DeepCopy()
+			vniRedSVI.Spec.RouterSVIAddress = ptr.To(sviIP + "/32")
+			err := Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					*vniRedSVI,
+					vniBlue,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the address is advertised to the hosts")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, []string{sviIP + "/32"}, ShouldExist)
+			}
+
+			By("pinging the address from the hosts")
+			nodes, err := k8s.GetNodes(cs)
+			Expect(err).NotTo(HaveOccurred())
+			for _, node := range nodes {
+				nodeExec := executor.ForContainer(node.Name)
+				Eventually(func() error {
+					res, err := nodeExec.Exec("ping", "-c", "1", "-W", "1", sviIP)
+					if err != nil {
+						return fmt.Errorf("failed to ping %s from %s: %s: %w", sviIP, node.Name, res, err)
+					}
+					return nil
+				}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+			}
+		})
+
+		It("leaks the routes of VRF Red into VRF Blue when Blue imports Red", func() {
+			By("advertising routes from leafA for VRF Red - VNI 100")
+			changeLeafPrefixes(infra.LeafAConfig, emptyPrefixes, leafAVRFRedPrefixes, emptyPrefixes)
+
+			By("checking the routes are not propagated to the Blue host session")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafAVRFRedPrefixes, ShouldExist)
+				checkBGPPrefixesForHostSession(frrk8s, *vniBlue.Spec.HostSession, leafAVRFRedPrefixes, !ShouldExist)
+			}
+
+			By("importing VRF Red into VRF Blue")
+			vniBlueImportingRed := vniBlue.DeepCopy()
+			vniBlueImportingRed.Spec.ImportVRFs = []string{vniRed.Spec.VRF}
+			err := Updater.Update(config.Resources{
+				L3VNIs: []v1alpha1.L3VNI{
+					vniRed,
+					*vniBlueImportingRed,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the routes of VRF Red are propagated to the Blue host session")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, *vniRed.Spec.HostSession, leafAVRFRedPrefixes, ShouldExist)
+				checkBGPPrefixesForHostSession(frrk8s, *vniBlue.Spec.HostSession, leafAVRFRedPrefixes, ShouldExist)
+			}
+		})
 	})
 
 	Context("testing e2e integration between a pod and the blue / red hosts", func() {
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
@@ -704,6 +705,82 @@ var _ = ginkgo.Describe("Router Host configuration", func() {
 			}
 		})
 
+		ginkgo.It("assigns a centralized gateway on a single node", func() {
+			l2vniCentralized := l2vni400.DeepCopy()
+			l2vniCentralized.Spec.GatewayMode = v1alpha1.CentralizedGateway
+			err := Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{
+					underlay,
+				},
+				L2VNIs: []v1alpha1.L2VNI{
+					*l2vniCentralized,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			bridge := fmt.Sprintf("br-pe-%d", l2vniCentralized.Spec.VNI)
+			gatewayIP := l2vniCentralized.Spec.L2GatewayIPs[0]
+			Eventually(func() (int, error) {
+				withGateway := 0
+				for _, p := range routerPods {
+					exec := executor.ForPod(p.Namespace, p.Name, "frr")
+					res, err := exec.Exec("ip", "-o", "addr", "show", "dev", bridge)
+					if err != nil {
+						return 0, fmt.Errorf("failed to get the addresses of %s in pod %s: %s %w", bridge, p.Name, res, err)
+					}
+					if strings.Contains(res, gatewayIP) {
+						withGateway++
+					}
+				}
+				return withGateway, nil
+			}, time.Minute, time.Second).Should(Equal(1))
+		})
+
+		ginkgo.It("ages out the mac addresses per the configured time", func() {
+			const testMAC = "00:00:5e:00:53:01"
+			l2vniAgeing := l2vni400.DeepCopy()
+			l2vniAgeing.Spec.MACAgeingTime = &metav1.Duration{Duration: 10 * time.Second}
+			err := Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{
+					underlay,
+				},
+				L2VNIs: []v1alpha1.L2VNI{
+					*l2vniAgeing,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			bridge := fmt.Sprintf("br-pe-%d", l2vniAgeing.Spec.VNI)
+			peVeth := fmt.Sprintf("pe-%d", l2vniAgeing.Spec.VNI)
+			for _, p := range routerPods {
+				exec := executor.ForPod(p.Namespace, p.Name, "frr")
+
+				ginkgo.By(fmt.Sprintf("checking the ageing time of %s in pod %s", bridge, p.Name))
+				Eventually(func() error {
+					res, err := exec.Exec("ip", "-d", "link", "show", "dev", bridge)
+					if err != nil {
+						return fmt.Errorf("failed to get %s in pod %s: %s %w", bridge, p.Name, res, err)
+					}
+					// the kernel reports the ageing time in hundredths of a second.
+					if !strings.Contains(res, "ageing_time 1000 ") {
+						return fmt.Errorf("unexpected ageing time of %s in pod %s: %s", bridge, p.Name, res)
+					}
+					return nil
+				}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+
+				ginkgo.By(fmt.Sprintf("checking a learned mac ages out in pod %s", p.Name))
+				res, err := exec.Exec("bridge", "fdb", "replace", testMAC, "dev", peVeth, "master", "dynamic")
+				Expect(err).NotTo(HaveOccurred(), res)
+				res, err = exec.Exec("bridge", "fdb", "show", "dev", peVeth)
+				Expect(err).NotTo(HaveOccurred(), res)
+				Expect(res).To(ContainSubstring(testMAC))
+
+				Eventually(func() (string, error) {
+					return exec.Exec("bridge", "fdb", "show", "dev", peVeth)
+				}, 30*time.Second, time.Second).ShouldNot(ContainSubstring(testMAC))
+			}
+		})
+
 		ginkgo.It("works while editing the vni parameters", func() {
 			resources := config.Resources{
 				Underlays: []v1alpha1.Underlay{
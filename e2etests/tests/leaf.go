@@ -35,6 +35,24 @@ func changeLeafPrefixes(leaf infra.Leaf, defaultPrefixes, redPrefixes, bluePrefi
 	Expect(err).NotTo(HaveOccurred())
 }
 
+// changeLeafDefaultPrefixesWithCommunities advertises the given prefixes in the
+// default vrf of the leaf, tagged with the given communities.
+func changeLeafDefaultPrefixesWithCommunities(leaf infra.Leaf, prefixes, communities []string) {
+	ipv4, ipv6 := separateIPFamilies(prefixes)
+	leafConfiguration := infra.LeafConfiguration{
+		Leaf: leaf,
+		Default: infra.Addresses{
+			IPV4:        ipv4,
+			IPV6:        ipv6,
+			Communities: communities,
+		},
+	}
+	config, err := infra.LeafConfigToFRR(leafConfiguration)
+	Expect(err).NotTo(HaveOccurred())
+	err = leaf.ReloadConfig(config)
+	Expect(err).NotTo(HaveOccurred())
+}
+
 func removeLeafPrefixes(leaf infra.Leaf) {
 	changeLeafPrefixes(leaf, []string{}, []string{}, []string{})
 }
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/e2etests/pkg/config"
+	"github.com/openperouter/openperouter/e2etests/pkg/infra"
+	"github.com/openperouter/openperouter/e2etests/pkg/k8sclient"
+	"github.com/openperouter/openperouter/e2etests/pkg/openperouter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+var _ = ginkgo.Describe("Node index allocations", func() {
+	var cs clientset.Interface
+
+	ginkgo.BeforeEach(func() {
+		cs = k8sclient.New()
+		err := Updater.CleanAll()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	ginkgo.AfterEach(func() {
+		dumpIfFails(cs)
+		err := Updater.CleanAll()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	ginkgo.It("are published consistently with the node annotations", func() {
+		err := Updater.Update(config.Resources{
+			Underlays: []v1alpha1.Underlay{
+				infra.Underlay,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			nodes, err := cs.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			allocations, err := openperouter.NodeAllocations(cs)
+			if err != nil {
+				return err
+			}
+			if len(allocations) != len(nodes.Items) {
+				return fmt.Errorf("expected %d allocations, got %v", len(nodes.Items), allocations)
+			}
+			for _, node := range nodes.Items {
+				allocation, ok := allocations[node.Name]
+				if !ok {
+					return fmt.Errorf("no allocation for node %s in %v", node.Name, allocations)
+				}
+				index := node.Annotations["openpe.io/nodeindex"]
+				if strconv.Itoa(allocation.Index) != index {
+					return fmt.Errorf("node %s: expected index %s, got %d", node.Name, index, allocation.Index)
+				}
+				vtep, err := openperouter.VtepIPForNode(infra.Underlay.Spec.EVPN.VTEPCIDR, &node)
+				if err != nil {
+					return err
+				}
+				ip, _, err := net.ParseCIDR(vtep)
+				if err != nil {
+					return err
+				}
+				if allocation.VTEPIP != ip.String() {
+					return fmt.Errorf("node %s: expected vtep ip %s, got %s", node.Name, ip, allocation.VTEPIP)
+				}
+			}
+			return nil
+		}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/e2etests/pkg/config"
+	"github.com/openperouter/openperouter/e2etests/pkg/frrk8s"
+	"github.com/openperouter/openperouter/e2etests/pkg/infra"
+	"github.com/openperouter/openperouter/e2etests/pkg/k8sclient"
+	"github.com/openperouter/openperouter/e2etests/pkg/openperouter"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+var _ = Describe("Passthrough with an underlay without EVPN", Ordered, func() {
+	var cs clientset.Interface
+	var routers openperouter.Routers
+
+	underlayWithoutEVPN := infra.Underlay.DeepCopy()
+	underlayWithoutEVPN.Spec.EVPN = nil
+
+	passthrough := v1alpha1.L3Passthrough{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "passthrough",
+			Namespace: openperouter.Namespace,
+		},
+		Spec: v1alpha1.L3PassthroughSpec{
+			HostSession: v1alpha1.HostSession{
+				ASN:     64514,
+				HostASN: 64515,
+				LocalCIDR: v1alpha1.LocalCIDRConfig{
+					IPv4: "192.169.10.0/24",
+					IPv6: "2001:db8:1::/64",
+				},
+			},
+		},
+	}
+
+	BeforeAll(func() {
+		err := Updater.CleanAll()
+		Expect(err).NotTo(HaveOccurred())
+
+		cs = k8sclient.New()
+		routers, err = openperouter.Get(cs, HostMode)
+		Expect(err).NotTo(HaveOccurred())
+
+		routers.Dump(GinkgoWriter)
+
+		err = Updater.Update(config.Resources{
+			Underlays: []v1alpha1.Underlay{
+				*underlayWithoutEVPN,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterAll(func() {
+		err := Updater.CleanAll()
+		Expect(err).NotTo(HaveOccurred())
+		By("waiting for the router pod to rollout after removing the underlay")
+		Eventually(func() error {
+			newRouters, err := openperouter.Get(cs, HostMode)
+			if err != nil {
+				return err
+			}
+			return openperouter.DaemonsetRolled(routers, newRouters)
+		}, 2*time.Minute, time.Second).ShouldNot(HaveOccurred())
+	})
+
+	Context("with passthrough and frr-k8s", func() {
+		ShouldExist := true
+		frrk8sPods := []*corev1.Pod{}
+		frrK8sConfig, err := frrk8s.ConfigFromHostSession(passthrough.Spec.HostSession, passthrough.Name)
+		if err != nil {
+			panic(err)
+		}
+
+		BeforeEach(func() {
+			frrk8sPods, err = frrk8s.Pods(cs)
+			Expect(err).NotTo(HaveOccurred())
+
+			DumpPods("FRRK8s pods", frrk8sPods)
+
+			err = Updater.Update(config.Resources{
+				L3Passthrough: []v1alpha1.L3Passthrough{
+					passthrough,
+				},
+				FRRConfigurations: frrK8sConfig,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			validateFRRK8sSessionForHostSession(passthrough.Name, passthrough.Spec.HostSession, Established, frrk8sPods...)
+		})
+
+		AfterEach(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			removeLeafPrefixes(infra.LeafAConfig)
+			removeLeafPrefixes(infra.LeafBConfig)
+		})
+
+		It("does not create the vtep loopback", func() {
+			for exec := range routers.GetExecutors() {
+				res, err := exec.Exec("ip", "link", "show")
+				Expect(err).NotTo(HaveOccurred(), fmt.Sprintf("failed to list links on router %s: %s", exec.Name(), res))
+				Expect(strings.Contains(res, "lound")).To(BeFalse(), "unexpected vtep loopback on router %s: %s", exec.Name(), res)
+			}
+		})
+
+		It("translates BGP incoming routes as BGP routes", func() {
+			By("advertising routes from both leaves")
+			changeLeafPrefixes(infra.LeafAConfig, leafADefaultPrefixes, emptyPrefixes, emptyPrefixes)
+			changeLeafPrefixes(infra.LeafBConfig, leafBDefaultPrefixes, emptyPrefixes, emptyPrefixes)
+
+			By("checking routes are propagated via BGP")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, passthrough.Spec.HostSession, leafADefaultPrefixes, ShouldExist)
+				checkBGPPrefixesForHostSession(frrk8s, passthrough.Spec.HostSession, leafBDefaultPrefixes, ShouldExist)
+			}
+		})
+	})
+})
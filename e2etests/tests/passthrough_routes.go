@@ -5,6 +5,7 @@ package tests
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	frrk8sapi "github.com/metallb/frr-k8s/api/v1beta1"
@@ -13,6 +14,7 @@ import (
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	"github.com/openperouter/openperouter/e2etests/pkg/config"
 	"github.com/openperouter/openperouter/e2etests/pkg/executor"
+	"github.com/openperouter/openperouter/e2etests/pkg/frr"
 	"github.com/openperouter/openperouter/e2etests/pkg/frrk8s"
 	"github.com/openperouter/openperouter/e2etests/pkg/infra"
 	"github.com/openperouter/openperouter/e2etests/pkg/ipfamily"
@@ -23,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 )
 
 var (
@@ -147,6 +150,280 @@ var _ = Describe("Routes between bgp and the fabric", Ordered, func() {
 		})
 	})
 
+	Context("with passthrough with next hop self and frr-k8s", func() {
+		ShouldExist := true
+		frrk8sPods := []*corev1.Pod{}
+		passthroughNextHopSelf := passthrough.DeepCopy()
+		passthroughNextHopSelf.Spec.HostSession.NextHopSelf = ptr.To(true)
+
+		frrK8sConfig, err := frrk8s.ConfigFromHostSession(passthroughNextHopSelf.Spec.HostSession, passthroughNextHopSelf.Name)
+		if err != nil {
+			panic(err)
+		}
+
+		BeforeEach(func() {
+			frrk8sPods, err = frrk8s.Pods(cs)
+			Expect(err).NotTo(HaveOccurred())
+
+			DumpPods("FRRK8s pods", frrk8sPods)
+
+			err = Updater.Update(config.Resources{
+				L3Passthrough: []v1alpha1.L3Passthrough{
+					*passthroughNextHopSelf,
+				},
+				FRRConfigurations: frrK8sConfig,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			validateFRRK8sSessionForHostSession(passthroughNextHopSelf.Name, passthroughNextHopSelf.Spec.HostSession, Established, frrk8sPods...)
+		})
+
+		AfterEach(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			removeLeafPrefixes(infra.LeafAConfig)
+		})
+
+		It("advertises the fabric routes with the router as next hop", func() {
+			By("advertising routes from leaf A")
+			changeLeafPrefixes(infra.LeafAConfig, leafADefaultPrefixes, emptyPrefixes, emptyPrefixes)
+
+			By("checking routes are propagated via BGP with the router as nexthop")
+			for _, frrk8s := range frrk8sPods {
+				checkBGPPrefixesForHostSession(frrk8s, passthroughNextHopSelf.Spec.HostSession, leafADefaultPrefixes, ShouldExist)
+			}
+
+			By("checking the hosts see the router as the only next hop of the routes")
+			routerIP, err := openperouter.RouterIPFromCIDR(passthroughNextHopSelf.Spec.HostSession.LocalCIDR.IPv4)
+			Expect(err).NotTo(HaveOccurred())
+			for _, p := range frrk8sPods {
+				exec := executor.ForPod(p.Namespace, p.Name, "frr")
+				nodeExec := executor.ForContainer(p.Spec.NodeName)
+				Eventually(func(g Gomega) {
+					ipv4Routes, _, err := frr.BGPRoutesFor(exec)
+					g.Expect(err).NotTo(HaveOccurred())
+					for _, prefix := range leafADefaultPrefixes {
+						nextHops := ipv4Routes[prefix]
+						g.Expect(nextHops).NotTo(BeEmpty(), "no route to %s on %s", prefix, p.Name)
+						for _, nextHop := range nextHops {
+							g.Expect(nextHop).To(Equal(routerIP), "unexpected next hop of %s on %s", prefix, p.Name)
+						}
+
+						res, err := nodeExec.Exec("ip", "route", "show", prefix)
+						g.Expect(err).NotTo(HaveOccurred(), res)
+						g.Expect(res).To(ContainSubstring("via "+routerIP), "unexpected route to %s on node %s", prefix, p.Spec.NodeName)
+					}
+				}, time.Minute, time.Second).Should(Succeed())
+			}
+		})
+	})
+
+	Context("with passthrough stripping communities and frr-k8s", func() {
+		const fabricCommunity = "64520:100"
+		frrk8sPods := []*corev1.Pod{}
+		passthroughStrip := passthrough.DeepCopy()
+		passthroughStrip.Spec.HostSession.StripCommunitiesOnImport = true
+
+		frrK8sConfig, err := frrk8s.ConfigFromHostSession(passthroughStrip.Spec.HostSession, passthroughStrip.Name)
+		if err != nil {
+			panic(err)
+		}
+
+		BeforeEach(func() {
+			frrk8sPods, err = frrk8s.Pods(cs)
+			Expect(err).NotTo(HaveOccurred())
+
+			DumpPods("FRRK8s pods", frrk8sPods)
+
+			err = Updater.Update(config.Resources{
+				L3Passthrough: []v1alpha1.L3Passthrough{
+					*passthroughStrip,
+				},
+				FRRConfigurations: frrK8sConfig,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			validateFRRK8sSessionForHostSession(passthroughStrip.Name, passthroughStrip.Spec.HostSession, Established, frrk8sPods...)
+		})
+
+		AfterEach(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			removeLeafPrefixes(infra.LeafAConfig)
+		})
+
+		It("advertises the fabric routes to the host without communities", func() {
+			By("advertising routes with communities from leaf A")
+			changeLeafDefaultPrefixesWithCommunities(infra.LeafAConfig, leafADefaultPrefixes, []string{fabricCommunity})
+
+			By("checking the router receives the routes with the communities")
+			for exec := range routers.GetExecutors() {
+				checkBGPPrefixCommunity(exec, leafADefaultPrefixes[0], fabricCommunity, true)
+			}
+
+			By("checking the host receives the routes without the communities")
+			for _, pod := range frrk8sPods {
+				checkBGPPrefixesForHostSession(pod, passthroughStrip.Spec.HostSession, leafADefaultPrefixes, true)
+				checkBGPPrefixCommunity(executor.ForPod(pod.Namespace, pod.Name, "frr"), leafADefaultPrefixes[0], fabricCommunity, false)
+			}
+		})
+	})
+
+	Context("with passthrough sending no communities and frr-k8s", func() {
+		const fabricCommunity = "64520:100"
+		frrk8sPods := []*corev1.Pod{}
+		passthroughNoCommunity := passthrough.DeepCopy()
+		passthroughNoCommunity.Spec.HostSession.SendCommunity = ptr.To(v1alpha1.SendCommunityNone)
+
+		frrK8sConfig, err := frrk8s.ConfigFromHostSession(passthroughNoCommunity.Spec.HostSession, passthroughNoCommunity.Name)
+		if err != nil {
+			panic(err)
+		}
+
+		BeforeEach(func() {
+			frrk8sPods, err = frrk8s.Pods(cs)
+			Expect(err).NotTo(HaveOccurred())
+
+			DumpPods("FRRK8s pods", frrk8sPods)
+
+			err = Updater.Update(config.Resources{
+				L3Passthrough: []v1alpha1.L3Passthrough{
+					*passthroughNoCommunity,
+				},
+				FRRConfigurations: frrK8sConfig,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			validateFRRK8sSessionForHostSession(passthroughNoCommunity.Name, passthroughNoCommunity.Spec.HostSession, Established, frrk8sPods...)
+		})
+
+		AfterEach(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+			removeLeafPrefixes(infra.LeafAConfig)
+		})
+
+		It("does not propagate the communities to the host", func() {
+			By("advertising routes with communities from leaf A")
+			changeLeafDefaultPrefixesWithCommunities(infra.LeafAConfig, leafADefaultPrefixes, []string{fabricCommunity})
+
+			By("checking the router receives the routes with the communities")
+			for exec := range routers.GetExecutors() {
+				checkBGPPrefixCommunity(exec, leafADefaultPrefixes[0], fabricCommunity, true)
+			}
+
+			By("checking the host receives the routes without the communities")
+			for _, pod := range frrk8sPods {
+				checkBGPPrefixesForHostSession(pod, passthroughNoCommunity.Spec.HostSession, leafADefaultPrefixes, true)
+				checkBGPPrefixCommunity(executor.ForPod(pod.Namespace, pod.Name, "frr"), leafADefaultPrefixes[0], fabricCommunity, false)
+			}
+		})
+	})
+
+	Context("with passthrough with dynamic peers and frr-k8s", func() {
+		frrk8sPods := []*corev1.Pod{}
+		passthroughDynamicPeers := passthrough.DeepCopy()
+		passthroughDynamicPeers.Spec.HostSession.DynamicPeers = true
+
+		frrK8sConfig, err := frrk8s.ConfigFromHostSession(passthroughDynamicPeers.Spec.HostSession, passthroughDynamicPeers.Name)
+		if err != nil {
+			panic(err)
+		}
+
+		BeforeEach(func() {
+			frrk8sPods, err = frrk8s.Pods(cs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(frrk8sPods)).To(BeNumerically(">=", 2))
+
+			DumpPods("FRRK8s pods", frrk8sPods)
+
+			err = Updater.Update(config.Resources{
+				L3Passthrough: []v1alpha1.L3Passthrough{
+					*passthroughDynamicPeers,
+				},
+				FRRConfigurations: frrK8sConfig,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			dumpIfFails(cs)
+			err := Updater.CleanButUnderlay()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("establishes the sessions with all the hosts via the listen range", func() {
+			validateFRRK8sSessionForHostSession(passthroughDynamicPeers.Name, passthroughDynamicPeers.Spec.HostSession, Established, frrk8sPods...)
+		})
+
+		It("establishes the sessions with more than one peer of the same node via the listen range", func() {
+			const (
+				// far from the addresses assigned by node index.
+				secondPeerIP   = "192.169.10.250"
+				secondPeerPath = "secondpeer"
+			)
+			cidr := passthroughDynamicPeers.Spec.HostSession.LocalCIDR.IPv4
+			routerIP, err := openperouter.RouterIPFromCIDR(cidr)
+			Expect(err).NotTo(HaveOccurred())
+
+			frrk8sPod := frrk8sPods[0]
+			nodes, err := k8s.GetNodes(cs)
+			Expect(err).NotTo(HaveOccurred())
+			var node *corev1.Node
+			for i := range nodes {
+				if nodes[i].Name == frrk8sPod.Spec.NodeName {
+					node = &nodes[i]
+				}
+			}
+			Expect(node).NotTo(BeNil(), "node %s of pod %s not found", frrk8sPod.Spec.NodeName, frrk8sPod.Name)
+			hostIP, err := openperouter.HostIPFromCIDRForNode(cidr, node)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("waiting for the session with frr-k8s, the first peer of the node")
+			validateFRRK8sSessionForHostSession(passthroughDynamicPeers.Name, passthroughDynamicPeers.Spec.HostSession, Established, frrk8sPod)
+
+			By("adding the address of the second peer to the host leg of the router")
+			nodeExec := executor.ForContainer(node.Name)
+			res, err := nodeExec.Exec("ip", "-o", "-4", "address", "show", "to", hostIP+"/32")
+			Expect(err).NotTo(HaveOccurred(), res)
+			fields := strings.Fields(res)
+			Expect(len(fields)).To(BeNumerically(">=", 2), "no interface with address %s: %s", hostIP, res)
+			hostLeg := fields[1]
+			res, err = nodeExec.Exec("ip", "address", "add", secondPeerIP+"/24", "dev", hostLeg)
+			Expect(err).NotTo(HaveOccurred(), res)
+			DeferCleanup(func() {
+				_, _ = nodeExec.Exec("ip", "address", "del", secondPeerIP+"/24", "dev", hostLeg)
+			})
+
+			By("starting a second bgpd in the host namespace, sourcing from the second peer address")
+			frrExec := executor.ForPod(frrk8sPod.Namespace, frrk8sPod.Name, "frr")
+			bgpdConfig := fmt.Sprintf(`router bgp %d
+ bgp router-id 10.250.250.250
+ no bgp ebgp-requires-policy
+ neighbor %s remote-as %d
+ neighbor %s update-source %s
+`, passthroughDynamicPeers.Spec.HostSession.HostASN, routerIP, passthroughDynamicPeers.Spec.HostSession.ASN, routerIP, secondPeerIP)
+			res, err = frrExec.Exec("sh", "-c", fmt.Sprintf(
+				"mkdir -p /etc/frr/%[1]s /var/run/frr/%[1]s && chown frr:frr /var/run/frr/%[1]s && printf '%%s' '%[2]s' > /etc/frr/%[1]s/bgpd.conf",
+				secondPeerPath, bgpdConfig))
+			Expect(err).NotTo(HaveOccurred(), res)
+			// the second bgpd neither listens, not to clash with the frr-k8s one, nor talks to zebra.
+			res, err = frrExec.Exec("/usr/lib/frr/bgpd", "-N", secondPeerPath, "-d", "-Z", "-p", "0", "-P", "0",
+				"-f", fmt.Sprintf("/etc/frr/%s/bgpd.conf", secondPeerPath))
+			Expect(err).NotTo(HaveOccurred(), res)
+			DeferCleanup(func() {
+				_, _ = frrExec.Exec("sh", "-c", fmt.Sprintf("kill $(cat /var/run/frr/%s/bgpd.pid)", secondPeerPath))
+			})
+
+			By("checking both the peers of the node have a session established with the router")
+			validateSessionWithNeighbor(secondPeerPath, passthroughDynamicPeers.Name, pathspaceExecutor{frrExec, secondPeerPath}, routerIP, Established)
+			validateFRRK8sSessionForHostSession(passthroughDynamicPeers.Name, passthroughDynamicPeers.Spec.HostSession, Established, frrk8sPod)
+		})
+	})
+
 	Context("testing e2e integration between a pod the red default host", func() {
 		const testNamespace = "test-namespace"
 		var testPod *corev1.Pod
@@ -273,3 +550,17 @@ var _ = Describe("Routes between bgp and the fabric", Ordered, func() {
 	})
 
 })
+
+// pathspaceExecutor runs vtysh against the FRR instance
+// of the given pathspace, via the given executor.
+type pathspaceExecutor struct {
+	executor.Executor
+	pathspace string
+}
+
+func (e pathspaceExecutor) Exec(cmd string, args ...string) (string, error) {
+	if cmd == "vtysh" {
+		args = append([]string{"-N", e.pathspace}, args...)
+	}
+	return e.Executor.Exec(cmd, args...)
+}
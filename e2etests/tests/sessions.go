@@ -19,9 +19,11 @@ import (
 	"github.com/openperouter/openperouter/e2etests/pkg/k8sclient"
 	"github.com/openperouter/openperouter/e2etests/pkg/openperouter"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("Router Host configuration", Ordered, func() {
@@ -117,6 +119,33 @@ var _ = Describe("Router Host configuration", Ordered, func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("reports the data path as healthy on all the nodes", func() {
+			enabled, err := openperouter.ControllerHasArg(cs, "--datapath-selftest")
+			Expect(err).NotTo(HaveOccurred())
+			if !enabled {
+				Skip("the data path self test is not enabled on the controller")
+			}
+
+			Eventually(func() error {
+				toCheck := v1alpha1.L3VNI{}
+				err := Updater.Client().Get(context.Background(), client.ObjectKeyFromObject(&vni), &toCheck)
+				if err != nil {
+					return err
+				}
+				for _, node := range nodes {
+					conditionType := node.Name + "/DataPathHealthy"
+					condition := meta.FindStatusCondition(toCheck.Status.Conditions, conditionType)
+					if condition == nil {
+						return fmt.Errorf("condition %s not found in %v", conditionType, toCheck.Status.Conditions)
+					}
+					if condition.Status != metav1.ConditionTrue {
+						return fmt.Errorf("condition %s is not true: %s", conditionType, condition.Message)
+					}
+				}
+				return nil
+			}, 2*time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+
 		It("establishes a session with the host and then removes it when deleting the vni", func() {
 			frrConfig, err := frrk8s.ConfigFromHostSession(*vni.Spec.HostSession, vni.Name)
 			Expect(err).ToNot(HaveOccurred())
@@ -4,6 +4,7 @@ package tests
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/onsi/gomega"
@@ -55,3 +56,25 @@ func checkPrefixesForIPFamily(frrk8s *corev1.Pod, prefixes []string, localCIDR s
 	}
 	return nil
 }
+
+// checkBGPPrefixCommunity checks whether the route for the given prefix
+// carries the given community, as seen by the given bgp speaker.
+func checkBGPPrefixCommunity(exec executor.Executor, prefix, community string, shouldExist bool) {
+	Eventually(func() error {
+		res, err := exec.Exec("vtysh", "-c", fmt.Sprintf("show bgp ipv4 unicast %s json", prefix))
+		if err != nil {
+			return fmt.Errorf("failed to get route for %s: %s: %w", prefix, res, err)
+		}
+		if !strings.Contains(res, prefix) {
+			return fmt.Errorf("route for %s not found: %s", prefix, res)
+		}
+		hasCommunity := strings.Contains(res, community)
+		if shouldExist && !hasCommunity {
+			return fmt.Errorf("community %s not found for %s: %s", community, prefix, res)
+		}
+		if !shouldExist && hasCommunity {
+			return fmt.Errorf("community %s found for %s: %s", community, prefix, res)
+		}
+		return nil
+	}, 4*time.Minute, time.Second).WithOffset(1).ShouldNot(HaveOccurred())
+}
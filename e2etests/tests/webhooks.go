@@ -3,6 +3,9 @@
 package tests
 
 import (
+	"context"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/openperouter/openperouter/api/v1alpha1"
@@ -11,6 +14,7 @@ import (
 	"github.com/openperouter/openperouter/e2etests/pkg/openperouter"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 )
 
 var _ = Describe("Webhooks", func() {
@@ -559,6 +563,116 @@ var _ = Describe("Webhooks", func() {
 		)
 	})
 
+	Context("when deleting the underlay", func() {
+		underlay := v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "underlay1",
+				Namespace: openperouter.Namespace,
+			},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"nic1"},
+				EVPN: &v1alpha1.EVPNConfig{
+					VTEPCIDR: "192.168.1.0/24",
+				},
+			},
+		}
+		vni := v1alpha1.L3VNI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-vni-1",
+				Namespace: openperouter.Namespace,
+			},
+			Spec: v1alpha1.L3VNISpec{
+				VRF:       "test-vrf-1",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+		}
+
+		BeforeEach(func() {
+			err := Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{underlay},
+				L3VNIs:    []v1alpha1.L3VNI{vni},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should be blocked while a vni exists, and allowed after deleting it", func() {
+			err := Updater.Client().Delete(context.Background(), underlay.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("l3vni " + openperouter.Namespace + "/" + vni.Name))
+
+			By("deleting the vni")
+			err = Updater.Client().Delete(context.Background(), vni.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				return Updater.Client().Delete(context.Background(), underlay.DeepCopy())
+			}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("when deleting an l3vni", func() {
+		underlay := v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "underlay1",
+				Namespace: openperouter.Namespace,
+			},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"nic1"},
+				EVPN: &v1alpha1.EVPNConfig{
+					VTEPCIDR: "192.168.1.0/24",
+				},
+			},
+		}
+		l3vni := v1alpha1.L3VNI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-vni-1",
+				Namespace: openperouter.Namespace,
+			},
+			Spec: v1alpha1.L3VNISpec{
+				VRF:       "test-vrf-1",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+		}
+		l2vni := v1alpha1.L2VNI{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-l2vni-1",
+				Namespace: openperouter.Namespace,
+			},
+			Spec: v1alpha1.L2VNISpec{
+				VRF:       ptr.To("test-vrf-1"),
+				VNI:       200,
+				VXLanPort: 4789,
+			},
+		}
+
+		BeforeEach(func() {
+			err := Updater.Update(config.Resources{
+				Underlays: []v1alpha1.Underlay{underlay},
+				L3VNIs:    []v1alpha1.L3VNI{l3vni},
+				L2VNIs:    []v1alpha1.L2VNI{l2vni},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should be blocked while an l2vni uses its vrf, and allowed after deleting it", func() {
+			err := Updater.Client().Delete(context.Background(), l3vni.DeepCopy())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("l2vni " + openperouter.Namespace + "/" + l2vni.Name))
+
+			By("deleting the l2vni")
+			err = Updater.Client().Delete(context.Background(), l2vni.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				return Updater.Client().Delete(context.Background(), l3vni.DeepCopy())
+			}, time.Minute, time.Second).ShouldNot(HaveOccurred())
+		})
+	})
+
 	Context("when L3Passthrough webhooks are enabled", func() {
 		It("should block creating more than one passthrough", func() {
 			passthrough1 := v1alpha1.L3Passthrough{
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package audit provides the sinks the records of the configurations
+// applied to the node are emitted to.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// The kinds of sink an audit record can be emitted to.
+const (
+	SinkNone   = ""
+	SinkFile   = "file"
+	SinkEvents = "events"
+)
+
+// Record describes a configuration successfully applied to a node.
+type Record struct {
+	Time time.Time `json:"time"`
+	Node string    `json:"node"`
+	// Hash is the hash of the applied configuration.
+	Hash string `json:"hash"`
+	// Resources are the resources the configuration was built from,
+	// in the form kind/namespace/name.
+	Resources []string `json:"resources"`
+	// Phases contains the time spent in each phase of the reconciliation.
+	Phases map[string]string `json:"phases"`
+}
+
+// Sink receives the audit records.
+type Sink interface {
+	Emit(ctx context.Context, r Record) error
+}
+
+// ValidateSink checks that the given sink kind is supported and
+// provided with the parameters it requires.
+func ValidateSink(kind, filePath string) error {
+	switch kind {
+	case SinkNone, SinkEvents:
+		return nil
+	case SinkFile:
+		if filePath == "" {
+			return fmt.Errorf("audit sink %s requires a file path", kind)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported audit sink %q, must be one of %s, %s", kind, SinkFile, SinkEvents)
+}
+
+// FileSink appends the records to a file, one json document per line.
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (s *FileSink) Emit(_ context.Context, r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file %s: %w", s.Path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record to %s: %w", s.Path, err)
+	}
+	return nil
+}
+
+// EventSink emits the records as Kubernetes events attached to the node.
+type EventSink struct {
+	Recorder record.EventRecorder
+}
+
+const (
+	// ConfigurationAppliedReason is the reason of the events emitted by the EventSink.
+	ConfigurationAppliedReason = "ConfigurationApplied"
+	// maxEventMessageLength is the length the event messages are truncated
+	// to, the full record is available only with the file sink.
+	maxEventMessageLength = 1024
+)
+
+func (s *EventSink) Emit(_ context.Context, r Record) error {
+	node := &corev1.ObjectReference{Kind: "Node", Name: r.Node, APIVersion: "v1"}
+	phases := make([]string, 0, len(r.Phases))
+	for phase, duration := range r.Phases {
+		phases = append(phases, phase+"="+duration)
+	}
+	sort.Strings(phases)
+	message := fmt.Sprintf("applied configuration %s from %s, phases %s",
+		r.Hash, strings.Join(r.Resources, ","), strings.Join(phases, ","))
+	if len(message) > maxEventMessageLength {
+		message = message[:maxEventMessageLength-3] + "..."
+	}
+	s.Recorder.Event(node, corev1.EventTypeNormal, ConfigurationAppliedReason, message)
+	return nil
+}
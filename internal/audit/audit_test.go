@@ -0,0 +1,91 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/tools/record"
+)
+
+func testRecord() Record {
+	return Record{
+		Time:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Node:      "node1",
+		Hash:      "abcd",
+		Resources: []string{"underlay/openperouter-system/underlay"},
+		Phases:    map[string]string{"frr": "1s", "host": "2s"},
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink := &FileSink{Path: path}
+	want := testRecord()
+	for range 2 {
+		if err := sink.Emit(context.Background(), want); err != nil {
+			t.Fatalf("Emit() unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the audit file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(lines))
+	}
+	for _, l := range lines {
+		var got Record
+		if err := json.Unmarshal([]byte(l), &got); err != nil {
+			t.Fatalf("failed to unmarshal record %s: %v", l, err)
+		}
+		if !cmp.Equal(got, want) {
+			t.Errorf("record diff %s", cmp.Diff(got, want))
+		}
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	sink := &EventSink{Recorder: recorder}
+	if err := sink.Emit(context.Background(), testRecord()); err != nil {
+		t.Fatalf("Emit() unexpected error: %v", err)
+	}
+	got := <-recorder.Events
+	want := "Normal ConfigurationApplied applied configuration abcd from underlay/openperouter-system/underlay, phases frr=1s,host=2s"
+	if got != want {
+		t.Errorf("expected event %q, got %q", want, got)
+	}
+}
+
+func TestValidateSink(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		filePath string
+		wantErr  bool
+	}{
+		{name: "none", kind: SinkNone},
+		{name: "events", kind: SinkEvents},
+		{name: "file", kind: SinkFile, filePath: "/var/log/audit.log"},
+		{name: "file without path", kind: SinkFile, wantErr: true},
+		{name: "unknown", kind: "syslog", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSink(tt.kind, tt.filePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package frrk8sinterop warns about the FRRConfigurations of frr-k8s that
+// peer with the router of an L3VNI with ASNs not matching its host session.
+package frrk8sinterop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	frrk8sapi "github.com/metallb/frr-k8s/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/ipam"
+)
+
+// HostSessionConflictCondition is the type of the condition reported on the
+// L3VNIs telling if an FRRConfiguration peers with the router of the L3VNI
+// using ASNs different from the ones of its host session.
+const HostSessionConflictCondition = "FRRConfigurationConflict"
+
+const (
+	reasonNoConflict  = "NoConflict"
+	reasonASNMismatch = "ASNMismatch"
+)
+
+// allRequest is the only request reconciled, as any change of an L3VNI or
+// of an FRRConfiguration requires checking all of them again.
+var allRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "frrk8sinterop"}}
+
+type Reconciler struct {
+	client.Client
+	Logger *slog.Logger
+}
+
+// +kubebuilder:rbac:groups=frrk8s.metallb.io,resources=frrconfigurations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=l3vnis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=l3vnis/status,verbs=get;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger.With("controller", "FRRK8sInterop", "request", req.String())
+	logger.Info("start reconcile")
+	defer logger.Info("end reconcile")
+
+	var l3vnis v1alpha1.L3VNIList
+	if err := r.List(ctx, &l3vnis); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list l3vnis: %w", err)
+	}
+	var frrConfigs frrk8sapi.FRRConfigurationList
+	if err := r.List(ctx, &frrConfigs); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list frrconfigurations: %w", err)
+	}
+
+	errs := []error{}
+	for _, vni := range l3vnis.Items {
+		if vni.Spec.HostSession == nil {
+			continue
+		}
+		conflicts, err := hostSessionConflicts(*vni.Spec.HostSession, frrConfigs.Items)
+		if err != nil {
+			logger.Error("failed to check the host session", "l3vni", vni.Name, "error", err)
+			continue
+		}
+		condition := metav1.Condition{
+			Type:    HostSessionConflictCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonNoConflict,
+			Message: "no frrconfiguration conflicts with the host session",
+		}
+		if len(conflicts) > 0 {
+			logger.Warn("frrconfigurations conflicting with the host session", "l3vni", vni.Name, "conflicts", conflicts)
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = reasonASNMismatch
+			condition.Message = strings.Join(conflicts, "; ")
+		}
+		if err := r.setL3VNICondition(ctx, client.ObjectKeyFromObject(&vni), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return ctrl.Result{}, errors.Join(errs...)
+}
+
+// hostSessionConflicts returns a description of each neighbor of the given
+// FRRConfigurations peering with the router side of the host session, with
+// ASNs not matching the ones of the session.
+func hostSessionConflicts(session v1alpha1.HostSession, configs []frrk8sapi.FRRConfiguration) ([]string, error) {
+	veths, err := ipam.VethIPsFromPool(session.LocalCIDR.IPv4, session.LocalCIDR.IPv6, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local cidr: %w", err)
+	}
+	routerIPs := map[string]bool{}
+	for _, ip := range []ipam.VethIPsForFamily{veths.Ipv4, veths.Ipv6} {
+		if ip.PeSide.IP != nil {
+			routerIPs[ip.PeSide.IP.String()] = true
+		}
+	}
+
+	res := []string{}
+	for _, config := range configs {
+		for _, router := range config.Spec.BGP.Routers {
+			for _, neighbor := range router.Neighbors {
+				if !routerIPs[neighbor.Address] {
+					continue
+				}
+				if neighbor.ASN != 0 && neighbor.ASN != session.ASN {
+					res = append(res, fmt.Sprintf("frrconfiguration %s/%s peers with %s using asn %d instead of %d",
+						config.Namespace, config.Name, neighbor.Address, neighbor.ASN, session.ASN))
+				}
+				if session.HostASN != 0 && router.ASN != session.HostASN {
+					res = append(res, fmt.Sprintf("frrconfiguration %s/%s peers with %s from asn %d instead of %d",
+						config.Namespace, config.Name, neighbor.Address, router.ASN, session.HostASN))
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *Reconciler) setL3VNICondition(ctx context.Context, key client.ObjectKey, condition metav1.Condition) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vni := &v1alpha1.L3VNI{}
+		if err := r.Get(ctx, key, vni); err != nil {
+			return err
+		}
+		condition.ObservedGeneration = vni.Generation
+		if !meta.SetStatusCondition(&vni.Status.Conditions, condition) {
+			return nil
+		}
+		return r.Status().Update(ctx, vni)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the status of l3vni %s: %w", key, err)
+	}
+	return nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueAll := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{allRequest}
+	})
+	// Only the changes of the spec of the L3VNIs are relevant, so that
+	// updating their status doesn't trigger a new reconciliation.
+	return ctrl.NewControllerManagedBy(mgr).
+		Watches(&v1alpha1.L3VNI{}, enqueueAll, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&frrk8sapi.FRRConfiguration{}, enqueueAll, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("frrk8sinteropcontroller").
+		Complete(r)
+}
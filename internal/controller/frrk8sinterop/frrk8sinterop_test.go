@@ -0,0 +1,133 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frrk8sinterop
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	frrk8sapi "github.com/metallb/frr-k8s/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	frrConfig := func(name string, asn uint32, neighbor string, neighborASN uint32) *frrk8sapi.FRRConfiguration {
+		return &frrk8sapi.FRRConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "frr-k8s-system"},
+			Spec: frrk8sapi.FRRConfigurationSpec{
+				BGP: frrk8sapi.BGPConfig{
+					Routers: []frrk8sapi.Router{
+						{
+							ASN:       asn,
+							Neighbors: []frrk8sapi.Neighbor{{Address: neighbor, ASN: neighborASN}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		frrConfigs   []client.Object
+		wantStatus   metav1.ConditionStatus
+		wantMessages []string
+	}{
+		{
+			name:       "no frrconfiguration",
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name: "matching frrconfiguration",
+			frrConfigs: []client.Object{
+				frrConfig("red", 64515, "192.169.10.1", 64514),
+				frrConfig("red-v6", 64515, "2001:db8:1::1", 64514),
+			},
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name: "frrconfiguration peering with another address",
+			frrConfigs: []client.Object{
+				frrConfig("other", 65000, "192.168.1.1", 65001),
+			},
+			wantStatus: metav1.ConditionFalse,
+		},
+		{
+			name: "frrconfiguration with a mismatched router asn",
+			frrConfigs: []client.Object{
+				frrConfig("red", 64515, "192.169.10.1", 65000),
+			},
+			wantStatus:   metav1.ConditionTrue,
+			wantMessages: []string{"frrconfiguration frr-k8s-system/red peers with 192.169.10.1 using asn 65000 instead of 64514"},
+		},
+		{
+			name: "frrconfiguration with a mismatched host asn",
+			frrConfigs: []client.Object{
+				frrConfig("red-v6", 65001, "2001:db8:1::1", 64514),
+			},
+			wantStatus:   metav1.ConditionTrue,
+			wantMessages: []string{"frrconfiguration frr-k8s-system/red-v6 peers with 2001:db8:1::1 from asn 65001 instead of 64515"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vni := &v1alpha1.L3VNI{
+				ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+				Spec: v1alpha1.L3VNISpec{
+					VRF: "red",
+					VNI: 100,
+					HostSession: &v1alpha1.HostSession{
+						ASN:     64514,
+						HostASN: 64515,
+						LocalCIDR: v1alpha1.LocalCIDRConfig{
+							IPv4: "192.169.10.0/24",
+							IPv6: "2001:db8:1::/64",
+						},
+					},
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+			}
+			if err := frrk8sapi.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add frr-k8s to scheme: %v", err)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(tt.frrConfigs, vni)...).
+				WithStatusSubresource(&v1alpha1.L3VNI{}).Build()
+
+			r := &Reconciler{Client: cli, Logger: slog.Default()}
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, allRequest); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+
+			got := &v1alpha1.L3VNI{}
+			if err := cli.Get(ctx, client.ObjectKeyFromObject(vni), got); err != nil {
+				t.Fatalf("failed to get the l3vni: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, HostSessionConflictCondition)
+			if condition == nil {
+				t.Fatalf("condition not found on the l3vni: %+v", got.Status.Conditions)
+			}
+			if condition.Status != tt.wantStatus {
+				t.Errorf("expected condition status %s, got %s (%s)", tt.wantStatus, condition.Status, condition.Message)
+			}
+			for _, m := range tt.wantMessages {
+				if !strings.Contains(condition.Message, m) {
+					t.Errorf("expected %q in the condition message, got %q", m, condition.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package nodeindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/ipam"
+)
+
+// AllocationsConfigMap is the name of the ConfigMap the node index
+// allocations are published to, in the namespace of the nodemarker.
+const AllocationsConfigMap = "openpe-node-indexes"
+
+// NodeAllocation is what is allocated to a node, as published in the
+// AllocationsConfigMap under the name of the node.
+type NodeAllocation struct {
+	Index    int    `json:"index"`
+	VTEPIP   string `json:"vtepIP,omitempty"`
+	VTEPIPv6 string `json:"vtepIPv6,omitempty"`
+}
+
+// nodeAllocations returns the json encoded allocation of each node with a
+// valid index, keyed by node name. The VTEP IPs are derived from the EVPN
+// configuration of the underlay, and left empty if there is none.
+func nodeAllocations(nodes []v1.Node, underlays []v1alpha1.Underlay) (map[string]string, error) {
+	var evpn *v1alpha1.EVPNConfig
+	if len(underlays) > 0 {
+		evpn = underlays[0].Spec.EVPN
+	}
+
+	res := map[string]string{}
+	for _, n := range nodes {
+		index, err := strconv.Atoi(n.Annotations[OpenpeNodeIndex])
+		if err != nil {
+			continue
+		}
+		allocation := NodeAllocation{Index: index}
+		if evpn != nil && evpn.VTEPCIDR != "" {
+			ip, err := ipam.VTEPIp(evpn.VTEPCIDR, index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the vtep ip of node %s: %w", n.Name, err)
+			}
+			allocation.VTEPIP = ip.IP.String()
+		}
+		if evpn != nil && evpn.VTEPCIDRv6 != nil {
+			ip, err := ipam.VTEPIp(*evpn.VTEPCIDRv6, index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the ipv6 vtep ip of node %s: %w", n.Name, err)
+			}
+			allocation.VTEPIPv6 = ip.IP.String()
+		}
+		data, err := json.Marshal(allocation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the allocation of node %s: %w", n.Name, err)
+		}
+		res[n.Name] = string(data)
+	}
+	return res, nil
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package nodeindex
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestNodeAllocations(t *testing.T) {
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "first", Annotations: map[string]string{OpenpeNodeIndex: "0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "second", Annotations: map[string]string{OpenpeNodeIndex: "3"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "third", Annotations: map[string]string{OpenpeNodeIndex: "foo"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "fourth"}},
+	}
+
+	tests := []struct {
+		name      string
+		underlays []v1alpha1.Underlay
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "no underlay",
+			want: map[string]string{
+				"first":  `{"index":0}`,
+				"second": `{"index":3}`,
+			},
+		},
+		{
+			name: "underlay with vtep cidrs",
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR:   "100.65.0.0/24",
+							VTEPCIDRv6: ptr.To("2001:db8:65::/64"),
+						},
+					},
+				},
+			},
+			want: map[string]string{
+				"first":  `{"index":0,"vtepIP":"100.65.0.0","vtepIPv6":"2001:db8:65::"}`,
+				"second": `{"index":3,"vtepIP":"100.65.0.3","vtepIPv6":"2001:db8:65::3"}`,
+			},
+		},
+		{
+			name: "vtep cidr too small",
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "100.65.0.0/31"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nodeAllocations(nodes, tt.underlays)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected allocations, diff %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestPublishAllocations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go to scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &NodesReconciler{Client: cli, Logger: slog.Default(), Namespace: "openperouter-system"}
+	ctx := context.Background()
+
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "first", Annotations: map[string]string{OpenpeNodeIndex: "0"}}},
+	}
+	if err := r.publishAllocations(ctx, nodes); err != nil {
+		t.Fatalf("failed to publish the allocations: %v", err)
+	}
+	checkAllocations(t, cli, map[string]string{"first": `{"index":0}`})
+
+	nodes = append(nodes, v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "second", Annotations: map[string]string{OpenpeNodeIndex: "1"}},
+	})
+	if err := r.publishAllocations(ctx, nodes); err != nil {
+		t.Fatalf("failed to publish the allocations: %v", err)
+	}
+	checkAllocations(t, cli, map[string]string{"first": `{"index":0}`, "second": `{"index":1}`})
+}
+
+func checkAllocations(t *testing.T, cli client.Client, want map[string]string) {
+	t.Helper()
+	var cm v1.ConfigMap
+	key := client.ObjectKey{Namespace: "openperouter-system", Name: AllocationsConfigMap}
+	if err := cli.Get(context.Background(), key, &cm); err != nil {
+		t.Fatalf("failed to get the allocations configmap: %v", err)
+	}
+	if !cmp.Equal(cm.Data, want) {
+		t.Errorf("unexpected allocations, diff %s", cmp.Diff(want, cm.Data))
+	}
+}
@@ -18,14 +18,22 @@ package nodeindex
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"maps"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	v1 "k8s.io/api/core/v1"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
 )
 
 type requestKey string
@@ -35,9 +43,13 @@ type NodesReconciler struct {
 	Scheme   *runtime.Scheme
 	LogLevel string
 	Logger   *slog.Logger
+	// Namespace is where the AllocationsConfigMap is published. The
+	// allocations are not published if empty.
+	Namespace string
 }
 
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=underlays,verbs=get;list;watch
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,verbs=get;list;watch
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,resourceNames="openpe-validating-webhook-configuration",verbs=update
 
@@ -54,21 +66,75 @@ func (r *NodesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
+	annotated := map[string]v1.Node{}
 	nodesToAnnotate := nodesToAnnotate(nodes.Items)
 	for _, n := range nodesToAnnotate {
 		if err := r.Update(ctx, &n); err != nil {
 			slog.Error("failed to update node", "node", n.Name, "error", err)
 			return ctrl.Result{}, err
 		}
+		annotated[n.Name] = n
+	}
+
+	if r.Namespace == "" {
+		return ctrl.Result{}, nil
+	}
+	for i, n := range nodes.Items {
+		if updated, ok := annotated[n.Name]; ok {
+			nodes.Items[i] = updated
+		}
+	}
+	if err := r.publishAllocations(ctx, nodes.Items); err != nil {
+		slog.Error("failed to publish the node allocations", "error", err)
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// publishAllocations writes the allocation of each node to the
+// AllocationsConfigMap, for the administrators to audit them.
+func (r *NodesReconciler) publishAllocations(ctx context.Context, nodes []v1.Node) error {
+	var underlays v1alpha1.UnderlayList
+	if err := r.List(ctx, &underlays); err != nil {
+		return fmt.Errorf("failed to list underlays: %w", err)
+	}
+	allocations, err := nodeAllocations(nodes, underlays.Items)
+	if err != nil {
+		return err
+	}
+
+	var cm v1.ConfigMap
+	err = r.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: AllocationsConfigMap}, &cm)
+	if apierrors.IsNotFound(err) {
+		cm = v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: r.Namespace, Name: AllocationsConfigMap},
+			Data:       allocations,
+		}
+		if err := r.Create(ctx, &cm); err != nil {
+			return fmt.Errorf("failed to create configmap %s: %w", AllocationsConfigMap, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get configmap %s: %w", AllocationsConfigMap, err)
+	}
+	if maps.Equal(cm.Data, allocations) {
+		return nil
+	}
+	cm.Data = allocations
+	if err := r.Update(ctx, &cm); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %w", AllocationsConfigMap, err)
+	}
+	return nil
+}
+
 func (r *NodesReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// The underlays are watched as the published allocations
+	// include the VTEP IPs, derived from their EVPN configuration.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1.Node{}).
-		WithEventFilter(predicate.AnnotationChangedPredicate{}).
+		For(&v1.Node{}, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Watches(&v1alpha1.Underlay{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Named("nodecontroller").
 		Complete(r)
 }
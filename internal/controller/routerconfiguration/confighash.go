@@ -0,0 +1,38 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"sync"
+
+	"github.com/openperouter/openperouter/internal/metrics"
+)
+
+// configHashTracker remembers the hash of the configuration last
+// applied, to tell how often a reconciliation applies the same one.
+type configHashTracker struct {
+	mu      sync.Mutex
+	applied string
+}
+
+// setApplied records the hash of the applied configuration, and
+// tells if it matches the one of the configuration applied before.
+func (t *configHashTracker) setApplied(hash string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	unchanged := t.applied == hash
+	t.applied = hash
+	return unchanged
+}
+
+// configApplied counts the applied configuration as a cache hit if it
+// matches the last applied one, and as a cache miss otherwise. It tells
+// if the configuration changed.
+func (r *PERouterReconciler) configApplied(hash string) bool {
+	if r.configHashes.setApplied(hash) {
+		metrics.ReconcileCacheHits.Inc()
+		return false
+	}
+	metrics.ReconcileCacheMisses.Inc()
+	return true
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openperouter/openperouter/internal/metrics"
+)
+
+func TestConfigAppliedCacheMetrics(t *testing.T) {
+	r := &PERouterReconciler{}
+	hits := testutil.ToFloat64(metrics.ReconcileCacheHits)
+	misses := testutil.ToFloat64(metrics.ReconcileCacheMisses)
+
+	steps := []struct {
+		hash        string
+		wantHits    float64
+		wantMisses  float64
+		wantChanged bool
+	}{
+		{hash: "first", wantHits: 0, wantMisses: 1, wantChanged: true},
+		{hash: "first", wantHits: 1, wantMisses: 1, wantChanged: false},
+		{hash: "first", wantHits: 2, wantMisses: 1, wantChanged: false},
+		{hash: "second", wantHits: 2, wantMisses: 2, wantChanged: true},
+		{hash: "first", wantHits: 2, wantMisses: 3, wantChanged: true},
+	}
+	for i, s := range steps {
+		if changed := r.configApplied(s.hash); changed != s.wantChanged {
+			t.Errorf("step %d: expected changed %v, got %v", i, s.wantChanged, changed)
+		}
+		if got := testutil.ToFloat64(metrics.ReconcileCacheHits) - hits; got != s.wantHits {
+			t.Errorf("step %d: expected %v cache hits, got %v", i, s.wantHits, got)
+		}
+		if got := testutil.ToFloat64(metrics.ReconcileCacheMisses) - misses; got != s.wantMisses {
+			t.Errorf("step %d: expected %v cache misses, got %v", i, s.wantMisses, got)
+		}
+	}
+}
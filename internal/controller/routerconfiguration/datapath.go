@@ -0,0 +1,90 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+)
+
+const (
+	// DataPathHealthyCondition is the type of the condition, prefixed by the
+	// node name, reporting if the host side of the session of an L3VNI is
+	// reachable from the router running on that node.
+	DataPathHealthyCondition = "DataPathHealthy"
+
+	reasonHostReachable   = "HostReachable"
+	reasonHostUnreachable = "HostUnreachable"
+
+	// dataPathRecheckInterval is the interval the data path self test
+	// is run again after, as long as any L3VNI is not healthy.
+	dataPathRecheckInterval = 30 * time.Second
+)
+
+// ping is the data path probe, overridden in tests.
+var ping = hostnetwork.Ping
+
+// checkDataPath pings the host side of the session of each L3VNI from the
+// vrf of the L3VNI in the router namespace, and records the result as a condition of the L3VNI.
+// It returns true if the host is reachable for all the L3VNIs.
+func (r *PERouterReconciler) checkDataPath(ctx context.Context, l3vnis []v1alpha1.L3VNI, nodeIndex int, targetNS string) (bool, error) {
+	allHealthy := true
+	errs := []error{}
+	for _, vni := range l3vnis {
+		ips, err := conversion.HostSessionIPs(vni, nodeIndex)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(ips) == 0 {
+			continue
+		}
+
+		condition := metav1.Condition{
+			Type:    dataPathConditionType(r.MyNode),
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonHostReachable,
+			Message: fmt.Sprintf("host reachable at %v", ips),
+		}
+		for _, ip := range ips {
+			if err := ping(ctx, targetNS, vni.Spec.VRF, ip); err != nil {
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = reasonHostUnreachable
+				condition.Message = err.Error()
+				allHealthy = false
+				break
+			}
+		}
+		slog.DebugContext(ctx, "data path self test", "vni", vni.Name, "healthy", condition.Status, "message", condition.Message)
+
+		if r.MyNode == "" {
+			continue
+		}
+		if err := r.setL3VNICondition(ctx, client.ObjectKeyFromObject(&vni), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return allHealthy, errors.Join(errs...)
+}
+
+func (r *PERouterReconciler) setL3VNICondition(ctx context.Context, key client.ObjectKey, condition metav1.Condition) error {
+	vni := &v1alpha1.L3VNI{}
+	if err := r.setNodeCondition(ctx, key, vni, &vni.Status.Conditions, condition); err != nil {
+		return fmt.Errorf("failed to update the status of l3vni %s: %w", key, err)
+	}
+	return nil
+}
+
+func dataPathConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, DataPathHealthyCondition)
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestCheckDataPath(t *testing.T) {
+	l3vnis := []v1alpha1.L3VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+			Spec: v1alpha1.L3VNISpec{
+				VRF: "red",
+				VNI: 100,
+				HostSession: &v1alpha1.HostSession{
+					ASN:       65000,
+					HostASN:   65001,
+					LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"},
+			Spec: v1alpha1.L3VNISpec{
+				VRF: "blue",
+				VNI: 101,
+				HostSession: &v1alpha1.HostSession{
+					ASN:       65000,
+					HostASN:   65001,
+					LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.11.0/24"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nosession", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L3VNISpec{VRF: "green", VNI: 102},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	objects := []client.Object{}
+	for i := range l3vnis {
+		objects = append(objects, l3vnis[i].DeepCopy())
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&v1alpha1.L3VNI{}).Build()
+
+	pinged := []string{}
+	oldPing := ping
+	t.Cleanup(func() { ping = oldPing })
+	ping = func(_ context.Context, targetNS, vrf, ip string) error {
+		if targetNS != "namespace" {
+			t.Errorf("expected ping from namespace %q, got %q", "namespace", targetNS)
+		}
+		pinged = append(pinged, vrf+"/"+ip)
+		if ip == "192.169.11.3" {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+
+	r := &PERouterReconciler{Client: cli, MyNode: "node1"}
+	healthy, err := r.checkDataPath(context.Background(), l3vnis, 1, "namespace")
+	if err != nil {
+		t.Fatalf("checkDataPath() unexpected error: %v", err)
+	}
+	if healthy {
+		t.Errorf("checkDataPath() expected unhealthy data path")
+	}
+	if len(pinged) != 2 || pinged[0] != "red/192.169.10.3" || pinged[1] != "blue/192.169.11.3" {
+		t.Errorf("unexpected pinged ips %v", pinged)
+	}
+
+	wantStatus := map[string]metav1.ConditionStatus{
+		"red":  metav1.ConditionTrue,
+		"blue": metav1.ConditionFalse,
+	}
+	for name, want := range wantStatus {
+		vni := &v1alpha1.L3VNI{}
+		if err := cli.Get(context.Background(), client.ObjectKey{Name: name, Namespace: "openperouter-system"}, vni); err != nil {
+			t.Fatalf("failed to get l3vni %s: %v", name, err)
+		}
+		condition := meta.FindStatusCondition(vni.Status.Conditions, "node1/"+DataPathHealthyCondition)
+		if condition == nil {
+			t.Fatalf("expected data path condition on l3vni %s, got %v", name, vni.Status.Conditions)
+		}
+		if condition.Status != want {
+			t.Errorf("expected data path condition status %s on l3vni %s, got %s", want, name, condition.Status)
+		}
+	}
+
+	vni := &v1alpha1.L3VNI{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Name: "nosession", Namespace: "openperouter-system"}, vni); err != nil {
+		t.Fatalf("failed to get l3vni nosession: %v", err)
+	}
+	if len(vni.Status.Conditions) != 0 {
+		t.Errorf("expected no conditions on l3vni without host session, got %v", vni.Status.Conditions)
+	}
+}
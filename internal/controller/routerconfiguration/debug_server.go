@@ -0,0 +1,60 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/openperouter/openperouter/internal/probes"
+)
+
+// DefaultDebugAddr is the address the debugging endpoints are served on
+// when none is provided.
+const DefaultDebugAddr = "127.0.0.1:9082"
+
+// NewDebugServer returns a runnable to be added to the manager, serving the
+// given debugging handlers, keyed by path, on the given address. As the
+// controller runs in the network namespace of the node and the endpoints
+// are not authenticated, the address must be a loopback one.
+func NewDebugServer(addr string, handlers map[string]http.Handler) (*manager.Server, error) {
+	if err := validateLoopback(addr); err != nil {
+		return nil, err
+	}
+	listener, err := probes.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+	return &manager.Server{
+		Name: "debug",
+		Server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 32 * time.Second,
+		},
+		Listener: listener,
+	}, nil
+}
+
+func validateLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %s: %w", addr, err)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("invalid host in debug address %s, must be a loopback ip: %w", addr, err)
+	}
+	if !ip.IsLoopback() {
+		return fmt.Errorf("invalid debug address %s, must be a loopback ip", addr)
+	}
+	return nil
+}
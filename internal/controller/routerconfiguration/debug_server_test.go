@@ -0,0 +1,28 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import "testing"
+
+func TestValidateLoopback(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:9082"},
+		{addr: "[::1]:9082"},
+		{addr: ":9082", wantErr: true},
+		{addr: "0.0.0.0:9082", wantErr: true},
+		{addr: "192.168.1.1:9082", wantErr: true},
+		{addr: "localhost:9082", wantErr: true},
+		{addr: "127.0.0.1", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.addr, func(t *testing.T) {
+			err := validateLoopback(tc.addr)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateLoopback(%s): expected error %t, got %v", tc.addr, tc.wantErr, err)
+			}
+		})
+	}
+}
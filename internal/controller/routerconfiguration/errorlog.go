@@ -0,0 +1,110 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// repeatedErrorLogInterval is the interval an error is logged at most once
+// within. A failing reconcile is retried with a backoff, and a persistent
+// failure would otherwise flood the logs with the same line.
+const repeatedErrorLogInterval = time.Minute
+
+// failedReconcileRetryInterval is the interval a failed reconciliation is
+// retried after.
+const failedReconcileRetryInterval = 10 * time.Second
+
+// reconcileErrors limits the logging of the errors of the reconciliation.
+var reconcileErrors = newErrorLogLimiter(repeatedErrorLogInterval)
+
+// retryOnError logs the given reconciliation error, unless an identical one
+// was logged recently, and requeues the reconciliation. The error is not
+// returned, as controller-runtime would log it on each retry.
+func retryOnError(ctx context.Context, msg string, err error) (ctrl.Result, error) {
+	reconcileErrors.logError(ctx, msg, err)
+	return ctrl.Result{RequeueAfter: failedReconcileRetryInterval}, nil
+}
+
+// errorLogLimiter logs each error at most once per interval. The identical
+// errors happening within the interval are counted, and their number is
+// logged the next time the error is logged, or once the interval expires
+// if the error does not happen anymore. Two errors are identical when they
+// are logged with the same message, error and attributes.
+type errorLogLimiter struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	logged map[string]loggedError
+}
+
+type loggedError struct {
+	msg        string
+	err        string
+	at         time.Time
+	suppressed int
+}
+
+func newErrorLogLimiter(interval time.Duration) *errorLogLimiter {
+	return &errorLogLimiter{
+		interval: interval,
+		now:      time.Now,
+		logged:   map[string]loggedError{},
+	}
+}
+
+// logError logs the given error with the given message and attributes,
+// unless an identical error was logged within the interval.
+func (l *errorLogLimiter) logError(ctx context.Context, msg string, err error, args ...any) {
+	key := fmt.Sprint(msg, err, args)
+
+	l.mu.Lock()
+	now := l.now()
+	expired := l.expire(now, key)
+	previous, ok := l.logged[key]
+	if ok && now.Sub(previous.at) < l.interval {
+		previous.suppressed++
+		l.logged[key] = previous
+		l.mu.Unlock()
+		logSuppressed(ctx, expired)
+		return
+	}
+	l.logged[key] = loggedError{msg: msg, err: err.Error(), at: now}
+	l.mu.Unlock()
+
+	logSuppressed(ctx, expired)
+	if previous.suppressed > 0 {
+		args = append(args, "suppressed", previous.suppressed)
+	}
+	slog.ErrorContext(ctx, msg, append(args, "error", err)...)
+}
+
+// expire forgets the errors, other than the one with the given key,
+// last logged more than the interval ago, and returns the ones that
+// were suppressed since.
+func (l *errorLogLimiter) expire(now time.Time, key string) []loggedError {
+	var res []loggedError
+	for k, e := range l.logged {
+		if k == key || now.Sub(e.at) < l.interval {
+			continue
+		}
+		delete(l.logged, k)
+		if e.suppressed > 0 {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func logSuppressed(ctx context.Context, errs []loggedError) {
+	for _, e := range errs {
+		slog.InfoContext(ctx, "repeated error suppressed", "message", e.msg, "error", e.err, "suppressed", e.suppressed)
+	}
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestErrorLogLimiter(t *testing.T) {
+	recorder := &recordingHandler{}
+	oldLogger := slog.Default()
+	slog.SetDefault(slog.New(recorder))
+	t.Cleanup(func() { slog.SetDefault(oldLogger) })
+
+	now := time.Now()
+	limiter := newErrorLogLimiter(time.Minute)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+	err := errors.New("failed to create the vrf")
+
+	for range 5 {
+		limiter.logError(ctx, "failed to setup vni", err, "vni", "red")
+		now = now.Add(time.Second)
+	}
+	if len(recorder.records) != 1 {
+		t.Fatalf("expected 1 log line for the repeated error, got %d: %v", len(recorder.records), recorder.records)
+	}
+
+	limiter.logError(ctx, "failed to setup vni", err, "vni", "blue")
+	if len(recorder.records) != 2 {
+		t.Fatalf("expected the error of another vni to be logged, got %d lines: %v", len(recorder.records), recorder.records)
+	}
+
+	now = now.Add(time.Minute)
+	limiter.logError(ctx, "failed to setup vni", err, "vni", "red")
+	if len(recorder.records) != 3 {
+		t.Fatalf("expected the error to be logged again after the interval, got %d lines: %v", len(recorder.records), recorder.records)
+	}
+	last := recorder.records[2]
+	if last["suppressed"] != "4" || last["vni"] != "red" {
+		t.Errorf("expected the red vni error with 4 suppressed, got %v", last)
+	}
+
+	limiter.logError(ctx, "failed to setup vni", err, "vni", "red")
+	now = now.Add(time.Minute)
+	limiter.logError(ctx, "failed to configure the host", err)
+	if len(recorder.records) != 5 {
+		t.Fatalf("expected the suppressed summary and the new error, got %d lines: %v", len(recorder.records), recorder.records)
+	}
+	summary := recorder.records[3]
+	if summary["message"] != "failed to setup vni" || summary["suppressed"] != "1" {
+		t.Errorf("expected a summary of the suppressed error, got %v", summary)
+	}
+}
+
+func TestRetryOnError(t *testing.T) {
+	recorder := &recordingHandler{}
+	oldLogger := slog.Default()
+	slog.SetDefault(slog.New(recorder))
+	oldReconcileErrors := reconcileErrors
+	reconcileErrors = newErrorLogLimiter(time.Minute)
+	t.Cleanup(func() {
+		slog.SetDefault(oldLogger)
+		reconcileErrors = oldReconcileErrors
+	})
+
+	err := errors.New("failed to list the underlays")
+	for range 3 {
+		res, retErr := retryOnError(context.Background(), "failed to list the resources", err)
+		if retErr != nil {
+			t.Fatalf("retryOnError() returned error %v, expected the error not to be returned", retErr)
+		}
+		if res.RequeueAfter != failedReconcileRetryInterval {
+			t.Fatalf("retryOnError() requeue after %v, want %v", res.RequeueAfter, failedReconcileRetryInterval)
+		}
+	}
+	if len(recorder.records) != 1 {
+		t.Errorf("expected 1 log line for the repeated error, got %d: %v", len(recorder.records), recorder.records)
+	}
+}
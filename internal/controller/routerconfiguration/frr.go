@@ -19,20 +19,36 @@ type frrConfigData struct {
 }
 
 func configureFRR(ctx context.Context, data frrConfigData) error {
+	ctx = withPhase(ctx, phaseFRR)
 	slog.DebugContext(ctx, "reloading FRR config", "config", data)
-	frrConfig, err := conversion.APItoFRR(data.ApiConfigData)
+	configString, err := GenerateFRRConfig(ctx, data.ApiConfigData)
+	if err != nil {
+		return err
+	}
+
+	err = data.updater(ctx, configString)
+	if err != nil {
+		return fmt.Errorf("failed to update the frr configuration: %w", err)
+	}
+	return nil
+}
+
+// GenerateFRRConfig returns the FRR configuration file the given resources
+// are converted to, without applying it.
+func GenerateFRRConfig(ctx context.Context, apiConfig conversion.ApiConfigData) (string, error) {
+	frrConfig, err := conversion.APItoFRR(apiConfig)
 	emptyConfig := conversion.FRREmptyConfigError("")
 	if errors.As(err, &emptyConfig) {
-		slog.InfoContext(ctx, "reloading FRR config", "empty config", data, "event", "cleaning the frr configuration")
+		slog.InfoContext(ctx, "generating FRR config", "empty config", apiConfig, "event", "cleaning the frr configuration")
 		frrConfig = frr.Config{}
 	}
 	if err != nil && !errors.As(err, &emptyConfig) {
-		return fmt.Errorf("failed to generate the frr configuration: %w", err)
+		return "", fmt.Errorf("failed to generate the frr configuration: %w", err)
 	}
 
-	err = frr.ApplyConfig(ctx, &frrConfig, data.updater)
+	configString, err := frr.GenerateConfig(ctx, &frrConfig)
 	if err != nil {
-		return fmt.Errorf("failed to update the frr configuration: %w", err)
+		return "", fmt.Errorf("failed to generate the frr configuration: %w", err)
 	}
-	return nil
+	return configString, nil
 }
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// FRRConfigExportPath is the path the FRRConfigExportHandler is served on.
+const FRRConfigExportPath = "/frrconfig/export"
+
+// FRRConfigExportHandler writes, on each POST request, the FRR configuration
+// the current resources are converted to for this node to the file at Path,
+// for export or backup purposes. FRR is never reloaded. The configuration is
+// written to a file on the node instead of being served, as it contains the
+// passwords of the sessions.
+type FRRConfigExportHandler struct {
+	// Reconciler is the reconciler applying the configuration
+	// to this node, whose parameters the resources are completed with.
+	Reconciler *PERouterReconciler
+	Path       string
+}
+
+func (h *FRRConfigExportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.export(req); err != nil {
+		slog.ErrorContext(req.Context(), "failed to export the frr configuration", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := fmt.Fprintf(w, "frr configuration written to %s\n", h.Path); err != nil {
+		slog.ErrorContext(req.Context(), "failed to write the export response", "error", err)
+	}
+}
+
+func (h *FRRConfigExportHandler) export(req *http.Request) error {
+	ctx := req.Context()
+	apiConfig, _, err := h.Reconciler.nodeAPIConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	configString, err := GenerateFRRConfig(ctx, apiConfig)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(h.Path, []byte(configString), 0600); err != nil {
+		return fmt.Errorf("failed to write the frr configuration to %s: %w", h.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestFRRConfigExportHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "node",
+				Annotations: map[string]string{MaintenanceAnnotation: "true"},
+			},
+		},
+		&v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+				Neighbors: []v1alpha1.Neighbor{
+					{ASN: 65001, Address: "192.168.1.1"},
+				},
+			},
+		},
+	).Build()
+
+	path := filepath.Join(t.TempDir(), "frr.conf")
+	handler := &FRRConfigExportHandler{
+		Reconciler: &PERouterReconciler{
+			Client:         cli,
+			MyNode:         "node",
+			RouterProvider: fakeRouterProvider{nodeIndex: 2, targetNS: "namespace"},
+		},
+		Path: path,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, FRRConfigExportPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for a get, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be written on a get, got %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, FRRConfigExportPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the exported config: %v", err)
+	}
+	// The configuration is completed as the applied one, shutting
+	// down the sessions of the node in maintenance.
+	for _, want := range []string{"router bgp 65000", "neighbor 192.168.1.1 remote-as 65001", "neighbor 192.168.1.1 shutdown"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected the exported config to contain %q, got:\n%s", want, data)
+		}
+	}
+}
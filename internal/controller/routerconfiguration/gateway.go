@@ -0,0 +1,80 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/controller/nodeindex"
+)
+
+// noGatewayNode is the index of the node assigning the centralized
+// gateway when no node can carry it.
+const noGatewayNode = -1
+
+// centralizedGatewayIndex returns the index of the node assigning the
+// gateway of the L2VNIs in centralized gateway mode. It is the lowest index
+// among the nodes that can carry the gateway, so that the gateway moves to
+// another node when its node is drained, put in maintenance or removed.
+func (r *PERouterReconciler) centralizedGatewayIndex(ctx context.Context, l2vnis []v1alpha1.L2VNI) (int, error) {
+	if !slices.ContainsFunc(l2vnis, func(l2vni v1alpha1.L2VNI) bool {
+		return l2vni.Spec.GatewayMode == v1alpha1.CentralizedGateway
+	}) {
+		return noGatewayNode, nil
+	}
+	var nodes v1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return noGatewayNode, fmt.Errorf("failed to list the nodes: %w", err)
+	}
+	return lowestGatewayIndex(nodes.Items), nil
+}
+
+// lowestGatewayIndex returns the lowest index among the given nodes
+// that can carry the gateway, or noGatewayNode if none can.
+func lowestGatewayIndex(nodes []v1.Node) int {
+	res := noGatewayNode
+	for i := range nodes {
+		if !canCarryGateway(&nodes[i]) {
+			continue
+		}
+		index, err := strconv.Atoi(nodes[i].Annotations[nodeindex.OpenpeNodeIndex])
+		if err != nil {
+			continue
+		}
+		if res == noGatewayNode || index < res {
+			res = index
+		}
+	}
+	return res
+}
+
+// canCarryGateway tells if the node is ready, not cordoned
+// and not in maintenance.
+func canCarryGateway(node *v1.Node) bool {
+	if node.Spec.Unschedulable || isMaintenance(node) {
+		return false
+	}
+	return nodeReady(node)
+}
+
+func nodeReady(node *v1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// gatewayEligibilityChanged tells if the update of the node
+// changed whether it can carry the centralized gateway, or its index.
+func gatewayEligibilityChanged(old, node *v1.Node) bool {
+	return canCarryGateway(old) != canCarryGateway(node) ||
+		old.Annotations[nodeindex.OpenpeNodeIndex] != node.Annotations[nodeindex.OpenpeNodeIndex]
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openperouter/openperouter/internal/controller/nodeindex"
+)
+
+func TestLowestGatewayIndex(t *testing.T) {
+	node := func(index string, ready bool, modify func(*v1.Node)) v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		n := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{nodeindex.OpenpeNodeIndex: index}},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}},
+		}
+		if modify != nil {
+			modify(&n)
+		}
+		return n
+	}
+	cordoned := func(n *v1.Node) { n.Spec.Unschedulable = true }
+	inMaintenance := func(n *v1.Node) { n.Annotations[MaintenanceAnnotation] = "true" }
+
+	tests := []struct {
+		name  string
+		nodes []v1.Node
+		want  int
+	}{
+		{
+			name:  "lowest index",
+			nodes: []v1.Node{node("2", true, nil), node("0", true, nil), node("1", true, nil)},
+			want:  0,
+		},
+		{
+			name:  "lowest node not ready",
+			nodes: []v1.Node{node("0", false, nil), node("1", true, nil)},
+			want:  1,
+		},
+		{
+			name:  "lowest node drained",
+			nodes: []v1.Node{node("0", true, cordoned), node("1", true, nil)},
+			want:  1,
+		},
+		{
+			name:  "lowest node in maintenance",
+			nodes: []v1.Node{node("0", true, inMaintenance), node("2", true, nil)},
+			want:  2,
+		},
+		{
+			name:  "node without index",
+			nodes: []v1.Node{node("", true, nil), node("3", true, nil)},
+			want:  3,
+		},
+		{
+			name:  "no node can carry the gateway",
+			nodes: []v1.Node{node("0", false, nil)},
+			want:  noGatewayNode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowestGatewayIndex(tt.nodes); got != tt.want {
+				t.Errorf("lowestGatewayIndex() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
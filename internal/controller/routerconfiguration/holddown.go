@@ -0,0 +1,105 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// transientFailureRetryInterval is the interval the reconciliation is retried
+// after when setting up a VNI failed, but the failure is not persistent yet.
+const transientFailureRetryInterval = 5 * time.Second
+
+// VNISetupError is returned when setting up the host side of a VNI fails.
+type VNISetupError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e VNISetupError) Error() string {
+	return fmt.Sprintf("failed to setup %s %s: %v", e.Kind, e.Name, e.Err)
+}
+
+func (e VNISetupError) Unwrap() error {
+	return e.Err
+}
+
+// vniFailureTracker counts the consecutive failures to set up each VNI, to
+// tell the transient failures from the persistent ones. A failure is
+// persistent once it happened in threshold consecutive reconciliations, or
+// once it lasts for holdDown, whichever comes first.
+type vniFailureTracker struct {
+	threshold int
+	holdDown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]vniFailure
+}
+
+type vniFailure struct {
+	count int
+	since time.Time
+}
+
+func newVNIFailureTracker(threshold int, holdDown time.Duration) *vniFailureTracker {
+	return &vniFailureTracker{
+		threshold: threshold,
+		holdDown:  holdDown,
+		now:       time.Now,
+		failures:  map[string]vniFailure{},
+	}
+}
+
+// failed records a failure of the given VNI, and tells if it is persistent.
+func (t *vniFailureTracker) failed(vni string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, ok := t.failures[vni]
+	if !ok {
+		f.since = t.now()
+	}
+	f.count++
+	t.failures[vni] = f
+
+	if t.threshold <= 1 && t.holdDown == 0 {
+		return true
+	}
+	if t.threshold > 1 && f.count >= t.threshold {
+		return true
+	}
+	return t.holdDown > 0 && t.now().Sub(f.since) >= t.holdDown
+}
+
+// reset forgets the failures of all the VNIs, to be called
+// after the configuration is successfully applied.
+func (t *vniFailureTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = map[string]vniFailure{}
+}
+
+// handleVNIFailure tells whether the given reconciliation error must be
+// reported as a failure. A VNI setup failure still within its hold-down is
+// reported as transient, and the reconciliation is retried later.
+func (r *PERouterReconciler) handleVNIFailure(ctx context.Context, err error) (ctrl.Result, error) {
+	var vniErr VNISetupError
+	if !errors.As(err, &vniErr) || r.vniFailures == nil {
+		return ctrl.Result{}, err
+	}
+	if r.vniFailures.failed(vniErr.Kind + "/" + vniErr.Name) {
+		slog.ErrorContext(ctx, "failed to configure the host", "kind", vniErr.Kind, "name", vniErr.Name, "error", err)
+		return ctrl.Result{}, err
+	}
+	slog.InfoContext(ctx, "transient failure configuring the host, retrying", "kind", vniErr.Kind, "name", vniErr.Name, "error", err)
+	return ctrl.Result{RequeueAfter: transientFailureRetryInterval}, nil
+}
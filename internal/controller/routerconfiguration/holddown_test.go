@@ -0,0 +1,93 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHandleVNIFailure(t *testing.T) {
+	vniErr := fmt.Errorf("failed to configure the host: %w",
+		VNISetupError{Kind: "L3VNI", Name: "red", Err: errors.New("device or resource busy")})
+
+	tests := []struct {
+		name      string
+		threshold int
+		holdDown  time.Duration
+		failures  int
+		elapsed   time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "no hold down",
+			threshold: 1,
+			failures:  1,
+			wantErr:   true,
+		},
+		{
+			name:      "single transient failure",
+			threshold: 3,
+			failures:  1,
+			wantErr:   false,
+		},
+		{
+			name:      "failure persisting across the threshold",
+			threshold: 3,
+			failures:  3,
+			wantErr:   true,
+		},
+		{
+			name:      "failure within the hold down",
+			threshold: 1,
+			holdDown:  time.Minute,
+			failures:  2,
+			elapsed:   30 * time.Second,
+			wantErr:   false,
+		},
+		{
+			name:      "failure lasting more than the hold down",
+			threshold: 10,
+			holdDown:  time.Minute,
+			failures:  2,
+			elapsed:   2 * time.Minute,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			r := &PERouterReconciler{vniFailures: newVNIFailureTracker(tt.threshold, tt.holdDown)}
+			r.vniFailures.now = func() time.Time { return now }
+
+			var err error
+			for i := range tt.failures {
+				if i == tt.failures-1 {
+					now = now.Add(tt.elapsed)
+				}
+				_, err = r.handleVNIFailure(context.Background(), vniErr)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleVNIFailure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVNIFailureTrackerReset(t *testing.T) {
+	tracker := newVNIFailureTracker(2, 0)
+	if tracker.failed("L3VNI/red") {
+		t.Fatalf("expected the first failure to be transient")
+	}
+	tracker.reset()
+	if tracker.failed("L3VNI/red") {
+		t.Fatalf("expected the first failure after a success to be transient")
+	}
+	if !tracker.failed("L3VNI/red") {
+		t.Fatalf("expected the second consecutive failure to be persistent")
+	}
+}
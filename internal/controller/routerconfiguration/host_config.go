@@ -7,24 +7,78 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/openperouter/openperouter/internal/conversion"
 	"github.com/openperouter/openperouter/internal/hostnetwork"
+	"github.com/openperouter/openperouter/internal/logging"
 )
 
 type interfacesConfiguration struct {
 	targetNamespace string
+	// bestEffort makes the setup of each VNI independent from the
+	// others, instead of stopping at the first failing one.
+	bestEffort bool
+	// teardowns tells which of the vnis not configured anymore must be
+	// kept until their routes are withdrawn. If nil, they are removed
+	// right away.
+	teardowns *vniTeardownTracker
 	conversion.ApiConfigData
 }
 
+// The host network operations, overridden in tests.
+var (
+	hasUnderlayInterface    = hostnetwork.HasUnderlayInterface
+	ensureIPv6Forwarding    = hostnetwork.EnsureIPv6Forwarding
+	setupUnderlay           = hostnetwork.SetupUnderlay
+	setupL3VNI              = hostnetwork.SetupL3VNI
+	setupL2VNI              = hostnetwork.SetupL2VNI
+	setupPassthrough        = hostnetwork.SetupPassthrough
+	removeNonConfiguredVNIs = hostnetwork.RemoveNonConfiguredVNIs
+	vrfsByVNI               = hostnetwork.VRFsByVNI
+	removePassthrough       = hostnetwork.RemovePassthrough
+	removeUnderlay          = hostnetwork.RemoveUnderlay
+	setupConntrackBypass    = hostnetwork.SetupConntrackBypass
+)
+
 type UnderlayRemovedError struct{}
 
 func (n UnderlayRemovedError) Error() string {
 	return "no underlays configured"
 }
 
+// VNIFailuresError is returned in best effort mode when
+// setting up some of the VNIs failed.
+type VNIFailuresError struct {
+	Failures []VNISetupError
+	// Attempted is the number of VNIs whose setup was attempted.
+	Attempted int
+}
+
+func (e VNIFailuresError) Error() string {
+	return fmt.Sprintf("failed to setup %d of %d vnis: %v", len(e.Failures), e.Attempted, errors.Join(e.Unwrap()...))
+}
+
+func (e VNIFailuresError) Unwrap() []error {
+	res := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		res = append(res, f)
+	}
+	return res
+}
+
+// AllFailed tells if the setup of all the attempted VNIs failed.
+func (e VNIFailuresError) AllFailed() bool {
+	return len(e.Failures) == e.Attempted
+}
+
+// configureInterfaces sets up the host side of the configuration. The
+// resources are set up in dependency order, regardless of the order they
+// are listed in: the underlay first, then all the L3VNIs, creating their
+// VRFs, and only then the L2VNIs, which may be attached to those VRFs.
 func configureInterfaces(ctx context.Context, config interfacesConfiguration) error {
-	hasAlreadyUnderlay, err := hostnetwork.HasUnderlayInterface(config.targetNamespace)
+	ctx = withPhase(ctx, phaseHost)
+	hasAlreadyUnderlay, err := hasUnderlayInterface(config.targetNamespace)
 	if err != nil {
 		return fmt.Errorf("failed to check if target namespace %s has underlay: %w", config.targetNamespace, err)
 	}
@@ -45,6 +99,7 @@ func configureInterfaces(ctx context.Context, config interfacesConfiguration) er
 		L3VNIs:             config.L3VNIs,
 		L2VNIs:             config.L2VNIs,
 		L3Passthrough:      config.L3Passthrough,
+		DeviceNamePrefix:   config.DeviceNamePrefix,
 	}
 	hostConfig, err := conversion.APItoHostConfig(config.NodeIndex, config.targetNamespace, apiConfig)
 	if err != nil {
@@ -52,53 +107,157 @@ func configureInterfaces(ctx context.Context, config interfacesConfiguration) er
 	}
 
 	slog.InfoContext(ctx, "ensuring IPv6 forwarding")
-	if err := hostnetwork.EnsureIPv6Forwarding(config.targetNamespace); err != nil {
+	if err := ensureIPv6Forwarding(config.targetNamespace); err != nil {
 		return fmt.Errorf("failed to ensure IPv6 forwarding: %w", err)
 	}
 
 	slog.InfoContext(ctx, "setting up underlay")
-	if err := hostnetwork.SetupUnderlay(ctx, hostConfig.Underlay); err != nil {
+	if err := setupUnderlay(ctx, hostConfig.Underlay); err != nil {
 		return fmt.Errorf("failed to setup underlay: %w", err)
 	}
+
+	// Removing the vnis that are not configured anymore before setting up
+	// the current ones, so that deleted vnis stop forwarding as soon as
+	// possible. The vnis that are still configured are left untouched.
+	slog.InfoContext(ctx, "removing deleted vnis")
+	toCheck := make([]hostnetwork.VNIParams, 0, len(hostConfig.L3VNIs)+len(hostConfig.L2VNIs))
 	for _, vni := range hostConfig.L3VNIs {
+		toCheck = append(toCheck, vni.VNIParams)
+	}
+	for _, l2vni := range hostConfig.L2VNIs {
+		toCheck = append(toCheck, l2vni.VNIParams)
+	}
+
+	staleVRFs, err := replacedVRFs(config.targetNamespace, config.DeviceNamePrefix, toCheck)
+	if err != nil {
+		return fmt.Errorf("failed to get the replaced vrfs: %w", err)
+	}
+
+	// The replaced vrfs are kept until the vnis are moved to the new
+	// ones, and removed afterwards.
+	withdraw := waitForWithdrawal(ctx, config.teardowns)
+	if err := removeNonConfiguredVNIs(config.targetNamespace, config.DeviceNamePrefix, slices.Concat(toCheck, staleVRFs), withdraw); err != nil {
+		return fmt.Errorf("failed to remove deleted vnis: %w", err)
+	}
+
+	// The host parameters of the vnis are in the same order as the resources.
+	failures := VNIFailuresError{}
+	for i, vni := range hostConfig.L3VNIs {
+		resource := apiConfig.L3VNIs[i]
+		ctx := withResource(ctx, "L3VNI", resource.Name)
 		slog.InfoContext(ctx, "setting up VNI", "vni", vni.VRF)
-		if err := hostnetwork.SetupL3VNI(ctx, vni); err != nil {
-			return fmt.Errorf("failed to setup vni: %w", err)
+		failures.Attempted++
+		if err := setupL3VNI(ctx, vni); err != nil {
+			setupErr := VNISetupError{Kind: "L3VNI", Namespace: resource.Namespace, Name: resource.Name, Err: err}
+			if !config.bestEffort {
+				return fmt.Errorf("failed to setup vni: %w", setupErr)
+			}
+			reconcileErrors.logError(ctx, "failed to setup vni, moving on to the next ones", err, "vni", vni.VRF)
+			failures.Failures = append(failures.Failures, setupErr)
 		}
 	}
 
-	for _, vni := range hostConfig.L2VNIs {
+	for i, vni := range hostConfig.L2VNIs {
+		resource := apiConfig.L2VNIs[i]
+		ctx := withResource(ctx, "L2VNI", resource.Name)
 		slog.InfoContext(ctx, "setting up L2VNI", "vni", vni.VNI)
-		if err := hostnetwork.SetupL2VNI(ctx, vni); err != nil {
-			return fmt.Errorf("failed to setup vni: %w", err)
+		failures.Attempted++
+		if err := setupL2VNI(ctx, vni); err != nil {
+			setupErr := VNISetupError{Kind: "L2VNI", Namespace: resource.Namespace, Name: resource.Name, Err: err}
+			if !config.bestEffort {
+				return fmt.Errorf("failed to setup vni: %w", setupErr)
+			}
+			reconcileErrors.logError(ctx, "failed to setup vni, moving on to the next ones", err, "vni", vni.VNI)
+			failures.Failures = append(failures.Failures, setupErr)
+		}
+	}
+
+	if len(staleVRFs) > 0 {
+		slog.InfoContext(ctx, "removing replaced vrfs")
+		if err := removeNonConfiguredVNIs(config.targetNamespace, config.DeviceNamePrefix, toCheck, nil); err != nil {
+			return fmt.Errorf("failed to remove replaced vrfs: %w", err)
 		}
 	}
 
 	slog.InfoContext(ctx, "setting up passthrough")
 	if hostConfig.L3Passthrough != nil {
-		if err := hostnetwork.SetupPassthrough(ctx, *hostConfig.L3Passthrough); err != nil {
+		ctx := withResource(ctx, "L3Passthrough", apiConfig.L3Passthrough[0].Name)
+		if err := setupPassthrough(ctx, *hostConfig.L3Passthrough); err != nil {
 			return fmt.Errorf("failed to setup passthrough: %w", err)
 		}
 	}
 
-	slog.InfoContext(ctx, "removing deleted vnis")
-	toCheck := make([]hostnetwork.VNIParams, 0, len(hostConfig.L3VNIs)+len(hostConfig.L2VNIs))
-	for _, vni := range hostConfig.L3VNIs {
-		toCheck = append(toCheck, vni.VNIParams)
+	if len(apiConfig.L3Passthrough) == 0 {
+		if err := removePassthrough(config.targetNamespace); err != nil {
+			return fmt.Errorf("failed to remove passthrough: %w", err)
+		}
 	}
-	for _, l2vni := range hostConfig.L2VNIs {
-		toCheck = append(toCheck, l2vni.VNIParams)
+
+	changed, err := setupConntrackBypass(config.targetNamespace, hostConfig.ConntrackBypassCIDRs)
+	if err != nil {
+		return fmt.Errorf("failed to setup conntrack bypass: %w", err)
 	}
-	if err := hostnetwork.RemoveNonConfiguredVNIs(config.targetNamespace, toCheck); err != nil {
-		return fmt.Errorf("failed to remove deleted vnis: %w", err)
+	if changed && len(hostConfig.ConntrackBypassCIDRs) > 0 {
+		slog.WarnContext(ctx, "connection tracking disabled for the overlay subnets, stateful filtering and nat rules won't apply to their traffic",
+			"cidrs", hostConfig.ConntrackBypassCIDRs)
 	}
+	if changed && len(hostConfig.ConntrackBypassCIDRs) == 0 {
+		slog.InfoContext(ctx, "connection tracking enabled again for the overlay subnets")
+	}
+	if len(failures.Failures) > 0 {
+		return failures
+	}
+	return nil
+}
 
-	if len(apiConfig.L3Passthrough) == 0 {
-		if err := hostnetwork.RemovePassthrough(config.targetNamespace); err != nil {
-			return fmt.Errorf("failed to remove passthrough: %w", err)
+// Cleanup removes all the devices the router configuration created in
+// the target namespace, together with the host side of their veths, and
+// moves the underlay interface back to the host. The devices are
+// recognized by the given device name prefix.
+func Cleanup(ctx context.Context, targetNS, deviceNamePrefix string) error {
+	slog.InfoContext(ctx, "cleanup start", "namespace", targetNS)
+	defer slog.InfoContext(ctx, "cleanup end", "namespace", targetNS)
+
+	errs := []error{}
+	if err := removeNonConfiguredVNIs(targetNS, deviceNamePrefix, []hostnetwork.VNIParams{}, nil); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove vnis: %w", err))
+	}
+	if err := removePassthrough(targetNS); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove passthrough: %w", err))
+	}
+	if err := removeUnderlay(ctx, targetNS); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove underlay: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// replacedVRFs returns the vrfs held by the vnis whose vrf name changed.
+// Renaming a vrf in place requires bringing it down, which flushes its
+// routes, and frr does not follow the rename, so the new vrf is created
+// instead. The old ones are kept until the vnis are set up in the new
+// ones, and removed afterwards.
+func replacedVRFs(targetNS, deviceNamePrefix string, params []hostnetwork.VNIParams) ([]hostnetwork.VNIParams, error) {
+	current, err := vrfsByVNI(targetNS, deviceNamePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the vrfs of the vnis: %w", err)
+	}
+	res := []hostnetwork.VNIParams{}
+	seen := map[string]bool{}
+	for _, p := range params {
+		vrf, ok := current[p.VNI]
+		if !ok || vrf == p.VRF || seen[vrf] {
+			continue
 		}
+		seen[vrf] = true
+		res = append(res, hostnetwork.VNIParams{VRF: vrf, VNI: p.VNI})
 	}
-	return nil
+	return res, nil
+}
+
+// withResource returns a context whose log lines are tagged with
+// the kind and the name of the resource being configured.
+func withResource(ctx context.Context, kind, name string) context.Context {
+	return logging.WithAttrs(ctx, slog.String("kind", kind), slog.String("name", name))
 }
 
 // nonRecoverableHostError tells whether the router pod
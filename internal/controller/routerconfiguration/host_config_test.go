@@ -0,0 +1,544 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// fakeHostNetwork replaces the host network operations
+// and records the order they are called in.
+func fakeHostNetwork(t *testing.T) *[]string {
+	t.Helper()
+	ops := []string{}
+
+	oldHasUnderlayInterface := hasUnderlayInterface
+	oldEnsureIPv6Forwarding := ensureIPv6Forwarding
+	oldSetupUnderlay := setupUnderlay
+	oldSetupL3VNI := setupL3VNI
+	oldSetupL2VNI := setupL2VNI
+	oldSetupPassthrough := setupPassthrough
+	oldRemoveNonConfiguredVNIs := removeNonConfiguredVNIs
+	oldRemovePassthrough := removePassthrough
+	oldRemoveUnderlay := removeUnderlay
+	oldVRFsByVNI := vrfsByVNI
+	oldSetupConntrackBypass := setupConntrackBypass
+	t.Cleanup(func() {
+		hasUnderlayInterface = oldHasUnderlayInterface
+		ensureIPv6Forwarding = oldEnsureIPv6Forwarding
+		setupUnderlay = oldSetupUnderlay
+		setupL3VNI = oldSetupL3VNI
+		setupL2VNI = oldSetupL2VNI
+		setupPassthrough = oldSetupPassthrough
+		removeNonConfiguredVNIs = oldRemoveNonConfiguredVNIs
+		removePassthrough = oldRemovePassthrough
+		removeUnderlay = oldRemoveUnderlay
+		vrfsByVNI = oldVRFsByVNI
+		setupConntrackBypass = oldSetupConntrackBypass
+	})
+
+	hasUnderlayInterface = func(string) (bool, error) {
+		return true, nil
+	}
+	ensureIPv6Forwarding = func(string) error {
+		ops = append(ops, "ensure ipv6 forwarding")
+		return nil
+	}
+	setupUnderlay = func(context.Context, hostnetwork.UnderlayParams) error {
+		ops = append(ops, "setup underlay")
+		return nil
+	}
+	setupL3VNI = func(_ context.Context, params hostnetwork.L3VNIParams) error {
+		ops = append(ops, fmt.Sprintf("setup l3vni %d", params.VNI))
+		return nil
+	}
+	setupL2VNI = func(_ context.Context, params hostnetwork.L2VNIParams) error {
+		ops = append(ops, fmt.Sprintf("setup l2vni %d", params.VNI))
+		return nil
+	}
+	setupPassthrough = func(context.Context, hostnetwork.PassthroughParams) error {
+		ops = append(ops, "setup passthrough")
+		return nil
+	}
+	removeNonConfiguredVNIs = func(_, _ string, params []hostnetwork.VNIParams, _ hostnetwork.WithdrawFunc) error {
+		vnis := []int{}
+		for _, p := range params {
+			vnis = append(vnis, p.VNI)
+		}
+		ops = append(ops, fmt.Sprintf("remove vnis not in %v", vnis))
+		return nil
+	}
+	removePassthrough = func(string) error {
+		ops = append(ops, "remove passthrough")
+		return nil
+	}
+	removeUnderlay = func(context.Context, string) error {
+		ops = append(ops, "remove underlay")
+		return nil
+	}
+	vrfsByVNI = func(string, string) (map[int]string, error) {
+		return map[int]string{}, nil
+	}
+	setupConntrackBypass = func(_ string, cidrs []string) (bool, error) {
+		ops = append(ops, fmt.Sprintf("conntrack bypass %v", cidrs))
+		return len(cidrs) > 0, nil
+	}
+	return &ops
+}
+
+func TestCleanup(t *testing.T) {
+	ops := fakeHostNetwork(t)
+
+	if err := Cleanup(context.Background(), "namespace", ""); err != nil {
+		t.Fatalf("Cleanup() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"remove vnis not in []",
+		"remove passthrough",
+		"remove underlay",
+	}
+	if diff := cmp.Diff(want, *ops); diff != "" {
+		t.Errorf("unexpected host network operations (-want +got):\n%s", diff)
+	}
+}
+
+func TestCleanupContinuesOnErrors(t *testing.T) {
+	ops := fakeHostNetwork(t)
+	removeNonConfiguredVNIs = func(string, string, []hostnetwork.VNIParams, hostnetwork.WithdrawFunc) error {
+		return errors.New("device or resource busy")
+	}
+
+	if err := Cleanup(context.Background(), "namespace", ""); err == nil {
+		t.Fatalf("Cleanup() expected error, got nil")
+	}
+
+	want := []string{
+		"remove passthrough",
+		"remove underlay",
+	}
+	if diff := cmp.Diff(want, *ops); diff != "" {
+		t.Errorf("unexpected host network operations (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigureInterfacesOrder(t *testing.T) {
+	ops := fakeHostNetwork(t)
+
+	config := interfacesConfiguration{
+		targetNamespace: "namespace",
+		ApiConfigData: conversion.ApiConfigData{
+			Underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			L3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789},
+				},
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "l2red"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 200, VXLanPort: 4789},
+				},
+			},
+		},
+	}
+
+	if err := configureInterfaces(context.Background(), config); err != nil {
+		t.Fatalf("configureInterfaces() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"ensure ipv6 forwarding",
+		"setup underlay",
+		"remove vnis not in [100 200]",
+		"setup l3vni 100",
+		"setup l2vni 200",
+		"remove passthrough",
+		"conntrack bypass []",
+	}
+	if !cmp.Equal(*ops, want) {
+		t.Errorf("configureInterfaces() operations diff %s", cmp.Diff(*ops, want))
+	}
+}
+
+func TestConfigureInterfacesDependencyOrder(t *testing.T) {
+	ops := fakeHostNetwork(t)
+
+	// the l2vnis are listed before the l3vnis owning their vrfs, and the
+	// resources of each kind are not sorted by vrf.
+	config := interfacesConfiguration{
+		targetNamespace: "namespace",
+		ApiConfigData: conversion.ApiConfigData{
+			L2VNIs: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "l2blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, VRF: ptr.To("blue")},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "l2red"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 200, VXLanPort: 4789, VRF: ptr.To("red")},
+				},
+			},
+			L3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L3VNISpec{VRF: "blue", VNI: 101, VXLanPort: 4789},
+				},
+			},
+			Underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+		},
+	}
+
+	if err := configureInterfaces(context.Background(), config); err != nil {
+		t.Fatalf("configureInterfaces() unexpected error: %v", err)
+	}
+
+	setups := []string{}
+	for _, op := range *ops {
+		if strings.HasPrefix(op, "setup ") {
+			setups = append(setups, op)
+		}
+	}
+	want := []string{
+		"setup underlay",
+		"setup l3vni 100",
+		"setup l3vni 101",
+		"setup l2vni 201",
+		"setup l2vni 200",
+	}
+	if diff := cmp.Diff(want, setups); diff != "" {
+		t.Errorf("unexpected setup order (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigureInterfacesPassthroughWithoutEVPN(t *testing.T) {
+	ops := fakeHostNetwork(t)
+	var underlayParams hostnetwork.UnderlayParams
+	setupUnderlay = func(_ context.Context, params hostnetwork.UnderlayParams) error {
+		*ops = append(*ops, "setup underlay")
+		underlayParams = params
+		return nil
+	}
+
+	config := interfacesConfiguration{
+		targetNamespace: "namespace",
+		ApiConfigData: conversion.ApiConfigData{
+			Underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}}},
+			},
+			L3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							ASN:       65000,
+							HostASN:   65001,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := configureInterfaces(context.Background(), config); err != nil {
+		t.Fatalf("configureInterfaces() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"ensure ipv6 forwarding",
+		"setup underlay",
+		"remove vnis not in []",
+		"setup passthrough",
+		"conntrack bypass []",
+	}
+	if !cmp.Equal(*ops, want) {
+		t.Errorf("configureInterfaces() operations diff %s", cmp.Diff(*ops, want))
+	}
+	if underlayParams.EVPN != nil {
+		t.Errorf("expected no evpn in the underlay params, got %+v", underlayParams.EVPN)
+	}
+}
+
+func TestConfigureInterfacesBestEffort(t *testing.T) {
+	l2vnis := []v1alpha1.L2VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 200, VXLanPort: 4789},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "failing", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "third", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 202, VXLanPort: 4789},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		bestEffort bool
+		wantOps    []string
+	}{
+		{
+			name:       "stopping at the first failure",
+			bestEffort: false,
+			wantOps: []string{
+				"ensure ipv6 forwarding",
+				"setup underlay",
+				"remove vnis not in [200 201 202]",
+				"setup l2vni 200",
+			},
+		},
+		{
+			name:       "best effort",
+			bestEffort: true,
+			wantOps: []string{
+				"ensure ipv6 forwarding",
+				"setup underlay",
+				"remove vnis not in [200 201 202]",
+				"setup l2vni 200",
+				"setup l2vni 202",
+				"remove passthrough",
+				"conntrack bypass []",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := fakeHostNetwork(t)
+			setupL2VNI = func(_ context.Context, params hostnetwork.L2VNIParams) error {
+				if params.VNI == 201 {
+					return errors.New("device or resource busy")
+				}
+				*ops = append(*ops, fmt.Sprintf("setup l2vni %d", params.VNI))
+				return nil
+			}
+
+			config := interfacesConfiguration{
+				targetNamespace: "namespace",
+				bestEffort:      tt.bestEffort,
+				ApiConfigData: conversion.ApiConfigData{
+					Underlays: []v1alpha1.Underlay{
+						{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+					},
+					L2VNIs: l2vnis,
+				},
+			}
+
+			err := configureInterfaces(context.Background(), config)
+			if !cmp.Equal(*ops, tt.wantOps) {
+				t.Errorf("configureInterfaces() operations diff %s", cmp.Diff(tt.wantOps, *ops))
+			}
+
+			var setupErr VNISetupError
+			if !errors.As(err, &setupErr) {
+				t.Fatalf("expected a vni setup error, got %v", err)
+			}
+			if setupErr.Kind != "L2VNI" || setupErr.Name != "failing" {
+				t.Errorf("expected the failure of L2VNI failing, got %s %s", setupErr.Kind, setupErr.Name)
+			}
+
+			var vniFailures VNIFailuresError
+			if errors.As(err, &vniFailures) != tt.bestEffort {
+				t.Fatalf("expected a vni failures error only in best effort mode, got %v", err)
+			}
+			if tt.bestEffort && (len(vniFailures.Failures) != 1 || vniFailures.Attempted != 3 || vniFailures.AllFailed()) {
+				t.Errorf("expected 1 of 3 vnis failing, got %d of %d", len(vniFailures.Failures), vniFailures.Attempted)
+			}
+		})
+	}
+}
+
+func TestConfigureInterfacesReplacesVRF(t *testing.T) {
+	tests := []struct {
+		name    string
+		current map[int]string
+		l2VRF   string
+		wantOps []string
+	}{
+		{
+			name:    "vrf name changed",
+			current: map[int]string{100: "red", 200: "red"},
+			l2VRF:   "blue",
+			wantOps: []string{
+				"ensure ipv6 forwarding",
+				"setup underlay",
+				"remove vrfs not in [blue blue red]",
+				"setup l3vni 100",
+				"setup l2vni 200",
+				"remove vrfs not in [blue blue]",
+				"remove passthrough",
+				"conntrack bypass []",
+			},
+		},
+		{
+			name:    "vrf split across the vnis",
+			current: map[int]string{100: "red", 200: "red"},
+			l2VRF:   "red",
+			wantOps: []string{
+				"ensure ipv6 forwarding",
+				"setup underlay",
+				"remove vrfs not in [blue red red]",
+				"setup l3vni 100",
+				"setup l2vni 200",
+				"remove vrfs not in [blue red]",
+				"remove passthrough",
+				"conntrack bypass []",
+			},
+		},
+		{
+			name:    "vrf name unchanged",
+			current: map[int]string{100: "blue", 200: "blue"},
+			l2VRF:   "blue",
+			wantOps: []string{
+				"ensure ipv6 forwarding",
+				"setup underlay",
+				"remove vrfs not in [blue blue]",
+				"setup l3vni 100",
+				"setup l2vni 200",
+				"remove passthrough",
+				"conntrack bypass []",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := fakeHostNetwork(t)
+			vrfsByVNI = func(string, string) (map[int]string, error) {
+				return tt.current, nil
+			}
+			removeNonConfiguredVNIs = func(_, _ string, params []hostnetwork.VNIParams, _ hostnetwork.WithdrawFunc) error {
+				vrfs := []string{}
+				for _, p := range params {
+					vrfs = append(vrfs, p.VRF)
+				}
+				*ops = append(*ops, fmt.Sprintf("remove vrfs not in %v", vrfs))
+				return nil
+			}
+
+			config := interfacesConfiguration{
+				targetNamespace: "namespace",
+				ApiConfigData: conversion.ApiConfigData{
+					Underlays: []v1alpha1.Underlay{
+						{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+					},
+					L3VNIs: []v1alpha1.L3VNI{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+							Spec:       v1alpha1.L3VNISpec{VRF: "blue", VNI: 100, VXLanPort: 4789},
+						},
+					},
+					L2VNIs: []v1alpha1.L2VNI{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "l2blue"},
+							Spec:       v1alpha1.L2VNISpec{VRF: ptr.To(tt.l2VRF), VNI: 200, VXLanPort: 4789},
+						},
+					},
+				},
+			}
+
+			if err := configureInterfaces(context.Background(), config); err != nil {
+				t.Fatalf("configureInterfaces() unexpected error: %v", err)
+			}
+			if !cmp.Equal(*ops, tt.wantOps) {
+				t.Errorf("configureInterfaces() operations diff %s", cmp.Diff(tt.wantOps, *ops))
+			}
+		})
+	}
+}
+
+func TestConfigureInterfacesConntrackBypass(t *testing.T) {
+	ops := fakeHostNetwork(t)
+
+	config := interfacesConfiguration{
+		targetNamespace: "namespace",
+		ApiConfigData: conversion.ApiConfigData{
+			Underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			L3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:       "red",
+						VNI:       100,
+						VXLanPort: 4789,
+						HostSession: &v1alpha1.HostSession{
+							ASN:       65000,
+							HostASN:   65001,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24", IPv6: "2001:db8:1::/64"},
+						},
+						DisableConntrack:     true,
+						ConntrackBypassCIDRs: []string{"10.244.0.0/16"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "green"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:                  "green",
+						VNI:                  102,
+						VXLanPort:            4789,
+						DisableConntrack:     true,
+						ConntrackBypassCIDRs: []string{"10.245.1.1/24"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:       "blue",
+						VNI:       101,
+						VXLanPort: 4789,
+						HostSession: &v1alpha1.HostSession{
+							ASN:       65000,
+							HostASN:   65001,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.11.0/24"},
+						},
+					},
+				},
+			},
+			L2VNIs: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "l2red"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:              200,
+						VXLanPort:        4789,
+						VRF:              ptr.To("red"),
+						L2GatewayIPs:     []string{"192.170.1.1/24"},
+						DisableConntrack: true,
+					},
+				},
+			},
+		},
+	}
+
+	if err := configureInterfaces(context.Background(), config); err != nil {
+		t.Fatalf("configureInterfaces() unexpected error: %v", err)
+	}
+
+	want := "conntrack bypass [192.169.10.0/24 2001:db8:1::/64 10.244.0.0/16 10.245.1.0/24 192.170.1.0/24]"
+	if got := (*ops)[len(*ops)-1]; got != want {
+		t.Errorf("expected the last operation to be %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+// HostConfigPath is the path the HostConfigHandler is served on.
+const HostConfigPath = "/hostconfig"
+
+// redacted replaces the values of the secret fields in the served configuration.
+const redacted = "<redacted>"
+
+// HostConfigHandler serves, for debugging purposes, the host configuration
+// the current resources are converted to for this node, as json. The
+// configuration is computed on each request, and never applied.
+type HostConfigHandler struct {
+	// Reconciler is the reconciler applying the configuration
+	// to this node, whose parameters the resources are completed with.
+	Reconciler *PERouterReconciler
+}
+
+func (h *HostConfigHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	res, err := h.hostConfig(req)
+	if err != nil {
+		slog.ErrorContext(req.Context(), "failed to compute the host configuration", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(res); err != nil {
+		slog.ErrorContext(req.Context(), "failed to write the host configuration", "error", err)
+	}
+}
+
+func (h *HostConfigHandler) hostConfig(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	apiConfig, targetNS, err := h.Reconciler.nodeAPIConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hostConfig, err := conversion.APItoHostConfig(apiConfig.NodeIndex, targetNS, apiConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert config to host configuration: %w", err)
+	}
+
+	// The configuration goes through a generic representation to redact
+	// the secrets regardless of the struct they are in.
+	data, err := json.Marshal(hostConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the host configuration: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the host configuration: %w", err)
+	}
+	return json.MarshalIndent(redactSecrets(generic), "", "  ")
+}
+
+// nodeAPIConfig reads the current resources and completes them with the
+// parameters of this node the same way Reconcile does, returning them
+// together with the network namespace of the router.
+func (r *PERouterReconciler) nodeAPIConfig(ctx context.Context) (conversion.ApiConfigData, string, error) {
+	apiConfig, err := readAPIConfig(ctx, r.Client)
+	if err != nil {
+		return conversion.ApiConfigData{}, "", err
+	}
+	nodeIndex, err := r.RouterProvider.NodeIndex(ctx)
+	if err != nil {
+		return conversion.ApiConfigData{}, "", fmt.Errorf("failed to get node index: %w", err)
+	}
+	if err := r.completeAPIConfig(ctx, &apiConfig, nodeIndex); err != nil {
+		return conversion.ApiConfigData{}, "", err
+	}
+
+	router, err := r.RouterProvider.New(ctx)
+	if err != nil {
+		return conversion.ApiConfigData{}, "", fmt.Errorf("failed to get router instance: %w", err)
+	}
+	targetNS, err := router.TargetNS(ctx)
+	if err != nil {
+		return conversion.ApiConfigData{}, "", fmt.Errorf("failed to retrieve target namespace: %w", err)
+	}
+	apiConfig.UnderlayMultusInterface, err = underlayMultusInterface(router, r.UnderlayMultusNetwork)
+	if err != nil {
+		return conversion.ApiConfigData{}, "", fmt.Errorf("failed to get the underlay multus interface: %w", err)
+	}
+	return apiConfig, targetNS, nil
+}
+
+// redactSecrets replaces the values of all the fields whose
+// name contains "password" in the given json document.
+func redactSecrets(v any) any {
+	switch o := v.(type) {
+	case map[string]any:
+		for k, val := range o {
+			if strings.Contains(strings.ToLower(k), "password") {
+				o[k] = redacted
+				continue
+			}
+			o[k] = redactSecrets(val)
+		}
+	case []any:
+		for i := range o {
+			o[i] = redactSecrets(o[i])
+		}
+	}
+	return v
+}
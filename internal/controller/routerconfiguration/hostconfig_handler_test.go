@@ -0,0 +1,139 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+)
+
+type fakeRouterProvider struct {
+	nodeIndex int
+	targetNS  string
+}
+
+func (p fakeRouterProvider) New(context.Context) (Router, error) {
+	return fakeRouter{targetNS: p.targetNS}, nil
+}
+
+func (p fakeRouterProvider) NodeIndex(context.Context) (int, error) {
+	return p.nodeIndex, nil
+}
+
+type fakeRouter struct {
+	targetNS string
+}
+
+func (r fakeRouter) TargetNS(context.Context) (string, error) {
+	return r.targetNS, nil
+}
+
+func (r fakeRouter) HandleNonRecoverableError(context.Context, error) error {
+	return nil
+}
+
+func (r fakeRouter) CanReconcile(context.Context) (bool, error) {
+	return true, nil
+}
+
+func TestHostConfigHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+				Neighbors: []v1alpha1.Neighbor{
+					{ASN: 65001, Address: "192.168.1.1", Password: "secret"},
+				},
+			},
+		},
+		&v1alpha1.L3VNI{
+			ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789},
+		},
+	).Build()
+
+	handler := &HostConfigHandler{
+		Reconciler: &PERouterReconciler{
+			Client:         cli,
+			RouterProvider: fakeRouterProvider{nodeIndex: 2, targetNS: "namespace"},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HostConfigPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var got conversion.HostConfigData
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal the host config %s: %v", rec.Body.String(), err)
+	}
+	want := conversion.HostConfigData{
+		Underlay: hostnetwork.UnderlayParams{
+			UnderlayInterface: "eth0",
+			TargetNS:          "namespace",
+			EVPN:              &hostnetwork.UnderlayEVPNParams{VtepIP: "10.0.0.2/32"},
+		},
+		L3VNIs: []hostnetwork.L3VNIParams{
+			{
+				VNIParams: hostnetwork.VNIParams{
+					VRF:       "red",
+					TargetNS:  "namespace",
+					VTEPIP:    "10.0.0.2/32",
+					VNI:       100,
+					VXLanPort: 4789,
+				},
+			},
+		},
+		L2VNIs: []hostnetwork.L2VNIParams{},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("unexpected host config, diff %s", cmp.Diff(want, got))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, HostConfigPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for a post, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"neighbors": [{"address": "192.168.1.1", "password": "secret"}],
+		"nested": {"bgpPassword": "secret", "name": "red"}
+	}`), &doc); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	got := redactSecrets(doc)
+
+	want := map[string]any{
+		"neighbors": []any{map[string]any{"address": "192.168.1.1", "password": redacted}},
+		"nested":    map[string]any{"bgpPassword": redacted, "name": "red"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("unexpected redacted document, diff %s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+// MaintenanceAnnotation is the annotation of the node that, when set
+// to "true", shuts down all the bgp sessions of the router of the node,
+// leaving the devices in place.
+const MaintenanceAnnotation = "openpe.openperouter.github.io/maintenance"
+
+// MaintenanceCondition is the type of the condition, prefixed by the node
+// name, reported on the underlays. It is true when the node is in
+// maintenance and its sessions are shut down.
+const MaintenanceCondition = "Maintenance"
+
+const (
+	reasonMaintenanceOn  = "MaintenanceOn"
+	reasonMaintenanceOff = "MaintenanceOff"
+)
+
+// nodeInMaintenance tells if the node of the reconciler is annotated
+// to be in maintenance.
+func (r *PERouterReconciler) nodeInMaintenance(ctx context.Context) (bool, error) {
+	if r.MyNode == "" {
+		return false, nil
+	}
+	var node v1.Node
+	if err := r.Get(ctx, client.ObjectKey{Name: r.MyNode}, &node); err != nil {
+		return false, fmt.Errorf("failed to get node %s: %w", r.MyNode, err)
+	}
+	return isMaintenance(&node), nil
+}
+
+func isMaintenance(node *v1.Node) bool {
+	return node.Annotations[MaintenanceAnnotation] == "true"
+}
+
+// reportMaintenance sets, on each underlay, a condition telling if the
+// sessions of the node are shut down for maintenance. Failing to do it
+// does not fail the reconciliation, as the condition is informative only.
+func (r *PERouterReconciler) reportMaintenance(ctx context.Context, underlays []v1alpha1.Underlay, maintenance bool) {
+	condition := metav1.Condition{
+		Type:    maintenanceConditionType(r.MyNode),
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonMaintenanceOff,
+		Message: "the bgp sessions of the node are enabled",
+	}
+	if maintenance {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonMaintenanceOn
+		condition.Message = "the bgp sessions of the node are shut down for maintenance"
+	}
+	errs := []error{}
+	for _, underlay := range underlays {
+		if err := r.setUnderlayCondition(ctx, client.ObjectKeyFromObject(&underlay), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		slog.ErrorContext(ctx, "failed to report the maintenance", "error", err)
+	}
+}
+
+func maintenanceConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, MaintenanceCondition)
+}
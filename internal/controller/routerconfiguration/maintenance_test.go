@@ -0,0 +1,101 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+func TestMaintenanceShutsDownSessions(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+			EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+			Neighbors: []v1alpha1.Neighbor{
+				{ASN: 65001, Address: "192.168.1.1"},
+			},
+		},
+	}
+	l3vni := v1alpha1.L3VNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+		Spec: v1alpha1.L3VNISpec{
+			VRF:       "red",
+			VNI:       100,
+			VXLanPort: 4789,
+			HostSession: &v1alpha1.HostSession{
+				ASN:     65000,
+				HostASN: 65100,
+				LocalCIDR: v1alpha1.LocalCIDRConfig{
+					IPv4: "192.169.10.0/24",
+				},
+			},
+		},
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node1",
+			Annotations: map[string]string{MaintenanceAnnotation: "true"},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go to scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node, underlay.DeepCopy()).WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+	r := &PERouterReconciler{Client: cli, MyNode: "node1"}
+	ctx := context.Background()
+
+	maintenance, err := r.nodeInMaintenance(ctx)
+	if err != nil {
+		t.Fatalf("nodeInMaintenance() unexpected error: %v", err)
+	}
+	if !maintenance {
+		t.Fatalf("expected the node to be in maintenance")
+	}
+
+	config, err := GenerateFRRConfig(ctx, conversion.ApiConfigData{
+		Underlays:   []v1alpha1.Underlay{underlay},
+		L3VNIs:      []v1alpha1.L3VNI{l3vni},
+		Maintenance: maintenance,
+	})
+	if err != nil {
+		t.Fatalf("GenerateFRRConfig() unexpected error: %v", err)
+	}
+	for _, want := range []string{"neighbor 192.168.1.1 shutdown", "neighbor 192.169.10.2 shutdown"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("expected the config to contain %q, got:\n%s", want, config)
+		}
+	}
+
+	r.reportMaintenance(ctx, []v1alpha1.Underlay{underlay}, maintenance)
+	got := &v1alpha1.Underlay{}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(&underlay), got); err != nil {
+		t.Fatalf("failed to get the underlay: %v", err)
+	}
+	condition := meta.FindStatusCondition(got.Status.Conditions, "node1/"+MaintenanceCondition)
+	if condition == nil {
+		t.Fatalf("condition not found on the underlay: %+v", got.Status.Conditions)
+	}
+	if condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected condition status %s, got %s", metav1.ConditionTrue, condition.Status)
+	}
+}
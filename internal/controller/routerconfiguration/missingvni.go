@@ -0,0 +1,104 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+// MissingVNICondition is the type of the condition, prefixed by the node
+// name, reported on the underlays declaring the VNIs expected on every node.
+// It is true when some of the expected VNIs are not set up on the node.
+const MissingVNICondition = "MissingVNI"
+
+const (
+	reasonVNIsMissing = "VNIsMissing"
+	reasonVNIsPresent = "VNIsPresent"
+)
+
+// missingVNIs returns, sorted, the expected VNIs that are not configured by
+// any L3VNI or L2VNI, or whose setup failed, given the error returned by
+// the configuration of the host.
+func missingVNIs(expected []uint32, apiConfig conversion.ApiConfigData, hostErr error) []uint32 {
+	failed := map[string]bool{}
+	var vniFailures VNIFailuresError
+	if errors.As(hostErr, &vniFailures) {
+		for _, f := range vniFailures.Failures {
+			failed[f.Kind+"/"+f.Namespace+"/"+f.Name] = true
+		}
+	}
+
+	configured := map[uint32]bool{}
+	for _, vni := range apiConfig.L3VNIs {
+		if !failed["L3VNI/"+vni.Namespace+"/"+vni.Name] {
+			configured[vni.Spec.VNI] = true
+		}
+	}
+	for _, vni := range apiConfig.L2VNIs {
+		if !failed["L2VNI/"+vni.Namespace+"/"+vni.Name] {
+			configured[vni.Spec.VNI] = true
+		}
+	}
+
+	res := []uint32{}
+	for _, vni := range expected {
+		if !configured[vni] {
+			res = append(res, vni)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// reportMissingVNIs sets, on each underlay declaring the expected VNIs,
+// a condition telling if some of them are not set up on the node. Failing
+// to do it does not fail the reconciliation, as the condition is
+// informative only.
+func (r *PERouterReconciler) reportMissingVNIs(ctx context.Context, apiConfig conversion.ApiConfigData, hostErr error) {
+	errs := []error{}
+	for _, underlay := range apiConfig.Underlays {
+		if underlay.Spec.EVPN == nil || len(underlay.Spec.EVPN.ExpectedVNIs) == 0 {
+			continue
+		}
+		condition := metav1.Condition{
+			Type:    missingVNIConditionType(r.MyNode),
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonVNIsPresent,
+			Message: "all the expected vnis are set up",
+		}
+		missing := missingVNIs(underlay.Spec.EVPN.ExpectedVNIs, apiConfig, hostErr)
+		if len(missing) > 0 {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = reasonVNIsMissing
+			condition.Message = fmt.Sprintf("expected vnis not set up: %s", joinVNIs(missing))
+		}
+		if err := r.setUnderlayCondition(ctx, client.ObjectKeyFromObject(&underlay), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		slog.ErrorContext(ctx, "failed to report the missing vnis", "error", err)
+	}
+}
+
+func joinVNIs(vnis []uint32) string {
+	res := make([]string, 0, len(vnis))
+	for _, vni := range vnis {
+		res = append(res, fmt.Sprint(vni))
+	}
+	return strings.Join(res, ", ")
+}
+
+func missingVNIConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, MissingVNICondition)
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+func TestMissingVNIs(t *testing.T) {
+	apiConfig := conversion.ApiConfigData{
+		L3VNIs: []v1alpha1.L3VNI{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.L3VNISpec{VNI: 100},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.L3VNISpec{VNI: 101},
+			},
+		},
+		L2VNIs: []v1alpha1.L2VNI{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.L2VNISpec{VNI: 200},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expected []uint32
+		hostErr  error
+		want     []uint32
+	}{
+		{
+			name:     "all configured",
+			expected: []uint32{100, 101, 200},
+			want:     []uint32{},
+		},
+		{
+			name:     "some not configured",
+			expected: []uint32{300, 100, 201, 200},
+			want:     []uint32{201, 300},
+		},
+		{
+			name:     "setup failed",
+			expected: []uint32{100, 101, 200},
+			hostErr: VNIFailuresError{
+				Failures: []VNISetupError{
+					{Kind: "L3VNI", Namespace: "openperouter-system", Name: "blue", Err: errors.New("failed")},
+				},
+				Attempted: 3,
+			},
+			want: []uint32{101},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingVNIs(tt.expected, apiConfig, tt.hostErr)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected missing vnis, diff %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestReportMissingVNIs(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			EVPN: &v1alpha1.EVPNConfig{ExpectedVNIs: []uint32{100, 101}},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(underlay.DeepCopy()).WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+
+	r := &PERouterReconciler{Client: cli, MyNode: "node1"}
+	ctx := context.Background()
+	r.reportMissingVNIs(ctx, conversion.ApiConfigData{
+		Underlays: []v1alpha1.Underlay{underlay},
+		L3VNIs: []v1alpha1.L3VNI{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.L3VNISpec{VNI: 100},
+			},
+		},
+	}, nil)
+
+	got := &v1alpha1.Underlay{}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(&underlay), got); err != nil {
+		t.Fatalf("failed to get the underlay: %v", err)
+	}
+	condition := meta.FindStatusCondition(got.Status.Conditions, "node1/"+MissingVNICondition)
+	if condition == nil {
+		t.Fatalf("condition not found on the underlay: %+v", got.Status.Conditions)
+	}
+	if condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected condition status %s, got %s", metav1.ConditionTrue, condition.Status)
+	}
+	if condition.Message != "expected vnis not set up: 101" {
+		t.Errorf("unexpected condition message %q", condition.Message)
+	}
+}
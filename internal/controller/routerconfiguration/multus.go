@@ -0,0 +1,95 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// multusNetworkStatusAnnotation is the annotation Multus
+// reports the networks attached to a pod in.
+const multusNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+
+// multusNetworksAnnotation is the annotation requesting
+// the Multus networks to attach a pod to.
+const multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+
+// multusRouter is implemented by the routers
+// that can be attached to Multus networks.
+type multusRouter interface {
+	// MultusInterface returns the name of the interface
+	// the given network is attached to the router with.
+	MultusInterface(network string) (string, error)
+}
+
+// ValidateUnderlayMultusNetwork checks that the network providing the
+// underlay is set if and only if the underlay is built with Multus, and that
+// it is in the form <namespace>/<name> or <name>.
+func ValidateUnderlayMultusNetwork(underlayFromMultus bool, network string) error {
+	if !underlayFromMultus {
+		if network != "" {
+			return fmt.Errorf("underlay multus network %s set, but the underlay is not built with multus", network)
+		}
+		return nil
+	}
+	if network == "" {
+		return errors.New("the underlay multus network must be set when the underlay is built with multus")
+	}
+	parts := strings.Split(network, "/")
+	if len(parts) > 2 || slices.Contains(parts, "") {
+		return fmt.Errorf("invalid underlay multus network %s, must be in the form <namespace>/<name> or <name>", network)
+	}
+	return nil
+}
+
+type multusNetworkStatus struct {
+	Name      string `json:"name"`
+	Interface string `json:"interface"`
+}
+
+// multusInterface returns the interface the given network is attached to the pod
+// with, according to the network status reported by Multus. A network without a
+// namespace is looked for in the namespace of the pod.
+func multusInterface(pod *v1.Pod, network string) (string, error) {
+	if !strings.Contains(network, "/") {
+		network = pod.Namespace + "/" + network
+	}
+	status, ok := pod.Annotations[multusNetworkStatusAnnotation]
+	if !ok {
+		return "", fmt.Errorf("pod %s/%s has no multus network status", pod.Namespace, pod.Name)
+	}
+	var networks []multusNetworkStatus
+	if err := json.Unmarshal([]byte(status), &networks); err != nil {
+		return "", fmt.Errorf("failed to parse the multus network status of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	for _, n := range networks {
+		if n.Name != network {
+			continue
+		}
+		if n.Interface == "" {
+			return "", fmt.Errorf("network %s of pod %s/%s has no interface", network, pod.Namespace, pod.Name)
+		}
+		return n.Interface, nil
+	}
+	return "", fmt.Errorf("network %s is not attached to pod %s/%s", network, pod.Namespace, pod.Name)
+}
+
+// underlayMultusInterface returns the interface the given network providing
+// the underlay is attached to the router with, or an empty string if the
+// underlay does not come from a Multus network.
+func underlayMultusInterface(router Router, network string) (string, error) {
+	if network == "" {
+		return "", nil
+	}
+	r, ok := router.(multusRouter)
+	if !ok {
+		return "", fmt.Errorf("the router can't be attached to the multus network %s", network)
+	}
+	return r.MultusInterface(network)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateUnderlayMultusNetwork(t *testing.T) {
+	tests := []struct {
+		name               string
+		underlayFromMultus bool
+		network            string
+		wantErr            bool
+	}{
+		{name: "no multus", underlayFromMultus: false, network: ""},
+		{name: "network name", underlayFromMultus: true, network: "underlay"},
+		{name: "namespaced network", underlayFromMultus: true, network: "openperouter-system/underlay"},
+		{name: "missing network", underlayFromMultus: true, network: "", wantErr: true},
+		{name: "network without multus", underlayFromMultus: false, network: "underlay", wantErr: true},
+		{name: "empty namespace", underlayFromMultus: true, network: "/underlay", wantErr: true},
+		{name: "too many parts", underlayFromMultus: true, network: "a/b/c", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUnderlayMultusNetwork(tt.underlayFromMultus, tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUnderlayMultusNetwork(%t, %q) error = %v, wantErr %v", tt.underlayFromMultus, tt.network, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMultusInterface(t *testing.T) {
+	networkStatus := `[
+		{"name": "kindnet", "interface": "eth0", "default": true},
+		{"name": "openperouter-system/underlay", "interface": "net1"},
+		{"name": "other/underlay", "interface": "net2"}
+	]`
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "router",
+			Namespace:   "openperouter-system",
+			Annotations: map[string]string{multusNetworkStatusAnnotation: networkStatus},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pod     *v1.Pod
+		network string
+		want    string
+		wantErr bool
+	}{
+		{name: "network in the pod namespace", pod: pod, network: "underlay", want: "net1"},
+		{name: "namespaced network", pod: pod, network: "other/underlay", want: "net2"},
+		{name: "network not attached", pod: pod, network: "missing", wantErr: true},
+		{
+			name:    "no network status",
+			pod:     &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "router", Namespace: "openperouter-system"}},
+			network: "underlay",
+			wantErr: true,
+		},
+		{
+			name: "invalid network status",
+			pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        "router",
+				Namespace:   "openperouter-system",
+				Annotations: map[string]string{multusNetworkStatusAnnotation: "{"},
+			}},
+			network: "underlay",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := multusInterface(tt.pod, tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("multusInterface(%q) error = %v, wantErr %v", tt.network, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("multusInterface(%q) = %q, want %q", tt.network, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnderlayMultusInterface(t *testing.T) {
+	got, err := underlayMultusInterface(fakeRouter{}, "")
+	if err != nil || got != "" {
+		t.Errorf("expected no interface and no error without a network, got %q %v", got, err)
+	}
+	if _, err := underlayMultusInterface(fakeRouter{}, "underlay"); err == nil {
+		t.Errorf("expected an error for a router not attachable to multus networks")
+	}
+}
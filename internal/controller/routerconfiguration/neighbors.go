@@ -0,0 +1,105 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/frr"
+	"github.com/openperouter/openperouter/internal/frrconfig"
+)
+
+// NeighborsIncompleteCondition is the type of the condition, prefixed by
+// the node name, reported on the underlays declaring the number of expected
+// neighbors. It is true when fewer sessions with the neighbors of the
+// underlay are established on the router of the node.
+const NeighborsIncompleteCondition = "NeighborsIncomplete"
+
+const (
+	reasonNeighborsIncomplete = "NeighborsIncomplete"
+	reasonNeighborsComplete   = "NeighborsComplete"
+
+	// neighborsRecheckInterval is the interval the sessions with the
+	// neighbors are checked again after, as long as fewer than the
+	// expected ones are established.
+	neighborsRecheckInterval = 30 * time.Second
+)
+
+// bgpNeighbors returns the BGP neighbors of the router, overridden in tests.
+var bgpNeighbors = frrconfig.Neighbors
+
+// establishedNeighbors returns how many of the neighbors of the given
+// underlay have an established session, among the ones of the router.
+func establishedNeighbors(underlay v1alpha1.Underlay, neighbors []*frr.Neighbor) int {
+	underlayNeighbors := map[string]bool{}
+	for _, n := range underlay.Spec.Neighbors {
+		if ip := net.ParseIP(n.Address); ip != nil {
+			underlayNeighbors[ip.String()] = true
+		}
+	}
+	res := 0
+	for _, n := range neighbors {
+		if n.Connected && underlayNeighbors[n.IP.String()] {
+			res++
+		}
+	}
+	return res
+}
+
+// reportIncompleteNeighbors sets, on each underlay declaring the number of
+// expected neighbors, a condition telling if fewer sessions with them are
+// established. It returns true if the sessions must be checked again, as
+// some are missing or their state could not be retrieved. Failing to report
+// the condition does not fail the reconciliation, as it is informative only.
+func (r *PERouterReconciler) reportIncompleteNeighbors(ctx context.Context, underlays []v1alpha1.Underlay) bool {
+	if !slices.ContainsFunc(underlays, func(u v1alpha1.Underlay) bool { return u.Spec.ExpectedNeighbors != nil }) {
+		return false
+	}
+	neighbors, err := bgpNeighbors(ctx, r.FRRReloadSocket)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get the bgp neighbors", "error", err)
+		return true
+	}
+
+	recheck := false
+	errs := []error{}
+	for _, underlay := range underlays {
+		if underlay.Spec.ExpectedNeighbors == nil {
+			continue
+		}
+		expected := *underlay.Spec.ExpectedNeighbors
+		established := establishedNeighbors(underlay, neighbors)
+		condition := metav1.Condition{
+			Type:    neighborsIncompleteConditionType(r.MyNode),
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonNeighborsComplete,
+			Message: fmt.Sprintf("%d of %d expected neighbors established", established, expected),
+		}
+		if established < expected {
+			recheck = true
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = reasonNeighborsIncomplete
+		}
+		if err := r.setUnderlayCondition(ctx, client.ObjectKeyFromObject(&underlay), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		slog.ErrorContext(ctx, "failed to report the incomplete neighbors", "error", err)
+	}
+	return recheck
+}
+
+func neighborsIncompleteConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, NeighborsIncompleteCondition)
+}
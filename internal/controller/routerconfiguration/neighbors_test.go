@@ -0,0 +1,110 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/frr"
+)
+
+func TestReportIncompleteNeighbors(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			Neighbors: []v1alpha1.Neighbor{
+				{ASN: 64512, Address: "192.168.11.2"},
+				{ASN: 64512, Address: "2001:db8::2"},
+			},
+			ExpectedNeighbors: ptr.To(2),
+		},
+	}
+	neighbor := func(ip string, connected bool) *frr.Neighbor {
+		return &frr.Neighbor{IP: net.ParseIP(ip), Connected: connected}
+	}
+
+	tests := []struct {
+		name        string
+		neighbors   []*frr.Neighbor
+		wantStatus  metav1.ConditionStatus
+		wantMessage string
+	}{
+		{
+			name: "all the expected neighbors established",
+			neighbors: []*frr.Neighbor{
+				neighbor("192.168.11.2", true),
+				neighbor("2001:db8::2", true),
+			},
+			wantStatus:  metav1.ConditionFalse,
+			wantMessage: "2 of 2 expected neighbors established",
+		},
+		{
+			name: "one session not established",
+			neighbors: []*frr.Neighbor{
+				neighbor("192.168.11.2", true),
+				neighbor("2001:db8::2", false),
+			},
+			wantStatus:  metav1.ConditionTrue,
+			wantMessage: "1 of 2 expected neighbors established",
+		},
+		{
+			name: "established session with a host is not counted",
+			neighbors: []*frr.Neighbor{
+				neighbor("192.168.11.2", true),
+				neighbor("192.169.10.1", true),
+			},
+			wantStatus:  metav1.ConditionTrue,
+			wantMessage: "1 of 2 expected neighbors established",
+		},
+	}
+
+	oldBGPNeighbors := bgpNeighbors
+	t.Cleanup(func() { bgpNeighbors = oldBGPNeighbors })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bgpNeighbors = func(_ context.Context, socketPath string) ([]*frr.Neighbor, error) {
+				if socketPath != "/etc/frr/reload.sock" {
+					t.Errorf("expected the neighbors from socket %q, got %q", "/etc/frr/reload.sock", socketPath)
+				}
+				return tt.neighbors, nil
+			}
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(underlay.DeepCopy()).WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+
+			r := &PERouterReconciler{Client: cli, MyNode: "node1", FRRReloadSocket: "/etc/frr/reload.sock"}
+			ctx := context.Background()
+			recheck := r.reportIncompleteNeighbors(ctx, []v1alpha1.Underlay{underlay})
+			if recheck != (tt.wantStatus == metav1.ConditionTrue) {
+				t.Errorf("expected recheck %t, got %t", tt.wantStatus == metav1.ConditionTrue, recheck)
+			}
+
+			got := &v1alpha1.Underlay{}
+			if err := cli.Get(ctx, client.ObjectKeyFromObject(&underlay), got); err != nil {
+				t.Fatalf("failed to get the underlay: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, "node1/"+NeighborsIncompleteCondition)
+			if condition == nil {
+				t.Fatalf("condition not found on the underlay: %+v", got.Status.Conditions)
+			}
+			if condition.Status != tt.wantStatus {
+				t.Errorf("expected condition status %s, got %s", tt.wantStatus, condition.Status)
+			}
+			if condition.Message != tt.wantMessage {
+				t.Errorf("expected condition message %q, got %q", tt.wantMessage, condition.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setNodeCondition sets the given condition, of the node the controller runs
+// on, among the conditions of the status of the given object, and removes the
+// conditions of the nodes that do not exist anymore. The conditions point to
+// the conditions of the object, which is read again before each attempt.
+//
+// The status is merge patched with an optimistic lock, so that only the
+// conditions are written and the ones set concurrently by the controllers
+// running on the other nodes are never overwritten.
+func (r *PERouterReconciler) setNodeCondition(ctx context.Context, key client.ObjectKey, obj client.Object, conditions *[]metav1.Condition, condition metav1.Condition) error {
+	nodes, err := r.existingNodes(ctx)
+	if err != nil {
+		slog.DebugContext(ctx, "not removing the conditions of the deleted nodes", "error", err)
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, key, obj); err != nil {
+			return err
+		}
+		base := obj.DeepCopyObject().(client.Object)
+		condition.ObservedGeneration = obj.GetGeneration()
+		changed := meta.SetStatusCondition(conditions, condition)
+		if nodes != nil && pruneNodeConditions(conditions, nodes) {
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return r.Status().Patch(ctx, obj, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// existingNodes returns the names of the nodes of the cluster, always
+// including the one the controller runs on.
+func (r *PERouterReconciler) existingNodes(ctx context.Context) (map[string]bool, error) {
+	var nodes v1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list the nodes: %w", err)
+	}
+	res := map[string]bool{r.MyNode: true}
+	for _, node := range nodes.Items {
+		res[node.Name] = true
+	}
+	return res, nil
+}
+
+// pruneNodeConditions removes the conditions prefixed by the name of a node
+// not among the given ones, and tells if any was removed. The conditions not
+// prefixed by a node name are kept.
+func pruneNodeConditions(conditions *[]metav1.Condition, nodes map[string]bool) bool {
+	before := len(*conditions)
+	*conditions = slices.DeleteFunc(*conditions, func(c metav1.Condition) bool {
+		node, _, ok := strings.Cut(c.Type, "/")
+		return ok && !nodes[node]
+	})
+	return len(*conditions) != before
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestSetNodeConditionPrunesDeletedNodes(t *testing.T) {
+	condition := func(conditionType string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue, Reason: "Reason", LastTransitionTime: metav1.Now()}
+	}
+	underlay := &v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Status: v1alpha1.UnderlayStatus{
+			Conditions: []metav1.Condition{
+				condition("node2/" + MaintenanceCondition),
+				condition("deleted/" + MaintenanceCondition),
+				condition("Unprefixed"),
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			underlay,
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}},
+		).
+		WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+
+	r := &PERouterReconciler{Client: cli, MyNode: "node1"}
+	if err := r.setUnderlayCondition(context.Background(), client.ObjectKeyFromObject(underlay), condition("node1/"+MaintenanceCondition)); err != nil {
+		t.Fatalf("setUnderlayCondition() unexpected error: %v", err)
+	}
+
+	got := &v1alpha1.Underlay{}
+	if err := cli.Get(context.Background(), client.ObjectKeyFromObject(underlay), got); err != nil {
+		t.Fatalf("failed to get the underlay: %v", err)
+	}
+	types := []string{}
+	for _, c := range got.Status.Conditions {
+		types = append(types, c.Type)
+	}
+	slices.Sort(types)
+	want := []string{"Unprefixed", "node1/" + MaintenanceCondition, "node2/" + MaintenanceCondition}
+	if !slices.Equal(types, want) {
+		t.Errorf("expected conditions %v, got %v", want, types)
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+// NodeIndexChangedCondition is the type of the condition, prefixed by the
+// node name, reported on the underlays telling if the index of the node
+// changed since the configuration was last applied. The VTEP IP, the router ID and the
+// veth IPs of the node are all derived from its index, so a change of the
+// index reconfigures the whole node.
+const NodeIndexChangedCondition = "NodeIndexChanged"
+
+const (
+	reasonNodeIndexApplied = "NodeIndexApplied"
+	reasonNodeIndexChanged = "NodeIndexChanged"
+)
+
+// NodeIndexChangedError is returned when the index of the node changed
+// since the configuration was last applied, and the change is refused.
+type NodeIndexChangedError struct {
+	Previous int
+	Current  int
+	// Path is the file the applied index is persisted to.
+	Path string
+}
+
+func (e NodeIndexChangedError) Error() string {
+	return fmt.Sprintf("node index changed from %d to %d, refusing to reconfigure the node: remove %s to accept the new index",
+		e.Previous, e.Current, e.Path)
+}
+
+// nodeIndexFile is the name of the file, in the directory of the frr
+// configuration, the index of the node the configuration was last
+// applied with is persisted to.
+const nodeIndexFile = "node-index"
+
+// nodeIndexTracker remembers the index of the node the configuration
+// was last applied with, to tell when it changes. The index is persisted
+// to path, when set, so that a change happening while the controller is
+// not running, as when the node rejoins the cluster, is detected too.
+type nodeIndexTracker struct {
+	mu       sync.Mutex
+	path     string
+	loaded   bool
+	applied  *int
+	reported bool
+}
+
+// load reads the persisted index, the first time it is called.
+func (t *nodeIndexTracker) load() {
+	if t.loaded || t.path == "" {
+		return
+	}
+	t.loaded = true
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to read the applied node index", "path", t.path, "error", err)
+		return
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		slog.Error("invalid applied node index", "path", t.path, "error", err)
+		return
+	}
+	t.applied = &index
+}
+
+// changed tells if the given index differs from the one the configuration
+// was last applied with, returned as well. Nothing is changed before the
+// configuration is applied for the first time.
+func (t *nodeIndexTracker) changed(index int) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+	if t.applied == nil {
+		return index, false
+	}
+	return *t.applied, *t.applied != index
+}
+
+// setApplied records, and persists, the index the configuration was
+// applied with, and tells if it is the first one recorded since the
+// controller started.
+func (t *nodeIndexTracker) setApplied(index int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load()
+	first := !t.reported
+	t.reported = true
+	if t.applied != nil && *t.applied == index {
+		return first
+	}
+	t.applied = &index
+	if t.path != "" {
+		if err := os.WriteFile(t.path, []byte(strconv.Itoa(index)), 0o644); err != nil {
+			slog.Error("failed to persist the applied node index", "path", t.path, "error", err)
+		}
+	}
+	return first
+}
+
+// checkNodeIndex detects a change of the index of the node since the
+// configuration was last applied, reporting it as a condition of the
+// given underlays. The change is refused, returning a NodeIndexChangedError,
+// if the reconciler is set to do so.
+func (r *PERouterReconciler) checkNodeIndex(ctx context.Context, underlays []v1alpha1.Underlay, index int) error {
+	previous, changed := r.nodeIndexes.changed(index)
+	if !changed {
+		return nil
+	}
+	slog.WarnContext(ctx, "the index of the node changed, its vtep ip changes as well", "previous", previous, "current", index)
+	r.reportNodeIndex(ctx, underlays, metav1.Condition{
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonNodeIndexChanged,
+		Message: fmt.Sprintf("node index changed from %d to %d", previous, index),
+	})
+	if r.RefuseNodeIndexChange {
+		return NodeIndexChangedError{Previous: previous, Current: index, Path: r.nodeIndexes.path}
+	}
+	return nil
+}
+
+// nodeIndexApplied records the index the configuration was applied with.
+// The first time, it reports as a condition of the given underlays that
+// the index did not change.
+func (r *PERouterReconciler) nodeIndexApplied(ctx context.Context, underlays []v1alpha1.Underlay, index int) {
+	if !r.nodeIndexes.setApplied(index) {
+		return
+	}
+	r.reportNodeIndex(ctx, underlays, metav1.Condition{
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonNodeIndexApplied,
+		Message: fmt.Sprintf("node index %d applied", index),
+	})
+}
+
+// reportNodeIndex sets the given condition, about the index of the node,
+// to the given underlays. Failing to do it does not fail the reconciliation,
+// as the condition is informative only.
+func (r *PERouterReconciler) reportNodeIndex(ctx context.Context, underlays []v1alpha1.Underlay, condition metav1.Condition) {
+	if r.MyNode == "" {
+		return
+	}
+	condition.Type = nodeIndexChangedConditionType(r.MyNode)
+	errs := []error{}
+	for _, underlay := range underlays {
+		if err := r.setUnderlayCondition(ctx, client.ObjectKeyFromObject(&underlay), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		slog.ErrorContext(ctx, "failed to report the node index", "error", err)
+	}
+}
+
+func (r *PERouterReconciler) setUnderlayCondition(ctx context.Context, key client.ObjectKey, condition metav1.Condition) error {
+	underlay := &v1alpha1.Underlay{}
+	if err := r.setNodeCondition(ctx, key, underlay, &underlay.Status.Conditions, condition); err != nil {
+		return fmt.Errorf("failed to update the status of underlay %s: %w", key, err)
+	}
+	return nil
+}
+
+func nodeIndexChangedConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, NodeIndexChangedCondition)
+}
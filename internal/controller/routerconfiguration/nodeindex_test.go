@@ -0,0 +1,145 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestCheckNodeIndex(t *testing.T) {
+	tests := []struct {
+		name          string
+		applied       []int
+		current       int
+		refuse        bool
+		wantErr       bool
+		wantCondition metav1.ConditionStatus
+	}{
+		{
+			name:          "first configuration",
+			current:       1,
+			wantCondition: metav1.ConditionFalse,
+		},
+		{
+			name:          "same index",
+			applied:       []int{1},
+			current:       1,
+			wantCondition: metav1.ConditionFalse,
+		},
+		{
+			name:          "index changed",
+			applied:       []int{1},
+			current:       2,
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:          "index changed back",
+			applied:       []int{1, 2},
+			current:       1,
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:          "index change refused",
+			applied:       []int{1},
+			current:       2,
+			refuse:        true,
+			wantErr:       true,
+			wantCondition: metav1.ConditionTrue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlay := v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+			}
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(underlay.DeepCopy()).WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+			underlays := []v1alpha1.Underlay{underlay}
+
+			r := &PERouterReconciler{Client: cli, MyNode: "node1", RefuseNodeIndexChange: tt.refuse}
+			ctx := context.Background()
+			for _, index := range tt.applied {
+				if err := r.checkNodeIndex(ctx, underlays, index); err != nil {
+					t.Fatalf("checkNodeIndex(%d) unexpected error: %v", index, err)
+				}
+				r.nodeIndexApplied(ctx, underlays, index)
+			}
+
+			err := r.checkNodeIndex(ctx, underlays, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkNodeIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.As(err, &NodeIndexChangedError{}) {
+				t.Fatalf("checkNodeIndex() error = %v, want a NodeIndexChangedError", err)
+			}
+			if err == nil {
+				r.nodeIndexApplied(ctx, underlays, tt.current)
+			}
+
+			got := &v1alpha1.Underlay{}
+			if err := cli.Get(ctx, client.ObjectKeyFromObject(&underlay), got); err != nil {
+				t.Fatalf("failed to get the underlay: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, "node1/"+NodeIndexChangedCondition)
+			if condition == nil {
+				t.Fatalf("condition not found on the underlay: %+v", got.Status.Conditions)
+			}
+			if condition.Status != tt.wantCondition {
+				t.Errorf("expected condition status %s, got %s (%s)", tt.wantCondition, condition.Status, condition.Message)
+			}
+		})
+	}
+}
+
+func TestCheckNodeIndexAfterRestart(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(underlay.DeepCopy()).WithStatusSubresource(&v1alpha1.Underlay{}).Build()
+	underlays := []v1alpha1.Underlay{underlay}
+	path := filepath.Join(t.TempDir(), nodeIndexFile)
+	ctx := context.Background()
+
+	r := &PERouterReconciler{Client: cli, MyNode: "node1", RefuseNodeIndexChange: true}
+	r.nodeIndexes.path = path
+	if err := r.checkNodeIndex(ctx, underlays, 1); err != nil {
+		t.Fatalf("checkNodeIndex() unexpected error: %v", err)
+	}
+	r.nodeIndexApplied(ctx, underlays, 1)
+
+	restarted := &PERouterReconciler{Client: cli, MyNode: "node1", RefuseNodeIndexChange: true}
+	restarted.nodeIndexes.path = path
+	err := restarted.checkNodeIndex(ctx, underlays, 2)
+	if !errors.As(err, &NodeIndexChangedError{}) {
+		t.Fatalf("checkNodeIndex() error = %v, want a NodeIndexChangedError", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove the applied node index: %v", err)
+	}
+	accepted := &PERouterReconciler{Client: cli, MyNode: "node1", RefuseNodeIndexChange: true}
+	accepted.nodeIndexes.path = path
+	if err := accepted.checkNodeIndex(ctx, underlays, 2); err != nil {
+		t.Fatalf("checkNodeIndex() unexpected error after removing the applied index: %v", err)
+	}
+}
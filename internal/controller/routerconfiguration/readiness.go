@@ -0,0 +1,54 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+// RouterNamespaceCheck is the name of the readiness check of the
+// network namespace of the router.
+const RouterNamespaceCheck = "router-namespace"
+
+// RouterNamespaceChecker is a readiness check failing when the network
+// namespace of the router can't be reached, as it happens when the router
+// is restarted and the namespace resolved before is gone, or when an
+// underlay is configured but the namespace misses the underlay interface.
+type RouterNamespaceChecker struct {
+	Client         client.Reader
+	RouterProvider RouterProvider
+}
+
+// Check implements healthz.Checker.
+func (c *RouterNamespaceChecker) Check(req *http.Request) error {
+	ctx := req.Context()
+	var underlays v1alpha1.UnderlayList
+	if err := c.Client.List(ctx, &underlays); err != nil {
+		return fmt.Errorf("failed to list the underlays: %w", err)
+	}
+	if len(underlays.Items) == 0 {
+		return nil
+	}
+
+	router, err := c.RouterProvider.New(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get router instance: %w", err)
+	}
+	targetNS, err := router.TargetNS(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve target namespace: %w", err)
+	}
+	hasUnderlay, err := hasUnderlayInterface(targetNS)
+	if err != nil {
+		return fmt.Errorf("router namespace %s is not reachable: %w", targetNS, err)
+	}
+	if !hasUnderlay {
+		return fmt.Errorf("router namespace %s has no underlay interface", targetNS)
+	}
+	return nil
+}
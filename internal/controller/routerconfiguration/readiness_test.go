@@ -0,0 +1,81 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestRouterNamespaceChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		underlay    bool
+		hasUnderlay bool
+		nsErr       error
+		wantErr     bool
+	}{
+		{
+			name: "no underlay configured",
+		},
+		{
+			name:        "underlay interface present",
+			underlay:    true,
+			hasUnderlay: true,
+		},
+		{
+			name:     "underlay interface missing",
+			underlay: true,
+			wantErr:  true,
+		},
+		{
+			name:     "namespace gone",
+			underlay: true,
+			nsErr:    errors.New("no such file or directory"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeHostNetwork(t)
+			var checkedNS string
+			hasUnderlayInterface = func(namespace string) (bool, error) {
+				checkedNS = namespace
+				return tt.hasUnderlay, tt.nsErr
+			}
+
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+			}
+			objects := []client.Object{}
+			if tt.underlay {
+				objects = append(objects, &v1alpha1.Underlay{
+					ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				})
+			}
+			checker := &RouterNamespaceChecker{
+				Client:         fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				RouterProvider: fakeRouterProvider{targetNS: "/run/netns/router"},
+			}
+
+			err := checker.Check(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.underlay && checkedNS != "/run/netns/router" {
+				t.Errorf("expected the router namespace to be checked, got %q", checkedNS)
+			}
+		})
+	}
+}
@@ -4,43 +4,107 @@ package routerconfiguration
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openperouter/openperouter/internal/audit"
 
 	"github.com/openperouter/openperouter/internal/conversion"
 	"github.com/openperouter/openperouter/internal/frr"
+	"github.com/openperouter/openperouter/internal/logging"
 )
 
-func Reconcile(ctx context.Context, apiConfig conversion.ApiConfigData, frrConfigPath, targetNamespace string, updater frr.ConfigUpdater) error {
-	if err := conversion.ValidateUnderlays(apiConfig.Underlays); err != nil {
-		return fmt.Errorf("failed to validate underlays: %w", err)
-	}
+// The phases of a reconciliation, attached to the log lines
+// emitted while running each of them.
+const (
+	phaseValidation = "validation"
+	phaseFRR        = "frr"
+	phaseHost       = "host"
+)
 
-	if err := conversion.ValidateL3VNIs(apiConfig.L3VNIs); err != nil {
-		return fmt.Errorf("failed to validate l3vnis: %w", err)
-	}
+// withPhase returns a context whose log lines are tagged with the given phase.
+func withPhase(ctx context.Context, phase string) context.Context {
+	return logging.WithAttrs(ctx, slog.String("phase", phase))
+}
 
-	if err := conversion.ValidateL2VNIs(apiConfig.L2VNIs); err != nil {
-		return fmt.Errorf("failed to validate l2vnis: %w", err)
+// Reconcile applies the given configuration to the router and to the host,
+// returning the audit record describing the applied configuration. In best
+// effort mode, the VNIs are set up independently from each other: if only
+// some of them fail, the record is returned together with a VNIFailuresError.
+// The frr configuration is applied first, so that the routes of the removed
+// VNIs are withdrawn before their devices are deleted, by the first
+// reconciliation happening after their teardown delay tracked by teardowns.
+func Reconcile(ctx context.Context, apiConfig conversion.ApiConfigData, frrConfigPath, targetNamespace string, updater frr.ConfigUpdater, bestEffort bool, teardowns *vniTeardownTracker) (audit.Record, error) {
+	phases := map[string]string{}
+	timePhase := func(phase string, start time.Time) {
+		phases[phase] = time.Since(start).String()
 	}
 
-	if err := conversion.ValidateHostSessions(apiConfig.L3VNIs, apiConfig.L3Passthrough); err != nil {
-		return fmt.Errorf("failed to validate host sessions: %w", err)
+	start := time.Now()
+	slog.DebugContext(withPhase(ctx, phaseValidation), "validating the configuration")
+	if err := conversion.ValidateAll(apiConfig.Underlays, apiConfig.L3VNIs, apiConfig.L2VNIs, apiConfig.L3Passthrough); err != nil {
+		return audit.Record{}, err
+	}
+	if err := conversion.ValidateDeviceNames(apiConfig.DeviceNamePrefix, apiConfig.L3VNIs, apiConfig.L2VNIs); err != nil {
+		return audit.Record{}, err
 	}
+	timePhase(phaseValidation, start)
 
+	start = time.Now()
 	if err := configureFRR(ctx, frrConfigData{
 		configFile:    frrConfigPath,
 		updater:       updater,
 		ApiConfigData: apiConfig,
 	}); err != nil {
-		return fmt.Errorf("failed to reload frr config: %w", err)
+		return audit.Record{}, fmt.Errorf("failed to reload frr config: %w", err)
 	}
+	timePhase(phaseFRR, start)
 
-	if err := configureInterfaces(ctx, interfacesConfiguration{
+	start = time.Now()
+	hostErr := configureInterfaces(ctx, interfacesConfiguration{
 		targetNamespace: targetNamespace,
+		bestEffort:      bestEffort,
+		teardowns:       teardowns,
 		ApiConfigData:   apiConfig,
-	}); err != nil {
-		return fmt.Errorf("failed to configure the host: %w", err)
+	})
+	var vniFailures VNIFailuresError
+	partialFailure := errors.As(hostErr, &vniFailures) && !vniFailures.AllFailed()
+	if hostErr != nil && !partialFailure {
+		return audit.Record{}, fmt.Errorf("failed to configure the host: %w", hostErr)
 	}
+	timePhase(phaseHost, start)
 
-	return nil
+	hash, err := conversion.ConfigHash(apiConfig)
+	if err != nil {
+		return audit.Record{}, fmt.Errorf("failed to hash the applied configuration: %w", err)
+	}
+	slog.InfoContext(ctx, "configuration applied", "hash", hash)
+
+	return audit.Record{
+		Time:      time.Now(),
+		Hash:      hash,
+		Resources: configResources(apiConfig),
+		Phases:    phases,
+	}, hostErr
+}
+
+// configResources returns the resources the given configuration is
+// built from, in the form kind/namespace/name.
+func configResources(apiConfig conversion.ApiConfigData) []string {
+	res := []string{}
+	for _, u := range apiConfig.Underlays {
+		res = append(res, "underlay/"+u.Namespace+"/"+u.Name)
+	}
+	for _, vni := range apiConfig.L3VNIs {
+		res = append(res, "l3vni/"+vni.Namespace+"/"+vni.Name)
+	}
+	for _, vni := range apiConfig.L2VNIs {
+		res = append(res, "l2vni/"+vni.Namespace+"/"+vni.Name)
+	}
+	for _, p := range apiConfig.L3Passthrough {
+		res = append(res, "l3passthrough/"+p.Namespace+"/"+p.Name)
+	}
+	return res
 }
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/audit"
+	"github.com/openperouter/openperouter/internal/conversion"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+	"github.com/openperouter/openperouter/internal/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []map[string]string
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := map[string]string{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, attrs)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func TestConfigureFRRLogsPhase(t *testing.T) {
+	recorder := &recordingHandler{}
+	oldLogger := slog.Default()
+	slog.SetDefault(slog.New(logging.NewContextHandler(recorder)))
+	t.Cleanup(func() { slog.SetDefault(oldLogger) })
+
+	ctx := logging.WithAttrs(context.Background(), slog.String("request", "openperouter-system/underlay"))
+	updater := func(context.Context, string) error { return nil }
+
+	err := configureFRR(ctx, frrConfigData{
+		updater:       updater,
+		ApiConfigData: conversion.ApiConfigData{},
+	})
+	if err != nil {
+		t.Fatalf("configureFRR() unexpected error: %v", err)
+	}
+
+	if len(recorder.records) == 0 {
+		t.Fatalf("expected log records, got none")
+	}
+	for _, attrs := range recorder.records {
+		if attrs["phase"] != phaseFRR {
+			t.Errorf("expected phase %q, got %q in %v", phaseFRR, attrs["phase"], attrs)
+		}
+		if attrs["request"] != "openperouter-system/underlay" {
+			t.Errorf("expected request %q, got %q in %v", "openperouter-system/underlay", attrs["request"], attrs)
+		}
+	}
+}
+
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (s *recordingSink) Emit(_ context.Context, r audit.Record) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestReconcileEmitsAuditRecord(t *testing.T) {
+	fakeHostNetwork(t)
+	sink := &recordingSink{}
+	r := &PERouterReconciler{MyNode: "node1", AuditSink: sink}
+
+	apiConfig := conversion.ApiConfigData{
+		Underlays: []v1alpha1.Underlay{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec: v1alpha1.UnderlaySpec{
+					ASN:  65000,
+					Nics: []string{"eth0"},
+					EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+				},
+			},
+		},
+		L3VNIs: []v1alpha1.L3VNI{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789},
+			},
+		},
+	}
+	updater := func(context.Context, string) error { return nil }
+
+	record, err := Reconcile(context.Background(), apiConfig, "", "namespace", updater, false, nil)
+	if err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	r.emitAudit(context.Background(), record)
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected one audit record, got %d", len(sink.records))
+	}
+	got := sink.records[0]
+	if got.Node != "node1" {
+		t.Errorf("expected node %q, got %q", "node1", got.Node)
+	}
+	wantHash, err := conversion.ConfigHash(apiConfig)
+	if err != nil {
+		t.Fatalf("ConfigHash() unexpected error: %v", err)
+	}
+	if got.Hash != wantHash {
+		t.Errorf("expected hash %q, got %q", wantHash, got.Hash)
+	}
+	wantResources := []string{"underlay/openperouter-system/underlay", "l3vni/openperouter-system/red"}
+	if !cmp.Equal(got.Resources, wantResources) {
+		t.Errorf("audit record resources diff %s", cmp.Diff(got.Resources, wantResources))
+	}
+	for _, phase := range []string{phaseValidation, phaseFRR, phaseHost} {
+		if _, ok := got.Phases[phase]; !ok {
+			t.Errorf("expected timing for phase %q in %v", phase, got.Phases)
+		}
+	}
+	if got.Time.IsZero() {
+		t.Errorf("expected the record time to be set")
+	}
+}
+
+func TestReconcileWithdrawsVNIsBeforeRemovingThem(t *testing.T) {
+	ops := fakeHostNetwork(t)
+	removeNonConfiguredVNIs = func(_, _ string, _ []hostnetwork.VNIParams, withdraw hostnetwork.WithdrawFunc) error {
+		// vni 200 is not configured anymore but its devices are still there.
+		if withdraw != nil {
+			*ops = append(*ops, fmt.Sprintf("keep vnis %v", withdraw([]int{200})))
+		}
+		*ops = append(*ops, "remove vnis")
+		return nil
+	}
+
+	apiConfig := conversion.ApiConfigData{
+		Underlays: []v1alpha1.Underlay{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec: v1alpha1.UnderlaySpec{
+					ASN:  65000,
+					Nics: []string{"eth0"},
+					EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+				},
+			},
+		},
+	}
+	updater := func(context.Context, string) error {
+		*ops = append(*ops, "apply frr")
+		return nil
+	}
+
+	if _, err := Reconcile(context.Background(), apiConfig, "", "namespace", updater, false, newVNITeardownTracker(time.Hour)); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+
+	want := []string{"apply frr", "ensure ipv6 forwarding", "setup underlay", "keep vnis [200]", "remove vnis"}
+	if diff := cmp.Diff(want, (*ops)[:len(want)]); diff != "" {
+		t.Errorf("unexpected operations (-want +got):\n%s", diff)
+	}
+}
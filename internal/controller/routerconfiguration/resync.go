@@ -0,0 +1,45 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+// DefaultResyncPeriod is the default interval the configuration of the
+// node is periodically reconciled at, to catch up with the changes
+// (as the underlay sessions going up or down) that don't produce any
+// kubernetes event.
+const DefaultResyncPeriod = 5 * time.Minute
+
+// resyncObjectName is the name of the object the periodic resync events
+// are about. Each reconcile applies the whole configuration of the node,
+// so it does not need to match any existing object.
+const resyncObjectName = "periodic-resync"
+
+// resync sends a resync event every period, until the context is done.
+// An event is dropped if the previous one is not consumed yet, as it
+// would trigger the same reconcile.
+func resync(ctx context.Context, period time.Duration, events chan<- event.GenericEvent) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case events <- event.GenericEvent{Object: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: resyncObjectName},
+			}}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestResync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan event.GenericEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		resync(ctx, 10*time.Millisecond, events)
+		close(done)
+	}()
+
+	for range 2 {
+		select {
+		case e := <-events:
+			if e.Object.GetName() != resyncObjectName {
+				t.Fatalf("resync event for %q, want %q", e.Object.GetName(), resyncObjectName)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no resync event received")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resync did not stop after the context was done")
+	}
+}
+
+func TestResyncDropsUnconsumedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan event.GenericEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		resync(ctx, time.Millisecond, events)
+		close(done)
+	}()
+
+	// the ticks after the first one can't be delivered, and must
+	// not block the resync from stopping.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resync blocked on an unconsumed event")
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d pending resync events, want 1", len(events))
+	}
+}
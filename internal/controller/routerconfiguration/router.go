@@ -13,6 +13,6 @@ type RouterProvider interface {
 
 type Router interface {
 	TargetNS(ctx context.Context) (string, error)
-	HandleNonRecoverableError(ctx context.Context) error
+	HandleNonRecoverableError(ctx context.Context, reason error) error
 	CanReconcile(ctx context.Context) (bool, error)
 }
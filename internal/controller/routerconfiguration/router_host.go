@@ -13,11 +13,28 @@ import (
 	"github.com/openperouter/openperouter/internal/systemdctl"
 )
 
+// DefaultRouterUnitName is the systemd unit running the router
+// when none is provided.
+const DefaultRouterUnitName = "pod-routerpod.service"
+
 type RouterHostProvider struct {
 	FRRConfigPath     string
 	RouterPidFilePath string
 	CurrentNodeIndex  int
 	SystemdSocketPath string
+	RouterUnitName    string
+}
+
+// systemdClient is the subset of the systemd operations needed
+// to manage the router unit.
+type systemdClient interface {
+	Restart(ctx context.Context, unitName string) error
+	IsActive(unitName string) (bool, error)
+	Exists(ctx context.Context, unitName string) (bool, error)
+}
+
+var newSystemdClient = func() (systemdClient, error) {
+	return systemdctl.NewClient()
 }
 
 var _ RouterProvider = (*RouterHostProvider)(nil)
@@ -34,6 +51,30 @@ func (r *RouterHostProvider) New(ctx context.Context) (Router, error) {
 	}, nil
 }
 
+// ValidateUnit checks that the router systemd unit exists.
+func (r *RouterHostProvider) ValidateUnit(ctx context.Context) error {
+	client, err := newSystemdClient()
+	if err != nil {
+		return fmt.Errorf("failed to create systemd client %w", err)
+	}
+	unit := r.unitName()
+	exists, err := client.Exists(ctx, unit)
+	if err != nil {
+		return fmt.Errorf("failed to check if router unit %s exists: %w", unit, err)
+	}
+	if !exists {
+		return fmt.Errorf("router unit %s not found", unit)
+	}
+	return nil
+}
+
+func (r *RouterHostProvider) unitName() string {
+	if r.RouterUnitName == "" {
+		return DefaultRouterUnitName
+	}
+	return r.RouterUnitName
+}
+
 func (r *RouterHostProvider) NodeIndex(ctx context.Context) (int, error) {
 	return r.CurrentNodeIndex, nil
 }
@@ -54,28 +95,30 @@ func (r *RouterHostContainer) TargetNS(ctx context.Context) (string, error) {
 	return res, nil
 }
 
-func (r *RouterHostContainer) HandleNonRecoverableError(ctx context.Context) error {
-	client, err := systemdctl.NewClient()
+func (r *RouterHostContainer) HandleNonRecoverableError(ctx context.Context, reason error) error {
+	client, err := newSystemdClient()
 	if err != nil {
 		return fmt.Errorf("failed to create systemd client %w", err)
 	}
-	slog.Info("restarting router systemd unit", "unit", "pod-routerpod.service")
-	if err := client.Restart(ctx, "pod-routerpod.service"); err != nil {
-		return fmt.Errorf("failed to restart routerpod service")
+	unit := r.manager.unitName()
+	slog.Info("restarting router systemd unit", "unit", unit, "reason", reason)
+	if err := client.Restart(ctx, unit); err != nil {
+		return fmt.Errorf("failed to restart router unit %s: %w", unit, err)
 	}
-	slog.Info("router systemd unit restarted", "unit", "pod-routerpod.service")
+	slog.Info("router systemd unit restarted", "unit", unit)
 
 	return nil
 }
 
 func (r *RouterHostContainer) CanReconcile(ctx context.Context) (bool, error) {
-	client, err := systemdctl.NewClient()
+	client, err := newSystemdClient()
 	if err != nil {
 		return false, fmt.Errorf("failed to create systemd client %w", err)
 	}
-	res, err := client.IsActive("pod-routerpod.service")
+	unit := r.manager.unitName()
+	res, err := client.IsActive(unit)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if router pod service is active")
+		return false, fmt.Errorf("failed to check if router unit %s is active: %w", unit, err)
 	}
 
 	return res, nil
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSystemd struct {
+	units     map[string]bool
+	restarted []string
+	checked   []string
+}
+
+func (f *fakeSystemd) Restart(_ context.Context, unitName string) error {
+	f.restarted = append(f.restarted, unitName)
+	return nil
+}
+
+func (f *fakeSystemd) IsActive(unitName string) (bool, error) {
+	f.checked = append(f.checked, unitName)
+	return f.units[unitName], nil
+}
+
+func (f *fakeSystemd) Exists(_ context.Context, unitName string) (bool, error) {
+	_, ok := f.units[unitName]
+	return ok, nil
+}
+
+func TestRouterHostUnitName(t *testing.T) {
+	tests := []struct {
+		name     string
+		unitName string
+		units    map[string]bool
+		wantUnit string
+		wantErr  bool
+	}{
+		{
+			name:     "default unit",
+			units:    map[string]bool{DefaultRouterUnitName: true},
+			wantUnit: DefaultRouterUnitName,
+		},
+		{
+			name:     "custom unit",
+			unitName: "openperouter-router.service",
+			units:    map[string]bool{"openperouter-router.service": true},
+			wantUnit: "openperouter-router.service",
+		},
+		{
+			name:     "custom unit not found",
+			unitName: "openperouter-router.service",
+			units:    map[string]bool{DefaultRouterUnitName: true},
+			wantUnit: "openperouter-router.service",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSystemd{units: tt.units}
+			oldNewSystemdClient := newSystemdClient
+			newSystemdClient = func() (systemdClient, error) {
+				return fake, nil
+			}
+			t.Cleanup(func() { newSystemdClient = oldNewSystemdClient })
+
+			provider := &RouterHostProvider{RouterUnitName: tt.unitName}
+			err := provider.ValidateUnit(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateUnit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			router, err := provider.New(context.Background())
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			active, err := router.CanReconcile(context.Background())
+			if err != nil {
+				t.Fatalf("CanReconcile() unexpected error: %v", err)
+			}
+			if !active {
+				t.Errorf("CanReconcile() = false, want true")
+			}
+			if err := router.HandleNonRecoverableError(context.Background(), errors.New("failure")); err != nil {
+				t.Fatalf("HandleNonRecoverableError() unexpected error: %v", err)
+			}
+
+			if len(fake.checked) != 1 || fake.checked[0] != tt.wantUnit {
+				t.Errorf("checked units = %v, want [%s]", fake.checked, tt.wantUnit)
+			}
+			if len(fake.restarted) != 1 || fake.restarted[0] != tt.wantUnit {
+				t.Errorf("restarted units = %v, want [%s]", fake.restarted, tt.wantUnit)
+			}
+		})
+	}
+}
@@ -8,20 +8,47 @@ import (
 	"log/slog"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/openperouter/openperouter/internal/controller/nodeindex"
 	"github.com/openperouter/openperouter/internal/pods"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const nodeNameIndex = "spec.NodeName"
+const (
+	nodeNameIndex = "spec.NodeName"
+	// deletionReasonAnnotation records on the router pod the error
+	// it is deleted for.
+	deletionReasonAnnotation = "openpe.io/deletion-reason"
+)
+
+// The policies to choose the router pod with when more than one is
+// found for the node, for example during a rolling update with surge.
+const (
+	// RouterPodSelectionReady chooses the ready pod, the newest one
+	// if more than one is ready.
+	RouterPodSelectionReady = "ready"
+	// RouterPodSelectionNewest chooses the newest pod.
+	RouterPodSelectionNewest = "newest"
+)
 
 type RouterPodProvider struct {
 	PodRuntime    *pods.Runtime
 	Node          string
 	FRRConfigPath string
+	// DeletionCooldown is the minimum interval between two deletions of
+	// the router pod, so that a transient error does not cause a delete storm.
+	DeletionCooldown time.Duration
+	// PodSelection is the policy to choose the router pod with when
+	// more than one is found for the node. Defaults to RouterPodSelectionReady.
+	PodSelection string
 	client.Client
+
+	mu           sync.Mutex
+	lastDeletion time.Time
 }
 
 var _ RouterProvider = (*RouterPodProvider)(nil)
@@ -32,9 +59,10 @@ type RouterPod struct {
 }
 
 var _ Router = (*RouterPod)(nil)
+var _ multusRouter = (*RouterPod)(nil)
 
 func (r *RouterPodProvider) New(ctx context.Context) (Router, error) {
-	routerPod, err := routerPodForNode(ctx, r, r.Node)
+	routerPod, err := routerPodForNode(ctx, r, r.Node, r.PodSelection)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch router pod for node %s: %w", r.Node, err)
 	}
@@ -73,16 +101,35 @@ func (r *RouterPod) TargetNS(ctx context.Context) (string, error) {
 	return res, nil
 }
 
-func (r *RouterPod) HandleNonRecoverableError(ctx context.Context) error {
-	slog.Info("deleting router pod", "pod", r.pod.Name, "namespace", r.pod.Namespace)
+func (r *RouterPod) HandleNonRecoverableError(ctx context.Context, reason error) error {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+
+	if since := time.Since(r.manager.lastDeletion); since < r.manager.DeletionCooldown {
+		slog.Info("not deleting router pod, last deletion too recent", "pod", r.pod.Name, "namespace", r.pod.Namespace,
+			"reason", reason, "since", since, "cooldown", r.manager.DeletionCooldown)
+		return nil
+	}
+
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, deletionReasonAnnotation, reason.Error())
+	if err := r.manager.Patch(ctx, r.pod, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+		slog.Error("failed to annotate router pod", "pod", r.pod.Name, "error", err)
+	}
+
+	slog.Info("deleting router pod", "pod", r.pod.Name, "namespace", r.pod.Namespace, "reason", reason)
 	err := r.manager.Delete(ctx, r.pod)
 	if err != nil {
 		slog.Error("failed to delete router pod", "error", err)
 		return err
 	}
+	r.manager.lastDeletion = time.Now()
 	return nil
 }
 
+func (r *RouterPod) MultusInterface(network string) (string, error) {
+	return multusInterface(r.pod, network)
+}
+
 func (r *RouterPod) CanReconcile(ctx context.Context) (bool, error) {
 	routerPodIsReady := PodIsReady(r.pod)
 	if !routerPodIsReady {
@@ -92,8 +139,9 @@ func (r *RouterPod) CanReconcile(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-// routerPodForNode returns the router pod for the given node
-func routerPodForNode(ctx context.Context, cli client.Client, node string) (*v1.Pod, error) {
+// routerPodForNode returns the router pod for the given node, chosen
+// with the given selection policy if more than one is found.
+func routerPodForNode(ctx context.Context, cli client.Client, node, selection string) (*v1.Pod, error) {
 	var pods v1.PodList
 	if err := cli.List(ctx, &pods, client.MatchingLabels{"app": "router"},
 		client.MatchingFields{
@@ -101,13 +149,39 @@ func routerPodForNode(ctx context.Context, cli client.Client, node string) (*v1.
 		}); err != nil {
 		return nil, fmt.Errorf("failed to get router pod for node %s: %v", node, err)
 	}
-	if len(pods.Items) > 1 {
-		return nil, fmt.Errorf("more than one router pod found for node %s", node)
-	}
 	if len(pods.Items) == 0 {
 		return nil, fmt.Errorf("no router pods found for node %s", node)
 	}
-	return &pods.Items[0], nil
+	if len(pods.Items) == 1 {
+		return &pods.Items[0], nil
+	}
+
+	pod := selectRouterPod(pods.Items, selection)
+	if pod == nil {
+		return nil, fmt.Errorf("more than one router pod found for node %s, none of them usable with selection %q", node, selection)
+	}
+	slog.Info("more than one router pod found", "node", node, "count", len(pods.Items), "selected", pod.Name, "selection", selection)
+	return pod, nil
+}
+
+// selectRouterPod returns the pod to use among the given ones with the
+// given selection policy, or nil if none is usable. The pods being
+// deleted are never chosen.
+func selectRouterPod(pods []v1.Pod, selection string) *v1.Pod {
+	var res *v1.Pod
+	for i := range pods {
+		p := &pods[i]
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		if selection != RouterPodSelectionNewest && !PodIsReady(p) {
+			continue
+		}
+		if res == nil || res.CreationTimestamp.Before(&p.CreationTimestamp) {
+			res = p
+		}
+	}
+	return res
 }
 
 // PodIsReady returns the given pod's PodReady and ContainersReady condition.
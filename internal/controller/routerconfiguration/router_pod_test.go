@@ -0,0 +1,159 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRouterPodDeletionCooldown(t *testing.T) {
+	newPod := func() *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "router",
+				Namespace: "openperouter-system",
+				Labels:    map[string]string{"app": "router"},
+			},
+		}
+	}
+	ctx := context.Background()
+	cli := fake.NewClientBuilder().WithObjects(newPod()).Build()
+	provider := &RouterPodProvider{
+		Client:           cli,
+		DeletionCooldown: time.Hour,
+	}
+
+	getPod := func() (*v1.Pod, error) {
+		pod := &v1.Pod{}
+		err := cli.Get(ctx, client.ObjectKeyFromObject(newPod()), pod)
+		return pod, err
+	}
+
+	pod, err := getPod()
+	if err != nil {
+		t.Fatalf("failed to get router pod: %v", err)
+	}
+	router := &RouterPod{manager: provider, pod: pod}
+	if err := router.HandleNonRecoverableError(ctx, errors.New("first failure")); err != nil {
+		t.Fatalf("HandleNonRecoverableError() unexpected error: %v", err)
+	}
+	if got := pod.Annotations[deletionReasonAnnotation]; got != "first failure" {
+		t.Errorf("expected deletion reason %q, got %q", "first failure", got)
+	}
+	if _, err := getPod(); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected router pod to be deleted, got %v", err)
+	}
+
+	// The router pod is recreated and fails again within the cooldown.
+	if err := cli.Create(ctx, newPod()); err != nil {
+		t.Fatalf("failed to recreate router pod: %v", err)
+	}
+	pod, err = getPod()
+	if err != nil {
+		t.Fatalf("failed to get router pod: %v", err)
+	}
+	router = &RouterPod{manager: provider, pod: pod}
+	if err := router.HandleNonRecoverableError(ctx, errors.New("second failure")); err != nil {
+		t.Fatalf("HandleNonRecoverableError() unexpected error: %v", err)
+	}
+	if _, err := getPod(); err != nil {
+		t.Fatalf("expected router pod not to be deleted within the cooldown, got %v", err)
+	}
+}
+
+func TestRouterPodForNode(t *testing.T) {
+	now := time.Now()
+	routerPod := func(name string, ready bool, age time.Duration) *v1.Pod {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "openperouter-system",
+				Labels:            map[string]string{"app": "router"},
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: v1.PodSpec{NodeName: "node1"},
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{
+					{Type: v1.PodReady, Status: status},
+					{Type: v1.ContainersReady, Status: status},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pods      []*v1.Pod
+		selection string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:    "no pods",
+			wantErr: true,
+		},
+		{
+			name: "single pod not ready",
+			pods: []*v1.Pod{routerPod("old", false, time.Hour)},
+			want: "old",
+		},
+		{
+			name:      "ready pod is chosen",
+			pods:      []*v1.Pod{routerPod("old", true, time.Hour), routerPod("new", false, time.Minute)},
+			selection: RouterPodSelectionReady,
+			want:      "old",
+		},
+		{
+			name:      "newest ready pod is chosen",
+			pods:      []*v1.Pod{routerPod("old", true, time.Hour), routerPod("new", true, time.Minute)},
+			selection: RouterPodSelectionReady,
+			want:      "new",
+		},
+		{
+			name:      "none ready",
+			pods:      []*v1.Pod{routerPod("old", false, time.Hour), routerPod("new", false, time.Minute)},
+			selection: RouterPodSelectionReady,
+			wantErr:   true,
+		},
+		{
+			name:      "newest pod is chosen",
+			pods:      []*v1.Pod{routerPod("old", true, time.Hour), routerPod("new", false, time.Minute)},
+			selection: RouterPodSelectionNewest,
+			want:      "new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithIndex(&v1.Pod{}, nodeNameIndex, func(o client.Object) []string {
+				return []string{o.(*v1.Pod).Spec.NodeName}
+			})
+			for _, p := range tt.pods {
+				builder = builder.WithObjects(p)
+			}
+			got, err := routerPodForNode(context.Background(), builder.Build(), "node1", tt.selection)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Name != tt.want {
+				t.Errorf("expected pod %s, got %s", tt.want, got.Name)
+			}
+		})
+	}
+}
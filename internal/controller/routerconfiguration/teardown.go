@@ -0,0 +1,113 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// vniTeardownTracker remembers since when each removed VNI is waiting for
+// its routes to be withdrawn. Its devices are kept until the teardown
+// delay elapsed, and deleted by a later reconciliation, so that waiting
+// does not block the reconciliations in the meantime.
+type vniTeardownTracker struct {
+	delay time.Duration
+	now   func() time.Time
+
+	mu           sync.Mutex
+	removedSince map[int]time.Time
+}
+
+func newVNITeardownTracker(delay time.Duration) *vniTeardownTracker {
+	return &vniTeardownTracker{
+		delay:        delay,
+		now:          time.Now,
+		removedSince: map[int]time.Time{},
+	}
+}
+
+// withdrawing records the given removed VNIs, and returns the ones whose
+// teardown delay did not elapse yet. The VNIs not removed anymore, either
+// deleted or configured again, are forgotten.
+func (t *vniTeardownTracker) withdrawing(vnis []int) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	removedSince := map[int]time.Time{}
+	res := []int{}
+	for _, vni := range vnis {
+		since, ok := t.removedSince[vni]
+		if !ok {
+			since = now
+		}
+		removedSince[vni] = since
+		if now.Sub(since) < t.delay {
+			res = append(res, vni)
+		}
+	}
+	t.removedSince = removedSince
+	return res
+}
+
+// next returns the time left before the devices of the first of the
+// removed VNIs can be deleted, and false if no VNI is waiting for it.
+func (t *vniTeardownTracker) next() (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	var res time.Duration
+	found := false
+	for _, since := range t.removedSince {
+		left := t.delay - now.Sub(since)
+		if left <= 0 {
+			continue
+		}
+		if !found || left < res {
+			res = left
+			found = true
+		}
+	}
+	return res, found
+}
+
+// waitForWithdrawal returns the function called before removing the
+// devices of the vnis not configured anymore. The frr configuration is
+// applied before the host one, so the routes of those vnis are already
+// being withdrawn: their devices are kept until the teardown delay
+// elapsed, giving the peers the time to stop sending traffic towards
+// them instead of having it blackholed.
+func waitForWithdrawal(ctx context.Context, teardowns *vniTeardownTracker) hostnetwork.WithdrawFunc {
+	if teardowns == nil {
+		return nil
+	}
+	return func(vnis []int) []int {
+		withdrawing := teardowns.withdrawing(vnis)
+		if len(withdrawing) > 0 {
+			slog.InfoContext(ctx, "keeping the removed vnis until their routes are withdrawn", "vnis", withdrawing, "delay", teardowns.delay)
+		}
+		return withdrawing
+	}
+}
+
+// requeueForTeardowns makes the given result requeue the reconciliation
+// in time to delete the devices of the removed vnis, once their teardown
+// delay elapsed.
+func (r *PERouterReconciler) requeueForTeardowns(res ctrl.Result) ctrl.Result {
+	if r.vniTeardowns == nil {
+		return res
+	}
+	left, ok := r.vniTeardowns.next()
+	if !ok {
+		return res
+	}
+	if res.RequeueAfter == 0 || left < res.RequeueAfter {
+		res.RequeueAfter = left
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestVNITeardownTracker(t *testing.T) {
+	now := time.Now()
+	r := &PERouterReconciler{vniTeardowns: newVNITeardownTracker(time.Minute)}
+	r.vniTeardowns.now = func() time.Time { return now }
+
+	if got := r.vniTeardowns.withdrawing([]int{100, 200}); !cmp.Equal(got, []int{100, 200}) {
+		t.Fatalf("expected the just removed vnis to be kept, got %v", got)
+	}
+	if got := r.requeueForTeardowns(ctrl.Result{}); got.RequeueAfter != time.Minute {
+		t.Fatalf("expected a requeue after the teardown delay, got %v", got.RequeueAfter)
+	}
+
+	now = now.Add(20 * time.Second)
+	// vni 100 is configured again, and vni 300 is removed.
+	if got := r.vniTeardowns.withdrawing([]int{200, 300}); !cmp.Equal(got, []int{200, 300}) {
+		t.Fatalf("expected the vnis within the teardown delay to be kept, got %v", got)
+	}
+	if got := r.requeueForTeardowns(ctrl.Result{RequeueAfter: time.Hour}); got.RequeueAfter != 40*time.Second {
+		t.Fatalf("expected a requeue when the first teardown delay elapses, got %v", got.RequeueAfter)
+	}
+	if got := r.requeueForTeardowns(ctrl.Result{RequeueAfter: 5 * time.Second}); got.RequeueAfter != 5*time.Second {
+		t.Fatalf("expected the earlier requeue to be preserved, got %v", got.RequeueAfter)
+	}
+
+	now = now.Add(40 * time.Second)
+	if got := r.vniTeardowns.withdrawing([]int{200, 300}); !cmp.Equal(got, []int{300}) {
+		t.Fatalf("expected only the vnis within the teardown delay to be kept, got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := r.vniTeardowns.withdrawing(nil); len(got) != 0 {
+		t.Fatalf("expected no vni to be kept, got %v", got)
+	}
+	if got := r.requeueForTeardowns(ctrl.Result{}); got.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue once all the vnis are removed, got %v", got.RequeueAfter)
+	}
+}
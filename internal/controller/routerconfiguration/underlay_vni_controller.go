@@ -18,20 +18,29 @@ package routerconfiguration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/audit"
 	"github.com/openperouter/openperouter/internal/conversion"
 	"github.com/openperouter/openperouter/internal/frrconfig"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+	"github.com/openperouter/openperouter/internal/logging"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -41,17 +50,78 @@ type PERouterReconciler struct {
 	MyNode             string
 	MyNamespace        string
 	LogLevel           string
+	FRRLogLevel        string
 	Logger             *slog.Logger
 	UnderlayFromMultus bool
 	FRRConfigPath      string
 	FRRReloadSocket    string
 	RouterProvider     RouterProvider
+	// ReconcileWorkers is the number of concurrent reconciles. The
+	// configuration of the node is applied by one reconcile at a time,
+	// serialized by applyMu, and only the reporting of its outcome runs
+	// concurrently: more workers don't make applying it faster.
+	ReconcileWorkers int
+	applyMu          sync.Mutex
+	// DataPathSelfTest enables pinging the host side of the session of
+	// each L3VNI after the configuration is applied, reporting the result
+	// as a condition of the L3VNI.
+	DataPathSelfTest bool
+	// VNIFailureThreshold and VNIFailureHoldDown set when a failure to set
+	// up a VNI is reported: after it happens in VNIFailureThreshold
+	// consecutive reconciliations, or after it lasts for VNIFailureHoldDown.
+	// Until then, the failure is logged as transient and retried.
+	VNIFailureThreshold int
+	VNIFailureHoldDown  time.Duration
+	vniFailures         *vniFailureTracker
+	// AuditSink, when set, receives a record of each configuration
+	// successfully applied to the node, when it differs from the one
+	// applied before.
+	AuditSink audit.Sink
+	// BestEffortVNIs sets up each VNI independently from the others, so
+	// that a failing VNI does not block the setup of the following ones.
+	// The outcome is reported as a condition of each VNI, and the
+	// reconcile fails only if all the VNIs failed.
+	BestEffortVNIs bool
+	// VNITeardownDelay is the time waited for, after applying the frr
+	// configuration withdrawing the routes of the removed VNIs, before
+	// deleting their devices. The reconciliations are not blocked in the
+	// meantime: the devices are deleted by a later one.
+	VNITeardownDelay time.Duration
+	vniTeardowns     *vniTeardownTracker
+	// UnderlayMultusNetwork is the Multus network providing the
+	// underlay, in the form <namespace>/<name> or <name>.
+	UnderlayMultusNetwork string
+	// FRRConfigMode is the mode the frr configuration is written in,
+	// integrated or split. It must match the mode of the reloader.
+	FRRConfigMode string
+	// FRRBGPListenLimit is the maximum number of dynamic neighbors
+	// the router accepts. Zero leaves the FRR default.
+	FRRBGPListenLimit uint32
+	// ResyncPeriod is the interval the configuration of the node is
+	// reconciled at even without any event, so that the changes not
+	// visible to kubernetes (as the underlay session going down) are
+	// acted upon. Zero disables the periodic resync.
+	ResyncPeriod time.Duration
+	// RefuseNodeIndexChange makes the reconciliation fail, instead of
+	// reconfiguring the node, when the index of the node changes after
+	// the configuration was applied, to prevent an accidental renumbering.
+	RefuseNodeIndexChange bool
+	nodeIndexes           nodeIndexTracker
+	configHashes          configHashTracker
+	// DeviceNamePrefix is prepended to the names of the vxlan and bridge
+	// devices created for each vni, to avoid collisions with the existing
+	// devices of the node.
+	DeviceNamePrefix string
 }
 
-type requestKey string
+// defaultReconcileWorkers is the number of concurrent reconciles used
+// when none is provided. Each reconcile applies the whole configuration
+// of the node, so running them serially is the safe default.
+const defaultReconcileWorkers = 1
 
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=l3vnis,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=l3vnis/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=openpe.openperouter.github.io,resources=l3vnis/finalizers,verbs=update
@@ -70,46 +140,31 @@ func (r *PERouterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	logger.Info("start reconcile")
 	defer logger.Info("end reconcile")
 
-	ctx = context.WithValue(ctx, requestKey("request"), req.String())
+	ctx = logging.WithAttrs(ctx, slog.String("request", req.String()))
 
-	var underlays v1alpha1.UnderlayList
-	if err := r.List(ctx, &underlays); err != nil {
-		slog.Error("failed to list underlays", "error", err)
-		return ctrl.Result{}, err
-	}
-
-	var l3vnis v1alpha1.L3VNIList
-	if err := r.List(ctx, &l3vnis); err != nil {
-		slog.Error("failed to list l3vnis", "error", err)
-		return ctrl.Result{}, err
-	}
+	// Each reconcile applies the whole configuration of the node, so the
+	// applies are serialized, from reading the resources to configuring
+	// the node, not to race on the frr configuration and on the devices.
+	// Only the reporting done afterwards runs concurrently.
+	r.applyMu.Lock()
+	unlockApply := sync.OnceFunc(r.applyMu.Unlock)
+	defer unlockApply()
 
-	var l2vnis v1alpha1.L2VNIList
-	if err := r.List(ctx, &l2vnis); err != nil {
-		slog.Error("failed to list l2vnis", "error", err)
-		return ctrl.Result{}, err
-	}
-
-	var l3passthrough v1alpha1.L3PassthroughList
-	if err := r.List(ctx, &l3passthrough); err != nil {
-		slog.Error("failed to list l3passthrough", "error", err)
-		return ctrl.Result{}, err
+	apiConfig, err := readAPIConfig(ctx, r.Client)
+	if err != nil {
+		return retryOnError(ctx, "failed to list the resources", err)
 	}
 
 	nodeIndex, err := r.RouterProvider.NodeIndex(ctx)
 	if err != nil {
-		slog.Error("failed to get node index", "error", err)
-		return ctrl.Result{}, err
+		return retryOnError(ctx, "failed to get node index", err)
+	}
+	logger.Debug("using config", "l3vnis", apiConfig.L3VNIs, "l2vnis", apiConfig.L2VNIs, "underlays", apiConfig.Underlays, "l3passthrough", apiConfig.L3Passthrough)
+	if err := r.checkNodeIndex(ctx, apiConfig.Underlays, nodeIndex); err != nil {
+		return retryOnError(ctx, "node index changed", err)
 	}
-	logger.Debug("using config", "l3vnis", l3vnis.Items, "l2vnis", l2vnis.Items, "underlays", underlays.Items, "l3passthrough", l3passthrough.Items)
-	apiConfig := conversion.ApiConfigData{
-		NodeIndex:          nodeIndex,
-		UnderlayFromMultus: r.UnderlayFromMultus,
-		Underlays:          underlays.Items,
-		LogLevel:           r.LogLevel,
-		L3VNIs:             l3vnis.Items,
-		L2VNIs:             l2vnis.Items,
-		L3Passthrough:      l3passthrough.Items,
+	if err := r.completeAPIConfig(ctx, &apiConfig, nodeIndex); err != nil {
+		return retryOnError(ctx, "failed to complete the configuration of the node", err)
 	}
 
 	router, err := r.RouterProvider.New(ctx)
@@ -129,22 +184,141 @@ func (r *PERouterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		logger.Info("router is not ready for reconciliation, requeueing")
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
+	apiConfig.UnderlayMultusInterface, err = underlayMultusInterface(router, r.UnderlayMultusNetwork)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the underlay multus interface: %w", err)
+	}
 
-	updater := frrconfig.UpdaterForSocket(r.FRRReloadSocket, r.FRRConfigPath)
+	updater := frrconfig.UpdaterForMode(r.FRRConfigMode, r.FRRReloadSocket, r.FRRConfigPath)
 
-	err = Reconcile(ctx, apiConfig, r.FRRConfigPath, targetNS, updater)
+	auditRecord, err := Reconcile(ctx, apiConfig, r.FRRConfigPath, targetNS, updater, r.BestEffortVNIs, r.vniTeardowns)
+	unlockApply()
+	var vniFailures VNIFailuresError
+	hasVNIFailures := errors.As(err, &vniFailures)
+	if r.BestEffortVNIs && r.MyNode != "" && (err == nil || hasVNIFailures) {
+		if err := r.reportVNIStatus(ctx, apiConfig, err); err != nil {
+			slog.ErrorContext(ctx, "failed to report the status of the vnis", "error", err)
+		}
+	}
+	if r.MyNode != "" && (err == nil || hasVNIFailures) {
+		r.reportMissingVNIs(ctx, apiConfig, err)
+		r.reportMaintenance(ctx, apiConfig.Underlays, apiConfig.Maintenance)
+	}
+	partialFailure := hasVNIFailures && !vniFailures.AllFailed()
+	if partialFailure {
+		reconcileErrors.logError(ctx, "failed to setup some of the vnis, retrying", err)
+		err = nil
+	}
 	if nonRecoverableHostError(err) {
-		if err := router.HandleNonRecoverableError(ctx); err != nil {
-			slog.Error("failed to handle non recoverable error", "error", err)
+		if err := router.HandleNonRecoverableError(ctx, err); err != nil {
+			slog.ErrorContext(ctx, "failed to handle non recoverable error", "error", err)
 			return ctrl.Result{}, err
 		}
 	}
+	if errors.As(err, &VNISetupError{}) {
+		return r.handleVNIFailure(ctx, err)
+	}
 	if err != nil {
-		slog.Error("failed to configure the host", "error", err)
-		return ctrl.Result{}, err
+		return retryOnError(ctx, "failed to configure the host", err)
+	}
+	if r.vniFailures != nil {
+		r.vniFailures.reset()
+	}
+	// the reconciliations applying the same configuration again are not
+	// audited, not to flood the sink, the events in particular.
+	if r.configApplied(auditRecord.Hash) {
+		r.emitAudit(ctx, auditRecord)
+	}
+	r.nodeIndexApplied(ctx, apiConfig.Underlays, nodeIndex)
+
+	// The sessions are shut down on purpose during the maintenance.
+	neighborsIncomplete := false
+	if r.MyNode != "" && !apiConfig.Maintenance {
+		neighborsIncomplete = r.reportIncompleteNeighbors(ctx, apiConfig.Underlays)
+	}
+
+	if r.DataPathSelfTest {
+		healthy, err := r.checkDataPath(ctx, apiConfig.L3VNIs, nodeIndex, targetNS)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to run the data path self test", "error", err)
+			return ctrl.Result{}, err
+		}
+		if !healthy {
+			return r.requeueForTeardowns(ctrl.Result{RequeueAfter: dataPathRecheckInterval}), nil
+		}
 	}
 
-	return ctrl.Result{}, nil
+	if partialFailure {
+		return r.requeueForTeardowns(ctrl.Result{RequeueAfter: transientFailureRetryInterval}), nil
+	}
+	if neighborsIncomplete {
+		return r.requeueForTeardowns(ctrl.Result{RequeueAfter: neighborsRecheckInterval}), nil
+	}
+	return r.requeueForTeardowns(ctrl.Result{}), nil
+}
+
+// readAPIConfig lists the resources the configuration of the node is built from.
+func readAPIConfig(ctx context.Context, cli client.Reader) (conversion.ApiConfigData, error) {
+	var underlays v1alpha1.UnderlayList
+	if err := cli.List(ctx, &underlays); err != nil {
+		return conversion.ApiConfigData{}, fmt.Errorf("failed to list underlays: %w", err)
+	}
+
+	var l3vnis v1alpha1.L3VNIList
+	if err := cli.List(ctx, &l3vnis); err != nil {
+		return conversion.ApiConfigData{}, fmt.Errorf("failed to list l3vnis: %w", err)
+	}
+
+	var l2vnis v1alpha1.L2VNIList
+	if err := cli.List(ctx, &l2vnis); err != nil {
+		return conversion.ApiConfigData{}, fmt.Errorf("failed to list l2vnis: %w", err)
+	}
+
+	var l3passthrough v1alpha1.L3PassthroughList
+	if err := cli.List(ctx, &l3passthrough); err != nil {
+		return conversion.ApiConfigData{}, fmt.Errorf("failed to list l3passthrough: %w", err)
+	}
+
+	return conversion.ApiConfigData{
+		Underlays:     underlays.Items,
+		L3VNIs:        l3vnis.Items,
+		L2VNIs:        l2vnis.Items,
+		L3Passthrough: l3passthrough.Items,
+	}, nil
+}
+
+// completeAPIConfig completes the given resources with the parameters of
+// this node, so that they describe the configuration applied to it.
+func (r *PERouterReconciler) completeAPIConfig(ctx context.Context, apiConfig *conversion.ApiConfigData, nodeIndex int) error {
+	apiConfig.NodeIndex = nodeIndex
+	apiConfig.UnderlayFromMultus = r.UnderlayFromMultus
+	apiConfig.LogLevel = r.LogLevel
+	apiConfig.FRRLogLevel = r.FRRLogLevel
+	apiConfig.BGPListenLimit = r.FRRBGPListenLimit
+	apiConfig.DeviceNamePrefix = r.DeviceNamePrefix
+	var err error
+	apiConfig.Maintenance, err = r.nodeInMaintenance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check the maintenance of the node: %w", err)
+	}
+	apiConfig.CentralizedGatewayIndex, err = r.centralizedGatewayIndex(ctx, apiConfig.L2VNIs)
+	if err != nil {
+		return fmt.Errorf("failed to elect the centralized gateway node: %w", err)
+	}
+	return nil
+}
+
+// emitAudit sends the given record to the audit sink, if any. Failing to
+// emit the record does not fail the reconciliation, as the configuration
+// is already applied.
+func (r *PERouterReconciler) emitAudit(ctx context.Context, record audit.Record) {
+	if r.AuditSink == nil {
+		return
+	}
+	record.Node = r.MyNode
+	if err := r.AuditSink.Emit(ctx, record); err != nil {
+		slog.ErrorContext(ctx, "failed to emit the audit record", "error", err)
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -155,6 +329,10 @@ func (r *PERouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if o.Spec.NodeName != r.MyNode {
 				return false
 			}
+			// the pods attached to the overlay get the policy routing of the l2 vnis
+			if o.Annotations[multusNetworksAnnotation] != "" {
+				return true
+			}
 			if o.Namespace != r.MyNamespace {
 				return false
 			}
@@ -163,6 +341,8 @@ func (r *PERouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return true
 			}
 			return false
+		case *v1.Node: // the other nodes may carry the centralized gateway
+			return true
 		default:
 			return true
 		}
@@ -172,8 +352,18 @@ func (r *PERouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	filterUpdates := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			switch o := e.ObjectNew.(type) {
-			case *v1.Node:
-				return false
+			case *v1.Node: // handle only the maintenance and the gateway changes
+				old := e.ObjectOld.(*v1.Node)
+				if o.Name == r.MyNode && isMaintenance(old) != isMaintenance(o) {
+					return true
+				}
+				return gatewayEligibilityChanged(old, o)
+			case *v1alpha1.L3VNI: // ignore the status updates
+				return e.ObjectOld.GetGeneration() != o.GetGeneration()
+			case *v1alpha1.L2VNI: // ignore the status updates
+				return e.ObjectOld.GetGeneration() != o.GetGeneration()
+			case *v1alpha1.Underlay: // ignore the status updates
+				return e.ObjectOld.GetGeneration() != o.GetGeneration()
 			case *v1.Pod: // handle only status updates
 				old := e.ObjectOld.(*v1.Pod)
 				if PodIsReady(old) != PodIsReady(o) {
@@ -188,16 +378,48 @@ func (r *PERouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := setPodNodeNameIndex(mgr); err != nil {
 		return err
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := hostnetwork.ValidateDeviceNamePrefix(r.DeviceNamePrefix); err != nil {
+		return err
+	}
+	r.vniFailures = newVNIFailureTracker(r.VNIFailureThreshold, r.VNIFailureHoldDown)
+	r.vniTeardowns = newVNITeardownTracker(r.VNITeardownDelay)
+	if r.FRRConfigPath != "" {
+		r.nodeIndexes.path = filepath.Join(filepath.Dir(r.FRRConfigPath), nodeIndexFile)
+	}
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Underlay{}).
 		Watches(&v1.Pod{}, &handler.EnqueueRequestForObject{}).
+		Watches(&v1.Node{}, &handler.EnqueueRequestForObject{}).
 		Watches(&v1alpha1.L3VNI{}, &handler.EnqueueRequestForObject{}).
 		Watches(&v1alpha1.L2VNI{}, &handler.EnqueueRequestForObject{}).
 		Watches(&v1alpha1.L3Passthrough{}, &handler.EnqueueRequestForObject{}).
 		WithEventFilter(filterNonRouterPods).
 		WithEventFilter(filterUpdates).
 		Named("routercontroller").
-		Complete(r)
+		WithOptions(r.controllerOptions())
+
+	if r.ResyncPeriod > 0 {
+		resyncEvents := make(chan event.GenericEvent, 1)
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			resync(ctx, r.ResyncPeriod, resyncEvents)
+			return nil
+		})); err != nil {
+			return fmt.Errorf("failed to add the periodic resync: %w", err)
+		}
+		builder = builder.WatchesRawSource(source.Channel(resyncEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return builder.Complete(r)
+}
+
+// controllerOptions returns the options the controller is built with.
+func (r *PERouterReconciler) controllerOptions() controller.Options {
+	workers := r.ReconcileWorkers
+	if workers <= 0 {
+		workers = defaultReconcileWorkers
+	}
+	return controller.Options{
+		MaxConcurrentReconciles: workers,
+	}
 }
 
 func setPodNodeNameIndex(mgr ctrl.Manager) error {
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import "testing"
+
+func TestControllerOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		workers     int
+		wantWorkers int
+	}{
+		{
+			name:        "not set",
+			workers:     0,
+			wantWorkers: defaultReconcileWorkers,
+		},
+		{
+			name:        "negative",
+			workers:     -1,
+			wantWorkers: defaultReconcileWorkers,
+		},
+		{
+			name:        "explicit",
+			workers:     4,
+			wantWorkers: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &PERouterReconciler{ReconcileWorkers: tt.workers}
+			got := r.controllerOptions()
+			if got.MaxConcurrentReconciles != tt.wantWorkers {
+				t.Errorf("controllerOptions() MaxConcurrentReconciles = %d, want %d", got.MaxConcurrentReconciles, tt.wantWorkers)
+			}
+		})
+	}
+}
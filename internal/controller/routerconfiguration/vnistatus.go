@@ -0,0 +1,78 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+const (
+	// ConfiguredCondition is the type of the condition, prefixed by the node
+	// name, reporting if a VNI was set up on that node. It is reported only
+	// when the VNIs are configured in best effort mode.
+	ConfiguredCondition = "Configured"
+
+	reasonConfigured  = "Configured"
+	reasonSetupFailed = "SetupFailed"
+)
+
+// reportVNIStatus records, as a condition of each VNI, whether setting it up
+// succeeded, given the error returned by the configuration of the host.
+func (r *PERouterReconciler) reportVNIStatus(ctx context.Context, apiConfig conversion.ApiConfigData, hostErr error) error {
+	failed := map[string]error{}
+	var vniFailures VNIFailuresError
+	if errors.As(hostErr, &vniFailures) {
+		for _, f := range vniFailures.Failures {
+			failed[f.Kind+"/"+f.Namespace+"/"+f.Name] = f.Err
+		}
+	}
+	conditionFor := func(key string) metav1.Condition {
+		condition := metav1.Condition{
+			Type:    configuredConditionType(r.MyNode),
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonConfigured,
+			Message: "vni set up",
+		}
+		if err, ok := failed[key]; ok {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = reasonSetupFailed
+			condition.Message = err.Error()
+		}
+		return condition
+	}
+
+	errs := []error{}
+	for _, vni := range apiConfig.L3VNIs {
+		condition := conditionFor("L3VNI/" + vni.Namespace + "/" + vni.Name)
+		if err := r.setL3VNICondition(ctx, client.ObjectKeyFromObject(&vni), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, vni := range apiConfig.L2VNIs {
+		condition := conditionFor("L2VNI/" + vni.Namespace + "/" + vni.Name)
+		if err := r.setL2VNICondition(ctx, client.ObjectKeyFromObject(&vni), condition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *PERouterReconciler) setL2VNICondition(ctx context.Context, key client.ObjectKey, condition metav1.Condition) error {
+	vni := &v1alpha1.L2VNI{}
+	if err := r.setNodeCondition(ctx, key, vni, &vni.Status.Conditions, condition); err != nil {
+		return fmt.Errorf("failed to update the status of l2vni %s: %w", key, err)
+	}
+	return nil
+}
+
+func configuredConditionType(node string) string {
+	return fmt.Sprintf("%s/%s", node, ConfiguredCondition)
+}
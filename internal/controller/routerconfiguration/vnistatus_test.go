@@ -0,0 +1,80 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package routerconfiguration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+func TestReportVNIStatus(t *testing.T) {
+	l2vnis := []v1alpha1.L2VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 200},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "failing", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 201},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "third", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 202},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	objects := []client.Object{}
+	for i := range l2vnis {
+		objects = append(objects, l2vnis[i].DeepCopy())
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&v1alpha1.L2VNI{}).Build()
+
+	hostErr := fmt.Errorf("failed to configure the host: %w", VNIFailuresError{
+		Failures: []VNISetupError{
+			{Kind: "L2VNI", Namespace: "openperouter-system", Name: "failing", Err: errors.New("device or resource busy")},
+		},
+		Attempted: 3,
+	})
+
+	r := &PERouterReconciler{Client: cli, MyNode: "node1"}
+	if err := r.reportVNIStatus(context.Background(), conversion.ApiConfigData{L2VNIs: l2vnis}, hostErr); err != nil {
+		t.Fatalf("reportVNIStatus() unexpected error: %v", err)
+	}
+
+	wantStatus := map[string]metav1.ConditionStatus{
+		"first":   metav1.ConditionTrue,
+		"failing": metav1.ConditionFalse,
+		"third":   metav1.ConditionTrue,
+	}
+	for name, want := range wantStatus {
+		vni := &v1alpha1.L2VNI{}
+		if err := cli.Get(context.Background(), client.ObjectKey{Namespace: "openperouter-system", Name: name}, vni); err != nil {
+			t.Fatalf("failed to get l2vni %s: %v", name, err)
+		}
+		condition := meta.FindStatusCondition(vni.Status.Conditions, "node1/"+ConfiguredCondition)
+		if condition == nil {
+			t.Fatalf("condition not found on l2vni %s: %+v", name, vni.Status.Conditions)
+		}
+		if condition.Status != want {
+			t.Errorf("expected condition status %s on l2vni %s, got %s (%s)", want, name, condition.Status, condition.Message)
+		}
+		if want == metav1.ConditionFalse && condition.Reason != reasonSetupFailed {
+			t.Errorf("expected reason %s on l2vni %s, got %s", reasonSetupFailed, name, condition.Reason)
+		}
+	}
+}
@@ -15,6 +15,22 @@ type ApiConfigData struct {
 	L2VNIs             []v1alpha1.L2VNI
 	L3Passthrough      []v1alpha1.L3Passthrough
 	LogLevel           string
+	FRRLogLevel        string
+	// UnderlayMultusInterface is the interface of the router the
+	// Multus network providing the underlay is attached with.
+	UnderlayMultusInterface string
+	// BGPListenLimit is the maximum number of dynamic neighbors
+	// the router accepts. Zero leaves the FRR default.
+	BGPListenLimit uint32
+	// Maintenance shuts down all the bgp sessions of the node,
+	// leaving the rest of the configuration in place.
+	Maintenance bool
+	// CentralizedGatewayIndex is the index of the node assigning the
+	// gateway of the L2VNIs in centralized gateway mode.
+	CentralizedGatewayIndex int
+	// DeviceNamePrefix is prepended to the names of the vxlan and
+	// bridge devices created for each vni.
+	DeviceNamePrefix string
 }
 
 type HostConfigData struct {
@@ -22,4 +38,7 @@ type HostConfigData struct {
 	L3VNIs        []hostnetwork.L3VNIParams
 	L2VNIs        []hostnetwork.L2VNIParams
 	L3Passthrough *hostnetwork.PassthroughParams
+	// ConntrackBypassCIDRs are the overlay subnets whose traffic is
+	// not tracked in the router namespace.
+	ConntrackBypassCIDRs []string
 }
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	"github.com/openperouter/openperouter/internal/frr"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
 	"github.com/openperouter/openperouter/internal/ipam"
 	"github.com/openperouter/openperouter/internal/ipfamily"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,13 +41,27 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 
 	underlay := config.Underlays[0]
 
+	peerGroups, err := peerGroupsToFRR(underlay.Spec.PeerGroups)
+	if err != nil {
+		return frr.Config{}, err
+	}
+
 	underlayNeighbors := []frr.NeighborConfig{}
 	bfdProfiles := []frr.BFDProfile{}
 	for _, n := range underlay.Spec.Neighbors {
+		n, peerGroup, err := withPeerGroup(n, underlay.Spec.PeerGroups)
+		if err != nil {
+			return frr.Config{}, err
+		}
 		frrNeigh, err := neighborToFRR(n)
 		if err != nil {
 			return frr.Config{}, fmt.Errorf("failed to translate underlay neighbor %s to frr, err: %w", neighborName(n), err)
 		}
+		if peerGroup != nil {
+			frrNeigh.PeerGroup = peerGroup.Name
+			frrNeigh.ASNFromPeerGroup = peerGroup.ASN != nil
+		}
+		frrNeigh.Shutdown = config.Maintenance
 
 		bfdProfile := bfdProfileForNeighbor(n)
 		underlayNeighbors = append(underlayNeighbors, *frrNeigh)
@@ -59,9 +76,17 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 	}
 
 	underlayConfig := frr.UnderlayConfig{
-		MyASN:     underlay.Spec.ASN,
-		RouterID:  routerID,
-		Neighbors: underlayNeighbors,
+		MyASN:      underlay.Spec.ASN,
+		RouterID:   routerID,
+		BestPath:   bestPathToFRR(underlay.Spec.BestPath),
+		PeerGroups: peerGroups,
+		Neighbors:  underlayNeighbors,
+	}
+	if underlay.Spec.UpdateDelay != nil {
+		underlayConfig.UpdateDelay = uint32(underlay.Spec.UpdateDelay.Duration / time.Second)
+	}
+	if underlay.Spec.CoalesceTime != nil {
+		underlayConfig.CoalesceTime = uint32(underlay.Spec.CoalesceTime.Duration / time.Millisecond)
 	}
 
 	var passthroughConfig *frr.PassthroughConfig
@@ -70,6 +95,12 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 		if err != nil {
 			return frr.Config{}, fmt.Errorf("failed to translate passthrough to frr: %w", err)
 		}
+		if passthrough.LocalNeighborV4 != nil {
+			passthrough.LocalNeighborV4.Shutdown = config.Maintenance
+		}
+		if passthrough.LocalNeighborV6 != nil {
+			passthrough.LocalNeighborV6.Shutdown = config.Maintenance
+		}
 		passthroughConfig = passthrough
 	}
 
@@ -81,8 +112,10 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 			Underlay:    underlayConfig,
 			Passthrough: passthroughConfig,
 			BFDProfiles: bfdProfiles,
-			Loglevel:    config.LogLevel,
+			Loglevel:    frrLogLevel(config),
 			VNIs:        []frr.L3VNIConfig{},
+
+			BGPListenLimit: config.BGPListenLimit,
 		}, nil
 	}
 
@@ -90,8 +123,31 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 	if err != nil {
 		return frr.Config{}, fmt.Errorf("failed to get vtep ip, cidr %s, nodeIntex %d", underlay.Spec.EVPN.VTEPCIDR, config.NodeIndex)
 	}
+	vtepIPv6, err := vtepIPv6(underlay.Spec.EVPN, config.NodeIndex)
+	if err != nil {
+		return frr.Config{}, err
+	}
 	underlayConfig.EVPN = &frr.UnderlayEvpn{
-		VTEP: vtepIP.String(),
+		VTEP:   vtepIP.String(),
+		VTEPv6: vtepIPv6,
+	}
+	rtASN := ptr.Deref(underlay.Spec.EVPN.RTAutoDeriveASN, 0)
+	underlayConfig.EVPN.RouteTargetASN = rtASN
+	if !ptr.Deref(underlay.Spec.EVPN.AdvertiseAllVNI, true) {
+		underlayConfig.EVPN.RestrictAdvertisedVNIs = true
+		underlayConfig.EVPN.AdvertisedVNIs = underlay.Spec.EVPN.AdvertisedVNIs
+	}
+	if dad := underlay.Spec.EVPN.DuplicateAddressDetection; dad != nil {
+		underlayConfig.EVPN.DuplicateAddressDetection = &frr.DADConfig{
+			MaxMoves: dad.MaxMoves,
+			Time:     uint32(dad.DetectionTime.Duration / time.Second),
+		}
+	}
+	// the l2vnis are listed to set their route targets.
+	if rtASN != 0 {
+		for _, l2vni := range config.L2VNIs {
+			underlayConfig.EVPN.VNIs = append(underlayConfig.EVPN.VNIs, l2vni.Spec.VNI)
+		}
 	}
 
 	vniConfigs := []frr.L3VNIConfig{}
@@ -100,18 +156,68 @@ func APItoFRR(config ApiConfigData) (frr.Config, error) {
 		if err != nil {
 			return frr.Config{}, fmt.Errorf("failed to translate vni to frr: %w, vni %v", err, vni)
 		}
+		for i, v := range frrVNI {
+			if v.LocalNeighbor != nil {
+				v.LocalNeighbor.Shutdown = config.Maintenance
+			}
+			frrVNI[i].RouteTargetASN = rtASN
+		}
 		vniConfigs = append(vniConfigs, frrVNI...)
 	}
 
+	var l2Gateways []frr.L2GatewayConfig
+	var ethernetSegments []frr.EthernetSegmentConfig
+	for _, l2vni := range config.L2VNIs {
+		if es := ethernetSegmentToFRR(l2vni); es != nil {
+			ethernetSegments = append(ethernetSegments, *es)
+		}
+		if !hasIPv6Gateway(l2vni) {
+			continue
+		}
+		l2Gateways = append(l2Gateways, frr.L2GatewayConfig{
+			Interface:  hostnetwork.BridgeName(config.DeviceNamePrefix, int(l2vni.Spec.VNI)),
+			SuppressRA: ptr.Deref(l2vni.Spec.SuppressRA, true),
+		})
+	}
+
 	return frr.Config{
 		Underlay:    underlayConfig,
 		VNIs:        vniConfigs,
+		L2Gateways:  l2Gateways,
 		Passthrough: passthroughConfig,
 		BFDProfiles: bfdProfiles,
-		Loglevel:    config.LogLevel,
+		Loglevel:    frrLogLevel(config),
+
+		EthernetSegments: ethernetSegments,
+		BGPListenLimit:   config.BGPListenLimit,
 	}, nil
 }
 
+// ethernetSegmentToFRR returns the ethernet segment configuration
+// of the veth of the given L2VNI, if any.
+func ethernetSegmentToFRR(l2vni v1alpha1.L2VNI) *frr.EthernetSegmentConfig {
+	es := l2vni.Spec.EthernetSegment
+	if es == nil || (es.ID == nil && es.ESI == nil) {
+		return nil
+	}
+	return &frr.EthernetSegmentConfig{
+		Interface:    hostnetwork.PEVethName(int(l2vni.Spec.VNI)),
+		ID:           ptr.Deref(es.ID, 0),
+		SysMAC:       ptr.Deref(es.SysMAC, ""),
+		ESI:          ptr.Deref(es.ESI, ""),
+		DFPreference: ptr.Deref(es.DFPreference, 0),
+	}
+}
+
+// frrLogLevel returns the FRR specific log level if set,
+// falling back to the log level of the controller.
+func frrLogLevel(config ApiConfigData) string {
+	if config.FRRLogLevel != "" {
+		return config.FRRLogLevel
+	}
+	return config.LogLevel
+}
+
 func passthroughToFRR(passthrough v1alpha1.L3Passthrough, nodeIndex int) (*frr.PassthroughConfig, error) {
 	vethIPs, err := ipam.VethIPsFromPool(passthrough.Spec.HostSession.LocalCIDR.IPv4, passthrough.Spec.HostSession.LocalCIDR.IPv6, nodeIndex)
 	if err != nil {
@@ -125,9 +231,15 @@ func passthroughToFRR(passthrough v1alpha1.L3Passthrough, nodeIndex int) (*frr.P
 
 	if vethIPs.Ipv4.HostSide.IP != nil {
 		res.LocalNeighborV4 = &frr.NeighborConfig{
-			ASN:  passthrough.Spec.HostSession.HostASN,
-			Addr: vethIPs.Ipv4.HostSide.IP.String(),
+			ASN:              passthrough.Spec.HostSession.HostASN,
+			Addr:             vethIPs.Ipv4.HostSide.IP.String(),
+			NextHopSelf:      nextHopSelfForHostSession(passthrough.Spec.HostSession),
+			StripCommunities: passthrough.Spec.HostSession.StripCommunitiesOnImport,
+			SendCommunity:    ptr.Deref(passthrough.Spec.HostSession.SendCommunity, ""),
+			Description:      sanitizeDescription(passthrough.Spec.HostSession.Description),
+			AllowASIn:        ptr.Deref(passthrough.Spec.HostSession.AllowASIn, 0),
 		}
+		setDynamicPeers(res.LocalNeighborV4, passthrough.Spec.HostSession, ipfamily.IPv4)
 		ipnet := net.IPNet{
 			IP:   vethIPs.Ipv4.HostSide.IP,
 			Mask: net.CIDRMask(32, 32),
@@ -137,9 +249,16 @@ func passthroughToFRR(passthrough v1alpha1.L3Passthrough, nodeIndex int) (*frr.P
 	}
 	if vethIPs.Ipv6.HostSide.IP != nil {
 		res.LocalNeighborV6 = &frr.NeighborConfig{
-			ASN:  passthrough.Spec.HostSession.HostASN,
-			Addr: vethIPs.Ipv6.HostSide.IP.String(),
+			ASN:              passthrough.Spec.HostSession.HostASN,
+			Addr:             vethIPs.Ipv6.HostSide.IP.String(),
+			NextHopSelf:      nextHopSelfForHostSession(passthrough.Spec.HostSession),
+			StripCommunities: passthrough.Spec.HostSession.StripCommunitiesOnImport,
+			SendCommunity:    ptr.Deref(passthrough.Spec.HostSession.SendCommunity, ""),
+			Description:      sanitizeDescription(passthrough.Spec.HostSession.Description),
+			AllowASIn:        ptr.Deref(passthrough.Spec.HostSession.AllowASIn, 0),
+			ExtendedNextHop:  ipv6OnlyHostSession(passthrough.Spec.HostSession),
 		}
+		setDynamicPeers(res.LocalNeighborV6, passthrough.Spec.HostSession, ipfamily.IPv6)
 
 		ipnet := net.IPNet{
 			IP:   vethIPs.Ipv6.HostSide.IP,
@@ -148,17 +267,48 @@ func passthroughToFRR(passthrough v1alpha1.L3Passthrough, nodeIndex int) (*frr.P
 		res.ToAdvertiseIPv6 = append(res.ToAdvertiseIPv6, ipnet.String())
 	}
 
+	hostAdvertise, err := hostAdvertiseToFRR(passthrough.Spec.HostSession, "passthrough-host-advertise")
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefixes to advertise to the host for passthrough %s: %w", passthrough.Name, err)
+	}
+	connectTime, err := connectTimeToFRR(passthrough.Spec.HostSession.ConnectTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host session for passthrough %s: %w", passthrough.Name, err)
+	}
+	for _, n := range []*frr.NeighborConfig{res.LocalNeighborV4, res.LocalNeighborV6} {
+		if n != nil {
+			n.HostAdvertise = hostAdvertise
+			n.ConnectTime = connectTime
+		}
+	}
+
 	return res, nil
 }
 
 func l3vniToFRR(vni v1alpha1.L3VNI, routerID string, underlayASN uint32, nodeIndex int) ([]frr.L3VNIConfig, error) {
+	leakIPv4, leakIPv6, err := splitByFamily(vni.Spec.LeakToDefault)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefixes to leak for vni %s: %w", vni.Name, err)
+	}
+	exportRouteMap, err := exportRouteMapToFRR(vni)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evpn export route-map for vni %s: %w", vni.Name, err)
+	}
+
 	if vni.Spec.HostSession == nil { // no neighbor, just the vni / vrf
 		return []frr.L3VNIConfig{
 			{
-				VNI:      int(vni.Spec.VNI),
-				VRF:      vni.Spec.VRF,
-				ASN:      underlayASN, // Since there is no session, the ASN is arbitrary
-				RouterID: routerID,
+				VNI:               int(vni.Spec.VNI),
+				VRF:               vni.Spec.VRF,
+				ASN:               underlayASN, // Since there is no session, the ASN is arbitrary
+				RouterID:          routerID,
+				ImportVRFs:        vni.Spec.ImportVRFs,
+				LeakToDefaultIPv4: leakIPv4,
+				LeakToDefaultIPv6: leakIPv6,
+				NoAdvertiseIPv4:   !ptr.Deref(vni.Spec.AdvertiseIPv4, true),
+				NoAdvertiseIPv6:   !ptr.Deref(vni.Spec.AdvertiseIPv6, true),
+				NoFabricAdvertise: !ptr.Deref(vni.Spec.FabricAdvertise, true),
+				ExportRouteMap:    exportRouteMap,
 			},
 		}, nil
 	}
@@ -168,51 +318,114 @@ func l3vniToFRR(vni v1alpha1.L3VNI, routerID string, underlayASN uint32, nodeInd
 		return nil, fmt.Errorf("failed to get veths ips for vni %s: %w", vni.Name, err)
 	}
 
+	sviRoute, sviFamily, err := routerSVIHostRoute(vni.Spec.RouterSVIAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid router svi address for vni %s: %w", vni.Name, err)
+	}
+
 	var configs []frr.L3VNIConfig
 
 	// Create IPv4 neighbor if IPv4 IP is available
 	if veths.Ipv4.HostSide.IP != nil {
 		config := createVNIConfig(vni, veths.Ipv4.HostSide.IP, net.CIDRMask(32, 32), routerID)
+		if sviFamily == ipfamily.IPv4 {
+			config.ToAdvertiseIPv4 = append(config.ToAdvertiseIPv4, sviRoute)
+		}
 		configs = append(configs, config)
 	}
 
 	// Create IPv6 neighbor if IPv6 IP is available
 	if veths.Ipv6.HostSide.IP != nil {
 		config := createVNIConfig(vni, veths.Ipv6.HostSide.IP, net.CIDRMask(128, 128), routerID)
+		switch {
+		case sviFamily == ipfamily.IPv6:
+			config.ToAdvertiseIPv6 = append(config.ToAdvertiseIPv6, sviRoute)
+		case sviFamily == ipfamily.IPv4 && config.LocalNeighbor.ExtendedNextHop:
+			config.ToAdvertiseIPv4 = append(config.ToAdvertiseIPv4, sviRoute)
+		}
 		configs = append(configs, config)
 	}
 
 	if len(configs) == 0 {
 		return nil, fmt.Errorf("no valid host side IP found for vni %s", vni.Name)
 	}
+	hostAdvertise, err := hostAdvertiseToFRR(*vni.Spec.HostSession, vni.Spec.VRF+"-host-advertise")
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefixes to advertise to the host for vni %s: %w", vni.Name, err)
+	}
+	connectTime, err := connectTimeToFRR(vni.Spec.HostSession.ConnectTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host session for vni %s: %w", vni.Name, err)
+	}
+	for i := range configs {
+		configs[i].LocalNeighbor.HostAdvertise = hostAdvertise
+		configs[i].LocalNeighbor.ConnectTime = connectTime
+		configs[i].ExportRouteMap = exportRouteMap
+	}
+
+	// The imports and the leaks apply to the whole vrf, so they are set only once.
+	configs[0].ImportVRFs = vni.Spec.ImportVRFs
+	configs[0].LeakToDefaultIPv4 = leakIPv4
+	configs[0].LeakToDefaultIPv6 = leakIPv6
 
 	return configs, nil
 }
 
+// routerSVIHostRoute returns the host route of the given router svi
+// address, advertised to the host, together with its family. An empty
+// route is returned if the address is not set.
+func routerSVIHostRoute(address *string) (string, ipfamily.Family, error) {
+	if address == nil {
+		return "", "", nil
+	}
+	ip, _, err := net.ParseCIDR(*address)
+	if err != nil {
+		return "", "", err
+	}
+	family := ipfamily.ForAddress(ip)
+	bits := 128
+	if family == ipfamily.IPv4 {
+		bits = 32
+	}
+	route := net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	return route.String(), family, nil
+}
+
 // createVNIConfig creates a VNI configuration for a specific IP family
 func createVNIConfig(vni v1alpha1.L3VNI, hostIP net.IP, mask net.IPMask, routerID string) frr.L3VNIConfig {
 	vniNeighbor := &frr.NeighborConfig{
-		Addr: hostIP.String(),
+		Addr:             hostIP.String(),
+		NextHopSelf:      nextHopSelfForHostSession(*vni.Spec.HostSession),
+		StripCommunities: vni.Spec.HostSession.StripCommunitiesOnImport,
+		SendCommunity:    ptr.Deref(vni.Spec.HostSession.SendCommunity, ""),
+		Description:      sanitizeDescription(vni.Spec.HostSession.Description),
+		AllowASIn:        ptr.Deref(vni.Spec.HostSession.AllowASIn, 0),
+		ExtendedNextHop:  ipv6OnlyHostSession(*vni.Spec.HostSession),
 	}
 	vniNeighbor.ASN = vni.Spec.HostSession.ASN
 	if vni.Spec.HostSession.HostASN != 0 {
 		vniNeighbor.ASN = vni.Spec.HostSession.HostASN
 	}
 
+	ipFamily := ipfamily.ForAddress(hostIP)
+	setDynamicPeers(vniNeighbor, *vni.Spec.HostSession, ipFamily)
+
 	ipnet := net.IPNet{
 		IP:   hostIP,
 		Mask: mask,
 	}
 
 	config := frr.L3VNIConfig{
-		ASN:           vni.Spec.HostSession.ASN,
-		VNI:           int(vni.Spec.VNI),
-		VRF:           vni.Spec.VRF,
-		RouterID:      routerID,
-		LocalNeighbor: vniNeighbor,
+		ASN:               vni.Spec.HostSession.ASN,
+		VNI:               int(vni.Spec.VNI),
+		VRF:               vni.Spec.VRF,
+		RouterID:          routerID,
+		LocalNeighbor:     vniNeighbor,
+		NoAdvertiseIPv4:   !ptr.Deref(vni.Spec.AdvertiseIPv4, true),
+		NoAdvertiseIPv6:   !ptr.Deref(vni.Spec.AdvertiseIPv6, true),
+		NoFabricAdvertise: !ptr.Deref(vni.Spec.FabricAdvertise, true),
 	}
 
-	ipFamily := ipfamily.ForAddress(hostIP)
 	if ipFamily == ipfamily.IPv4 {
 		config.ToAdvertiseIPv4 = []string{ipnet.String()}
 		config.ToAdvertiseIPv6 = []string{}
@@ -226,6 +439,43 @@ func createVNIConfig(vni v1alpha1.L3VNI, hostIP net.IP, mask net.IPMask, routerI
 	return config
 }
 
+// setDynamicPeers turns the host neighbor into a peer group listening
+// on the whole local cidr of the given family, if the session enables
+// dynamic peers.
+func setDynamicPeers(neighbor *frr.NeighborConfig, session v1alpha1.HostSession, family ipfamily.Family) {
+	if !session.DynamicPeers {
+		return
+	}
+	cidr := session.LocalCIDR.IPv4
+	if family == ipfamily.IPv6 {
+		cidr = session.LocalCIDR.IPv6
+	}
+	neighbor.Addr = hostPeerGroupName(family)
+	neighbor.ListenRange = cidr
+}
+
+func hostPeerGroupName(family ipfamily.Family) string {
+	return fmt.Sprintf("hosts-%s", family)
+}
+
+// ipv6OnlyHostSession tells if the session with the host is established
+// over IPv6 only. The IPv4 routes are then exchanged over the IPv6 session,
+// which requires the extended next hop capability.
+func ipv6OnlyHostSession(session v1alpha1.HostSession) bool {
+	return session.LocalCIDR.IPv4 == "" && session.LocalCIDR.IPv6 != ""
+}
+
+// nextHopSelfForHostSession returns the next-hop-self configuration
+// for the host neighbor, or nil if not enabled.
+func nextHopSelfForHostSession(session v1alpha1.HostSession) *frr.NextHopSelf {
+	if !ptr.Deref(session.NextHopSelf, false) {
+		return nil
+	}
+	return &frr.NextHopSelf{
+		Force: ptr.Deref(session.NextHopSelfForce, false),
+	}
+}
+
 func neighborToFRR(n v1alpha1.Neighbor) (*frr.NeighborConfig, error) {
 	neighborFamily, err := ipfamily.ForAddresses(n.Address)
 	if err != nil {
@@ -237,24 +487,25 @@ func neighborToFRR(n v1alpha1.Neighbor) (*frr.NeighborConfig, error) {
 	}
 
 	res := &frr.NeighborConfig{
-		Name:         neighborName(n),
-		ASN:          n.ASN,
-		Addr:         n.Address,
-		Port:         n.Port,
-		IPFamily:     neighborFamily,
-		EBGPMultiHop: n.EBGPMultiHop,
+		Name:            neighborName(n),
+		ASN:             n.ASN,
+		Addr:            n.Address,
+		Port:            n.Port,
+		IPFamily:        neighborFamily,
+		EBGPMultiHop:    n.EBGPMultiHop || n.EBGPMultiHopTTL != nil,
+		EBGPMultiHopTTL: n.EBGPMultiHopTTL,
+		SendCommunity:   ptr.Deref(n.SendCommunity, ""),
+		Description:     sanitizeDescription(n.Description),
+		ExtendedNextHop: ptr.Deref(n.ExtendedNextHop, false),
 	}
 	res.HoldTime, res.KeepaliveTime, err = parseTimers(n.HoldTime, n.KeepaliveTime)
 	if err != nil {
 		return nil, fmt.Errorf("invalid timers for neighbor %s, err: %w", neighborName(n), err)
 	}
 
-	if n.ConnectTime != nil {
-		connectSecond, err := durationToUint64(n.ConnectTime.Duration / time.Second)
-		if err != nil {
-			return nil, fmt.Errorf("invalid connecttime %v: %w", n.ConnectTime.Duration, err)
-		}
-		res.ConnectTime = ptr.To(connectSecond)
+	res.ConnectTime, err = connectTimeToFRR(n.ConnectTime)
+	if err != nil {
+		return nil, err
 	}
 
 	if n.BFD == nil {
@@ -298,6 +549,55 @@ func bfdProfileNameForNeighbor(n v1alpha1.Neighbor) string {
 	return fmt.Sprintf("neighbor-%s", n.Address)
 }
 
+func bestPathToFRR(bestPath *v1alpha1.BestPathConfig) *frr.BestPathConfig {
+	if bestPath == nil {
+		return nil
+	}
+	return &frr.BestPathConfig{
+		ASPathMultipathRelax: bestPath.ASPathMultipathRelax,
+		ASPathIgnore:         bestPath.ASPathIgnore,
+		CompareRouterID:      bestPath.CompareRouterID,
+		MEDMissingAsWorst:    bestPath.MEDMissingAsWorst,
+	}
+}
+
+func peerGroupsToFRR(peerGroups []v1alpha1.PeerGroupSpec) ([]frr.PeerGroupConfig, error) {
+	var res []frr.PeerGroupConfig
+	for _, pg := range peerGroups {
+		frrPeerGroup := frr.PeerGroupConfig{
+			Name: pg.Name,
+		}
+		if pg.ASN != nil {
+			frrPeerGroup.ASN = *pg.ASN
+		}
+		var err error
+		frrPeerGroup.HoldTime, frrPeerGroup.KeepaliveTime, err = parseTimers(pg.HoldTime, pg.KeepaliveTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timers for peer group %s, err: %w", pg.Name, err)
+		}
+		res = append(res, frrPeerGroup)
+	}
+	return res, nil
+}
+
+// withPeerGroup returns the given neighbor with the ASN inherited from its
+// peer group, if it has none, together with the peer group itself.
+func withPeerGroup(n v1alpha1.Neighbor, peerGroups []v1alpha1.PeerGroupSpec) (v1alpha1.Neighbor, *v1alpha1.PeerGroupSpec, error) {
+	if n.PeerGroup == nil {
+		return n, nil, nil
+	}
+	for _, pg := range peerGroups {
+		if pg.Name != *n.PeerGroup {
+			continue
+		}
+		if n.ASN == 0 && pg.ASN != nil {
+			n.ASN = *pg.ASN
+		}
+		return n, &pg, nil
+	}
+	return n, nil, fmt.Errorf("peer group %s of neighbor %s not found", *n.PeerGroup, n.Address)
+}
+
 func neighborName(n v1alpha1.Neighbor) string {
 	return fmt.Sprintf("%d@%s", n.ASN, n.Address)
 }
@@ -335,6 +635,19 @@ func parseTimers(ht, ka *metav1.Duration) (*uint64, *uint64, error) {
 	return &htSeconds, &kaSeconds, nil
 }
 
+// connectTimeToFRR returns the given connect time in
+// seconds, or nil if it is not set.
+func connectTimeToFRR(connectTime *metav1.Duration) (*uint64, error) {
+	if connectTime == nil {
+		return nil, nil
+	}
+	connectSecond, err := durationToUint64(connectTime.Duration / time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connecttime %v: %w", connectTime.Duration, err)
+	}
+	return ptr.To(connectSecond), nil
+}
+
 func durationToUint64(value time.Duration) (uint64, error) {
 	if value < 0 {
 		return 0, fmt.Errorf("cannot convert negative value to uint64: %d", value)
@@ -354,3 +667,92 @@ func routerIDFromUnderlay(underlay v1alpha1.Underlay, nodeIndex int) (string, er
 	}
 	return routerID, nil
 }
+
+// splitByFamily splits the given prefixes into the ipv4 and the ipv6 ones.
+func splitByFamily(prefixes []string) ([]string, []string, error) {
+	var ipv4, ipv6 []string
+	for _, p := range prefixes {
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid prefix %s: %w", p, err)
+		}
+		if ipfamily.ForCIDR(ipNet) == ipfamily.IPv6 {
+			ipv6 = append(ipv6, ipNet.String())
+			continue
+		}
+		ipv4 = append(ipv4, ipNet.String())
+	}
+	return ipv4, ipv6, nil
+}
+
+// hostAdvertiseToFRR returns the filter limiting the routes advertised to
+// the host to the prefixes listed in the session, or nil if none is listed.
+func hostAdvertiseToFRR(session v1alpha1.HostSession, name string) (*frr.HostAdvertiseConfig, error) {
+	if len(session.HostAdvertise) == 0 {
+		return nil, nil
+	}
+	ipv4, ipv6, err := splitByFamily(session.HostAdvertise)
+	if err != nil {
+		return nil, err
+	}
+	return &frr.HostAdvertiseConfig{
+		Name: name,
+		IPv4: ipv4,
+		IPv6: ipv6,
+	}, nil
+}
+
+// exportRouteMapToFRR returns the route-map filtering the routes of the vrf
+// advertised as EVPN type-5 routes, or nil if the vni does not reference one.
+// A rule matching no prefix applies to both the families.
+func exportRouteMapToFRR(vni v1alpha1.L3VNI) (*frr.RouteMapConfig, error) {
+	if vni.Spec.EVPNExportRouteMap == nil {
+		return nil, nil
+	}
+	name := *vni.Spec.EVPNExportRouteMap
+	idx := slices.IndexFunc(vni.Spec.RouteMaps, func(r v1alpha1.RouteMapSpec) bool {
+		return r.Name == name
+	})
+	if idx == -1 {
+		return nil, fmt.Errorf("route-map %s not found", name)
+	}
+
+	res := &frr.RouteMapConfig{Name: fmt.Sprintf("%s-export-%s", vni.Spec.VRF, name)}
+	for i, rule := range vni.Spec.RouteMaps[idx].Rules {
+		entry := frr.RouteMapEntry{
+			Seq:    i + 1,
+			Action: rule.Action,
+		}
+		if rule.Set != nil {
+			entry.Communities = rule.Set.Communities
+			entry.LocalPreference = rule.Set.LocalPreference
+		}
+		if rule.Match == nil {
+			res.IPv4 = append(res.IPv4, entry)
+			res.IPv6 = append(res.IPv6, entry)
+			continue
+		}
+		ipv4, ipv6, err := splitByFamily(rule.Match.Prefixes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d of route-map %s: %w", i, name, err)
+		}
+		if len(ipv4) > 0 {
+			ipv4Entry := entry
+			ipv4Entry.Prefixes = ipv4
+			res.IPv4 = append(res.IPv4, ipv4Entry)
+		}
+		if len(ipv6) > 0 {
+			ipv6Entry := entry
+			ipv6Entry.Prefixes = ipv6
+			res.IPv6 = append(res.IPv6, ipv6Entry)
+		}
+	}
+	return res, nil
+}
+
+// sanitizeDescription returns the description of a neighbor with the
+// leading and trailing spaces removed and the inner ones collapsed, as
+// FRR does when parsing it.
+func sanitizeDescription(description *string) string {
+	return strings.Join(strings.Fields(ptr.Deref(description, "")), " ")
+}
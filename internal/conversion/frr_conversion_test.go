@@ -3,7 +3,10 @@
 package conversion
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,14 +19,17 @@ import (
 
 func TestAPItoFRR(t *testing.T) {
 	tests := []struct {
-		name          string
-		nodeIndex     int
-		underlays     []v1alpha1.Underlay
-		vnis          []v1alpha1.L3VNI
-		l3Passthrough []v1alpha1.L3Passthrough
-		logLevel      string
-		want          frr.Config
-		wantErr       bool
+		name           string
+		nodeIndex      int
+		underlays      []v1alpha1.Underlay
+		vnis           []v1alpha1.L3VNI
+		l2vnis         []v1alpha1.L2VNI
+		l3Passthrough  []v1alpha1.L3Passthrough
+		logLevel       string
+		frrLogLevel    string
+		bgpListenLimit uint32
+		want           frr.Config
+		wantErr        bool
 	}{
 		{
 			name:          "no underlays",
@@ -74,6 +80,370 @@ func TestAPItoFRR(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:      "ebgp multihop ttl",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001, EBGPMultiHopTTL: ptr.To[uint8](3)},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:            "65001@192.168.1.1",
+							ASN:             65001,
+							Addr:            "192.168.1.1",
+							IPFamily:        ipfamily.IPv4,
+							EBGPMultiHop:    true,
+							EBGPMultiHopTTL: ptr.To[uint8](3),
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "bestpath options",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						BestPath: &v1alpha1.BestPathConfig{
+							ASPathMultipathRelax: true,
+							CompareRouterID:      true,
+							MEDMissingAsWorst:    true,
+						},
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					BestPath: &frr.BestPathConfig{
+						ASPathMultipathRelax: true,
+						CompareRouterID:      true,
+						MEDMissingAsWorst:    true,
+					},
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "update delay and coalesce time",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						UpdateDelay:  &metav1.Duration{Duration: 2 * time.Minute},
+						CoalesceTime: &metav1.Duration{Duration: 1500 * time.Millisecond},
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID:     "10.0.0.1",
+					UpdateDelay:  120,
+					CoalesceTime: 1500,
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "zero update delay and coalesce time",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						UpdateDelay:  &metav1.Duration{},
+						CoalesceTime: &metav1.Duration{},
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "bgp listen limit",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001},
+						},
+					},
+				},
+			},
+			vnis:           []v1alpha1.L3VNI{},
+			l3Passthrough:  []v1alpha1.L3Passthrough{},
+			logLevel:       "debug",
+			bgpListenLimit: 500,
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs:           []frr.L3VNIConfig{},
+				BFDProfiles:    []frr.BFDProfile{},
+				Loglevel:       "debug",
+				BGPListenLimit: 500,
+			},
+			wantErr: false,
+		},
+		{
+			name:      "dual stack vtep",
+			nodeIndex: 1,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR:   "192.168.1.0/24",
+							VTEPCIDRv6: ptr.To("fd00:1::/64"),
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP:   "192.168.1.1/32",
+						VTEPv6: "fd00:1::1/128",
+					},
+					RouterID: "10.0.0.2",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:     "65001@192.168.1.1",
+							ASN:      65001,
+							Addr:     "192.168.1.1",
+							IPFamily: ipfamily.IPv4,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "neighbors in peer groups",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						PeerGroups: []v1alpha1.PeerGroupSpec{
+							{
+								Name:          "spines",
+								ASN:           ptr.To(uint32(65001)),
+								HoldTime:      &metav1.Duration{Duration: 30 * time.Second},
+								KeepaliveTime: &metav1.Duration{Duration: 10 * time.Second},
+							},
+							{Name: "leaves"},
+						},
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", PeerGroup: ptr.To("spines")},
+							{Address: "192.168.1.2", ASN: 65001, PeerGroup: ptr.To("spines")},
+							{Address: "192.168.2.1", ASN: 65002, PeerGroup: ptr.To("leaves")},
+							{Address: "192.168.3.1", ASN: 65003},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					PeerGroups: []frr.PeerGroupConfig{
+						{
+							Name:          "spines",
+							ASN:           65001,
+							HoldTime:      ptr.To(uint64(30)),
+							KeepaliveTime: ptr.To(uint64(10)),
+						},
+						{Name: "leaves"},
+					},
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:             "65001@192.168.1.1",
+							ASN:              65001,
+							Addr:             "192.168.1.1",
+							IPFamily:         ipfamily.IPv4,
+							PeerGroup:        "spines",
+							ASNFromPeerGroup: true,
+						},
+						{
+							Name:             "65001@192.168.1.2",
+							ASN:              65001,
+							Addr:             "192.168.1.2",
+							IPFamily:         ipfamily.IPv4,
+							PeerGroup:        "spines",
+							ASNFromPeerGroup: true,
+						},
+						{
+							Name:      "65002@192.168.2.1",
+							ASN:       65002,
+							Addr:      "192.168.2.1",
+							IPFamily:  ipfamily.IPv4,
+							PeerGroup: "leaves",
+						},
+						{
+							Name:     "65003@192.168.3.1",
+							ASN:      65003,
+							Addr:     "192.168.3.1",
+							IPFamily: ipfamily.IPv4,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
 		{
 			name:      "ipv4 only",
 			nodeIndex: 0,
@@ -85,7 +455,1351 @@ func TestAPItoFRR(t *testing.T) {
 							VTEPCIDR: "192.168.1.0/24",
 						},
 						RouterIDCIDR: "10.0.0.0/24",
-						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN: 65001,
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.2.2",
+							ASN:  65001,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "ipv6 only",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv6: "2001:db8::/64",
+							},
+							HostASN: 65001,
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:            "2001:db8::2",
+							ASN:             65001,
+							ExtendedNextHop: true,
+						},
+						ToAdvertiseIPv4: []string{},
+						ToAdvertiseIPv6: []string{"2001:db8::2/128"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "ipv6 only with an ipv4 router svi address",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv6: "2001:db8::/64",
+							},
+							HostASN: 65001,
+						},
+						RouterSVIAddress: ptr.To("192.168.250.1/32"),
+						VRF:              "vrf1",
+						VNI:              200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:            "2001:db8::2",
+							ASN:             65001,
+							ExtendedNextHop: true,
+						},
+						ToAdvertiseIPv4: []string{"192.168.250.1/32"},
+						ToAdvertiseIPv6: []string{"2001:db8::2/128"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "dual stack",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+								IPv6: "2001:db8::/64",
+							},
+							HostASN: 65001,
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.2.2",
+							ASN:  65001,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "2001:db8::2",
+							ASN:  65001,
+						},
+						ToAdvertiseIPv4: []string{},
+						ToAdvertiseIPv6: []string{"2001:db8::2/128"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "BFD with custom settings",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{
+								Address: "192.168.1.100",
+								ASN:     65001,
+								BFD: &v1alpha1.BFDSettings{
+									ReceiveInterval:  ptr.To(uint32(300)),
+									TransmitInterval: ptr.To(uint32(300)),
+									DetectMultiplier: ptr.To(uint32(3)),
+									EchoMode:         ptr.To(false),
+									PassiveMode:      ptr.To(false),
+								},
+							},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.100",
+							ASN:          65001,
+							Addr:         "192.168.1.100",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+							BFDEnabled:   true,
+							BFDProfile:   "neighbor-192.168.1.100",
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{
+					{
+						Name:             "neighbor-192.168.1.100",
+						ReceiveInterval:  ptr.To(uint32(300)),
+						TransmitInterval: ptr.To(uint32(300)),
+						DetectMultiplier: ptr.To(uint32(3)),
+					},
+				},
+				Loglevel: "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "BFD enabled without settings",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{
+								Address: "192.168.1.100",
+								ASN:     65001,
+								BFD:     &v1alpha1.BFDSettings{},
+							},
+						},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.100",
+							ASN:          65001,
+							Addr:         "192.168.1.100",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+							BFDEnabled:   true,
+							BFDProfile:   "",
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni without host session",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni not advertising ipv6",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:           "vrf1",
+						VNI:           200,
+						AdvertiseIPv4: ptr.To(true),
+						AdvertiseIPv6: ptr.To(false),
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:             65000,
+						VNI:             200,
+						VRF:             "vrf1",
+						RouterID:        "10.0.0.1",
+						NoAdvertiseIPv6: true,
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni not advertised into the fabric",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "local"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:             "local",
+						VNI:             200,
+						FabricAdvertise: ptr.To(false),
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "advertised"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:             "advertised",
+						VNI:             300,
+						FabricAdvertise: ptr.To(true),
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:               65000,
+						VNI:               200,
+						VRF:               "local",
+						RouterID:          "10.0.0.1",
+						NoFabricAdvertise: true,
+					},
+					{
+						ASN:      65000,
+						VNI:      300,
+						VRF:      "advertised",
+						RouterID: "10.0.0.1",
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni importing the vrf of another vni",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF: "red",
+						VNI: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:        "blue",
+						VNI:        200,
+						ImportVRFs: []string{"red"},
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      100,
+						VRF:      "red",
+						RouterID: "10.0.0.1",
+					},
+					{
+						ASN:        65000,
+						VNI:        200,
+						VRF:        "blue",
+						RouterID:   "10.0.0.1",
+						ImportVRFs: []string{"red"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni leaking prefixes to the default vrf",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF:           "red",
+						VNI:           100,
+						LeakToDefault: []string{"192.168.20.0/24", "2001:db8:20::/64"},
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:               65000,
+						VNI:               100,
+						VRF:               "red",
+						RouterID:          "10.0.0.1",
+						LeakToDefaultIPv4: []string{"192.168.20.0/24"},
+						LeakToDefaultIPv6: []string{"2001:db8:20::/64"},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni filtering the routes advertised to the fabric",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VRF: "red",
+						VNI: 100,
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{
+										Action: v1alpha1.RouteMapDeny,
+										Match:  &v1alpha1.RouteMapMatch{Prefixes: []string{"192.168.20.0/24"}},
+									},
+									{
+										Action: v1alpha1.RouteMapPermit,
+										Match:  &v1alpha1.RouteMapMatch{Prefixes: []string{"192.168.30.0/24", "2001:db8:30::/64"}},
+										Set: &v1alpha1.RouteMapSet{
+											Communities:     []string{"65000:100"},
+											LocalPreference: ptr.To[uint32](200),
+										},
+									},
+									{
+										Action: v1alpha1.RouteMapPermit,
+									},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      100,
+						VRF:      "red",
+						RouterID: "10.0.0.1",
+						ExportRouteMap: &frr.RouteMapConfig{
+							Name: "red-export-export",
+							IPv4: []frr.RouteMapEntry{
+								{Seq: 1, Action: "deny", Prefixes: []string{"192.168.20.0/24"}},
+								{
+									Seq:             2,
+									Action:          "permit",
+									Prefixes:        []string{"192.168.30.0/24"},
+									Communities:     []string{"65000:100"},
+									LocalPreference: ptr.To[uint32](200),
+								},
+								{Seq: 3, Action: "permit"},
+							},
+							IPv6: []frr.RouteMapEntry{
+								{
+									Seq:             2,
+									Action:          "permit",
+									Prefixes:        []string{"2001:db8:30::/64"},
+									Communities:     []string{"65000:100"},
+									LocalPreference: ptr.To[uint32](200),
+								},
+								{Seq: 3, Action: "permit"},
+							},
+						},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "empty routeridcidr uses default",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN: 65001,
+						},
+						VRF: "vni1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vni1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.2.2",
+							ASN:  65001,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "missing EVPN parameter",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN:          65000,
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			logLevel:      "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN:    65000,
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "L3 passthrough",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+								IPv6: "2001:db8::/64",
+							},
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:  65001,
+						Addr: "192.168.2.2",
+					},
+					LocalNeighborV6: &frr.NeighborConfig{
+						ASN:  65001,
+						Addr: "2001:db8::2",
+					},
+					ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+					ToAdvertiseIPv6: []string{"2001:db8::2/128"},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "host sessions with next hop self",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN:     65001,
+							NextHopSelf: ptr.To(true),
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							NextHopSelf:      ptr.To(true),
+							NextHopSelfForce: ptr.To(true),
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:        "192.168.2.2",
+							ASN:         65001,
+							NextHopSelf: &frr.NextHopSelf{},
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:         65001,
+						Addr:        "192.168.3.2",
+						NextHopSelf: &frr.NextHopSelf{Force: true},
+					},
+					ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+					ToAdvertiseIPv6: []string{},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "host sessions stripping communities",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN:                  65001,
+							StripCommunitiesOnImport: true,
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							StripCommunitiesOnImport: true,
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:             "192.168.2.2",
+							ASN:              65001,
+							StripCommunities: true,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:              65001,
+						Addr:             "192.168.3.2",
+						StripCommunities: true,
+					},
+					ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+					ToAdvertiseIPv6: []string{},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "host sessions allowing the local as in",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN:   65001,
+							AllowASIn: ptr.To[uint8](2),
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							AllowASIn: ptr.To[uint8](2),
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:      "192.168.2.2",
+							ASN:       65001,
+							AllowASIn: 2,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:       65001,
+						Addr:      "192.168.3.2",
+						AllowASIn: 2,
+					},
+					ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+					ToAdvertiseIPv6: []string{},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "vni with router svi address",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN: 65001,
+						},
+						VRF:              "vrf1",
+						VNI:              200,
+						RouterSVIAddress: ptr.To("10.250.0.1/24"),
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:         "65001@192.168.1.1",
+							ASN:          65001,
+							Addr:         "192.168.1.1",
+							IPFamily:     ipfamily.IPv4,
+							EBGPMultiHop: false,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.2.2",
+							ASN:  65001,
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32", "10.250.0.1/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "send community",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001, SendCommunity: ptr.To(v1alpha1.SendCommunityExtended)},
+						},
 					},
 				},
 			},
@@ -98,15 +1812,110 @@ func TestAPItoFRR(t *testing.T) {
 							LocalCIDR: v1alpha1.LocalCIDRConfig{
 								IPv4: "192.168.2.0/24",
 							},
+							HostASN:       65001,
+							SendCommunity: ptr.To(v1alpha1.SendCommunityNone),
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
 							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							SendCommunity: ptr.To(v1alpha1.SendCommunityLarge),
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:          "65001@192.168.1.1",
+							ASN:           65001,
+							Addr:          "192.168.1.1",
+							IPFamily:      ipfamily.IPv4,
+							SendCommunity: "extended",
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr:          "192.168.2.2",
+							ASN:           65001,
+							SendCommunity: "none",
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:           65001,
+						Addr:          "192.168.3.2",
+						SendCommunity: "large",
+					},
+					ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+					ToAdvertiseIPv6: []string{},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "neighbor and host session descriptions",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001, Description: ptr.To("  spine   one ")},
+						},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN:     65001,
+							Description: ptr.To("red host"),
 						},
 						VRF: "vrf1",
 						VNI: 200,
 					},
 				},
 			},
-			l3Passthrough: []v1alpha1.L3Passthrough{},
-			logLevel:      "debug",
+			logLevel: "debug",
 			want: frr.Config{
 				Underlay: frr.UnderlayConfig{
 					MyASN: 65000,
@@ -116,11 +1925,11 @@ func TestAPItoFRR(t *testing.T) {
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
-							Name:         "65001@192.168.1.1",
-							ASN:          65001,
-							Addr:         "192.168.1.1",
-							IPFamily:     ipfamily.IPv4,
-							EBGPMultiHop: false,
+							Name:        "65001@192.168.1.1",
+							ASN:         65001,
+							Addr:        "192.168.1.1",
+							IPFamily:    ipfamily.IPv4,
+							Description: "spine one",
 						},
 					},
 				},
@@ -131,8 +1940,9 @@ func TestAPItoFRR(t *testing.T) {
 						VRF:      "vrf1",
 						RouterID: "10.0.0.1",
 						LocalNeighbor: &frr.NeighborConfig{
-							Addr: "192.168.2.2",
-							ASN:  65001,
+							Addr:        "192.168.2.2",
+							ASN:         65001,
+							Description: "red host",
 						},
 						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
 						ToAdvertiseIPv6: []string{},
@@ -144,7 +1954,48 @@ func TestAPItoFRR(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:      "ipv6 only",
+			name:      "neighbor with extended next hop",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "2001:db8::1", ASN: 65001, ExtendedNextHop: ptr.To(true)},
+						},
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:            "65001@2001:db8::1",
+							ASN:             65001,
+							Addr:            "2001:db8::1",
+							IPFamily:        ipfamily.IPv6,
+							ExtendedNextHop: true,
+						},
+					},
+				},
+				VNIs:        []frr.L3VNIConfig{},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "host session with prefixes to advertise",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
@@ -165,17 +2016,17 @@ func TestAPItoFRR(t *testing.T) {
 						HostSession: &v1alpha1.HostSession{
 							ASN: 65000,
 							LocalCIDR: v1alpha1.LocalCIDRConfig{
-								IPv6: "2001:db8::/64",
+								IPv4: "192.168.2.0/24",
 							},
-							HostASN: 65001,
+							HostASN:       65001,
+							HostAdvertise: []string{"10.1.0.0/24", "2001:db8:1::/64", "10.2.0.0/16"},
 						},
 						VRF: "vrf1",
 						VNI: 200,
 					},
 				},
 			},
-			l3Passthrough: []v1alpha1.L3Passthrough{},
-			logLevel:      "debug",
+			logLevel: "debug",
 			want: frr.Config{
 				Underlay: frr.UnderlayConfig{
 					MyASN: 65000,
@@ -185,11 +2036,100 @@ func TestAPItoFRR(t *testing.T) {
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
-							Name:         "65001@192.168.1.1",
-							ASN:          65001,
-							Addr:         "192.168.1.1",
-							IPFamily:     ipfamily.IPv4,
-							EBGPMultiHop: false,
+							Name:     "65001@192.168.1.1",
+							ASN:      65001,
+							Addr:     "192.168.1.1",
+							IPFamily: ipfamily.IPv4,
+						},
+					},
+				},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:      65000,
+						VNI:      200,
+						VRF:      "vrf1",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.2.2",
+							ASN:  65001,
+							HostAdvertise: &frr.HostAdvertiseConfig{
+								Name: "vrf1-host-advertise",
+								IPv4: []string{"10.1.0.0/24", "10.2.0.0/16"},
+								IPv6: []string{"2001:db8:1::/64"},
+							},
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+				},
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
+			},
+			wantErr: false,
+		},
+		{
+			name:      "neighbor and host session connect time",
+			nodeIndex: 0,
+			underlays: []v1alpha1.Underlay{
+				{
+					Spec: v1alpha1.UnderlaySpec{
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR: "192.168.1.0/24",
+						},
+						RouterIDCIDR: "10.0.0.0/24",
+						Neighbors: []v1alpha1.Neighbor{
+							{Address: "192.168.1.1", ASN: 65001, ConnectTime: &metav1.Duration{Duration: 5 * time.Second}},
+						},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.2.0/24",
+							},
+							HostASN:     65001,
+							ConnectTime: &metav1.Duration{Duration: 2 * time.Second},
+						},
+						VRF: "vrf1",
+						VNI: 200,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L3VNISpec{
+						HostSession: &v1alpha1.HostSession{
+							ASN: 65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							HostASN: 65001,
+						},
+						VRF: "vrf2",
+						VNI: 201,
+					},
+				},
+			},
+			logLevel: "debug",
+			want: frr.Config{
+				Underlay: frr.UnderlayConfig{
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP: "192.168.1.0/32",
+					},
+					RouterID: "10.0.0.1",
+					Neighbors: []frr.NeighborConfig{
+						{
+							Name:        "65001@192.168.1.1",
+							ASN:         65001,
+							Addr:        "192.168.1.1",
+							IPFamily:    ipfamily.IPv4,
+							ConnectTime: ptr.To(uint64(5)),
 						},
 					},
 				},
@@ -200,11 +2140,24 @@ func TestAPItoFRR(t *testing.T) {
 						VRF:      "vrf1",
 						RouterID: "10.0.0.1",
 						LocalNeighbor: &frr.NeighborConfig{
-							Addr: "2001:db8::2",
+							Addr:        "192.168.2.2",
+							ASN:         65001,
+							ConnectTime: ptr.To(uint64(2)),
+						},
+						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
+						ToAdvertiseIPv6: []string{},
+					},
+					{
+						ASN:      65000,
+						VNI:      201,
+						VRF:      "vrf2",
+						RouterID: "10.0.0.1",
+						LocalNeighbor: &frr.NeighborConfig{
+							Addr: "192.168.3.2",
 							ASN:  65001,
 						},
-						ToAdvertiseIPv4: []string{},
-						ToAdvertiseIPv6: []string{"2001:db8::2/128"},
+						ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+						ToAdvertiseIPv6: []string{},
 					},
 				},
 				BFDProfiles: []frr.BFDProfile{},
@@ -213,7 +2166,7 @@ func TestAPItoFRR(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:      "dual stack",
+			name:      "host sessions with dynamic peers",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
@@ -237,15 +2190,29 @@ func TestAPItoFRR(t *testing.T) {
 								IPv4: "192.168.2.0/24",
 								IPv6: "2001:db8::/64",
 							},
-							HostASN: 65001,
+							HostASN:      65001,
+							DynamicPeers: true,
 						},
 						VRF: "vrf1",
 						VNI: 200,
 					},
 				},
 			},
-			l3Passthrough: []v1alpha1.L3Passthrough{},
-			logLevel:      "debug",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{
+							HostASN: 65001,
+							ASN:     65000,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{
+								IPv4: "192.168.3.0/24",
+							},
+							DynamicPeers: true,
+						},
+					},
+				},
+			},
+			logLevel: "debug",
 			want: frr.Config{
 				Underlay: frr.UnderlayConfig{
 					MyASN: 65000,
@@ -270,8 +2237,9 @@ func TestAPItoFRR(t *testing.T) {
 						VRF:      "vrf1",
 						RouterID: "10.0.0.1",
 						LocalNeighbor: &frr.NeighborConfig{
-							Addr: "192.168.2.2",
-							ASN:  65001,
+							Addr:        "hosts-ipv4",
+							ASN:         65001,
+							ListenRange: "192.168.2.0/24",
 						},
 						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
 						ToAdvertiseIPv6: []string{},
@@ -282,20 +2250,30 @@ func TestAPItoFRR(t *testing.T) {
 						VRF:      "vrf1",
 						RouterID: "10.0.0.1",
 						LocalNeighbor: &frr.NeighborConfig{
-							Addr: "2001:db8::2",
-							ASN:  65001,
+							Addr:        "hosts-ipv6",
+							ASN:         65001,
+							ListenRange: "2001:db8::/64",
 						},
 						ToAdvertiseIPv4: []string{},
 						ToAdvertiseIPv6: []string{"2001:db8::2/128"},
 					},
 				},
+				Passthrough: &frr.PassthroughConfig{
+					LocalNeighborV4: &frr.NeighborConfig{
+						ASN:         65001,
+						Addr:        "hosts-ipv4",
+						ListenRange: "192.168.3.0/24",
+					},
+					ToAdvertiseIPv4: []string{"192.168.3.2/32"},
+					ToAdvertiseIPv6: []string{},
+				},
 				BFDProfiles: []frr.BFDProfile{},
 				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "BFD with custom settings",
+			name:      "l2vnis with ipv6 gateway",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
@@ -305,23 +2283,35 @@ func TestAPItoFRR(t *testing.T) {
 							VTEPCIDR: "192.168.1.0/24",
 						},
 						RouterIDCIDR: "10.0.0.0/24",
-						Neighbors: []v1alpha1.Neighbor{
-							{
-								Address: "192.168.1.100",
-								ASN:     65001,
-								BFD: &v1alpha1.BFDSettings{
-									ReceiveInterval:  ptr.To(uint32(300)),
-									TransmitInterval: ptr.To(uint32(300)),
-									DetectMultiplier: ptr.To(uint32(3)),
-									EchoMode:         ptr.To(false),
-									PassiveMode:      ptr.To(false),
-								},
-							},
-						},
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "ipv4only"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          110,
+						L2GatewayIPs: []string{"192.171.24.1/24"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "suppressed"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          120,
+						L2GatewayIPs: []string{"192.171.25.1/24", "fd00:10:245:1::1/64"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "advertising"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          130,
+						L2GatewayIPs: []string{"fd00:10:245:2::1/64"},
+						SuppressRA:   ptr.To(false),
 					},
 				},
 			},
-			vnis:          []v1alpha1.L3VNI{},
 			l3Passthrough: []v1alpha1.L3Passthrough{},
 			logLevel:      "debug",
 			want: frr.Config{
@@ -333,31 +2323,26 @@ func TestAPItoFRR(t *testing.T) {
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
-							Name:         "65001@192.168.1.100",
+							Name:         "65001@192.168.1.1",
 							ASN:          65001,
-							Addr:         "192.168.1.100",
+							Addr:         "192.168.1.1",
 							IPFamily:     ipfamily.IPv4,
 							EBGPMultiHop: false,
-							BFDEnabled:   true,
-							BFDProfile:   "neighbor-192.168.1.100",
 						},
 					},
 				},
 				VNIs: []frr.L3VNIConfig{},
-				BFDProfiles: []frr.BFDProfile{
-					{
-						Name:             "neighbor-192.168.1.100",
-						ReceiveInterval:  ptr.To(uint32(300)),
-						TransmitInterval: ptr.To(uint32(300)),
-						DetectMultiplier: ptr.To(uint32(3)),
-					},
+				L2Gateways: []frr.L2GatewayConfig{
+					{Interface: "br-pe-120", SuppressRA: true},
+					{Interface: "br-pe-130", SuppressRA: false},
 				},
-				Loglevel: "debug",
+				BFDProfiles: []frr.BFDProfile{},
+				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "BFD enabled without settings",
+			name:      "l2vnis with ethernet segments",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
@@ -367,17 +2352,49 @@ func TestAPItoFRR(t *testing.T) {
 							VTEPCIDR: "192.168.1.0/24",
 						},
 						RouterIDCIDR: "10.0.0.0/24",
-						Neighbors: []v1alpha1.Neighbor{
-							{
-								Address: "192.168.1.100",
-								ASN:     65001,
-								BFD:     &v1alpha1.BFDSettings{},
-							},
+						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					},
+				},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "preferred"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 110,
+						EthernetSegment: &v1alpha1.EthernetSegment{
+							ID:           ptr.To(uint32(1)),
+							SysMAC:       ptr.To("44:38:39:ff:ff:01"),
+							DFPreference: ptr.To(uint16(50000)),
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "defaultpreference"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 120,
+						EthernetSegment: &v1alpha1.EthernetSegment{
+							ID:     ptr.To(uint32(2)),
+							SysMAC: ptr.To("44:38:39:ff:ff:01"),
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "singlehomed"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 130,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "operatoresi"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 140,
+						EthernetSegment: &v1alpha1.EthernetSegment{
+							ESI: ptr.To("00:44:38:39:ff:ff:01:00:00:03"),
 						},
 					},
 				},
 			},
-			vnis:          []v1alpha1.L3VNI{},
 			l3Passthrough: []v1alpha1.L3Passthrough{},
 			logLevel:      "debug",
 			want: frr.Config{
@@ -389,44 +2406,50 @@ func TestAPItoFRR(t *testing.T) {
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
-							Name:         "65001@192.168.1.100",
+							Name:         "65001@192.168.1.1",
 							ASN:          65001,
-							Addr:         "192.168.1.100",
+							Addr:         "192.168.1.1",
 							IPFamily:     ipfamily.IPv4,
 							EBGPMultiHop: false,
-							BFDEnabled:   true,
-							BFDProfile:   "",
 						},
 					},
 				},
-				VNIs:        []frr.L3VNIConfig{},
+				VNIs: []frr.L3VNIConfig{},
+				EthernetSegments: []frr.EthernetSegmentConfig{
+					{Interface: "pe-110", ID: 1, SysMAC: "44:38:39:ff:ff:01", DFPreference: 50000},
+					{Interface: "pe-120", ID: 2, SysMAC: "44:38:39:ff:ff:01"},
+					{Interface: "pe-140", ESI: "00:44:38:39:ff:ff:01:00:00:03"},
+				},
 				BFDProfiles: []frr.BFDProfile{},
 				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "vni without host session",
+			name:      "l2vnis with all the vnis advertised",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
 					Spec: v1alpha1.UnderlaySpec{
 						ASN: 65000,
 						EVPN: &v1alpha1.EVPNConfig{
-							VTEPCIDR: "192.168.1.0/24",
+							VTEPCIDR:        "192.168.1.0/24",
+							AdvertiseAllVNI: ptr.To(true),
 						},
 						RouterIDCIDR: "10.0.0.0/24",
 						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
 					},
 				},
 			},
-			vnis: []v1alpha1.L3VNI{
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
-					Spec: v1alpha1.L3VNISpec{
-						VRF: "vrf1",
-						VNI: 200,
-					},
+					ObjectMeta: metav1.ObjectMeta{Name: "first"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "second"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 120},
 				},
 			},
 			l3Passthrough: []v1alpha1.L3Passthrough{},
@@ -448,48 +2471,38 @@ func TestAPItoFRR(t *testing.T) {
 						},
 					},
 				},
-				VNIs: []frr.L3VNIConfig{
-					{
-						ASN:      65000,
-						VNI:      200,
-						VRF:      "vrf1",
-						RouterID: "10.0.0.1",
-					},
-				},
+				VNIs:        []frr.L3VNIConfig{},
 				BFDProfiles: []frr.BFDProfile{},
 				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "empty routeridcidr uses default",
+			name:      "l2vnis with explicitly advertised vnis",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
 					Spec: v1alpha1.UnderlaySpec{
 						ASN: 65000,
 						EVPN: &v1alpha1.EVPNConfig{
-							VTEPCIDR: "192.168.1.0/24",
+							VTEPCIDR:        "192.168.1.0/24",
+							AdvertiseAllVNI: ptr.To(false),
+							AdvertisedVNIs:  []uint32{110},
 						},
-						RouterIDCIDR: "",
+						RouterIDCIDR: "10.0.0.0/24",
 						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
 					},
 				},
 			},
-			vnis: []v1alpha1.L3VNI{
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
-					Spec: v1alpha1.L3VNISpec{
-						HostSession: &v1alpha1.HostSession{
-							ASN: 65000,
-							LocalCIDR: v1alpha1.LocalCIDRConfig{
-								IPv4: "192.168.2.0/24",
-							},
-							HostASN: 65001,
-						},
-						VRF: "vni1",
-						VNI: 200,
-					},
+					ObjectMeta: metav1.ObjectMeta{Name: "first"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "second"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 120},
 				},
 			},
 			l3Passthrough: []v1alpha1.L3Passthrough{},
@@ -498,7 +2511,9 @@ func TestAPItoFRR(t *testing.T) {
 				Underlay: frr.UnderlayConfig{
 					MyASN: 65000,
 					EVPN: &frr.UnderlayEvpn{
-						VTEP: "192.168.1.0/32",
+						VTEP:                   "192.168.1.0/32",
+						RestrictAdvertisedVNIs: true,
+						AdvertisedVNIs:         []uint32{110},
 					},
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
@@ -511,43 +2526,50 @@ func TestAPItoFRR(t *testing.T) {
 						},
 					},
 				},
-				VNIs: []frr.L3VNIConfig{
-					{
-						ASN:      65000,
-						VNI:      200,
-						VRF:      "vni1",
-						RouterID: "10.0.0.1",
-						LocalNeighbor: &frr.NeighborConfig{
-							Addr: "192.168.2.2",
-							ASN:  65001,
-						},
-						ToAdvertiseIPv4: []string{"192.168.2.2/32"},
-						ToAdvertiseIPv6: []string{},
-					},
-				},
+				VNIs:        []frr.L3VNIConfig{},
 				BFDProfiles: []frr.BFDProfile{},
 				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "missing EVPN parameter",
+			name:      "route targets derived from the fabric asn",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
 					Spec: v1alpha1.UnderlaySpec{
-						ASN:          65000,
+						ASN: 65000,
+						EVPN: &v1alpha1.EVPNConfig{
+							VTEPCIDR:        "192.168.1.0/24",
+							RTAutoDeriveASN: ptr.To(uint32(65100)),
+						},
 						RouterIDCIDR: "10.0.0.0/24",
 						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
 					},
 				},
 			},
-			vnis:          []v1alpha1.L3VNI{},
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100},
+				},
+			},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "first"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110},
+				},
+			},
 			l3Passthrough: []v1alpha1.L3Passthrough{},
 			logLevel:      "debug",
 			want: frr.Config{
 				Underlay: frr.UnderlayConfig{
-					MyASN:    65000,
+					MyASN: 65000,
+					EVPN: &frr.UnderlayEvpn{
+						VTEP:           "192.168.1.0/32",
+						VNIs:           []uint32{110},
+						RouteTargetASN: 65100,
+					},
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
@@ -559,49 +2581,37 @@ func TestAPItoFRR(t *testing.T) {
 						},
 					},
 				},
-				VNIs:        []frr.L3VNIConfig{},
+				VNIs: []frr.L3VNIConfig{
+					{
+						ASN:            65000,
+						VRF:            "red",
+						VNI:            100,
+						RouterID:       "10.0.0.1",
+						RouteTargetASN: 65100,
+					},
+				},
 				BFDProfiles: []frr.BFDProfile{},
 				Loglevel:    "debug",
 			},
 			wantErr: false,
 		},
 		{
-			name:      "L3 passthrough",
+			name:      "frr log level overrides the log level",
 			nodeIndex: 0,
 			underlays: []v1alpha1.Underlay{
 				{
 					Spec: v1alpha1.UnderlaySpec{
-						ASN: 65000,
-						EVPN: &v1alpha1.EVPNConfig{
-							VTEPCIDR: "192.168.1.0/24",
-						},
+						ASN:          65000,
 						RouterIDCIDR: "10.0.0.0/24",
 						Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
 					},
 				},
 			},
-			vnis: []v1alpha1.L3VNI{},
-			l3Passthrough: []v1alpha1.L3Passthrough{
-				{
-					Spec: v1alpha1.L3PassthroughSpec{
-						HostSession: v1alpha1.HostSession{
-							HostASN: 65001,
-							ASN:     65000,
-							LocalCIDR: v1alpha1.LocalCIDRConfig{
-								IPv4: "192.168.2.0/24",
-								IPv6: "2001:db8::/64",
-							},
-						},
-					},
-				},
-			},
-			logLevel: "debug",
+			logLevel:    "info",
+			frrLogLevel: "debugging",
 			want: frr.Config{
 				Underlay: frr.UnderlayConfig{
-					MyASN: 65000,
-					EVPN: &frr.UnderlayEvpn{
-						VTEP: "192.168.1.0/32",
-					},
+					MyASN:    65000,
 					RouterID: "10.0.0.1",
 					Neighbors: []frr.NeighborConfig{
 						{
@@ -613,21 +2623,9 @@ func TestAPItoFRR(t *testing.T) {
 						},
 					},
 				},
-				Passthrough: &frr.PassthroughConfig{
-					LocalNeighborV4: &frr.NeighborConfig{
-						ASN:  65001,
-						Addr: "192.168.2.2",
-					},
-					LocalNeighborV6: &frr.NeighborConfig{
-						ASN:  65001,
-						Addr: "2001:db8::2",
-					},
-					ToAdvertiseIPv4: []string{"192.168.2.2/32"},
-					ToAdvertiseIPv6: []string{"2001:db8::2/128"},
-				},
 				VNIs:        []frr.L3VNIConfig{},
 				BFDProfiles: []frr.BFDProfile{},
-				Loglevel:    "debug",
+				Loglevel:    "debugging",
 			},
 			wantErr: false,
 		},
@@ -636,11 +2634,14 @@ func TestAPItoFRR(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			apiConfig := ApiConfigData{
-				NodeIndex:     tt.nodeIndex,
-				Underlays:     tt.underlays,
-				L3VNIs:        tt.vnis,
-				L3Passthrough: tt.l3Passthrough,
-				LogLevel:      tt.logLevel,
+				NodeIndex:      tt.nodeIndex,
+				Underlays:      tt.underlays,
+				L3VNIs:         tt.vnis,
+				L2VNIs:         tt.l2vnis,
+				L3Passthrough:  tt.l3Passthrough,
+				LogLevel:       tt.logLevel,
+				FRRLogLevel:    tt.frrLogLevel,
+				BGPListenLimit: tt.bgpListenLimit,
 			}
 			got, err := APItoFRR(apiConfig)
 			if (err != nil) != tt.wantErr {
@@ -653,3 +2654,55 @@ func TestAPItoFRR(t *testing.T) {
 		})
 	}
 }
+
+func TestDuplicateAddressDetectionLines(t *testing.T) {
+	const dadLine = "dup-addr-detection max-moves 10 time 300"
+	tests := []struct {
+		name     string
+		dad      *v1alpha1.DADConfig
+		wantLine bool
+	}{
+		{
+			name: "duplicate address detection set",
+			dad: &v1alpha1.DADConfig{
+				MaxMoves:      10,
+				DetectionTime: metav1.Duration{Duration: 5 * time.Minute},
+			},
+			wantLine: true,
+		},
+		{
+			name:     "duplicate address detection not set",
+			wantLine: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlay := v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN:          65000,
+					RouterIDCIDR: "10.0.0.0/24",
+					Neighbors:    []v1alpha1.Neighbor{{Address: "192.168.1.1", ASN: 65001}},
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR:                  "192.168.1.0/24",
+						DuplicateAddressDetection: tt.dad,
+					},
+				},
+			}
+			config, err := APItoFRR(ApiConfigData{Underlays: []v1alpha1.Underlay{underlay}})
+			if err != nil {
+				t.Fatalf("APItoFRR() unexpected error: %v", err)
+			}
+			generated, err := frr.GenerateConfig(context.Background(), &config)
+			if err != nil {
+				t.Fatalf("GenerateConfig() unexpected error: %v", err)
+			}
+			if got := strings.Contains(generated, dadLine); got != tt.wantLine {
+				t.Errorf("expected %q in the configuration to be %t, got:\n%s", dadLine, tt.wantLine, generated)
+			}
+			if !tt.wantLine && strings.Contains(generated, "dup-addr-detection") {
+				t.Errorf("expected no duplicate address detection line, got:\n%s", generated)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package conversion
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// snapshotItem is the part of a resource the configuration hash is
+// computed on. The rest of the metadata and the status are left out,
+// as they change without affecting the applied configuration.
+type snapshotItem struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Spec      any    `json:"spec"`
+}
+
+type configSnapshot struct {
+	NodeIndex          int            `json:"nodeIndex"`
+	UnderlayFromMultus bool           `json:"underlayFromMultus"`
+	LogLevel           string         `json:"logLevel"`
+	FRRLogLevel        string         `json:"frrLogLevel"`
+	Items              []snapshotItem `json:"items"`
+	// UnderlayMultusInterface, BGPListenLimit and Maintenance are left out
+	// when unset, not to change the hash of the existing configurations.
+	UnderlayMultusInterface string `json:"underlayMultusInterface,omitempty"`
+	BGPListenLimit          uint32 `json:"bgpListenLimit,omitempty"`
+	Maintenance             bool   `json:"maintenance,omitempty"`
+}
+
+// ConfigHash returns a hash of the given configuration, which does not
+// depend on the order the resources are listed in. Comparing the hash of
+// the last applied configuration with the one of the current resources
+// tells whether a reconcile is pending.
+func ConfigHash(config ApiConfigData) (string, error) {
+	snapshot := configSnapshot{
+		NodeIndex:          config.NodeIndex,
+		UnderlayFromMultus: config.UnderlayFromMultus,
+		LogLevel:           config.LogLevel,
+		FRRLogLevel:        config.FRRLogLevel,
+
+		UnderlayMultusInterface: config.UnderlayMultusInterface,
+		BGPListenLimit:          config.BGPListenLimit,
+		Maintenance:             config.Maintenance,
+	}
+	for _, u := range config.Underlays {
+		snapshot.Items = append(snapshot.Items, snapshotItem{"Underlay", u.Namespace, u.Name, u.Spec})
+	}
+	for _, v := range config.L3VNIs {
+		snapshot.Items = append(snapshot.Items, snapshotItem{"L3VNI", v.Namespace, v.Name, v.Spec})
+	}
+	for _, v := range config.L2VNIs {
+		snapshot.Items = append(snapshot.Items, snapshotItem{"L2VNI", v.Namespace, v.Name, v.Spec})
+	}
+	for _, p := range config.L3Passthrough {
+		snapshot.Items = append(snapshot.Items, snapshotItem{"L3Passthrough", p.Namespace, p.Name, p.Spec})
+	}
+	slices.SortFunc(snapshot.Items, func(a, b snapshotItem) int {
+		return cmp.Or(
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	// The json encoding sorts the map keys, so the hash is stable.
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the configuration: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
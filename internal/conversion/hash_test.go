@@ -0,0 +1,85 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package conversion
+
+import (
+	"testing"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigHash(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+			EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "100.65.0.0/24"},
+		},
+	}
+	red := v1alpha1.L3VNI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "red",
+			Namespace: "openperouter-system",
+			Labels:    map[string]string{"a": "1", "b": "2", "c": "3"},
+		},
+		Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100},
+	}
+	blue := v1alpha1.L3VNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"},
+		Spec:       v1alpha1.L3VNISpec{VRF: "blue", VNI: 200},
+	}
+	config := ApiConfigData{
+		NodeIndex: 1,
+		Underlays: []v1alpha1.Underlay{underlay},
+		L3VNIs:    []v1alpha1.L3VNI{red, blue},
+	}
+
+	redWithMetadata := *red.DeepCopy()
+	redWithMetadata.ResourceVersion = "42"
+	redWithMetadata.Labels = map[string]string{"c": "3", "b": "2", "a": "1"}
+	reordered := ApiConfigData{
+		NodeIndex: 1,
+		Underlays: []v1alpha1.Underlay{underlay},
+		L3VNIs:    []v1alpha1.L3VNI{blue, redWithMetadata},
+	}
+
+	redChanged := *red.DeepCopy()
+	redChanged.Spec.VNI = 101
+	changed := ApiConfigData{
+		NodeIndex: 1,
+		Underlays: []v1alpha1.Underlay{underlay},
+		L3VNIs:    []v1alpha1.L3VNI{redChanged, blue},
+	}
+
+	hash, err := ConfigHash(config)
+	if err != nil {
+		t.Fatalf("ConfigHash() unexpected error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, err := ConfigHash(config)
+		if err != nil {
+			t.Fatalf("ConfigHash() unexpected error: %v", err)
+		}
+		if again != hash {
+			t.Fatalf("expected a stable hash, got %s and %s", hash, again)
+		}
+	}
+
+	reorderedHash, err := ConfigHash(reordered)
+	if err != nil {
+		t.Fatalf("ConfigHash() unexpected error: %v", err)
+	}
+	if reorderedHash != hash {
+		t.Errorf("expected the same hash for the reordered configuration, got %s and %s", hash, reorderedHash)
+	}
+
+	changedHash, err := ConfigHash(changed)
+	if err != nil {
+		t.Fatalf("ConfigHash() unexpected error: %v", err)
+	}
+	if changedHash == hash {
+		t.Errorf("expected a different hash for the changed configuration, got %s", hash)
+	}
+}
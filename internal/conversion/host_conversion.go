@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/openperouter/openperouter/api/v1alpha1"
 	"github.com/openperouter/openperouter/internal/hostnetwork"
 	"github.com/openperouter/openperouter/internal/ipam"
+	"k8s.io/utils/ptr"
 )
 
 func APItoHostConfig(nodeIndex int, targetNS string, apiConfig ApiConfigData) (HostConfigData, error) {
@@ -34,8 +36,11 @@ func APItoHostConfig(nodeIndex int, targetNS string, apiConfig ApiConfigData) (H
 	res.Underlay = hostnetwork.UnderlayParams{
 		TargetNS: targetNS,
 	}
-	if len(underlay.Spec.Nics) > 0 {
+	switch {
+	case len(underlay.Spec.Nics) > 0:
 		res.Underlay.UnderlayInterface = underlay.Spec.Nics[0]
+	case apiConfig.UnderlayFromMultus:
+		res.Underlay.UnderlayInterface = apiConfig.UnderlayMultusInterface
 	}
 
 	if len(apiConfig.L3Passthrough) == 1 {
@@ -69,19 +74,42 @@ func APItoHostConfig(nodeIndex int, targetNS string, apiConfig ApiConfigData) (H
 	if err != nil {
 		return res, fmt.Errorf("failed to get vtep ip, cidr %s, nodeIntex %d", underlay.Spec.EVPN.VTEPCIDR, nodeIndex)
 	}
+	vtepIPv6, err := vtepIPv6(underlay.Spec.EVPN, nodeIndex)
+	if err != nil {
+		return res, err
+	}
+	vtepMAC, err := vtepMAC(underlay.Spec.EVPN, nodeIndex)
+	if err != nil {
+		return res, err
+	}
 	res.Underlay.EVPN = &hostnetwork.UnderlayEVPNParams{
-		VtepIP: vtepIP.String(),
+		VtepIP:   vtepIP.String(),
+		VtepIPv6: vtepIPv6,
 	}
 
 	for _, vni := range apiConfig.L3VNIs {
 		v := hostnetwork.L3VNIParams{
 			VNIParams: hostnetwork.VNIParams{
-				VRF:       vni.Spec.VRF,
-				TargetNS:  targetNS,
-				VTEPIP:    vtepIP.String(),
-				VNI:       int(vni.Spec.VNI),
-				VXLanPort: int(vni.Spec.VXLanPort),
+				VRF:              vni.Spec.VRF,
+				TargetNS:         targetNS,
+				VTEPIP:           vtepIP.String(),
+				VTEPMAC:          vtepMAC,
+				VNI:              int(vni.Spec.VNI),
+				VXLanPort:        int(vni.Spec.VXLanPort),
+				DSCP:             vniDSCP(underlay.Spec.EVPN, vni.Spec.DSCP),
+				TXChecksum:       underlay.Spec.EVPN.TXChecksum,
+				GRO:              underlay.Spec.EVPN.GRO,
+				GSO:              underlay.Spec.EVPN.GSO,
+				DeviceNamePrefix: apiConfig.DeviceNamePrefix,
 			},
+			RouterSVIAddress: ptr.Deref(vni.Spec.RouterSVIAddress, ""),
+		}
+		if vni.Spec.DisableConntrack {
+			cidrs, err := l3vniConntrackBypassCIDRs(vni)
+			if err != nil {
+				return res, fmt.Errorf("invalid conntrack bypass cidrs for l3vni %s: %w", vni.Name, err)
+			}
+			res.ConntrackBypassCIDRs = append(res.ConntrackBypassCIDRs, cidrs...)
 		}
 		if vni.Spec.HostSession == nil {
 			res.L3VNIs = append(res.L3VNIs, v)
@@ -107,22 +135,53 @@ func APItoHostConfig(nodeIndex int, targetNS string, apiConfig ApiConfigData) (H
 	for _, l2vni := range apiConfig.L2VNIs {
 		vni := hostnetwork.L2VNIParams{
 			VNIParams: hostnetwork.VNIParams{
-				VRF:       l2vni.VRFName(),
-				TargetNS:  targetNS,
-				VTEPIP:    vtepIP.String(),
-				VNI:       int(l2vni.Spec.VNI),
-				VXLanPort: int(l2vni.Spec.VXLanPort),
+				VRF:              l2vni.VRFName(),
+				TargetNS:         targetNS,
+				VTEPIP:           vtepIP.String(),
+				VTEPMAC:          vtepMAC,
+				VNI:              int(l2vni.Spec.VNI),
+				VXLanPort:        int(l2vni.Spec.VXLanPort),
+				DSCP:             vniDSCP(underlay.Spec.EVPN, l2vni.Spec.DSCP),
+				TXChecksum:       vniToggle(underlay.Spec.EVPN.TXChecksum, l2vni.Spec.TXChecksum),
+				GRO:              vniToggle(underlay.Spec.EVPN.GRO, l2vni.Spec.GRO),
+				GSO:              vniToggle(underlay.Spec.EVPN.GSO, l2vni.Spec.GSO),
+				DeviceNamePrefix: apiConfig.DeviceNamePrefix,
 			},
+			ManagePolicyRouting: l2vni.Spec.ManagePolicyRouting,
+		}
+		if l2vni.Spec.Learning != nil {
+			vni.Learning = *l2vni.Spec.Learning
 		}
-		if len(l2vni.Spec.L2GatewayIPs) > 0 {
+		if l2vni.Spec.MulticastGroup != nil {
+			vni.MulticastGroup = *l2vni.Spec.MulticastGroup
+		}
+		if len(l2vni.Spec.StaticVTEPs) > 0 {
+			vni.StaticVTEPs = make([]string, len(l2vni.Spec.StaticVTEPs))
+			copy(vni.StaticVTEPs, l2vni.Spec.StaticVTEPs)
+		}
+		if l2vni.Spec.MACAgeingTime != nil {
+			vni.MACAgeingTime = l2vni.Spec.MACAgeingTime.Duration
+		}
+		if l2vni.Spec.AdvertiseHostRoutes != nil {
+			vni.AdvertiseHostRoutes = *l2vni.Spec.AdvertiseHostRoutes
+		}
+		if len(l2vni.Spec.L2GatewayIPs) > 0 && assignsGateway(l2vni, nodeIndex, apiConfig.CentralizedGatewayIndex) {
 			vni.L2GatewayIPs = make([]string, len(l2vni.Spec.L2GatewayIPs))
 			copy(vni.L2GatewayIPs, l2vni.Spec.L2GatewayIPs)
 		}
+		if l2vni.Spec.DisableConntrack {
+			cidrs, err := conntrackBypassCIDRs(l2vni.Spec.L2GatewayIPs...)
+			if err != nil {
+				return res, fmt.Errorf("invalid l2gatewayips for l2vni %s: %w", l2vni.Name, err)
+			}
+			res.ConntrackBypassCIDRs = append(res.ConntrackBypassCIDRs, cidrs...)
+		}
 		if l2vni.Spec.HostMaster != nil {
 			vni.HostMaster = &hostnetwork.HostMaster{
-				Name:       l2vni.Spec.HostMaster.Name,
-				Type:       l2vni.Spec.HostMaster.Type,
-				AutoCreate: l2vni.Spec.HostMaster.AutoCreate,
+				Name:         l2vni.Spec.HostMaster.Name,
+				Type:         l2vni.Spec.HostMaster.Type,
+				AutoCreate:   l2vni.Spec.HostMaster.AutoCreate,
+				DatapathType: ptr.Deref(l2vni.Spec.HostMaster.DatapathType, ""),
 			}
 		}
 
@@ -132,6 +191,45 @@ func APItoHostConfig(nodeIndex int, targetNS string, apiConfig ApiConfigData) (H
 	return res, nil
 }
 
+// assignsGateway tells if the node with the given index assigns the
+// gateway ips of the given L2VNI, given the index of the node elected
+// to assign the centralized gateways.
+func assignsGateway(l2vni v1alpha1.L2VNI, nodeIndex, gatewayIndex int) bool {
+	if l2vni.Spec.GatewayMode != v1alpha1.CentralizedGateway {
+		return true
+	}
+	return nodeIndex == gatewayIndex
+}
+
+// l3vniConntrackBypassCIDRs returns the subnets whose traffic is not
+// tracked for the given L3VNI: the ones of its host session, if any,
+// and the listed ones.
+func l3vniConntrackBypassCIDRs(vni v1alpha1.L3VNI) ([]string, error) {
+	addresses := []string{}
+	if vni.Spec.HostSession != nil {
+		addresses = append(addresses, vni.Spec.HostSession.LocalCIDR.IPv4, vni.Spec.HostSession.LocalCIDR.IPv6)
+	}
+	addresses = append(addresses, vni.Spec.ConntrackBypassCIDRs...)
+	return conntrackBypassCIDRs(addresses...)
+}
+
+// conntrackBypassCIDRs returns the subnets of the given addresses, in
+// CIDR notation, skipping the empty ones.
+func conntrackBypassCIDRs(addresses ...string) ([]string, error) {
+	res := []string{}
+	for _, a := range addresses {
+		if a == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ipNet.String())
+	}
+	return res, nil
+}
+
 // ipNetToString returns the string representation of the IPNet, or empty string if IP is nil
 func ipNetToString(ipNet net.IPNet) string {
 	if ipNet.IP == nil {
@@ -139,3 +237,71 @@ func ipNetToString(ipNet net.IPNet) string {
 	}
 	return ipNet.String()
 }
+
+// vniDSCP returns the DSCP to be set on the encapsulated packets of a VNI,
+// falling back to the one of the EVPN configuration if the VNI has none.
+func vniDSCP(evpn *v1alpha1.EVPNConfig, dscp *uint8) int {
+	if dscp != nil {
+		return int(*dscp)
+	}
+	if evpn.DSCP != nil {
+		return int(*evpn.DSCP)
+	}
+	return 0
+}
+
+// vniToggle returns the value of a toggle of the vxlan of a VNI, falling
+// back to the one of the EVPN configuration if the VNI has none.
+func vniToggle(evpn, vni *bool) *bool {
+	if vni != nil {
+		return vni
+	}
+	return evpn
+}
+
+// vtepIPv6 returns the ipv6 address of the VTEP on the ith node, or an
+// empty string if the EVPN configuration has no ipv6 VTEP CIDR.
+func vtepIPv6(evpn *v1alpha1.EVPNConfig, nodeIndex int) (string, error) {
+	if evpn.VTEPCIDRv6 == nil {
+		return "", nil
+	}
+	ip, err := ipam.VTEPIp(*evpn.VTEPCIDRv6, nodeIndex)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ipv6 vtep ip, cidr %s, nodeIndex %d: %w", *evpn.VTEPCIDRv6, nodeIndex, err)
+	}
+	return ip.String(), nil
+}
+
+// vtepMAC returns the MAC address of the VTEP on the ith node, or an
+// empty string if the EVPN configuration has no base VTEP MAC.
+func vtepMAC(evpn *v1alpha1.EVPNConfig, nodeIndex int) (string, error) {
+	if evpn.VTEPMAC == nil {
+		return "", nil
+	}
+	mac, err := ipam.VTEPMAC(*evpn.VTEPMAC, nodeIndex)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vtep mac, base %s, nodeIndex %d: %w", *evpn.VTEPMAC, nodeIndex, err)
+	}
+	return mac.String(), nil
+}
+
+// HostSessionIPs returns the addresses of the host side of the session of the
+// given L3VNI on the ith node, which act as the gateway towards the host.
+// It returns no addresses if the L3VNI has no host session.
+func HostSessionIPs(vni v1alpha1.L3VNI, nodeIndex int) ([]string, error) {
+	if vni.Spec.HostSession == nil {
+		return nil, nil
+	}
+	vethIPs, err := ipam.VethIPsFromPool(vni.Spec.HostSession.LocalCIDR.IPv4, vni.Spec.HostSession.LocalCIDR.IPv6, nodeIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get veth ips, cidr %v, nodeIndex %d: %w", vni.Spec.HostSession.LocalCIDR, nodeIndex, err)
+	}
+	res := []string{}
+	for _, ip := range []net.IPNet{vethIPs.Ipv4.HostSide, vethIPs.Ipv6.HostSide} {
+		if ip.IP == nil {
+			continue
+		}
+		res = append(res, ip.IP.String())
+	}
+	return res, nil
+}
@@ -5,9 +5,12 @@ package conversion
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	"github.com/openperouter/openperouter/internal/hostnetwork"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 func TestAPItoHostConfig(t *testing.T) {
@@ -16,6 +19,8 @@ func TestAPItoHostConfig(t *testing.T) {
 		nodeIndex          int
 		targetNS           string
 		underlayFromMultus bool
+		multusInterface    string
+		gatewayIndex       int
 		underlays          []v1alpha1.Underlay
 		vnis               []v1alpha1.L3VNI
 		l2vnis             []v1alpha1.L2VNI
@@ -243,6 +248,537 @@ func TestAPItoHostConfig(t *testing.T) {
 			wantPassthrough: nil,
 			wantErr:         false,
 		},
+		{
+			name:      "l2 vni with centralized gateway on the elected node",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0"}, L2GatewayIPs: []string{"192.168.100.1/24"}, GatewayMode: v1alpha1.CentralizedGateway}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					L2GatewayIPs: []string{"192.168.100.1/24"},
+					HostMaster:   &hostnetwork.HostMaster{Name: "br0"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with centralized gateway on another node",
+			nodeIndex: 1,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0"}, L2GatewayIPs: []string{"192.168.100.1/24"}, GatewayMode: v1alpha1.CentralizedGateway}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.1/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.1/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					HostMaster: &hostnetwork.HostMaster{Name: "br0"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:         "l2 vni with centralized gateway elected on a node other than the first",
+			nodeIndex:    1,
+			gatewayIndex: 1,
+			targetNS:     "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0"}, L2GatewayIPs: []string{"192.168.100.1/24"}, GatewayMode: v1alpha1.CentralizedGateway}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.1/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.1/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					L2GatewayIPs: []string{"192.168.100.1/24"},
+					HostMaster:   &hostnetwork.HostMaster{Name: "br0"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with managed policy routing",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0", Type: "linux-bridge"}, L2GatewayIPs: []string{"192.168.100.1/24"}, ManagePolicyRouting: true}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					L2GatewayIPs:        []string{"192.168.100.1/24"},
+					HostMaster:          &hostnetwork.HostMaster{Name: "br0", Type: "linux-bridge"},
+					ManagePolicyRouting: true,
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with learning",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0", Type: "linux-bridge"}, Learning: ptr.To(true), MulticastGroup: ptr.To("239.1.1.1")}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:       "namespace",
+						VTEPIP:         "10.0.0.0/32",
+						VNI:            201,
+						VXLanPort:      4789,
+						Learning:       true,
+						MulticastGroup: "239.1.1.1",
+					},
+					HostMaster: &hostnetwork.HostMaster{Name: "br0", Type: "linux-bridge"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with static vteps",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Name: "br0", Type: "linux-bridge"}, Learning: ptr.To(true), StaticVTEPs: []string{"192.168.10.1", "192.168.10.2"}}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:    "namespace",
+						VTEPIP:      "10.0.0.0/32",
+						VNI:         201,
+						VXLanPort:   4789,
+						Learning:    true,
+						StaticVTEPs: []string{"192.168.10.1", "192.168.10.2"},
+					},
+					HostMaster: &hostnetwork.HostMaster{Name: "br0", Type: "linux-bridge"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with mac ageing time",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, MACAgeingTime: &metav1.Duration{Duration: 30 * time.Minute}}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					MACAgeingTime: 30 * time.Minute,
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni with an autocreated ovs bridge with a datapath type",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, HostMaster: &v1alpha1.HostMaster{Type: "ovs-bridge", AutoCreate: true, DatapathType: ptr.To("netdev")}}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					HostMaster: &hostnetwork.HostMaster{Type: "ovs-bridge", AutoCreate: true, DatapathType: "netdev"},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "l2 vni advertising host routes",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, VRF: ptr.To("red"), L2GatewayIPs: []string{"192.168.1.1/24"}, AdvertiseHostRoutes: ptr.To(true)}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:       "red",
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+					},
+					L2GatewayIPs:        []string{"192.168.1.1/24"},
+					AdvertiseHostRoutes: true,
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "vnis with dscp",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24", DSCP: ptr.To(uint8(10))}}},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789}},
+			},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, DSCP: ptr.To(uint8(46))}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:       "red",
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       100,
+						VXLanPort: 4789,
+						DSCP:      10,
+					},
+				},
+			},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       201,
+						VXLanPort: 4789,
+						DSCP:      46,
+					},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "vnis with checksum and offloads",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24", TXChecksum: ptr.To(false), GRO: ptr.To(true)}}},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789}},
+			},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 201, VXLanPort: 4789, TXChecksum: ptr.To(true), GSO: ptr.To(false)}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:        "red",
+						TargetNS:   "namespace",
+						VTEPIP:     "10.0.0.0/32",
+						VNI:        100,
+						VXLanPort:  4789,
+						TXChecksum: ptr.To(false),
+						GRO:        ptr.To(true),
+					},
+				},
+			},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:   "namespace",
+						VTEPIP:     "10.0.0.0/32",
+						VNI:        201,
+						VXLanPort:  4789,
+						TXChecksum: ptr.To(true),
+						GRO:        ptr.To(true),
+						GSO:        ptr.To(false),
+					},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "vni with router svi address",
+			nodeIndex: 0,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789, RouterSVIAddress: ptr.To("10.250.0.1/32")}},
+			},
+			l2vnis:        []v1alpha1.L2VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:       "red",
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.0/32",
+						VNI:       100,
+						VXLanPort: 4789,
+					},
+					RouterSVIAddress: "10.250.0.1/32",
+				},
+			},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "dual stack vtep",
+			nodeIndex: 2,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24", VTEPCIDRv6: ptr.To("fd00:1::/64")}}},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789}},
+			},
+			l2vnis:        []v1alpha1.L2VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP:   "10.0.0.2/32",
+					VtepIPv6: "fd00:1::2/128",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:       "red",
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.2/32",
+						VNI:       100,
+						VXLanPort: 4789,
+					},
+				},
+			},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
+		{
+			name:      "vtep mac",
+			nodeIndex: 3,
+			targetNS:  "namespace",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{"eth0"}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24", VTEPMAC: ptr.To("02:00:00:00:00:fe")}}},
+			},
+			vnis: []v1alpha1.L3VNI{
+				{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100, VXLanPort: 4789}},
+			},
+			l2vnis: []v1alpha1.L2VNI{
+				{Spec: v1alpha1.L2VNISpec{VNI: 200, VXLanPort: 4789}},
+			},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "eth0",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.3/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						VRF:       "red",
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.3/32",
+						VTEPMAC:   "02:00:00:00:01:01",
+						VNI:       100,
+						VXLanPort: 4789,
+					},
+				},
+			},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{
+				{
+					VNIParams: hostnetwork.VNIParams{
+						TargetNS:  "namespace",
+						VTEPIP:    "10.0.0.3/32",
+						VTEPMAC:   "02:00:00:00:01:01",
+						VNI:       200,
+						VXLanPort: 4789,
+					},
+				},
+			},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
 		{
 			name:      "l3 vni without hostsession",
 			nodeIndex: 0,
@@ -359,6 +895,30 @@ func TestAPItoHostConfig(t *testing.T) {
 			wantPassthrough: nil,
 			wantErr:         false,
 		},
+		{
+			name:               "underlay from a multus network",
+			nodeIndex:          0,
+			targetNS:           "namespace",
+			underlayFromMultus: true,
+			multusInterface:    "net1",
+			underlays: []v1alpha1.Underlay{
+				{Spec: v1alpha1.UnderlaySpec{Nics: []string{}, EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"}}},
+			},
+			vnis:          []v1alpha1.L3VNI{},
+			l2vnis:        []v1alpha1.L2VNI{},
+			l3Passthrough: []v1alpha1.L3Passthrough{},
+			wantUnderlay: hostnetwork.UnderlayParams{
+				UnderlayInterface: "net1",
+				TargetNS:          "namespace",
+				EVPN: &hostnetwork.UnderlayEVPNParams{
+					VtepIP: "10.0.0.0/32",
+				},
+			},
+			wantL3VNIParams: []hostnetwork.L3VNIParams{},
+			wantL2VNIParams: []hostnetwork.L2VNIParams{},
+			wantPassthrough: nil,
+			wantErr:         false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -370,6 +930,9 @@ func TestAPItoHostConfig(t *testing.T) {
 				L3VNIs:             tt.vnis,
 				L2VNIs:             tt.l2vnis,
 				L3Passthrough:      tt.l3Passthrough,
+
+				UnderlayMultusInterface: tt.multusInterface,
+				CentralizedGatewayIndex: tt.gatewayIndex,
 			}
 
 			gotHostConfig, err := APItoHostConfig(tt.nodeIndex, tt.targetNS, apiConfig)
@@ -392,3 +955,58 @@ func TestAPItoHostConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestHostSessionIPs(t *testing.T) {
+	tests := []struct {
+		name      string
+		localCIDR *v1alpha1.LocalCIDRConfig
+		nodeIndex int
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "no host session",
+			nodeIndex: 0,
+			want:      nil,
+		},
+		{
+			name:      "ipv4 on the first node",
+			localCIDR: &v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+			nodeIndex: 0,
+			want:      []string{"192.169.10.2"},
+		},
+		{
+			name:      "ipv4 on another node",
+			localCIDR: &v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+			nodeIndex: 3,
+			want:      []string{"192.169.10.5"},
+		},
+		{
+			name:      "dual stack",
+			localCIDR: &v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24", IPv6: "2001:db8:1::/64"},
+			nodeIndex: 1,
+			want:      []string{"192.169.10.3", "2001:db8:1::3"},
+		},
+		{
+			name:      "invalid cidr",
+			localCIDR: &v1alpha1.LocalCIDRConfig{IPv4: "notacidr"},
+			nodeIndex: 0,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vni := v1alpha1.L3VNI{Spec: v1alpha1.L3VNISpec{VRF: "red", VNI: 100}}
+			if tt.localCIDR != nil {
+				vni.Spec.HostSession = &v1alpha1.HostSession{ASN: 65000, HostASN: 65001, LocalCIDR: *tt.localCIDR}
+			}
+			got, err := HostSessionIPs(vni, tt.nodeIndex)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HostSessionIPs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HostSessionIPs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
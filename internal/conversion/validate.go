@@ -0,0 +1,228 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package conversion
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/ipfamily"
+	"k8s.io/utils/ptr"
+)
+
+// ValidateAll validates the whole set of resources the router is configured
+// with. It runs the validators of each kind and the checks across kinds in a
+// fixed order, so that the admission webhooks and the controller validate
+// the configuration in the same way, and returns the first error found.
+func ValidateAll(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI, l3passthroughs []v1alpha1.L3Passthrough) error {
+	for _, v := range validations(underlays, l3vnis, l2vnis, l3passthroughs) {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("failed to validate %s: %w", v.what, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAllJoined is the variant of ValidateAll running all the
+// validators regardless of their failures, and returning all the errors
+// found joined together, so that they can all be fixed at once. Each
+// validator still stops at the first problem of what it validates.
+func ValidateAllJoined(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI, l3passthroughs []v1alpha1.L3Passthrough) error {
+	errs := []error{}
+	for _, v := range validations(underlays, l3vnis, l2vnis, l3passthroughs) {
+		if err := v.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to validate %s: %w", v.what, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validation is a validator of the resources, together with
+// what it validates.
+type validation struct {
+	what     string
+	validate func() error
+}
+
+func validations(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI, l3passthroughs []v1alpha1.L3Passthrough) []validation {
+	return []validation{
+		{"underlays", func() error { return ValidateUnderlays(underlays) }},
+		{"l3vnis", func() error { return ValidateL3VNIs(l3vnis) }},
+		{"l2vnis", func() error { return ValidateL2VNIsWithL3VNIs(l2vnis, l3vnis) }},
+		{"l3passthroughs", func() error { return ValidatePassthrough(l3passthroughs) }},
+		{"host sessions", func() error { return ValidateHostSessions(l3vnis, l3passthroughs) }},
+		{"vnis", func() error { return validateVNIsAcrossKinds(l3vnis, l2vnis) }},
+		{"vnis", func() error { return validateVNIsRequireEVPN(underlays, l3vnis, l2vnis) }},
+		{"vnis", func() error { return validateAdvertisedVNIs(underlays, l3vnis) }},
+		{"vnis", func() error { return validateRouteTargets(underlays, l3vnis, l2vnis) }},
+		{"vnis", func() error { return validateTXChecksum(underlays, l2vnis) }},
+		{"host interfaces", func() error { return validateHostInterfaces(hostInterfaceClaims(underlays, l3passthroughs)) }},
+	}
+}
+
+// hostInterfaceClaim is a host interface required by a resource.
+type hostInterfaceClaim struct {
+	// owner is the resource requiring the interface, in the form kind/name.
+	owner string
+	nic   string
+}
+
+// hostInterfaceClaims returns the host interfaces required by the given
+// resources. Only the underlay claims interfaces today, the kinds requiring
+// an interface of the host must add their claims here.
+func hostInterfaceClaims(underlays []v1alpha1.Underlay, _ []v1alpha1.L3Passthrough) []hostInterfaceClaim {
+	res := []hostInterfaceClaim{}
+	for _, underlay := range underlays {
+		for _, nic := range underlay.Spec.Nics {
+			res = append(res, hostInterfaceClaim{owner: "underlay/" + underlay.Name, nic: nic})
+		}
+	}
+	return res
+}
+
+// validateHostInterfaces checks that each host interface is claimed
+// only once, as it is moved to the router namespace by its owner.
+func validateHostInterfaces(claims []hostInterfaceClaim) error {
+	owners := map[string]string{}
+	for _, c := range claims {
+		if owner, ok := owners[c.nic]; ok {
+			return fmt.Errorf("interface %s is used by both %s and %s", c.nic, owner, c.owner)
+		}
+		owners[c.nic] = c.owner
+	}
+	return nil
+}
+
+// validateVNIsRequireEVPN checks that VNIs are defined only together with an
+// underlay with EVPN enabled. An underlay without EVPN is still allowed with
+// passthroughs only.
+func validateVNIsRequireEVPN(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI) error {
+	if len(l3vnis) == 0 && len(l2vnis) == 0 {
+		return nil
+	}
+	for _, underlay := range underlays {
+		if underlay.Spec.EVPN == nil {
+			return fmt.Errorf("underlay %s has no evpn configuration, required by the l3vnis and l2vnis", underlay.Name)
+		}
+	}
+	return nil
+}
+
+// maxExplicitVNI is the highest VNI that can be listed explicitly
+// in the configuration of the router, as VNIs are 24 bits long.
+const maxExplicitVNI = 1<<24 - 1
+
+// validateAdvertisedVNIs checks that the VNIs the advertisement of an
+// underlay is restricted to are listed only when it does not advertise all
+// the VNIs, and that each of them is the VNI of an L2VNI, as the routes of
+// the L3VNIs are not restricted.
+func validateAdvertisedVNIs(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI) error {
+	for _, underlay := range underlays {
+		if underlay.Spec.EVPN == nil {
+			continue
+		}
+		advertised := underlay.Spec.EVPN.AdvertisedVNIs
+		if ptr.Deref(underlay.Spec.EVPN.AdvertiseAllVNI, true) {
+			if len(advertised) > 0 {
+				return fmt.Errorf("underlay %s lists the advertised vnis %v, but advertises all the vnis", underlay.Name, advertised)
+			}
+			continue
+		}
+		seen := map[uint32]bool{}
+		for _, vni := range advertised {
+			if vni == 0 || vni > maxExplicitVNI {
+				return fmt.Errorf("advertised vni %d of underlay %s must be between 1 and %d", vni, underlay.Name, maxExplicitVNI)
+			}
+			if seen[vni] {
+				return fmt.Errorf("advertised vni %d of underlay %s is listed more than once", vni, underlay.Name)
+			}
+			seen[vni] = true
+		}
+		for _, l3vni := range l3vnis {
+			if seen[l3vni.Spec.VNI] {
+				return fmt.Errorf("advertised vni %d of underlay %s belongs to l3vni %s, only the vnis of the l2vnis can be listed",
+					l3vni.Spec.VNI, underlay.Name, l3vni.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// maxTwoBytesASN is the highest ASN a route target with a VNI
+// above 65535 can be derived from, as a route target carries
+// either a two bytes ASN or a two bytes local value.
+const maxTwoBytesASN = 1<<16 - 1
+
+// validateRouteTargets checks that, when the underlay derives the route
+// targets of the VNIs from a fabric wide ASN, the ASN is valid and each
+// VNI fits in the route target derived from it.
+func validateRouteTargets(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI) error {
+	for _, underlay := range underlays {
+		if underlay.Spec.EVPN == nil || underlay.Spec.EVPN.RTAutoDeriveASN == nil {
+			continue
+		}
+		asn := *underlay.Spec.EVPN.RTAutoDeriveASN
+		if asn == 0 {
+			return fmt.Errorf("invalid rtautoderiveasn for underlay %s: must be greater than 0", underlay.Name)
+		}
+		vnis := []uint32{}
+		for _, l3vni := range l3vnis {
+			vnis = append(vnis, l3vni.Spec.VNI)
+		}
+		for _, l2vni := range l2vnis {
+			vnis = append(vnis, l2vni.Spec.VNI)
+		}
+		for _, vni := range vnis {
+			if vni == 0 || vni > maxExplicitVNI {
+				return fmt.Errorf("vni %d can't be part of a route target derived by underlay %s, must be between 1 and %d",
+					vni, underlay.Name, maxExplicitVNI)
+			}
+			if asn > maxTwoBytesASN && vni > maxTwoBytesASN {
+				return fmt.Errorf("vni %d can't be part of a route target derived from the four bytes rtautoderiveasn %d of underlay %s, must be at most %d",
+					vni, asn, underlay.Name, maxTwoBytesASN)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTXChecksum checks that the UDP checksum of the VXLan packets is
+// not disabled on an IPv6 VTEP, neither by the underlay nor by a VNI, as
+// the remote VTEPs drop the IPv6 UDP packets with a zero checksum.
+func validateTXChecksum(underlays []v1alpha1.Underlay, l2vnis []v1alpha1.L2VNI) error {
+	for _, underlay := range underlays {
+		if underlay.Spec.EVPN == nil {
+			continue
+		}
+		_, vtepCIDR, err := net.ParseCIDR(underlay.Spec.EVPN.VTEPCIDR)
+		if err != nil || ipfamily.ForCIDR(vtepCIDR) != ipfamily.IPv6 {
+			continue
+		}
+		if !ptr.Deref(underlay.Spec.EVPN.TXChecksum, true) {
+			return fmt.Errorf("underlay %s can't disable txchecksum on the ipv6 vtep cidr %s", underlay.Name, underlay.Spec.EVPN.VTEPCIDR)
+		}
+		for _, l2vni := range l2vnis {
+			if !ptr.Deref(l2vni.Spec.TXChecksum, true) {
+				return fmt.Errorf("l2vni %s can't disable txchecksum on the ipv6 vtep cidr %s of underlay %s", l2vni.Name, underlay.Spec.EVPN.VTEPCIDR, underlay.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateVNIsAcrossKinds checks that the same VNI is not used
+// by both an L3VNI and an L2VNI.
+func validateVNIsAcrossKinds(l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI) error {
+	l3VNIs := map[uint32]string{}
+	for _, l3vni := range l3vnis {
+		l3VNIs[l3vni.Spec.VNI] = l3vni.Name
+	}
+	for _, l2vni := range l2vnis {
+		if l3vni, ok := l3VNIs[l2vni.Spec.VNI]; ok {
+			return fmt.Errorf("vni %d is used by both l3vni %s and l2vni %s", l2vni.Spec.VNI, l3vni, l2vni.Name)
+		}
+	}
+	return nil
+}
@@ -4,10 +4,16 @@ package conversion
 
 import (
 	"fmt"
+	"net"
 
 	v1alpha1 "github.com/openperouter/openperouter/api/v1alpha1"
+	"k8s.io/utils/ptr"
 )
 
+// maxAllowASIn is the highest number of occurrences of the local
+// AS number FRR can accept in the AS path of the received routes.
+const maxAllowASIn = 10
+
 type hostSessionInfo struct {
 	v1alpha1.HostSession
 	name string
@@ -46,6 +52,47 @@ func ValidateHostSessions(l3VNIs []v1alpha1.L3VNI, l3Passthrough []v1alpha1.L3Pa
 		if s.LocalCIDR.IPv4 == "" && s.LocalCIDR.IPv6 == "" {
 			return fmt.Errorf("at least one local CIDR (IPv4 or IPv6) must be provided for vni %s", s.name)
 		}
+		if ptr.Deref(s.NextHopSelfForce, false) && !ptr.Deref(s.NextHopSelf, false) {
+			return fmt.Errorf("%s nexthopselfforce requires nexthopself to be enabled", s.name)
+		}
+		if s.DynamicPeers && s.HostASN == 0 {
+			return fmt.Errorf("%s dynamicpeers requires hostasn to be set", s.name)
+		}
+		if err := validateSendCommunity(s.SendCommunity); err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		if err := validateDescription(s.Description); err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		if err := validateConnectTime(s.ConnectTime); err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		if err := validateHostAdvertise(s.HostAdvertise); err != nil {
+			return fmt.Errorf("%s invalid hostadvertise: %w", s.name, err)
+		}
+		if s.AllowASIn != nil && (*s.AllowASIn < 1 || *s.AllowASIn > maxAllowASIn) {
+			return fmt.Errorf("%s allowasin %d must be between 1 and %d", s.name, *s.AllowASIn, maxAllowASIn)
+		}
+	}
+	return nil
+}
+
+// validateHostAdvertise checks that the prefixes advertised
+// to the host are valid and unique.
+func validateHostAdvertise(prefixes []string) error {
+	advertised := map[string]struct{}{}
+	for _, p := range prefixes {
+		ip, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid prefix %s: %w", p, err)
+		}
+		if !ip.Equal(ipNet.IP) {
+			return fmt.Errorf("prefix %s has host bits set, expected %s", p, ipNet.String())
+		}
+		if _, ok := advertised[ipNet.String()]; ok {
+			return fmt.Errorf("prefix %s is advertised more than once", p)
+		}
+		advertised[ipNet.String()] = struct{}{}
 	}
 	return nil
 }
@@ -4,9 +4,11 @@ package conversion
 
 import (
 	"testing"
+	"time"
 
 	v1alpha1 "github.com/openperouter/openperouter/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 func TestValidateHostSessions(t *testing.T) {
@@ -238,6 +240,196 @@ func TestValidateHostSessions(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "next hop self force without next hop self",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough1"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, NextHopSelfForce: ptr.To(true)},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "next hop self force with next hop self",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, NextHopSelf: ptr.To(true), NextHopSelfForce: ptr.To(true)},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dynamic peers with host asn",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, DynamicPeers: true},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dynamic peers without host asn",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough1"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{ASN: 65001, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, DynamicPeers: true},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "host session sending standard communities",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, SendCommunity: ptr.To(v1alpha1.SendCommunityStandard)},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "host session with invalid send community",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough1"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, SendCommunity: ptr.To("both")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "host session with invalid description",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, Description: ptr.To("host\tone")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "host session with connect time",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, ConnectTime: &metav1.Duration{Duration: 10 * time.Second}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "host session with connect time below one second",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, ConnectTime: &metav1.Duration{Duration: 500 * time.Millisecond}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "host session with prefixes to advertise",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, HostAdvertise: []string{"10.0.0.0/24", "2001:db8::/64"}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "host session with an invalid prefix to advertise",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, HostAdvertise: []string{"10.0.0.1/24"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "passthrough host session with a duplicate prefix to advertise",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, HostAdvertise: []string{"10.0.0.0/24", "10.0.0.0/24"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "host session allowing the local as in",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, AllowASIn: ptr.To[uint8](3)},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "host session allowing the local as in zero times",
+			l3VNIs: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:         1001,
+						HostSession: &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, AllowASIn: ptr.To[uint8](0)},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "passthrough host session allowing the local as in too many times",
+			l3Passthrough: []v1alpha1.L3Passthrough{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "passthrough"},
+					Spec: v1alpha1.L3PassthroughSpec{
+						HostSession: v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24"}, AllowASIn: ptr.To[uint8](11)},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
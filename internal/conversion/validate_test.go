@@ -0,0 +1,386 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package conversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateAll(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+			EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "100.65.0.0/24"},
+		},
+	}
+	underlayWithAdvertisedVNIs := func(advertiseAll bool, vnis ...uint32) v1alpha1.Underlay {
+		return v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{
+					VTEPCIDR:        "100.65.0.0/24",
+					AdvertiseAllVNI: ptr.To(advertiseAll),
+					AdvertisedVNIs:  vnis,
+				},
+			},
+		}
+	}
+	underlayWithRTASN := func(asn uint32) v1alpha1.Underlay {
+		return v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{
+					VTEPCIDR:        "100.65.0.0/24",
+					RTAutoDeriveASN: ptr.To(asn),
+				},
+			},
+		}
+	}
+	underlayWithTXChecksum := func(vtepCIDR string, txChecksum *bool) v1alpha1.Underlay {
+		return v1alpha1.Underlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+			Spec: v1alpha1.UnderlaySpec{
+				ASN:  65000,
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{
+					VTEPCIDR:   vtepCIDR,
+					TXChecksum: txChecksum,
+				},
+			},
+		}
+	}
+	underlayWithoutEVPN := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+		},
+	}
+	l3vnis := []v1alpha1.L3VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "red"},
+			Spec: v1alpha1.L3VNISpec{
+				VRF: "red",
+				VNI: 100,
+			},
+		},
+	}
+	passthroughs := []v1alpha1.L3Passthrough{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "passthrough"},
+			Spec: v1alpha1.L3PassthroughSpec{
+				HostSession: v1alpha1.HostSession{
+					ASN:       65000,
+					HostASN:   65001,
+					LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		underlays      []v1alpha1.Underlay
+		l3vnis         []v1alpha1.L3VNI
+		l2vnis         []v1alpha1.L2VNI
+		l3passthroughs []v1alpha1.L3Passthrough
+		wantErr        bool
+	}{
+		{
+			name:      "valid configuration",
+			underlays: []v1alpha1.Underlay{underlay},
+			l3vnis:    l3vnis,
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red110"},
+					Spec: v1alpha1.L2VNISpec{
+						VRF: ptr.To("red"),
+						VNI: 110,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "l2vni with the same vni of an l3vni",
+			underlays: []v1alpha1.Underlay{underlay},
+			l3vnis:    l3vnis,
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red100"},
+					Spec: v1alpha1.L2VNISpec{
+						VRF: ptr.To("red"),
+						VNI: 100,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:           "passthrough with an underlay without evpn",
+			underlays:      []v1alpha1.Underlay{underlayWithoutEVPN},
+			l3passthroughs: passthroughs,
+			wantErr:        false,
+		},
+		{
+			name:      "l3vni with an underlay without evpn",
+			underlays: []v1alpha1.Underlay{underlayWithoutEVPN},
+			l3vnis:    l3vnis,
+			wantErr:   true,
+		},
+		{
+			name:      "l2vni with an underlay advertising only the listed vnis",
+			underlays: []v1alpha1.Underlay{underlayWithAdvertisedVNIs(false, 110)},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "green"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 120},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "advertised vnis listed while advertising all the vnis",
+			underlays: []v1alpha1.Underlay{underlayWithAdvertisedVNIs(true, 110)},
+			wantErr:   true,
+		},
+		{
+			name:      "advertised vni out of range",
+			underlays: []v1alpha1.Underlay{underlayWithAdvertisedVNIs(false, 1<<24)},
+			wantErr:   true,
+		},
+		{
+			name:      "advertised vni listed twice",
+			underlays: []v1alpha1.Underlay{underlayWithAdvertisedVNIs(false, 110, 110)},
+			wantErr:   true,
+		},
+		{
+			name:      "advertised vni of an l3vni",
+			underlays: []v1alpha1.Underlay{underlayWithAdvertisedVNIs(false, l3vnis[0].Spec.VNI)},
+			l3vnis:    l3vnis,
+			wantErr:   true,
+		},
+		{
+			name:      "vnis with route targets derived from a fabric asn",
+			underlays: []v1alpha1.Underlay{underlayWithRTASN(65100)},
+			l3vnis:    l3vnis,
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 100000},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "zero rtautoderiveasn",
+			underlays: []v1alpha1.Underlay{underlayWithRTASN(0)},
+			l3vnis:    l3vnis,
+			wantErr:   true,
+		},
+		{
+			name:      "l2vni out of the route target range",
+			underlays: []v1alpha1.Underlay{underlayWithRTASN(65100)},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 1 << 24},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "vnis with route targets derived from a four bytes fabric asn",
+			underlays: []v1alpha1.Underlay{underlayWithRTASN(4200000000)},
+			l3vnis:    l3vnis,
+			wantErr:   false,
+		},
+		{
+			name:      "l2vni too big for a four bytes rtautoderiveasn",
+			underlays: []v1alpha1.Underlay{underlayWithRTASN(4200000000)},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 100000},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "txchecksum disabled on an ipv4 vtep",
+			underlays: []v1alpha1.Underlay{underlayWithTXChecksum("100.65.0.0/24", ptr.To(false))},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110, GRO: ptr.To(false), GSO: ptr.To(false)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "txchecksum disabled on an ipv6 vtep",
+			underlays: []v1alpha1.Underlay{underlayWithTXChecksum("2001:db8:65::/64", ptr.To(false))},
+			l3vnis:    l3vnis,
+			wantErr:   true,
+		},
+		{
+			name:      "l2vni disabling txchecksum on an ipv6 vtep",
+			underlays: []v1alpha1.Underlay{underlayWithTXChecksum("2001:db8:65::/64", nil)},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 110, TXChecksum: ptr.To(false)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "l2vni out of the vnis range with an underlay advertising all the vnis",
+			underlays: []v1alpha1.Underlay{underlay},
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 1 << 24},
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAll(tt.underlays, tt.l3vnis, tt.l2vnis, tt.l3passthroughs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			err = ValidateAllJoined(tt.underlays, tt.l3vnis, tt.l2vnis, tt.l3passthroughs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAllJoined() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAllJoined(t *testing.T) {
+	underlays := []v1alpha1.Underlay{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+			Spec: v1alpha1.UnderlaySpec{
+				Nics: []string{"eth0"},
+				EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "100.65.0.0/24"},
+			},
+		},
+	}
+	l3vnis := []v1alpha1.L3VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "red"},
+			Spec: v1alpha1.L3VNISpec{
+				VRF: "red",
+				VNI: 100,
+			},
+		},
+	}
+	l2vnis := []v1alpha1.L2VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 100},
+		},
+	}
+	passthroughs := []v1alpha1.L3Passthrough{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "passthrough"},
+			Spec: v1alpha1.L3PassthroughSpec{
+				HostSession: v1alpha1.HostSession{
+					ASN:       65000,
+					HostASN:   65000,
+					LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+				},
+			},
+		},
+	}
+
+	err := ValidateAllJoined(underlays, l3vnis, l2vnis, passthroughs)
+	if err == nil {
+		t.Fatalf("ValidateAllJoined() expected error, got nil")
+	}
+	for _, want := range []string{
+		"failed to validate underlays: underlay underlay must have a valid ASN",
+		"failed to validate host sessions: l3passthrough passthrough local ASN 65000 must be different from remote ASN 65000",
+		"failed to validate vnis: vni 100 is used by both l3vni red and l2vni blue",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateAllJoined() error %q does not contain %q", err, want)
+		}
+	}
+
+	err = ValidateAll(underlays, l3vnis, l2vnis, passthroughs)
+	if err == nil || strings.Contains(err.Error(), "\n") {
+		t.Errorf("ValidateAll() expected a single error, got %v", err)
+	}
+}
+
+func TestValidateHostInterfaces(t *testing.T) {
+	underlay := v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		claims  []hostInterfaceClaim
+		wantErr bool
+	}{
+		{
+			name:   "underlay only",
+			claims: hostInterfaceClaims([]v1alpha1.Underlay{underlay}, nil),
+		},
+		{
+			name: "passthrough using another nic",
+			claims: append(hostInterfaceClaims([]v1alpha1.Underlay{underlay}, nil),
+				hostInterfaceClaim{owner: "l3passthrough/passthrough", nic: "eth1"}),
+		},
+		{
+			// The passthrough does not claim any interface yet, this covers
+			// a future passthrough nic colliding with the underlay one.
+			name: "passthrough using the underlay nic",
+			claims: append(hostInterfaceClaims([]v1alpha1.Underlay{underlay}, nil),
+				hostInterfaceClaim{owner: "l3passthrough/passthrough", nic: "eth0"}),
+			wantErr: true,
+		},
+		{
+			name: "underlay using the same nic twice",
+			claims: hostInterfaceClaims([]v1alpha1.Underlay{{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"eth0", "eth0"}},
+			}}, nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHostInterfaces(tt.claims)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHostInterfaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,8 +5,15 @@ package conversion
 import (
 	"fmt"
 	"net"
+	"regexp"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
+	"github.com/openperouter/openperouter/internal/ipfamily"
 )
 
 func ValidateUnderlays(underlays []v1alpha1.Underlay) error {
@@ -19,7 +26,53 @@ func ValidateUnderlays(underlays []v1alpha1.Underlay) error {
 			return fmt.Errorf("underlay %s must have a valid ASN", underlay.Name)
 		}
 
+		if err := validatePeerGroups(underlay.Spec.PeerGroups); err != nil {
+			return fmt.Errorf("underlay %s: %w", underlay.Name, err)
+		}
+
+		if bestPath := underlay.Spec.BestPath; bestPath != nil && bestPath.ASPathIgnore && bestPath.ASPathMultipathRelax {
+			return fmt.Errorf("underlay %s: bestpath aspathignore and aspathmultipathrelax are mutually exclusive", underlay.Name)
+		}
+
+		if err := validateBGPTimer("updatedelay", underlay.Spec.UpdateDelay, time.Second); err != nil {
+			return fmt.Errorf("underlay %s: %w", underlay.Name, err)
+		}
+		if err := validateBGPTimer("coalescetime", underlay.Spec.CoalesceTime, time.Millisecond); err != nil {
+			return fmt.Errorf("underlay %s: %w", underlay.Name, err)
+		}
+
+		if expected := underlay.Spec.ExpectedNeighbors; expected != nil && (*expected < 1 || *expected > len(underlay.Spec.Neighbors)) {
+			return fmt.Errorf("underlay %s: expectedneighbors must be between 1 and the number of neighbors %d, got %d",
+				underlay.Name, len(underlay.Spec.Neighbors), *expected)
+		}
+
 		for _, neighbor := range underlay.Spec.Neighbors {
+			neighbor, err := neighborWithPeerGroupASN(neighbor, underlay.Spec.PeerGroups)
+			if err != nil {
+				return fmt.Errorf("underlay %s: %w", underlay.Name, err)
+			}
+			if neighbor.EBGPMultiHopTTL != nil {
+				if underlay.Spec.ASN == neighbor.ASN {
+					return fmt.Errorf("underlay %s neighbor %s: ebgp multihop ttl can't be set on an iBGP neighbor", underlay.Name, neighbor.Address)
+				}
+				if *neighbor.EBGPMultiHopTTL == 0 {
+					return fmt.Errorf("underlay %s neighbor %s: ebgp multihop ttl must be between 1 and 255", underlay.Name, neighbor.Address)
+				}
+			}
+			if err := validateSendCommunity(neighbor.SendCommunity); err != nil {
+				return fmt.Errorf("underlay %s neighbor %s: %w", underlay.Name, neighbor.Address, err)
+			}
+			if err := validateDescription(neighbor.Description); err != nil {
+				return fmt.Errorf("underlay %s neighbor %s: %w", underlay.Name, neighbor.Address, err)
+			}
+			if err := validateConnectTime(neighbor.ConnectTime); err != nil {
+				return fmt.Errorf("underlay %s neighbor %s: %w", underlay.Name, neighbor.Address, err)
+			}
+			if ptr.Deref(neighbor.ExtendedNextHop, false) {
+				if ip := net.ParseIP(neighbor.Address); ip == nil || ip.To4() != nil {
+					return fmt.Errorf("underlay %s neighbor %s: extendedNextHop requires an ipv6 neighbor", underlay.Name, neighbor.Address)
+				}
+			}
 			if underlay.Spec.ASN == neighbor.ASN {
 				return fmt.Errorf("underlay %s local ASN %d must be different from remote ASN %d", underlay.Name, underlay.Spec.ASN, neighbor.ASN)
 			}
@@ -29,6 +82,18 @@ func ValidateUnderlays(underlays []v1alpha1.Underlay) error {
 			if _, _, err := net.ParseCIDR(underlay.Spec.EVPN.VTEPCIDR); err != nil {
 				return fmt.Errorf("invalid vtep CIDR format for underlay %s: %s - %w", underlay.Name, underlay.Spec.EVPN.VTEPCIDR, err)
 			}
+			if err := validateVTEPMAC(underlay.Spec.EVPN); err != nil {
+				return fmt.Errorf("underlay %s: %w", underlay.Name, err)
+			}
+			if err := validateVTEPCIDRv6(underlay.Spec.EVPN); err != nil {
+				return fmt.Errorf("invalid vtep CIDR for underlay %s: %w", underlay.Name, err)
+			}
+			if err := validateDSCP(underlay.Spec.EVPN.DSCP); err != nil {
+				return fmt.Errorf("invalid dscp for underlay %s: %w", underlay.Name, err)
+			}
+			if err := validateDuplicateAddressDetection(underlay.Spec.EVPN.DuplicateAddressDetection); err != nil {
+				return fmt.Errorf("invalid duplicate address detection for underlay %s: %w", underlay.Name, err)
+			}
 		}
 
 		if len(underlay.Spec.Nics) > 1 {
@@ -39,7 +104,187 @@ func ValidateUnderlays(underlays []v1alpha1.Underlay) error {
 			if err := isValidInterfaceName(n); err != nil {
 				return fmt.Errorf("invalid nic name for underlay %s: %s - %w", underlay.Name, n, err)
 			}
+			if _, _, _, err := hostnetwork.ParseVLANInterface(n); err != nil {
+				return fmt.Errorf("invalid vlan nic for underlay %s: %w", underlay.Name, err)
+			}
 		}
 	}
 	return nil
 }
+
+// validateVTEPCIDRv6 checks that, for a dual stack VTEP, the ipv6 CIDR
+// is a valid ipv6 CIDR and the primary one is an ipv4 CIDR. Being of
+// different families, the two CIDRs can't overlap.
+func validateVTEPCIDRv6(evpn *v1alpha1.EVPNConfig) error {
+	if evpn.VTEPCIDRv6 == nil {
+		return nil
+	}
+	_, cidr, err := net.ParseCIDR(*evpn.VTEPCIDRv6)
+	if err != nil {
+		return fmt.Errorf("invalid ipv6 vtep CIDR format %s: %w", *evpn.VTEPCIDRv6, err)
+	}
+	if ipfamily.ForCIDR(cidr) != ipfamily.IPv6 {
+		return fmt.Errorf("ipv6 vtep CIDR %s must be an ipv6 CIDR", *evpn.VTEPCIDRv6)
+	}
+	if ipfamily.ForCIDRString(evpn.VTEPCIDR) != ipfamily.IPv4 {
+		return fmt.Errorf("vtep CIDR %s must be an ipv4 CIDR when the ipv6 vtep CIDR is set", evpn.VTEPCIDR)
+	}
+	return nil
+}
+
+// The ranges of the duplicate address detection thresholds FRR accepts.
+const (
+	minDADMaxMoves      = 2
+	maxDADMaxMoves      = 1000
+	minDADDetectionTime = 2 * time.Second
+	maxDADDetectionTime = 1800 * time.Second
+)
+
+// validateDuplicateAddressDetection checks that the thresholds of the
+// duplicate address detection are in the ranges accepted by the router.
+func validateDuplicateAddressDetection(dad *v1alpha1.DADConfig) error {
+	if dad == nil {
+		return nil
+	}
+	if dad.MaxMoves < minDADMaxMoves || dad.MaxMoves > maxDADMaxMoves {
+		return fmt.Errorf("maxmoves %d must be between %d and %d", dad.MaxMoves, minDADMaxMoves, maxDADMaxMoves)
+	}
+	if dad.DetectionTime.Duration < minDADDetectionTime || dad.DetectionTime.Duration > maxDADDetectionTime {
+		return fmt.Errorf("detectiontime %s must be between %s and %s", dad.DetectionTime.Duration, minDADDetectionTime, maxDADDetectionTime)
+	}
+	if dad.DetectionTime.Duration%time.Second != 0 {
+		return fmt.Errorf("detectiontime %s must be a whole number of seconds", dad.DetectionTime.Duration)
+	}
+	return nil
+}
+
+// validateVTEPMAC checks that the base VTEP MAC, if set, is a valid
+// unicast MAC. The MAC of each node is derived by adding its index
+// to the base one, so the MACs are unique across the nodes.
+func validateVTEPMAC(evpn *v1alpha1.EVPNConfig) error {
+	if evpn.VTEPMAC == nil {
+		return nil
+	}
+	mac, err := net.ParseMAC(*evpn.VTEPMAC)
+	if err != nil {
+		return fmt.Errorf("invalid vtep mac %s: %w", *evpn.VTEPMAC, err)
+	}
+	if len(mac) != 6 {
+		return fmt.Errorf("invalid vtep mac %s: must be a 48 bit mac", *evpn.VTEPMAC)
+	}
+	if mac[0]&1 == 1 {
+		return fmt.Errorf("invalid vtep mac %s: must be a unicast mac", *evpn.VTEPMAC)
+	}
+	return nil
+}
+
+var peerGroupNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+func validatePeerGroups(peerGroups []v1alpha1.PeerGroupSpec) error {
+	names := map[string]bool{}
+	for _, pg := range peerGroups {
+		if !peerGroupNameRegexp.MatchString(pg.Name) {
+			return fmt.Errorf("invalid peer group name %q", pg.Name)
+		}
+		// the host sessions with dynamic peers are configured as these groups.
+		if pg.Name == hostPeerGroupName(ipfamily.IPv4) || pg.Name == hostPeerGroupName(ipfamily.IPv6) {
+			return fmt.Errorf("peer group name %s is reserved for the dynamic host peers", pg.Name)
+		}
+		if names[pg.Name] {
+			return fmt.Errorf("duplicate peer group %s", pg.Name)
+		}
+		names[pg.Name] = true
+	}
+	return nil
+}
+
+// neighborWithPeerGroupASN returns the given neighbor with the ASN inherited
+// from its peer group. It fails if the peer group does not exist, if the ASN
+// of the neighbor conflicts with the one of the group, or if none is set.
+func neighborWithPeerGroupASN(neighbor v1alpha1.Neighbor, peerGroups []v1alpha1.PeerGroupSpec) (v1alpha1.Neighbor, error) {
+	neighbor, peerGroup, err := withPeerGroup(neighbor, peerGroups)
+	if err != nil {
+		return neighbor, err
+	}
+	if peerGroup != nil && peerGroup.ASN != nil && neighbor.ASN != *peerGroup.ASN {
+		return neighbor, fmt.Errorf("neighbor %s ASN %d conflicts with ASN %d of peer group %s",
+			neighbor.Address, neighbor.ASN, *peerGroup.ASN, peerGroup.Name)
+	}
+	if neighbor.ASN == 0 {
+		return neighbor, fmt.Errorf("neighbor %s must have an ASN, or belong to a peer group with one", neighbor.Address)
+	}
+	return neighbor, nil
+}
+
+// maxBGPTimer is the longest bgp timer of the underlay that can be set.
+const maxBGPTimer = time.Hour
+
+// validateBGPTimer checks that the given timer is between zero and
+// maxBGPTimer, and a whole number of the given unit.
+func validateBGPTimer(name string, timer *metav1.Duration, unit time.Duration) error {
+	if timer == nil {
+		return nil
+	}
+	if timer.Duration < 0 || timer.Duration > maxBGPTimer {
+		return fmt.Errorf("%s %s must be between 0 and %s", name, timer.Duration, maxBGPTimer)
+	}
+	if timer.Duration%unit != 0 {
+		return fmt.Errorf("%s %s must be a whole number of %s", name, timer.Duration, unit)
+	}
+	return nil
+}
+
+// maxConnectTime is the longest bgp connect time FRR accepts.
+const maxConnectTime = 65535 * time.Second
+
+// validateConnectTime checks that the given bgp connect time is
+// between one second and maxConnectTime, and a whole number of seconds.
+func validateConnectTime(connectTime *metav1.Duration) error {
+	if connectTime == nil {
+		return nil
+	}
+	if connectTime.Duration < time.Second || connectTime.Duration > maxConnectTime {
+		return fmt.Errorf("connecttime %s must be between 1s and %s", connectTime.Duration, maxConnectTime)
+	}
+	if connectTime.Duration%time.Second != 0 {
+		return fmt.Errorf("connecttime %s must be a whole number of seconds", connectTime.Duration)
+	}
+	return nil
+}
+
+// validateSendCommunity validates the type of the communities
+// sent to a BGP neighbor.
+func validateSendCommunity(sendCommunity *string) error {
+	if sendCommunity == nil {
+		return nil
+	}
+	switch *sendCommunity {
+	case v1alpha1.SendCommunityStandard, v1alpha1.SendCommunityExtended, v1alpha1.SendCommunityLarge,
+		v1alpha1.SendCommunityAll, v1alpha1.SendCommunityNone:
+		return nil
+	}
+	return fmt.Errorf("invalid sendcommunity %q, must be one of %s, %s, %s, %s or %s", *sendCommunity,
+		v1alpha1.SendCommunityStandard, v1alpha1.SendCommunityExtended, v1alpha1.SendCommunityLarge,
+		v1alpha1.SendCommunityAll, v1alpha1.SendCommunityNone)
+}
+
+// maxDescriptionLength is the maximum length of the description of a
+// BGP neighbor accepted by FRR.
+const maxDescriptionLength = 80
+
+var descriptionRegexp = regexp.MustCompile(`^[ -~]*$`)
+
+// validateDescription validates the description of a BGP neighbor, which
+// is emitted as is in the FRR configuration and so must fit in one line.
+func validateDescription(description *string) error {
+	if description == nil {
+		return nil
+	}
+	if len(*description) > maxDescriptionLength {
+		return fmt.Errorf("description %q is longer than %d characters", *description, maxDescriptionLength)
+	}
+	if !descriptionRegexp.MatchString(*description) {
+		return fmt.Errorf("description %q must contain printable ascii characters only", *description)
+	}
+	return nil
+}
@@ -3,9 +3,14 @@
 package conversion
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
+	"k8s.io/utils/ptr"
 )
 
 func TestValidateUnderlay(t *testing.T) {
@@ -132,6 +137,182 @@ func TestValidateUnderlay(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "ebgp multihop ttl on eBGP neighbor",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:             65002,
+							Address:         "192.168.1.1",
+							EBGPMultiHopTTL: ptr.To[uint8](2),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ebgp multihop ttl on iBGP neighbor",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:             65001,
+							Address:         "192.168.1.1",
+							EBGPMultiHopTTL: ptr.To[uint8](2),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero ebgp multihop ttl",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:             65002,
+							Address:         "192.168.1.1",
+							EBGPMultiHopTTL: ptr.To[uint8](0),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor sending no communities",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:           65002,
+							Address:       "192.168.1.1",
+							SendCommunity: ptr.To(v1alpha1.SendCommunityNone),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid send community",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:           65002,
+							Address:       "192.168.1.1",
+							SendCommunity: ptr.To("both"),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor with description",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:         65002,
+							Address:     "192.168.1.1",
+							Description: ptr.To("spine-1 (rack 3)"),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "neighbor description with a newline",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:         65002,
+							Address:     "192.168.1.1",
+							Description: ptr.To("spine\n password foo"),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor description too long",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:         65002,
+							Address:     "192.168.1.1",
+							Description: ptr.To(strings.Repeat("a", 81)),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor connect time not a whole number of seconds",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:         65002,
+							Address:     "192.168.1.1",
+							ConnectTime: &metav1.Duration{Duration: 1500 * time.Millisecond},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "extended next hop on an ipv6 neighbor",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:             65002,
+							Address:         "2001:db8::1",
+							ExtendedNextHop: ptr.To(true),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "extended next hop on an ipv4 neighbor",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					ASN: 65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{
+							ASN:             65002,
+							Address:         "192.168.1.1",
+							ExtendedNextHop: ptr.To(true),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "underlay NIC is a vlan sub-interface",
 			underlay: v1alpha1.Underlay{
@@ -144,6 +325,32 @@ func TestValidateUnderlay(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "underlay NIC is a vlan sub-interface with vlan id out of range",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+					},
+					Nics: []string{"eno2.4095"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "underlay NIC is a vlan sub-interface with vlan id zero",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+					},
+					Nics: []string{"eno2.0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "underlay NIC starts with dot",
 			underlay: v1alpha1.Underlay{
@@ -183,6 +390,484 @@ func TestValidateUnderlay(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "evpn with dscp",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DSCP:     ptr.To(uint8(46)),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "evpn with dscp out of range",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DSCP:     ptr.To(uint8(64)),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evpn with duplicate address detection",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DuplicateAddressDetection: &v1alpha1.DADConfig{
+							MaxMoves:      5,
+							DetectionTime: metav1.Duration{Duration: 3 * time.Minute},
+						},
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate address detection with too few moves",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DuplicateAddressDetection: &v1alpha1.DADConfig{
+							MaxMoves:      1,
+							DetectionTime: metav1.Duration{Duration: 3 * time.Minute},
+						},
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate address detection with too long detection time",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DuplicateAddressDetection: &v1alpha1.DADConfig{
+							MaxMoves:      5,
+							DetectionTime: metav1.Duration{Duration: time.Hour},
+						},
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate address detection with fractional detection time",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						DuplicateAddressDetection: &v1alpha1.DADConfig{
+							MaxMoves:      5,
+							DetectionTime: metav1.Duration{Duration: 2500 * time.Millisecond},
+						},
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dual stack vtep",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR:   "192.168.1.0/24",
+						VTEPCIDRv6: ptr.To("fd00:1::/64"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dual stack vtep with an ipv4 ipv6 cidr",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR:   "192.168.1.0/24",
+						VTEPCIDRv6: ptr.To("192.168.2.0/24"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dual stack vtep with an ipv6 primary cidr",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR:   "fd00:2::/64",
+						VTEPCIDRv6: ptr.To("fd00:1::/64"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dual stack vtep with an invalid ipv6 cidr",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR:   "192.168.1.0/24",
+						VTEPCIDRv6: ptr.To("invalidCIDR"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "vtep mac",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						VTEPMAC:  ptr.To("02:00:00:00:00:01"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid vtep mac",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						VTEPMAC:  ptr.To("02:00:00:00:00"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multicast vtep mac",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					EVPN: &v1alpha1.EVPNConfig{
+						VTEPCIDR: "192.168.1.0/24",
+						VTEPMAC:  ptr.To("01:00:5e:00:00:01"),
+					},
+					Nics: []string{"eth0"},
+					ASN:  65001,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor in a peer group",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "neighbor in a non existing peer group",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("leaves")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor ASN conflicting with the peer group one",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", ASN: 65003, PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neighbor ASN matching the peer group one",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", ASN: 65002, PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "no ASN on both the neighbor and the peer group",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines"},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "peer group ASN same as the local one",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65001))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate peer groups",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "spines", ASN: ptr.To(uint32(65002))},
+						{Name: "spines", ASN: ptr.To(uint32(65003))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("spines")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid peer group name",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "192.168.1.2", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("192.168.1.2")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "peer group name reserved for the ipv4 dynamic host peers",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "hosts-ipv4", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("hosts-ipv4")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "peer group name reserved for the ipv6 dynamic host peers",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					PeerGroups: []v1alpha1.PeerGroupSpec{
+						{Name: "hosts-ipv6", ASN: ptr.To(uint32(65002))},
+					},
+					Neighbors: []v1alpha1.Neighbor{
+						{Address: "192.168.1.2", PeerGroup: ptr.To("hosts-ipv6")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bestpath options",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					BestPath: &v1alpha1.BestPathConfig{
+						ASPathIgnore:      true,
+						CompareRouterID:   true,
+						MEDMissingAsWorst: true,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "bestpath ignoring the as path with multipath relax",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					BestPath: &v1alpha1.BestPathConfig{
+						ASPathIgnore:         true,
+						ASPathMultipathRelax: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "update delay and coalesce time",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics:         []string{"eth0"},
+					ASN:          65001,
+					EVPN:         &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					UpdateDelay:  &metav1.Duration{Duration: 5 * time.Minute},
+					CoalesceTime: &metav1.Duration{Duration: 200 * time.Millisecond},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "update delay not a whole number of seconds",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics:        []string{"eth0"},
+					ASN:         65001,
+					EVPN:        &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					UpdateDelay: &metav1.Duration{Duration: 1500 * time.Millisecond},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "update delay longer than an hour",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics:        []string{"eth0"},
+					ASN:         65001,
+					EVPN:        &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					UpdateDelay: &metav1.Duration{Duration: 2 * time.Hour},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative coalesce time",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics:         []string{"eth0"},
+					ASN:          65001,
+					EVPN:         &v1alpha1.EVPNConfig{VTEPCIDR: "10.0.0.0/24"},
+					CoalesceTime: &metav1.Duration{Duration: -time.Second},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "expected neighbors",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{ASN: 65002, Address: "192.168.1.1"},
+						{ASN: 65002, Address: "192.168.2.1"},
+					},
+					ExpectedNeighbors: ptr.To(2),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "more expected neighbors than neighbors",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{ASN: 65002, Address: "192.168.1.1"},
+					},
+					ExpectedNeighbors: ptr.To(2),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero expected neighbors",
+			underlay: v1alpha1.Underlay{
+				Spec: v1alpha1.UnderlaySpec{
+					Nics: []string{"eth0"},
+					ASN:  65001,
+					Neighbors: []v1alpha1.Neighbor{
+						{ASN: 65002, Address: "192.168.1.1"},
+					},
+					ExpectedNeighbors: ptr.To(0),
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -6,13 +6,21 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/hostnetwork"
 	"github.com/openperouter/openperouter/internal/ipfamily"
+	"k8s.io/utils/ptr"
 )
 
 var interfaceNameRegexp *regexp.Regexp
 
+const maxDSCP = 63
+
 func init() {
 	interfaceNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._-]*$`)
 }
@@ -22,6 +30,195 @@ func ValidateL3VNIs(l3Vnis []v1alpha1.L3VNI) error {
 	if err := validateVNIs(vnis); err != nil {
 		return err
 	}
+	if err := validateImportVRFs(l3Vnis); err != nil {
+		return err
+	}
+	for _, l3vni := range l3Vnis {
+		if err := validateLeakToDefault(l3vni.Spec.LeakToDefault); err != nil {
+			return fmt.Errorf("invalid leaktodefault for l3vni %s: %w", l3vni.Name, err)
+		}
+		fabricAdvertise := ptr.Deref(l3vni.Spec.FabricAdvertise, true)
+		if !fabricAdvertise && (ptr.Deref(l3vni.Spec.AdvertiseIPv4, false) || ptr.Deref(l3vni.Spec.AdvertiseIPv6, false)) {
+			return fmt.Errorf("l3vni %s can't advertise ipv4 or ipv6 when fabricadvertise is false", l3vni.Name)
+		}
+		if fabricAdvertise && l3vni.Spec.AdvertiseIPv4 != nil && !*l3vni.Spec.AdvertiseIPv4 &&
+			l3vni.Spec.AdvertiseIPv6 != nil && !*l3vni.Spec.AdvertiseIPv6 {
+			return fmt.Errorf("l3vni %s must advertise at least one of ipv4 and ipv6", l3vni.Name)
+		}
+		// The host session subnet covers the link to the host only, not
+		// the prefixes routed through the vrf, which must be listed.
+		if l3vni.Spec.DisableConntrack && len(l3vni.Spec.ConntrackBypassCIDRs) == 0 {
+			return fmt.Errorf("disableconntrack for l3vni %s requires conntrackbypasscidrs", l3vni.Name)
+		}
+		for _, cidr := range l3vni.Spec.ConntrackBypassCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid conntrackbypasscidrs for l3vni %s: %w", l3vni.Name, err)
+			}
+		}
+		if err := validateRouteMaps(l3vni); err != nil {
+			return fmt.Errorf("invalid route-maps for l3vni %s: %w", l3vni.Name, err)
+		}
+		if err := validateRouterSVIAddress(l3vni); err != nil {
+			return fmt.Errorf("invalid routersviaddress for l3vni %s: %w", l3vni.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateRouterSVIAddress checks that the address assigned to the bridge
+// of the L3VNI, if any, is valid and doesn't overlap the local CIDRs of
+// the host session.
+func validateRouterSVIAddress(l3vni v1alpha1.L3VNI) error {
+	if l3vni.Spec.RouterSVIAddress == nil {
+		return nil
+	}
+	address := *l3vni.Spec.RouterSVIAddress
+	if _, _, err := net.ParseCIDR(address); err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	if l3vni.Spec.HostSession == nil {
+		return nil
+	}
+	for _, cidr := range []string{l3vni.Spec.HostSession.LocalCIDR.IPv4, l3vni.Spec.HostSession.LocalCIDR.IPv6} {
+		if cidr == "" {
+			continue
+		}
+		overlap, err := cidrsOverlap(address, cidr)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return fmt.Errorf("address %s overlaps the local cidr %s of the host session", address, cidr)
+		}
+	}
+	return nil
+}
+
+// validateRouteMaps checks that the route-maps of the L3VNI are uniquely
+// named and made of valid rules, and that the one filtering the routes
+// advertised to the fabric exists.
+func validateRouteMaps(l3vni v1alpha1.L3VNI) error {
+	names := map[string]struct{}{}
+	for _, routeMap := range l3vni.Spec.RouteMaps {
+		if _, ok := names[routeMap.Name]; ok {
+			return fmt.Errorf("duplicate route-map %s", routeMap.Name)
+		}
+		names[routeMap.Name] = struct{}{}
+		if len(routeMap.Rules) == 0 {
+			return fmt.Errorf("route-map %s has no rules", routeMap.Name)
+		}
+		for i, rule := range routeMap.Rules {
+			if err := validateRouteMapRule(rule); err != nil {
+				return fmt.Errorf("invalid rule %d of route-map %s: %w", i, routeMap.Name, err)
+			}
+		}
+	}
+
+	if l3vni.Spec.EVPNExportRouteMap == nil {
+		return nil
+	}
+	if !ptr.Deref(l3vni.Spec.FabricAdvertise, true) {
+		return fmt.Errorf("evpnexportroutemap can't be set when fabricadvertise is false")
+	}
+	if _, ok := names[*l3vni.Spec.EVPNExportRouteMap]; !ok {
+		return fmt.Errorf("evpnexportroutemap references the non existing route-map %s", *l3vni.Spec.EVPNExportRouteMap)
+	}
+	return nil
+}
+
+func validateRouteMapRule(rule v1alpha1.RouteMapRule) error {
+	if rule.Action != v1alpha1.RouteMapPermit && rule.Action != v1alpha1.RouteMapDeny {
+		return fmt.Errorf("invalid action %q, must be %s or %s", rule.Action, v1alpha1.RouteMapPermit, v1alpha1.RouteMapDeny)
+	}
+	if rule.Match != nil {
+		if len(rule.Match.Prefixes) == 0 {
+			return fmt.Errorf("match must have at least one prefix")
+		}
+		for _, p := range rule.Match.Prefixes {
+			ip, ipNet, err := net.ParseCIDR(p)
+			if err != nil {
+				return fmt.Errorf("invalid prefix %s: %w", p, err)
+			}
+			if !ip.Equal(ipNet.IP) {
+				return fmt.Errorf("prefix %s has host bits set, expected %s", p, ipNet.String())
+			}
+		}
+	}
+	if rule.Set == nil {
+		return nil
+	}
+	if rule.Action == v1alpha1.RouteMapDeny {
+		return fmt.Errorf("set can't be used with the %s action", v1alpha1.RouteMapDeny)
+	}
+	for _, c := range rule.Set.Communities {
+		if err := validateCommunity(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCommunity checks that the given community is a standard
+// community in the <AS>:<value> form, with both the parts on 16 bits.
+func validateCommunity(community string) error {
+	as, value, ok := strings.Cut(community, ":")
+	if !ok {
+		return fmt.Errorf("invalid community %s, expected <AS>:<value>", community)
+	}
+	for _, part := range []string{as, value} {
+		if _, err := strconv.ParseUint(part, 10, 16); err != nil {
+			return fmt.Errorf("invalid community %s, expected <AS>:<value> with 16 bits parts", community)
+		}
+	}
+	return nil
+}
+
+// validateLeakToDefault checks that the prefixes to be leaked into the
+// default VRF are valid and unique. Leaking a default route is not allowed,
+// as it would expose the whole VRF.
+func validateLeakToDefault(prefixes []string) error {
+	leaked := map[string]struct{}{}
+	for _, p := range prefixes {
+		ip, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid prefix %s: %w", p, err)
+		}
+		if !ip.Equal(ipNet.IP) {
+			return fmt.Errorf("prefix %s has host bits set, expected %s", p, ipNet.String())
+		}
+		if ones, _ := ipNet.Mask.Size(); ones == 0 {
+			return fmt.Errorf("prefix %s would leak the whole vrf", p)
+		}
+		if _, ok := leaked[ipNet.String()]; ok {
+			return fmt.Errorf("prefix %s is leaked more than once", p)
+		}
+		leaked[ipNet.String()] = struct{}{}
+	}
+	return nil
+}
+
+// validateImportVRFs checks that every L3VNI imports only the
+// VRFs of other existing L3VNIs, each of them once.
+func validateImportVRFs(l3Vnis []v1alpha1.L3VNI) error {
+	vrfs := map[string]struct{}{}
+	for _, l3vni := range l3Vnis {
+		vrfs[l3vni.Spec.VRF] = struct{}{}
+	}
+	for _, l3vni := range l3Vnis {
+		imported := map[string]struct{}{}
+		for _, vrf := range l3vni.Spec.ImportVRFs {
+			if vrf == l3vni.Spec.VRF {
+				return fmt.Errorf("l3vni %s can't import its own vrf %s", l3vni.Name, vrf)
+			}
+			if _, ok := vrfs[vrf]; !ok {
+				return fmt.Errorf("l3vni %s imports vrf %s which does not match any l3vni", l3vni.Name, vrf)
+			}
+			if _, ok := imported[vrf]; ok {
+				return fmt.Errorf("l3vni %s imports vrf %s more than once", l3vni.Name, vrf)
+			}
+			imported[vrf] = struct{}{}
+		}
+	}
 	return nil
 }
 
@@ -41,22 +238,372 @@ func ValidateL2VNIs(l2Vnis []v1alpha1.L2VNI) error {
 				return fmt.Errorf("invalid hostmaster name for vni %s: %s - %w", vni.Name, vni.Spec.HostMaster.Name, err)
 			}
 		}
+		if err := validateDatapathType(vni); err != nil {
+			return err
+		}
 		if len(vni.Spec.L2GatewayIPs) > 0 {
 			_, err := ipfamily.ForCIDRStrings(vni.Spec.L2GatewayIPs...)
 			if err != nil {
 				return fmt.Errorf("invalid l2gatewayips for vni %q = %v: %w", vni.Name, vni.Spec.L2GatewayIPs, err)
 			}
+			if err := validateGatewayIPs(vni); err != nil {
+				return err
+			}
+		}
+		if err := validateGatewayMode(vni); err != nil {
+			return err
+		}
+		if vni.Spec.SuppressRA != nil && !hasIPv6Gateway(vni) {
+			return fmt.Errorf("suppressra for vni %q requires an ipv6 l2gatewayip", vni.Name)
+		}
+		if vni.Spec.ManagePolicyRouting {
+			if len(vni.Spec.L2GatewayIPs) == 0 {
+				return fmt.Errorf("managepolicyrouting for vni %q requires l2gatewayips", vni.Name)
+			}
+			if vni.Spec.HostMaster == nil || vni.Spec.HostMaster.Type != v1alpha1.LinuxBridge {
+				return fmt.Errorf("managepolicyrouting for vni %q requires a %s hostmaster", vni.Name, v1alpha1.LinuxBridge)
+			}
+		}
+		if ptr.Deref(vni.Spec.AdvertiseHostRoutes, false) && len(vni.Spec.L2GatewayIPs) == 0 {
+			return fmt.Errorf("advertisehostroutes for vni %q requires l2gatewayips", vni.Name)
+		}
+		if vni.Spec.DisableConntrack && len(vni.Spec.L2GatewayIPs) == 0 {
+			return fmt.Errorf("disableconntrack for vni %q requires l2gatewayips", vni.Name)
+		}
+		if err := validateLearning(vni); err != nil {
+			return err
+		}
+		if err := validateMACAgeingTime(vni); err != nil {
+			return err
+		}
+		if err := validateEthernetSegment(vni); err != nil {
+			return err
+		}
+	}
+
+	if err := validateGatewaysUniqueness(l2Vnis); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateL2VNIsWithL3VNIs validates the given L2VNIs and ensures that
+// every L2VNI with an explicit VRF is bound to the VRF of an existing L3VNI.
+func ValidateL2VNIsWithL3VNIs(l2Vnis []v1alpha1.L2VNI, l3Vnis []v1alpha1.L3VNI) error {
+	if err := ValidateL2VNIs(l2Vnis); err != nil {
+		return err
+	}
+
+	l3VRFs := map[string]struct{}{}
+	for _, l3vni := range l3Vnis {
+		l3VRFs[l3vni.Spec.VRF] = struct{}{}
+	}
+
+	for _, l2vni := range l2Vnis {
+		if l2vni.Spec.VRF == nil || *l2vni.Spec.VRF == "" {
+			if ptr.Deref(l2vni.Spec.AdvertiseHostRoutes, false) {
+				return fmt.Errorf("advertisehostroutes for l2vni %s requires the vrf of a l3vni", l2vni.Name)
+			}
+			continue
+		}
+		if _, ok := l3VRFs[*l2vni.Spec.VRF]; !ok {
+			return fmt.Errorf("l2vni %s references vrf %s which does not match any l3vni", l2vni.Name, *l2vni.Spec.VRF)
+		}
+	}
+
+	return nil
+}
+
+// maxESID is the highest local discriminator of a type-3 ESI.
+const maxESID = 1<<24 - 1
+
+// validateEthernetSegment checks that the ethernet segment of the given
+// L2VNI, if any, has a valid ID and system MAC, and a valid DF preference.
+func validateEthernetSegment(l2vni v1alpha1.L2VNI) error {
+	es := l2vni.Spec.EthernetSegment
+	if es == nil {
+		return nil
+	}
+	if es.DFPreference != nil && es.ID == nil && es.ESI == nil {
+		return fmt.Errorf("dfpreference for vni %q requires an ethernet segment id or esi", l2vni.Name)
+	}
+	if es.DFPreference != nil && *es.DFPreference == 0 {
+		return fmt.Errorf("invalid dfpreference for vni %q: must be between 1 and 65535", l2vni.Name)
+	}
+	if es.ESI != nil {
+		if es.ID != nil || es.SysMAC != nil {
+			return fmt.Errorf("ethernet segment esi for vni %q can't be set together with id and sysmac", l2vni.Name)
+		}
+		if err := validateESI(*es.ESI); err != nil {
+			return fmt.Errorf("invalid ethernet segment esi for vni %q: %w", l2vni.Name, err)
+		}
+		return nil
+	}
+	if es.ID == nil {
+		return fmt.Errorf("ethernetsegment for vni %q requires an id", l2vni.Name)
+	}
+	if *es.ID == 0 || *es.ID > maxESID {
+		return fmt.Errorf("invalid ethernet segment id for vni %q: %d must be between 1 and %d", l2vni.Name, *es.ID, maxESID)
+	}
+	if es.SysMAC == nil {
+		return fmt.Errorf("ethernet segment id for vni %q requires a sysmac", l2vni.Name)
+	}
+	mac, err := net.ParseMAC(*es.SysMAC)
+	if err != nil || len(mac) != 6 {
+		return fmt.Errorf("invalid ethernet segment sysmac for vni %q: %s", l2vni.Name, *es.SysMAC)
+	}
+	return nil
+}
+
+// esiLength is the length in bytes of an ESI.
+const esiLength = 10
+
+// validateESI checks that the given ESI is made of 10 bytes in
+// hexadecimal, separated by colons, and that it is a type-0 one.
+func validateESI(esi string) error {
+	octets := strings.Split(esi, ":")
+	if len(octets) != esiLength {
+		return fmt.Errorf("%s must be %d bytes long", esi, esiLength)
+	}
+	for _, b := range octets {
+		if len(b) != 2 {
+			return fmt.Errorf("%s is not in the form 00:11:22:33:44:55:66:77:88:99", esi)
+		}
+		if _, err := strconv.ParseUint(b, 16, 8); err != nil {
+			return fmt.Errorf("%s is not in the form 00:11:22:33:44:55:66:77:88:99", esi)
+		}
+	}
+	if octets[0] != "00" {
+		return fmt.Errorf("%s must be a type-0 esi, starting with 00", esi)
+	}
+	return nil
+}
+
+// validateLearning checks that the flood-and-learn mode of the given L2VNI
+// comes with either a valid multicast group or a list of valid static vteps,
+// and that neither is set otherwise, where the flooding is learned via EVPN.
+func validateLearning(l2vni v1alpha1.L2VNI) error {
+	learning := l2vni.Spec.Learning != nil && *l2vni.Spec.Learning
+	if !learning {
+		if l2vni.Spec.MulticastGroup != nil {
+			return fmt.Errorf("multicastgroup for vni %q requires learning to be enabled", l2vni.Name)
+		}
+		if len(l2vni.Spec.StaticVTEPs) > 0 {
+			return fmt.Errorf("staticvteps for vni %q require learning to be enabled", l2vni.Name)
+		}
+		return nil
+	}
+	if l2vni.Spec.MulticastGroup != nil && len(l2vni.Spec.StaticVTEPs) > 0 {
+		return fmt.Errorf("multicastgroup and staticvteps for vni %q are mutually exclusive", l2vni.Name)
+	}
+	if len(l2vni.Spec.StaticVTEPs) > 0 {
+		return validateStaticVTEPs(l2vni)
+	}
+	if l2vni.Spec.MulticastGroup == nil {
+		return fmt.Errorf("learning for vni %q requires a multicastgroup or staticvteps", l2vni.Name)
+	}
+	group := net.ParseIP(*l2vni.Spec.MulticastGroup)
+	if group == nil || !group.IsMulticast() {
+		return fmt.Errorf("invalid multicastgroup for vni %q: %s is not a multicast address", l2vni.Name, *l2vni.Spec.MulticastGroup)
+	}
+	return nil
+}
+
+// validateStaticVTEPs checks that the static vteps of the given L2VNI
+// are unique unicast addresses.
+func validateStaticVTEPs(l2vni v1alpha1.L2VNI) error {
+	existing := map[string]bool{}
+	for _, vtep := range l2vni.Spec.StaticVTEPs {
+		ip := net.ParseIP(vtep)
+		if ip == nil || ip.IsMulticast() || ip.IsUnspecified() {
+			return fmt.Errorf("invalid staticvtep for vni %q: %s is not a unicast address", l2vni.Name, vtep)
+		}
+		if existing[ip.String()] {
+			return fmt.Errorf("duplicate staticvtep %s for vni %q", vtep, l2vni.Name)
+		}
+		existing[ip.String()] = true
+	}
+	return nil
+}
+
+const (
+	minMACAgeingTime = 10 * time.Second
+	maxMACAgeingTime = time.Hour
+)
+
+// validateMACAgeingTime checks that the mac ageing time of the given
+// L2VNI, if set, is within a sane range.
+func validateMACAgeingTime(l2vni v1alpha1.L2VNI) error {
+	if l2vni.Spec.MACAgeingTime == nil {
+		return nil
+	}
+	ageingTime := l2vni.Spec.MACAgeingTime.Duration
+	if ageingTime < minMACAgeingTime || ageingTime > maxMACAgeingTime {
+		return fmt.Errorf("invalid macageingtime for vni %q: %s, must be between %s and %s",
+			l2vni.Name, ageingTime, minMACAgeingTime, maxMACAgeingTime)
+	}
+	return nil
+}
+
+// validateGatewayIPs checks that the gateway ips of the given L2VNI are host
+// addresses within their subnet, and not its network or broadcast address.
+func validateGatewayIPs(l2vni v1alpha1.L2VNI) error {
+	for _, gatewayIP := range l2vni.Spec.L2GatewayIPs {
+		ip, subnet, err := net.ParseCIDR(gatewayIP)
+		if err != nil {
+			return fmt.Errorf("invalid l2gatewayip %s for vni %q: %w", gatewayIP, l2vni.Name, err)
+		}
+		ones, bits := subnet.Mask.Size()
+		// Point to point subnets have no network nor broadcast address.
+		if bits-ones < 2 {
+			continue
+		}
+		if ip.Equal(subnet.IP) {
+			return fmt.Errorf("invalid l2gatewayip %s for vni %q: it is the network address of the subnet", gatewayIP, l2vni.Name)
+		}
+		if ip.To4() != nil && ip.Equal(broadcastAddress(subnet)) {
+			return fmt.Errorf("invalid l2gatewayip %s for vni %q: it is the broadcast address of the subnet", gatewayIP, l2vni.Name)
+		}
+	}
+	return nil
+}
+
+// validateGatewaysUniqueness checks that no two L2VNIs share a gateway ip,
+// and that the L2VNIs enslaved to the same host bridge don't have
+// overlapping gateway subnets, as both would cause ARP conflicts.
+func validateGatewaysUniqueness(l2Vnis []v1alpha1.L2VNI) error {
+	existingIPs := map[string]string{} // a map between the gateway ip and the VNI instance it's configured in
+	for _, l2vni := range l2Vnis {
+		for _, gatewayIP := range l2vni.Spec.L2GatewayIPs {
+			ip, _, err := net.ParseCIDR(gatewayIP)
+			if err != nil {
+				return fmt.Errorf("invalid l2gatewayip %s for vni %q: %w", gatewayIP, l2vni.Name, err)
+			}
+			if existing, ok := existingIPs[ip.String()]; ok {
+				return fmt.Errorf("duplicate l2gatewayip %s: %s - %s", ip, existing, l2vni.Name)
+			}
+			existingIPs[ip.String()] = l2vni.Name
+		}
+	}
+
+	for i, l2vni := range l2Vnis {
+		if l2vni.Spec.HostMaster == nil || l2vni.Spec.HostMaster.Name == "" {
+			continue
+		}
+		for _, other := range l2Vnis[i+1:] {
+			if other.Spec.HostMaster == nil || other.Spec.HostMaster.Name != l2vni.Spec.HostMaster.Name {
+				continue
+			}
+			for _, gatewayIP := range l2vni.Spec.L2GatewayIPs {
+				for _, otherGatewayIP := range other.Spec.L2GatewayIPs {
+					overlap, err := cidrsOverlap(gatewayIP, otherGatewayIP)
+					if err != nil {
+						return err
+					}
+					if overlap {
+						return fmt.Errorf("l2gatewayips %s of vni %s and %s of vni %s overlap on the shared hostmaster %s",
+							gatewayIP, l2vni.Name, otherGatewayIP, other.Name, l2vni.Spec.HostMaster.Name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func broadcastAddress(subnet *net.IPNet) net.IP {
+	ip := subnet.IP.To4()
+	broadcast := make(net.IP, len(ip))
+	for i := range ip {
+		broadcast[i] = ip[i] | ^subnet.Mask[i]
+	}
+	return broadcast
+}
+
+// NADSubnetLister returns the subnets the pods attached to the given L2VNI
+// through a NetworkAttachmentDefinition get their addresses from, or none
+// if no such NetworkAttachmentDefinition is known.
+type NADSubnetLister interface {
+	SubnetsFor(l2vni v1alpha1.L2VNI) ([]string, error)
+}
+
+// ValidateL2VNIGatewaysInNADSubnets checks that each gateway ip of the given
+// L2VNIs is within one of the subnets of its family the pods attached to the
+// L2VNI get their addresses from, as returned by the lister. The gateway ips
+// with no subnet of their family known are not checked.
+func ValidateL2VNIGatewaysInNADSubnets(l2vnis []v1alpha1.L2VNI, lister NADSubnetLister) error {
+	for _, vni := range l2vnis {
+		if len(vni.Spec.L2GatewayIPs) == 0 {
+			continue
+		}
+		subnets, err := lister.SubnetsFor(vni)
+		if err != nil {
+			return fmt.Errorf("failed to get the nad subnets of vni %q: %w", vni.Name, err)
+		}
+		for _, gatewayIP := range vni.Spec.L2GatewayIPs {
+			ip, _, err := net.ParseCIDR(gatewayIP)
+			if err != nil {
+				return fmt.Errorf("invalid l2gatewayip %s for vni %q: %w", gatewayIP, vni.Name, err)
+			}
+			sameFamily := []string{}
+			within := false
+			for _, s := range subnets {
+				_, subnet, err := net.ParseCIDR(s)
+				if err != nil {
+					return fmt.Errorf("invalid nad subnet %s for vni %q: %w", s, vni.Name, err)
+				}
+				if (subnet.IP.To4() == nil) != (ip.To4() == nil) {
+					continue
+				}
+				sameFamily = append(sameFamily, s)
+				within = within || subnet.Contains(ip)
+			}
+			if len(sameFamily) > 0 && !within {
+				return fmt.Errorf("l2gatewayip %s of vni %q is not within the subnets %v of the pods attached to it", gatewayIP, vni.Name, sameFamily)
+			}
 		}
 	}
+	return nil
+}
 
+// validateGatewayMode validates the gateway mode of the given L2VNI.
+func validateGatewayMode(l2vni v1alpha1.L2VNI) error {
+	switch l2vni.Spec.GatewayMode {
+	case "", v1alpha1.AnycastGateway:
+		return nil
+	case v1alpha1.CentralizedGateway:
+	default:
+		return fmt.Errorf("invalid gatewaymode for vni %q: %q, must be %s or %s",
+			l2vni.Name, l2vni.Spec.GatewayMode, v1alpha1.AnycastGateway, v1alpha1.CentralizedGateway)
+	}
+	if len(l2vni.Spec.L2GatewayIPs) == 0 {
+		return fmt.Errorf("%s gatewaymode for vni %q requires l2gatewayips", v1alpha1.CentralizedGateway, l2vni.Name)
+	}
+	if l2vni.Spec.ManagePolicyRouting {
+		return fmt.Errorf("managepolicyrouting for vni %q is not supported with %s gatewaymode", l2vni.Name, v1alpha1.CentralizedGateway)
+	}
 	return nil
 }
 
+// hasIPv6Gateway tells whether the given L2VNI has an IPv6 gateway address.
+func hasIPv6Gateway(l2vni v1alpha1.L2VNI) bool {
+	if len(l2vni.Spec.L2GatewayIPs) == 0 {
+		return false
+	}
+	family, err := ipfamily.ForCIDRStrings(l2vni.Spec.L2GatewayIPs...)
+	if err != nil {
+		return false
+	}
+	return family == ipfamily.IPv6 || family == ipfamily.DualStack
+}
+
 // vni holds VNI validation data
 type vni struct {
 	name    string
 	vni     uint32
 	vrfName string
+	dscp    *uint8
 }
 
 // vnisFromL3VNIs converts L3VNIs to vni slice
@@ -67,6 +614,7 @@ func vnisFromL3VNIs(l3vnis []v1alpha1.L3VNI) []vni {
 			name:    l3vni.Name,
 			vni:     l3vni.Spec.VNI,
 			vrfName: l3vni.Spec.VRF,
+			dscp:    l3vni.Spec.DSCP,
 		}
 	}
 	return result
@@ -80,11 +628,25 @@ func vnisFromL2VNIs(l2vnis []v1alpha1.L2VNI) []vni {
 			name:    l2vni.Name,
 			vni:     l2vni.Spec.VNI,
 			vrfName: l2vni.VRFName(),
+			dscp:    l2vni.Spec.DSCP,
 		}
 	}
 	return result
 }
 
+// ValidateDeviceNames checks that the names of the devices created for
+// each vni, prefixed with the given device name prefix, fit in the length
+// limit of the interface names. The prefix is known only to the controller
+// running on the node, so this is not part of the webhook validation.
+func ValidateDeviceNames(prefix string, l3Vnis []v1alpha1.L3VNI, l2Vnis []v1alpha1.L2VNI) error {
+	for _, vni := range slices.Concat(vnisFromL3VNIs(l3Vnis), vnisFromL2VNIs(l2Vnis)) {
+		if err := hostnetwork.ValidateDeviceNames(prefix, int(vni.vni)); err != nil {
+			return fmt.Errorf("invalid vni %s: %w", vni.name, err)
+		}
+	}
+	return nil
+}
+
 // validateVNIs performs common validation logic for VNIs
 func validateVNIs(vnis []vni) error {
 	existingVrfs := map[string]string{} // a map between the given VRF and the VNI instance it's configured in
@@ -105,11 +667,23 @@ func validateVNIs(vnis []vni) error {
 			return fmt.Errorf("duplicate vni %d:%s - %s", vni.vni, existingVNI, vni.name)
 		}
 		existingVNIs[vni.vni] = vni.name
+
+		if err := validateDSCP(vni.dscp); err != nil {
+			return fmt.Errorf("invalid dscp for vni %s: %w", vni.name, err)
+		}
 	}
 
 	return nil
 }
 
+// validateDSCP checks that the given DSCP, if set, fits in its six bits.
+func validateDSCP(dscp *uint8) error {
+	if dscp != nil && *dscp > maxDSCP {
+		return fmt.Errorf("dscp %d must be between 0 and %d", *dscp, maxDSCP)
+	}
+	return nil
+}
+
 func cidrsOverlap(cidr1, cidr2 string) (bool, error) {
 	net1, ipNet1, err1 := net.ParseCIDR(cidr1)
 	if err1 != nil {
@@ -151,3 +725,20 @@ func isValidCIDR(cidr string) error {
 	}
 	return nil
 }
+
+// validateDatapathType checks that the datapath type is a supported one,
+// and that it is set only on an automatically created ovs bridge.
+func validateDatapathType(vni v1alpha1.L2VNI) error {
+	if vni.Spec.HostMaster == nil || vni.Spec.HostMaster.DatapathType == nil {
+		return nil
+	}
+	datapathType := *vni.Spec.HostMaster.DatapathType
+	if datapathType != v1alpha1.OVSDatapathSystem && datapathType != v1alpha1.OVSDatapathNetdev {
+		return fmt.Errorf("invalid datapathtype for vni %q: %q, must be %s or %s",
+			vni.Name, datapathType, v1alpha1.OVSDatapathSystem, v1alpha1.OVSDatapathNetdev)
+	}
+	if vni.Spec.HostMaster.Type != v1alpha1.OVSBridge || !vni.Spec.HostMaster.AutoCreate {
+		return fmt.Errorf("datapathtype for vni %q requires an autocreated %s hostmaster", vni.Name, v1alpha1.OVSBridge)
+	}
+	return nil
+}
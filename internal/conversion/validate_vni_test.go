@@ -4,6 +4,7 @@ package conversion
 
 import (
 	"testing"
+	"time"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -136,6 +137,539 @@ func TestValidateVNIs(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "dscp out of range",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:  1001,
+						VRF:  "vrf1",
+						DSCP: ptr.To(uint8(64)),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "import vrf of another l3vni",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:        200,
+						VRF:        "blue",
+						ImportVRFs: []string{"red"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "import own vrf",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:        200,
+						VRF:        "blue",
+						ImportVRFs: []string{"blue"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "import non existing vrf",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:        200,
+						VRF:        "blue",
+						ImportVRFs: []string{"green"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "import the same vrf twice",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:        200,
+						VRF:        "blue",
+						ImportVRFs: []string{"red", "red"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leak prefixes to default",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						LeakToDefault: []string{"192.168.20.0/24", "2001:db8:20::/64"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "leak invalid prefix to default",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						LeakToDefault: []string{"192.168.20.0/33"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leak prefix with host bits to default",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						LeakToDefault: []string{"192.168.20.1/24"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leak the default route to default",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						LeakToDefault: []string{"0.0.0.0/0"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leak the same prefix twice to default",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						LeakToDefault: []string{"192.168.20.0/24", "192.168.20.0/24"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "advertise only ipv4",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						AdvertiseIPv6: ptr.To(false),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "advertise no family",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:           100,
+						VRF:           "red",
+						AdvertiseIPv4: ptr.To(false),
+						AdvertiseIPv6: ptr.To(false),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "not advertised into the fabric",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:             100,
+						VRF:             "red",
+						FabricAdvertise: ptr.To(false),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not advertised into the fabric with no family",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:             100,
+						VRF:             "red",
+						FabricAdvertise: ptr.To(false),
+						AdvertiseIPv4:   ptr.To(false),
+						AdvertiseIPv6:   ptr.To(false),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not advertised into the fabric advertising ipv4",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:             100,
+						VRF:             "red",
+						FabricAdvertise: ptr.To(false),
+						AdvertiseIPv4:   ptr.To(true),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conntrack disabled without bypass cidrs",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:              100,
+						VRF:              "red",
+						DisableConntrack: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conntrack disabled for the host session only",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						HostSession: &v1alpha1.HostSession{
+							ASN:       65000,
+							HostASN:   65001,
+							LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.169.10.0/24"},
+						},
+						DisableConntrack: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conntrack disabled for explicit cidrs without host session",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:                  100,
+						VRF:                  "red",
+						DisableConntrack:     true,
+						ConntrackBypassCIDRs: []string{"10.244.0.0/16", "fd00:10:244::/64"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid conntrack bypass cidr",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:                  100,
+						VRF:                  "red",
+						DisableConntrack:     true,
+						ConntrackBypassCIDRs: []string{"10.244.0.0"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid evpn export route-map",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{
+										Action: v1alpha1.RouteMapPermit,
+										Match:  &v1alpha1.RouteMapMatch{Prefixes: []string{"192.168.20.0/24", "2001:db8:20::/64"}},
+										Set:    &v1alpha1.RouteMapSet{Communities: []string{"65000:100"}, LocalPreference: ptr.To[uint32](200)},
+									},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "evpn export route-map not existing",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{Action: v1alpha1.RouteMapPermit},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("other"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "evpn export route-map with fabricadvertise false",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:             100,
+						VRF:             "red",
+						FabricAdvertise: ptr.To(false),
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{Action: v1alpha1.RouteMapPermit},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route-map with invalid action",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{Action: "accept"},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route-map matching a prefix with host bits",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{
+										Action: v1alpha1.RouteMapDeny,
+										Match:  &v1alpha1.RouteMapMatch{Prefixes: []string{"192.168.20.1/24"}},
+									},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route-map setting on deny",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{
+										Action: v1alpha1.RouteMapDeny,
+										Set:    &v1alpha1.RouteMapSet{LocalPreference: ptr.To[uint32](200)},
+									},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route-map with invalid community",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI: 100,
+						VRF: "red",
+						RouteMaps: []v1alpha1.RouteMapSpec{
+							{
+								Name: "export",
+								Rules: []v1alpha1.RouteMapRule{
+									{
+										Action: v1alpha1.RouteMapPermit,
+										Set:    &v1alpha1.RouteMapSet{Communities: []string{"65000:70000"}},
+									},
+								},
+							},
+						},
+						EVPNExportRouteMap: ptr.To("export"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid router svi address",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:              100,
+						VRF:              "red",
+						HostSession:      &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24", IPv6: "2001:db8::/64"}},
+						RouterSVIAddress: ptr.To("10.250.0.1/32"),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid router svi address",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:              100,
+						VRF:              "red",
+						HostSession:      &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24", IPv6: "2001:db8::/64"}},
+						RouterSVIAddress: ptr.To("10.250.0.1"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "router svi address overlapping the ipv4 local cidr",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:              100,
+						VRF:              "red",
+						HostSession:      &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24", IPv6: "2001:db8::/64"}},
+						RouterSVIAddress: ptr.To("192.168.1.10/32"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "router svi address overlapping the ipv6 local cidr",
+			vnis: []v1alpha1.L3VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "red"},
+					Spec: v1alpha1.L3VNISpec{
+						VNI:              100,
+						VRF:              "red",
+						HostSession:      &v1alpha1.HostSession{ASN: 65001, HostASN: 65002, LocalCIDR: v1alpha1.LocalCIDRConfig{IPv4: "192.168.1.0/24", IPv6: "2001:db8::/64"}},
+						RouterSVIAddress: ptr.To("2001:db8::10/128"),
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -148,228 +682,1257 @@ func TestValidateVNIs(t *testing.T) {
 	}
 }
 
-func TestValidateL2VNIs(t *testing.T) {
-	tests := []struct {
-		name    string
-		vnis    []v1alpha1.L2VNI
-		wantErr bool
-	}{
+func TestValidateL2VNIs(t *testing.T) {
+	tests := []struct {
+		name    string
+		vnis    []v1alpha1.L2VNI
+		wantErr bool
+	}{
+		{
+			name: "valid L2VNIs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1002,
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate VRF name",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						VRF: ptr.To("vrf1"),
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1002,
+						VRF: ptr.To("vrf1"),
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate VNI",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid VRF name",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						VRF: ptr.To("invalid-vrf-name-with-dashes"),
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid hostmaster name",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Name: "invalid-hostmaster-name-with-dashes",
+						},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid hostmaster name",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Name: "validhostmaster",
+						},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "nil hostmaster name with autocreate true",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							AutoCreate: true,
+						},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "datapathtype on an autocreated ovs bridge",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Type:         v1alpha1.OVSBridge,
+							AutoCreate:   true,
+							DatapathType: ptr.To(v1alpha1.OVSDatapathNetdev),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid datapathtype",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Type:         v1alpha1.OVSBridge,
+							AutoCreate:   true,
+							DatapathType: ptr.To("dpdk"),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "datapathtype on an existing ovs bridge",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Name:         "br0",
+							Type:         v1alpha1.OVSBridge,
+							DatapathType: ptr.To(v1alpha1.OVSDatapathSystem),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "datapathtype on a linux bridge",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						HostMaster: &v1alpha1.HostMaster{
+							Type:         v1alpha1.LinuxBridge,
+							AutoCreate:   true,
+							DatapathType: ptr.To(v1alpha1.OVSDatapathSystem),
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid L2GatewayIPs IPv4 CIDR",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid L2GatewayIPs IPv6 CIDR",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"2001:db8::1/64"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid L2GatewayIPs dual-stack",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24", "2001:db8::1/64"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ivalid L2GatewayIPs dual-stack both ipv4",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24", "192.168.2.1/24"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ivalid L2GatewayIPs dual-stack both ipv6",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"2002:db8::1/64", "2001:db8::1/64"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "invalid L2GatewayIPs CIDR",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"invalid-cidr-format"},
+					},
+					Status: v1alpha1.L2VNIStatus{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid L2GatewayIPs IPv4 network address",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.0/24"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid L2GatewayIPs IPv4 broadcast address",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.255/24"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid L2GatewayIPs IPv6 network address",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"2001:db8::/64"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid L2GatewayIPs IPv4 point to point",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.0/31"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid L2GatewayIPs IPv6 last address",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"2001:db8::ffff:ffff:ffff:ffff/64"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "suppressra with ipv6 L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24", "2001:db8::1/64"},
+						SuppressRA:   ptr.To(false),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "suppressra with ipv4 only L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						SuppressRA:   ptr.To(true),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "suppressra without L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:        1001,
+						SuppressRA: ptr.To(true),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "managepolicyrouting with L2GatewayIPs and linux bridge",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						HostMaster:          &v1alpha1.HostMaster{Type: v1alpha1.LinuxBridge, AutoCreate: true},
+						ManagePolicyRouting: true,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "managepolicyrouting without L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						HostMaster:          &v1alpha1.HostMaster{Type: v1alpha1.LinuxBridge, AutoCreate: true},
+						ManagePolicyRouting: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "managepolicyrouting without hostmaster",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						ManagePolicyRouting: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "centralized gatewaymode with L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						GatewayMode:  v1alpha1.CentralizedGateway,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "centralized gatewaymode without L2GatewayIPs",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						GatewayMode: v1alpha1.CentralizedGateway,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "centralized gatewaymode with managepolicyrouting",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						HostMaster:          &v1alpha1.HostMaster{Type: v1alpha1.LinuxBridge, AutoCreate: true},
+						ManagePolicyRouting: true,
+						GatewayMode:         v1alpha1.CentralizedGateway,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid gatewaymode",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						GatewayMode:  "foo",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "learning with multicast group",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:            1001,
+						Learning:       ptr.To(true),
+						MulticastGroup: ptr.To("239.1.1.1"),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "learning disabled explicitly",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:      1001,
+						Learning: ptr.To(false),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "learning without multicast group",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:      1001,
+						Learning: ptr.To(true),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "learning with unicast group",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:            1001,
+						Learning:       ptr.To(true),
+						MulticastGroup: ptr.To("192.168.1.1"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multicast group without learning",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:            1001,
+						MulticastGroup: ptr.To("239.1.1.1"),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "learning with static vteps",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						Learning:    ptr.To(true),
+						StaticVTEPs: []string{"192.168.1.1", "2001:db8::1"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "static vteps without learning",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						StaticVTEPs: []string{"192.168.1.1"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "static vteps with multicast group",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:            1001,
+						Learning:       ptr.To(true),
+						MulticastGroup: ptr.To("239.1.1.1"),
+						StaticVTEPs:    []string{"192.168.1.1"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid static vtep",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						Learning:    ptr.To(true),
+						StaticVTEPs: []string{"192.168.1"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multicast static vtep",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						Learning:    ptr.To(true),
+						StaticVTEPs: []string{"239.1.1.1"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate static vteps",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:         1001,
+						Learning:    ptr.To(true),
+						StaticVTEPs: []string{"192.168.1.1", "192.168.1.1"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mac ageing time",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:           1001,
+						MACAgeingTime: &metav1.Duration{Duration: 30 * time.Minute},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "mac ageing time too short",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:           1001,
+						MACAgeingTime: &metav1.Duration{Duration: time.Second},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mac ageing time too long",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:           1001,
+						MACAgeingTime: &metav1.Duration{Duration: 2 * time.Hour},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "managepolicyrouting with ovs bridge",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						HostMaster:          &v1alpha1.HostMaster{Type: "ovs-bridge", AutoCreate: true},
+						ManagePolicyRouting: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dscp",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:  1001,
+						DSCP: ptr.To(uint8(46)),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dscp out of range",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:  1001,
+						DSCP: ptr.To(uint8(255)),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment with df preference",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ID: ptr.To(uint32(1)), SysMAC: ptr.To("44:38:39:ff:ff:01"), DFPreference: ptr.To(uint16(50))},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "df preference without ethernet segment id",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{DFPreference: ptr.To(uint16(50))},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero df preference",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ID: ptr.To(uint32(1)), SysMAC: ptr.To("44:38:39:ff:ff:01"), DFPreference: ptr.To(uint16(0))},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment id out of range",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ID: ptr.To(uint32(1 << 24)), SysMAC: ptr.To("44:38:39:ff:ff:01")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment id without sysmac",
+			vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ID: ptr.To(uint32(1))},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
-			name: "valid L2VNIs",
+			name: "ethernet segment with invalid sysmac",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ID: ptr.To(uint32(1)), SysMAC: ptr.To("44:38:39:ff:ff")},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment with esi and df preference",
+			vnis: []v1alpha1.L2VNI{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1002,
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ESI: ptr.To("00:44:38:39:ff:ff:01:00:00:03"), DFPreference: ptr.To(uint16(50))},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "duplicate VRF name",
+			name: "ethernet segment with esi and id",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
-						VRF: ptr.To("vrf1"),
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ESI: ptr.To("00:44:38:39:ff:ff:01:00:00:03"), ID: ptr.To(uint32(1))},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment with a short esi",
+			vnis: []v1alpha1.L2VNI{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1002,
-						VRF: ptr.To("vrf1"),
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ESI: ptr.To("00:44:38:39:ff:ff:01:00:00")},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "duplicate VNI",
+			name: "ethernet segment with a non hex esi",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ESI: ptr.To("00:44:38:39:ff:ff:01:00:00:zz")},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ethernet segment with a type-3 esi",
+			vnis: []v1alpha1.L2VNI{
 				{
-					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
+						VNI:             1001,
+						EthernetSegment: &v1alpha1.EthernetSegment{ESI: ptr.To("03:44:38:39:ff:ff:01:00:00:03")},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "invalid VRF name",
+			name: "duplicate L2GatewayIPs",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
-						VRF: ptr.To("invalid-vrf-name-with-dashes"),
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1002,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "invalid hostmaster name",
+			name: "duplicate L2GatewayIPs with different prefix lengths",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
-						HostMaster: &v1alpha1.HostMaster{
-							Name: "invalid-hostmaster-name-with-dashes",
-						},
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1002,
+						L2GatewayIPs: []string{"192.168.1.1/28"},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "valid hostmaster name",
+			name: "overlapping L2GatewayIPs subnets on distinct bridges",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
-						HostMaster: &v1alpha1.HostMaster{
-							Name: "validhostmaster",
-						},
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br1", Type: v1alpha1.LinuxBridge},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1002,
+						L2GatewayIPs: []string{"192.168.1.2/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br2", Type: v1alpha1.LinuxBridge},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "nil hostmaster name with autocreate true",
+			name: "overlapping L2GatewayIPs subnets on a shared bridge",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI: 1001,
-						HostMaster: &v1alpha1.HostMaster{
-							AutoCreate: true,
-						},
+						VNI:          1001,
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br1", Type: v1alpha1.LinuxBridge},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1002,
+						L2GatewayIPs: []string{"192.168.1.2/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br1", Type: v1alpha1.LinuxBridge},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 		{
-			name: "valid L2GatewayIPs IPv4 CIDR",
+			name: "distinct L2GatewayIPs subnets on a shared bridge",
 			vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
 						VNI:          1001,
-						L2GatewayIPs: []string{"192.168.1.0/24"},
+						L2GatewayIPs: []string{"192.168.1.1/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br1", Type: v1alpha1.LinuxBridge},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1002,
+						L2GatewayIPs: []string{"192.168.2.1/24"},
+						HostMaster:   &v1alpha1.HostMaster{Name: "br1", Type: v1alpha1.LinuxBridge},
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: false,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateL2VNIs(tt.vnis)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateL2VNIs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateL2VNIsWithL3VNIs(t *testing.T) {
+	l3vnis := []v1alpha1.L3VNI{
 		{
-			name: "valid L2GatewayIPs IPv6 CIDR",
-			vnis: []v1alpha1.L2VNI{
+			ObjectMeta: metav1.ObjectMeta{Name: "red"},
+			Spec: v1alpha1.L3VNISpec{
+				VRF: "red",
+				VNI: 100,
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		l2vnis  []v1alpha1.L2VNI
+		l3vnis  []v1alpha1.L3VNI
+		wantErr bool
+	}{
+		{
+			name: "l2vni bound to an existing l3vni vrf",
+			l2vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI:          1001,
-						L2GatewayIPs: []string{"2001:db8::/64"},
+						VNI: 1001,
+						VRF: ptr.To("red"),
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
+			l3vnis:  l3vnis,
 			wantErr: false,
 		},
 		{
-			name: "valid L2GatewayIPs dual-stack",
-			vnis: []v1alpha1.L2VNI{
+			name: "l2vni without vrf",
+			l2vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI:          1001,
-						L2GatewayIPs: []string{"192.168.1.0/24", "2001:db8::/64"},
+						VNI: 1001,
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "ivalid L2GatewayIPs dual-stack both ipv4",
-			vnis: []v1alpha1.L2VNI{
+			name: "l2vni with dangling vrf",
+			l2vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI:          1001,
-						L2GatewayIPs: []string{"192.168.1.0/24", "192.168.2.0/24"},
+						VNI: 1001,
+						VRF: ptr.To("blue"),
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
+			l3vnis:  l3vnis,
 			wantErr: true,
 		},
 		{
-			name: "ivalid L2GatewayIPs dual-stack both ipv6",
-			vnis: []v1alpha1.L2VNI{
+			name: "l2vni with vrf and no l3vnis",
+			l2vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI:          1001,
-						L2GatewayIPs: []string{"2002:db8::/64", "2001:db8::/64"},
+						VNI: 1001,
+						VRF: ptr.To("red"),
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
 			wantErr: true,
 		},
-
 		{
-			name: "invalid L2GatewayIPs CIDR",
-			vnis: []v1alpha1.L2VNI{
+			name: "l2vni advertising host routes in a l3vni vrf",
+			l2vnis: []v1alpha1.L2VNI{
 				{
 					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
 					Spec: v1alpha1.L2VNISpec{
-						VNI:          1001,
-						L2GatewayIPs: []string{"invalid-cidr-format"},
+						VNI:                 1001,
+						VRF:                 ptr.To("red"),
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						AdvertiseHostRoutes: ptr.To(true),
+					},
+				},
+			},
+			l3vnis:  l3vnis,
+			wantErr: false,
+		},
+		{
+			name: "l2vni advertising host routes without vrf",
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						L2GatewayIPs:        []string{"192.168.1.1/24"},
+						AdvertiseHostRoutes: ptr.To(true),
+					},
+				},
+			},
+			l3vnis:  l3vnis,
+			wantErr: true,
+		},
+		{
+			name: "l2vni disabling conntrack without l2gatewayips",
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:              1001,
+						VRF:              ptr.To("red"),
+						DisableConntrack: true,
+					},
+				},
+			},
+			l3vnis:  l3vnis,
+			wantErr: true,
+		},
+		{
+			name: "l2vni advertising host routes without l2gatewayips",
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:                 1001,
+						VRF:                 ptr.To("red"),
+						AdvertiseHostRoutes: ptr.To(true),
+					},
+				},
+			},
+			l3vnis:  l3vnis,
+			wantErr: true,
+		},
+		{
+			name: "invalid l2vnis",
+			l2vnis: []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
+						VRF: ptr.To("red"),
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni2"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI: 1001,
 					},
-					Status: v1alpha1.L2VNIStatus{},
 				},
 			},
+			l3vnis:  l3vnis,
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateL2VNIs(tt.vnis)
+			err := ValidateL2VNIsWithL3VNIs(tt.l2vnis, tt.l3vnis)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("ValidateL2VNIs() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("ValidateL2VNIsWithL3VNIs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDeviceNames(t *testing.T) {
+	l3vnis := []v1alpha1.L3VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "red"},
+			Spec:       v1alpha1.L3VNISpec{VNI: 100, VRF: "red"},
+		},
+	}
+	l2vnis := []v1alpha1.L2VNI{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "blue"},
+			Spec:       v1alpha1.L2VNISpec{VNI: 123456},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		wantErr bool
+	}{
+		{
+			name: "no prefix",
+		},
+		{
+			name:   "prefix fitting all the vnis",
+			prefix: "op-",
+		},
+		{
+			name:    "prefix too long for the largest vni",
+			prefix:  "opr-",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceNames(tt.prefix, l3vnis, l2vnis)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDeviceNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeNADSubnetLister map[string][]string
+
+func (f fakeNADSubnetLister) SubnetsFor(l2vni v1alpha1.L2VNI) ([]string, error) {
+	return f[l2vni.Name], nil
+}
+
+func TestValidateL2VNIGatewaysInNADSubnets(t *testing.T) {
+	tests := []struct {
+		name       string
+		gatewayIPs []string
+		subnets    []string
+		wantErr    bool
+	}{
+		{
+			name:       "no nad subnet known",
+			gatewayIPs: []string{"192.170.1.1/24"},
+		},
+		{
+			name:       "gateway within the nad subnet",
+			gatewayIPs: []string{"192.170.1.1/24"},
+			subnets:    []string{"192.170.1.0/24"},
+		},
+		{
+			name:       "gateway within one of the nad subnets",
+			gatewayIPs: []string{"192.170.1.1/24", "2001:db8::1/64"},
+			subnets:    []string{"192.171.1.0/24", "192.170.1.0/25", "2001:db8::/64"},
+		},
+		{
+			name:       "gateway outside the nad subnet",
+			gatewayIPs: []string{"192.170.1.1/24"},
+			subnets:    []string{"192.171.1.0/24"},
+			wantErr:    true,
+		},
+		{
+			name:       "ipv6 gateway outside the nad subnet",
+			gatewayIPs: []string{"192.170.1.1/24", "2001:db8::1/64"},
+			subnets:    []string{"192.170.1.0/24", "2001:db9::/64"},
+			wantErr:    true,
+		},
+		{
+			name:       "no nad subnet of the family of the gateway",
+			gatewayIPs: []string{"192.170.1.1/24", "2001:db8::1/64"},
+			subnets:    []string{"192.170.1.0/24"},
+		},
+		{
+			name:    "no gateway",
+			subnets: []string{"192.170.1.0/24"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l2vnis := []v1alpha1.L2VNI{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vni1"},
+					Spec: v1alpha1.L2VNISpec{
+						VNI:          1001,
+						L2GatewayIPs: tt.gatewayIPs,
+					},
+				},
+			}
+			err := ValidateL2VNIGatewaysInNADSubnets(l2vnis, fakeNADSubnetLister{"vni1": tt.subnets})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateL2VNIGatewaysInNADSubnets() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
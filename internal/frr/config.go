@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"slices"
 	"text/template"
 
 	"github.com/openperouter/openperouter/internal/ipfamily"
@@ -19,24 +21,109 @@ var (
 	templates embed.FS
 )
 
+// LogLevels are the log levels supported by FRR.
+var LogLevels = []string{
+	"emergencies",
+	"alerts",
+	"critical",
+	"errors",
+	"warnings",
+	"notifications",
+	"informational",
+	"debugging",
+}
+
+// ValidateLogLevel returns an error if the given level is not a FRR log level.
+func ValidateLogLevel(level string) error {
+	if !slices.Contains(LogLevels, level) {
+		return fmt.Errorf("invalid frr log level %s: possible values are %v", level, LogLevels)
+	}
+	return nil
+}
+
+// MaxBGPListenLimit is the highest limit of the dynamic
+// neighbors accepted by the bgp router FRR supports.
+const MaxBGPListenLimit = 65535
+
+// ValidateBGPListenLimit returns an error if the given limit of the dynamic
+// neighbors is out of the range supported by FRR. Zero leaves the default.
+func ValidateBGPListenLimit(limit int) error {
+	if limit < 0 || limit > MaxBGPListenLimit {
+		return fmt.Errorf("invalid frr bgp listen limit %d: must be between 0 and %d", limit, MaxBGPListenLimit)
+	}
+	return nil
+}
+
 type Config struct {
 	Loglevel    string
 	Hostname    string
 	Underlay    UnderlayConfig
 	VNIs        []L3VNIConfig
+	L2Gateways  []L2GatewayConfig
 	Passthrough *PassthroughConfig
 	BFDProfiles []BFDProfile
+	// EthernetSegments are the EVPN multihoming
+	// ethernet segments of the L2VNIs.
+	EthernetSegments []EthernetSegmentConfig
+	// BGPListenLimit is the maximum number of dynamic neighbors each
+	// bgp router accepts. Zero leaves the FRR default.
+	BGPListenLimit uint32
 }
 
 type UnderlayConfig struct {
-	MyASN     uint32
-	RouterID  string
-	Neighbors []NeighborConfig
-	EVPN      *UnderlayEvpn
+	MyASN      uint32
+	RouterID   string
+	BestPath   *BestPathConfig
+	PeerGroups []PeerGroupConfig
+	Neighbors  []NeighborConfig
+	EVPN       *UnderlayEvpn
+
+	// UpdateDelay is the bgp update-delay in seconds, and CoalesceTime
+	// the bgp coalesce-time in milliseconds. Zero leaves them unset.
+	UpdateDelay  uint32
+	CoalesceTime uint32
+}
+
+// BestPathConfig contains the options of the
+// best path selection of the default bgp router.
+type BestPathConfig struct {
+	ASPathMultipathRelax bool
+	ASPathIgnore         bool
+	CompareRouterID      bool
+	MEDMissingAsWorst    bool
+}
+
+// PeerGroupConfig is a peer group the underlay neighbors can be
+// assigned to. A zero ASN means the group has no remote-as set.
+type PeerGroupConfig struct {
+	Name          string
+	ASN           uint32
+	HoldTime      *uint64
+	KeepaliveTime *uint64
 }
 
 type UnderlayEvpn struct {
 	VTEP string
+	// VTEPv6 is the ipv6 address of a dual stack VTEP, if any.
+	VTEPv6 string
+	// RestrictAdvertisedVNIs restricts the MAC/IP and multicast routes
+	// advertised to the neighbors to the ones of AdvertisedVNIs.
+	RestrictAdvertisedVNIs bool
+	AdvertisedVNIs         []uint32
+	VNIs                   []uint32
+	// RouteTargetASN, when set, is the ASN the route targets of
+	// the VNIs listed in VNIs are derived from, as <ASN>:<VNI>.
+	RouteTargetASN uint32
+	// DuplicateAddressDetection, when set, overrides the
+	// default thresholds of the duplicate address detection.
+	DuplicateAddressDetection *DADConfig
+}
+
+// DADConfig flags as duplicate the addresses moving more
+// than MaxMoves times within Time seconds.
+type DADConfig struct {
+	MaxMoves uint32
+	Time     uint32
 }
 
 type PassthroughConfig struct {
@@ -54,6 +141,63 @@ type L3VNIConfig struct {
 	VRF             string
 	VNI             int
 	RouterID        string
+	// ImportVRFs are the VRFs whose routes are leaked into this VRF.
+	ImportVRFs []string
+	// LeakToDefaultIPv4 and LeakToDefaultIPv6 are the prefixes of
+	// this VRF leaked into the default VRF. The leaked routes are
+	// tagged as no-advertise, not to be advertised to the fabric.
+	LeakToDefaultIPv4 []string
+	LeakToDefaultIPv6 []string
+	// NoAdvertiseIPv4 and NoAdvertiseIPv6 disable advertising the
+	// routes of the given family of this VRF as EVPN type-5 routes.
+	NoAdvertiseIPv4 bool
+	NoAdvertiseIPv6 bool
+	// NoFabricAdvertise keeps the VRF local to the node,
+	// leaving out its EVPN address family altogether.
+	NoFabricAdvertise bool
+	// RouteTargetASN, when set, is the ASN the route targets
+	// of the VNI are derived from, as <ASN>:<VNI>.
+	RouteTargetASN uint32
+	// ExportRouteMap, when set, filters the routes of the
+	// VRF advertised as EVPN type-5 routes.
+	ExportRouteMap *RouteMapConfig
+}
+
+// RouteMapConfig is a route-map split by family. Name is the prefix of
+// the names of the route-map of each family and of their prefix lists.
+type RouteMapConfig struct {
+	Name string
+	IPv4 []RouteMapEntry
+	IPv6 []RouteMapEntry
+}
+
+// RouteMapEntry is an entry of a route-map. An entry without
+// prefixes matches all the routes.
+type RouteMapEntry struct {
+	Seq             int
+	Action          string
+	Prefixes        []string
+	Communities     []string
+	LocalPreference *uint32
+}
+
+// L2GatewayConfig is the IPv6 configuration of
+// the interface acting as gateway of a L2VNI.
+type L2GatewayConfig struct {
+	Interface  string
+	SuppressRA bool
+}
+
+// EthernetSegmentConfig is the EVPN multihoming ethernet segment
+// configuration of the interface of a L2VNI. The segment is identified
+// either by the type-0 ESI, or by the ID and the SysMAC forming a type-3
+// one. A zero DFPreference leaves the default preference of FRR.
+type EthernetSegmentConfig struct {
+	Interface    string
+	ID           uint32
+	SysMAC       string
+	ESI          string
+	DFPreference uint16
 }
 
 type BFDProfile struct {
@@ -68,18 +212,168 @@ type BFDProfile struct {
 }
 
 type NeighborConfig struct {
-	Name          string
-	ASN           uint32
-	Addr          string
-	Port          *uint16
-	HoldTime      *uint64
-	KeepaliveTime *uint64
-	ConnectTime   *uint64
-	Password      string
-	BFDEnabled    bool
-	BFDProfile    string
-	EBGPMultiHop  bool
-	IPFamily      ipfamily.Family
+	Name            string
+	ASN             uint32
+	Addr            string
+	Port            *uint16
+	HoldTime        *uint64
+	KeepaliveTime   *uint64
+	ConnectTime     *uint64
+	Password        string
+	BFDEnabled      bool
+	BFDProfile      string
+	EBGPMultiHop    bool
+	EBGPMultiHopTTL *uint8
+	IPFamily        ipfamily.Family
+	NextHopSelf     *NextHopSelf
+	// ListenRange, when set, makes the neighbor a peer group named
+	// after Addr accepting dynamic sessions from the given CIDR.
+	ListenRange string
+	// StripCommunities removes the communities from the
+	// routes advertised to the neighbor.
+	StripCommunities bool
+	// PeerGroup is the name of the peer group the neighbor is assigned to.
+	PeerGroup string
+	// ASNFromPeerGroup tells the remote-as of the neighbor
+	// is inherited from its peer group.
+	ASNFromPeerGroup bool
+	// SendCommunity is the type of the communities sent to the
+	// neighbor (standard, extended, large, all or none). Empty
+	// leaves the default of FRR, sending all of them.
+	SendCommunity string
+	// Description is a free text identifying the neighbor.
+	Description string
+	// ExtendedNextHop advertises the extended next hop capability,
+	// to exchange IPv4 routes over an IPv6 session.
+	ExtendedNextHop bool
+	// HostAdvertise, when set, limits the routes advertised
+	// to the neighbor to the given prefixes.
+	HostAdvertise *HostAdvertiseConfig
+	// Shutdown administratively shuts down the session
+	// with the neighbor, keeping its configuration.
+	Shutdown bool
+	// AllowASIn, when not zero, is the number of times the local
+	// AS number is accepted in the AS path of the received routes.
+	AllowASIn uint8
+}
+
+type NextHopSelf struct {
+	Force bool
+}
+
+// HostAdvertiseConfig lists the only prefixes advertised to a host
+// neighbor. Name is the prefix of the names of the route-maps and
+// of the prefix lists implementing the filter, shared by the host
+// neighbors with the same prefixes.
+type HostAdvertiseConfig struct {
+	Name string
+	IPv4 []string
+	IPv6 []string
+}
+
+// StripsCommunities tells whether any of the host neighbors
+// requires the communities to be stripped.
+func (c *Config) StripsCommunities() bool {
+	for _, vni := range c.VNIs {
+		if vni.LocalNeighbor != nil && vni.LocalNeighbor.StripCommunities {
+			return true
+		}
+	}
+	if c.Passthrough == nil {
+		return false
+	}
+	for _, n := range []*NeighborConfig{c.Passthrough.LocalNeighborV4, c.Passthrough.LocalNeighborV6} {
+		if n != nil && n.StripCommunities {
+			return true
+		}
+	}
+	return false
+}
+
+// HostAdvertisingNeighbors returns the host neighbors the advertised
+// routes are limited for, one for each filter.
+func (c *Config) HostAdvertisingNeighbors() []*NeighborConfig {
+	neighbors := []*NeighborConfig{}
+	for _, vni := range c.VNIs {
+		neighbors = append(neighbors, vni.LocalNeighbor)
+	}
+	if c.Passthrough != nil {
+		neighbors = append(neighbors, c.Passthrough.LocalNeighborV4, c.Passthrough.LocalNeighborV6)
+	}
+
+	res := []*NeighborConfig{}
+	seen := map[string]bool{}
+	for _, n := range neighbors {
+		if n == nil || n.HostAdvertise == nil || seen[n.HostAdvertise.Name] {
+			continue
+		}
+		seen[n.HostAdvertise.Name] = true
+		res = append(res, n)
+	}
+	return res
+}
+
+// ExportRouteMaps returns the route-maps filtering the routes
+// advertised as EVPN type-5 routes, each one once.
+func (c *Config) ExportRouteMaps() []*RouteMapConfig {
+	res := []*RouteMapConfig{}
+	seen := map[string]bool{}
+	for _, vni := range c.VNIs {
+		if vni.ExportRouteMap == nil || seen[vni.ExportRouteMap.Name] {
+			continue
+		}
+		seen[vni.ExportRouteMap.Name] = true
+		res = append(res, vni.ExportRouteMap)
+	}
+	return res
+}
+
+// VRFsLeakingToDefault returns the VRFs leaking some of their
+// prefixes of the given family into the default VRF.
+func (c *Config) VRFsLeakingToDefault(family string) []string {
+	res := []string{}
+	for _, vni := range c.VNIs {
+		prefixes := vni.LeakToDefaultIPv4
+		if family == string(ipfamily.IPv6) {
+			prefixes = vni.LeakToDefaultIPv6
+		}
+		if len(prefixes) > 0 {
+			res = append(res, vni.VRF)
+		}
+	}
+	return res
+}
+
+// prefixRange returns the prefix list range matching the given
+// prefix together with all the more specific ones.
+func prefixRange(prefix string) string {
+	_, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return prefix
+	}
+	ones, bits := ipNet.Mask.Size()
+	if ones == bits {
+		return prefix
+	}
+	return fmt.Sprintf("%s le %d", prefix, bits)
+}
+
+// CommunitiesNotSent returns the types of the communities to stop
+// sending to the neighbor, as FRR sends all of them by default and
+// enabling a single type does not disable the others.
+func (n *NeighborConfig) CommunitiesNotSent() []string {
+	res := []string{}
+	switch n.SendCommunity {
+	case "standard", "extended", "large":
+	default:
+		return res
+	}
+	for _, c := range []string{"standard", "extended", "large"} {
+		if c != n.SendCommunity {
+			res = append(res, c)
+		}
+	}
+	return res
 }
 
 func (n *NeighborConfig) ID() string {
@@ -122,6 +416,7 @@ func templateConfig(data interface{}) (string, error) {
 			"activateNeighborFor": func(ipFamily string, neighbourFamily ipfamily.Family) bool {
 				return string(neighbourFamily) == ipFamily
 			},
+			"prefixRange": prefixRange,
 		}).ParseFS(templates, "templates/*")
 	if err != nil {
 		return "", err
@@ -132,10 +427,9 @@ func templateConfig(data interface{}) (string, error) {
 	return b.String(), err
 }
 
-// generateAndReloadConfigFile takes a 'struct Config' and, using a template,
-// generates and writes a valid FRR configuration file. If this completes
-// successfully it will also force FRR to reload that configuration file.
-func generateAndReloadConfigFile(ctx context.Context, config *Config, updater ConfigUpdater) error {
+// generateConfigFile takes a 'struct Config' and, using a template,
+// generates a valid FRR configuration file.
+func generateConfigFile(ctx context.Context, config *Config) (string, error) {
 	slog.InfoContext(ctx, "frr generate config", "event", "start")
 	defer slog.InfoContext(ctx, "frr generate config", "event", "stop")
 
@@ -144,9 +438,21 @@ func generateAndReloadConfigFile(ctx context.Context, config *Config, updater Co
 	configString, err := templateConfig(config)
 	if err != nil {
 		slog.Error("failed to generate config from template", "error", err, "cause", "template", "config", config)
-		return err
+		return "", err
 	}
+	configString = configHeader(configString) + configString
 	slog.DebugContext(ctx, "frr generaetd configuration", "config", configString)
+	return configString, nil
+}
+
+// generateAndReloadConfigFile takes a 'struct Config' and, using a template,
+// generates and writes a valid FRR configuration file. If this completes
+// successfully it will also force FRR to reload that configuration file.
+func generateAndReloadConfigFile(ctx context.Context, config *Config, updater ConfigUpdater) error {
+	configString, err := generateConfigFile(ctx, config)
+	if err != nil {
+		return err
+	}
 	err = updater(ctx, configString)
 	if err != nil {
 		slog.Error("failed to write frr config", "error", err, "cause", "updater", "config", config)
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"errors"
 
@@ -30,6 +31,12 @@ func init() {
 	osHostname = func() (string, error) {
 		return "hostname", nil
 	}
+	operatorVersion = func() string {
+		return "v0.0.0-test"
+	}
+	timeNow = func() time.Time {
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
 }
 
 func TestMain(m *testing.M) {
@@ -30,6 +30,18 @@ func ApplyConfig(ctx context.Context, config *Config, updater ConfigUpdater) err
 	return generateAndReloadConfigFile(ctx, config, updater)
 }
 
+// GenerateConfig returns the FRR configuration file for the given
+// configuration, without applying it.
+func GenerateConfig(ctx context.Context, config *Config) (string, error) {
+	hostname, err := osHostname()
+	if err != nil {
+		return "", err
+	}
+
+	config.Hostname = hostname
+	return generateConfigFile(ctx, config)
+}
+
 func NewFRR(logger log.Logger) *FRR {
 	res := &FRR{}
 	return res
@@ -154,11 +154,1123 @@ func TestIPv6Only(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
+func TestLocalNeighborExtendedNextHop(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:             64515,
+					Addr:            "2001:db8::2",
+					IPFamily:        ipfamily.IPv6,
+					ExtendedNextHop: true,
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.250.1/32",
+				},
+				ToAdvertiseIPv6: []string{
+					"2001:db8::2/128",
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestDualStackVTEP(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP:   "100.64.0.1/32",
+				VTEPv6: "fd00:64::1/128",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+				{
+					ASN:      64513,
+					Addr:     "2001:db8::1",
+					IPFamily: ipfamily.IPv6,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestPeerGroups(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			PeerGroups: []PeerGroupConfig{
+				{
+					Name:          "spines",
+					ASN:           64513,
+					HoldTime:      ptr.To(uint64(30)),
+					KeepaliveTime: ptr.To(uint64(10)),
+				},
+				{
+					Name: "leaves",
+				},
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:              64513,
+					Addr:             "192.168.1.2",
+					IPFamily:         ipfamily.IPv4,
+					PeerGroup:        "spines",
+					ASNFromPeerGroup: true,
+				},
+				{
+					ASN:              64513,
+					Addr:             "192.168.1.3",
+					IPFamily:         ipfamily.IPv4,
+					PeerGroup:        "spines",
+					ASNFromPeerGroup: true,
+				},
+				{
+					ASN:       64514,
+					Addr:      "192.168.2.2",
+					IPFamily:  ipfamily.IPv4,
+					PeerGroup: "leaves",
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
 func TestEmpty(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
-	config := Config{}
+	config := Config{}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestNoVNIs(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestBFDEnabled(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:        64512,
+					Addr:       "192.168.1.2",
+					IPFamily:   ipfamily.IPv4,
+					BFDEnabled: true,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestBFDProfile(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:        64512,
+					Addr:       "192.168.1.2",
+					IPFamily:   ipfamily.IPv4,
+					BFDEnabled: true,
+					BFDProfile: "foo",
+				},
+			},
+		},
+		BFDProfiles: []BFDProfile{
+			{
+				Name:            "foo",
+				ReceiveInterval: ptr.To(uint32(43)),
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestL3VNIWithoutLocalNeighborAndAdvertise(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				RouterID: "10.0.0.1",
+				VRF:      "red",
+				VNI:      100,
+				ASN:      64512,
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestImportVRFs(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				RouterID: "10.0.0.1",
+				VRF:      "red",
+				VNI:      100,
+				ASN:      64512,
+			},
+			{
+				RouterID:   "10.0.0.1",
+				VRF:        "blue",
+				VNI:        200,
+				ASN:        64512,
+				ImportVRFs: []string{"red"},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestLeakToDefault(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				RouterID:          "10.0.0.1",
+				VRF:               "red",
+				VNI:               100,
+				ASN:               64512,
+				LeakToDefaultIPv4: []string{"192.168.20.0/24", "192.168.30.10/32"},
+				LeakToDefaultIPv6: []string{"2001:db8:20::/64"},
+			},
+			{
+				RouterID:          "10.0.0.1",
+				VRF:               "blue",
+				VNI:               200,
+				ASN:               64512,
+				LeakToDefaultIPv4: []string{"192.168.21.0/24"},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestVNIAdvertisedFamilies(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				RouterID:        "10.0.0.1",
+				VRF:             "red",
+				VNI:             100,
+				ASN:             64512,
+				NoAdvertiseIPv6: true,
+			},
+			{
+				RouterID:        "10.0.0.1",
+				VRF:             "blue",
+				VNI:             200,
+				ASN:             64512,
+				NoAdvertiseIPv4: true,
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestVNINoFabricAdvertise(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				ASN:      64512,
+				VNI:      100,
+				VRF:      "red",
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:  64515,
+					Addr: "192.168.10.2",
+				},
+				ToAdvertiseIPv4:   []string{"192.168.10.0/24"},
+				NoFabricAdvertise: true,
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestUnderlayBestPath(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			BestPath: &BestPathConfig{
+				ASPathMultipathRelax: true,
+				ASPathIgnore:         true,
+				CompareRouterID:      true,
+				MEDMissingAsWorst:    true,
+			},
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestUnderlayUpdateDelay(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:        64512,
+			RouterID:     "10.0.0.1",
+			UpdateDelay:  120,
+			CoalesceTime: 1500,
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestAdvertisedVNIs(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP:                   "100.64.0.1/32",
+				RestrictAdvertisedVNIs: true,
+				AdvertisedVNIs:         []uint32{110, 120},
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.3",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestRouteTargetASN(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP:           "100.64.0.1/32",
+				VNIs:           []uint32{110},
+				RouteTargetASN: 65100,
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:            "red",
+				ASN:            64512,
+				VNI:            100,
+				RouterID:       "10.0.0.1",
+				RouteTargetASN: 65100,
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestDuplicateAddressDetection(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+				DuplicateAddressDetection: &DADConfig{
+					MaxMoves: 10,
+					Time:     300,
+				},
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestEVPNExportRouteMap(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	exportRouteMap := &RouteMapConfig{
+		Name: "red-export-filter",
+		IPv4: []RouteMapEntry{
+			{Seq: 1, Action: "deny", Prefixes: []string{"192.168.20.0/24"}},
+			{
+				Seq:             2,
+				Action:          "permit",
+				Prefixes:        []string{"192.168.30.0/24", "192.168.31.0/24"},
+				Communities:     []string{"65000:100", "65000:200"},
+				LocalPreference: ptr.To[uint32](200),
+			},
+			{Seq: 3, Action: "permit"},
+		},
+	}
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:            "red",
+				ASN:            64512,
+				VNI:            100,
+				RouterID:       "10.0.0.1",
+				ExportRouteMap: exportRouteMap,
+				LocalNeighbor: &NeighborConfig{
+					ASN:      64515,
+					Addr:     "192.168.10.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+			{
+				VRF:            "red",
+				ASN:            64512,
+				VNI:            100,
+				RouterID:       "10.0.0.1",
+				ExportRouteMap: exportRouteMap,
+				LocalNeighbor: &NeighborConfig{
+					ASN:      64515,
+					Addr:     "2001:db8:10::2",
+					IPFamily: ipfamily.IPv6,
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+// TestBGPListenLimit covers the nodes with many hosts peering with the
+// router, where the number of dynamic neighbors accepted by default by a
+// single listener becomes the bottleneck. The limit must be raised on the
+// default router as well as on each vrf one, as it applies per instance.
+func TestBGPListenLimit(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:         64515,
+					Addr:        "hosts-ipv4",
+					ListenRange: "192.168.10.0/24",
+				},
+			},
+		},
+		BGPListenLimit: 1000,
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestHostSessionAllowASIn(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:       64515,
+					Addr:      "192.168.10.2",
+					IPFamily:  ipfamily.IPv4,
+					AllowASIn: 2,
+				},
+				ToAdvertiseIPv4: []string{"192.168.10.2/32"},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:       64515,
+				Addr:      "192.168.11.2",
+				IPFamily:  ipfamily.IPv4,
+				AllowASIn: 2,
+			},
+			ToAdvertiseIPv4: []string{"192.168.11.2/32"},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestPassthroughNoEVPN(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:      64512,
+				Addr:     "192.168.1.3",
+				IPFamily: ipfamily.IPv4,
+			},
+			ToAdvertiseIPv4: []string{
+				"192.169.20.0/24",
+				"192.169.21.0/24",
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestPassthroughV4(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:      64512,
+				Addr:     "192.168.1.3",
+				IPFamily: ipfamily.IPv4,
+			},
+			ToAdvertiseIPv4: []string{
+				"192.169.20.0/24",
+				"192.169.21.0/24",
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestPassthroughDual(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:      64512,
+				Addr:     "192.168.1.3",
+				IPFamily: ipfamily.IPv4,
+			},
+			LocalNeighborV6: &NeighborConfig{
+				ASN:      64512,
+				Addr:     "2001:db8:20::2",
+				IPFamily: ipfamily.IPv6,
+			},
+			ToAdvertiseIPv4: []string{
+				"192.169.20.0/24",
+				"192.169.21.0/24",
+			},
+			ToAdvertiseIPv6: []string{
+				"2001:db8:20::/64",
+				"2001:db8:21::/64",
+			},
+		},
+		VNIs: []L3VNIConfig{},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestNextHopSelf(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:         64515,
+					Addr:        "192.168.10.2",
+					IPFamily:    ipfamily.IPv4,
+					NextHopSelf: &NextHopSelf{},
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:         64515,
+				Addr:        "192.168.1.3",
+				IPFamily:    ipfamily.IPv4,
+				NextHopSelf: &NextHopSelf{Force: true},
+			},
+			ToAdvertiseIPv4: []string{
+				"192.168.1.3/32",
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestStripCommunities(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64512,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:              64515,
+					Addr:             "192.168.10.2",
+					IPFamily:         ipfamily.IPv4,
+					StripCommunities: true,
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:              64515,
+				Addr:             "192.168.1.3",
+				IPFamily:         ipfamily.IPv4,
+				StripCommunities: true,
+			},
+			ToAdvertiseIPv4: []string{
+				"192.168.1.3/32",
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestSendCommunity(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:           64513,
+					Addr:          "192.168.1.2",
+					IPFamily:      ipfamily.IPv4,
+					SendCommunity: "none",
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:           64515,
+					Addr:          "192.168.10.2",
+					IPFamily:      ipfamily.IPv4,
+					SendCommunity: "standard",
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:           64515,
+				Addr:          "192.168.1.3",
+				IPFamily:      ipfamily.IPv4,
+				SendCommunity: "all",
+			},
+			ToAdvertiseIPv4: []string{
+				"192.168.1.3/32",
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestSendCommunityEVPN(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:           64513,
+					Addr:          "192.168.1.2",
+					IPFamily:      ipfamily.IPv4,
+					SendCommunity: "standard",
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestNeighborDescription(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:         64513,
+					Addr:        "192.168.1.2",
+					IPFamily:    ipfamily.IPv4,
+					Description: "spine-1 rack 3",
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:         64515,
+					Addr:        "192.168.10.2",
+					IPFamily:    ipfamily.IPv4,
+					Description: "red host",
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+		},
+	}
 	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
 		t.Fatalf("Failed to apply config: %s", err)
 	}
@@ -166,7 +1278,7 @@ func TestEmpty(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestNoVNIs(t *testing.T) {
+func TestExtendedNextHop(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
@@ -179,9 +1291,10 @@ func TestNoVNIs(t *testing.T) {
 			RouterID: "10.0.0.1",
 			Neighbors: []NeighborConfig{
 				{
-					ASN:      64512,
-					Addr:     "192.168.1.2",
-					IPFamily: ipfamily.IPv4,
+					ASN:             64513,
+					Addr:            "2001:db8::2",
+					IPFamily:        ipfamily.IPv6,
+					ExtendedNextHop: true,
 				},
 			},
 		},
@@ -193,10 +1306,14 @@ func TestNoVNIs(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestBFDEnabled(t *testing.T) {
+func TestHostAdvertise(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
+	hostAdvertise := &HostAdvertiseConfig{
+		Name: "red-host-advertise",
+		IPv4: []string{"192.169.20.0/24", "192.169.21.0/24"},
+	}
 	config := Config{
 		Underlay: UnderlayConfig{
 			MyASN: 64512,
@@ -206,12 +1323,58 @@ func TestBFDEnabled(t *testing.T) {
 			RouterID: "10.0.0.1",
 			Neighbors: []NeighborConfig{
 				{
-					ASN:        64512,
-					Addr:       "192.168.1.2",
-					IPFamily:   ipfamily.IPv4,
-					BFDEnabled: true,
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
+			},
+		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:              64515,
+					Addr:             "192.168.10.2",
+					IPFamily:         ipfamily.IPv4,
+					StripCommunities: true,
+					HostAdvertise:    hostAdvertise,
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:              64515,
+					Addr:             "2001:db8:10::2",
+					IPFamily:         ipfamily.IPv6,
+					StripCommunities: true,
+					HostAdvertise:    hostAdvertise,
+				},
+				ToAdvertiseIPv6: []string{
+					"2001:db8:10::2/128",
+				},
+			},
+		},
+		Passthrough: &PassthroughConfig{
+			LocalNeighborV4: &NeighborConfig{
+				ASN:      64516,
+				Addr:     "192.168.9.2",
+				IPFamily: ipfamily.IPv4,
+				HostAdvertise: &HostAdvertiseConfig{
+					Name: "passthrough-host-advertise",
+					IPv4: []string{"10.100.0.0/16"},
+					IPv6: []string{"2001:db8:100::/48"},
 				},
 			},
+			ToAdvertiseIPv4: []string{"192.168.9.2/32"},
 		},
 	}
 	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
@@ -221,7 +1384,7 @@ func TestBFDEnabled(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestBFDProfile(t *testing.T) {
+func TestLocalNeighborConnectTime(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
@@ -234,18 +1397,27 @@ func TestBFDProfile(t *testing.T) {
 			RouterID: "10.0.0.1",
 			Neighbors: []NeighborConfig{
 				{
-					ASN:        64512,
-					Addr:       "192.168.1.2",
-					IPFamily:   ipfamily.IPv4,
-					BFDEnabled: true,
-					BFDProfile: "foo",
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
 				},
 			},
 		},
-		BFDProfiles: []BFDProfile{
+		VNIs: []L3VNIConfig{
 			{
-				Name:            "foo",
-				ReceiveInterval: ptr.To(uint32(43)),
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:         64515,
+					Addr:        "192.168.10.2",
+					IPFamily:    ipfamily.IPv4,
+					ConnectTime: ptr.To(uint64(2)),
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
 			},
 		},
 	}
@@ -256,31 +1428,41 @@ func TestBFDProfile(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestL3VNIWithoutLocalNeighborAndAdvertise(t *testing.T) {
+func TestShutdownNeighbors(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
 	config := Config{
 		Underlay: UnderlayConfig{
-			MyASN:    64512,
-			RouterID: "10.0.0.1",
+			MyASN: 64512,
 			EVPN: &UnderlayEvpn{
 				VTEP: "100.64.0.1/32",
 			},
+			RouterID: "10.0.0.1",
 			Neighbors: []NeighborConfig{
 				{
-					ASN:      64512,
+					ASN:      64513,
 					Addr:     "192.168.1.2",
 					IPFamily: ipfamily.IPv4,
+					Shutdown: true,
 				},
 			},
 		},
 		VNIs: []L3VNIConfig{
 			{
-				RouterID: "10.0.0.1",
 				VRF:      "red",
-				VNI:      100,
 				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:      64515,
+					Addr:     "192.168.10.2",
+					IPFamily: ipfamily.IPv4,
+					Shutdown: true,
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
 			},
 		},
 	}
@@ -291,13 +1473,51 @@ func TestL3VNIWithoutLocalNeighborAndAdvertise(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestPassthroughNoEVPN(t *testing.T) {
+func TestEBGPMultiHop(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
 	config := Config{
 		Underlay: UnderlayConfig{
-			MyASN:    64512,
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:          64513,
+					Addr:         "192.168.1.2",
+					IPFamily:     ipfamily.IPv4,
+					EBGPMultiHop: true,
+				},
+				{
+					ASN:             64514,
+					Addr:            "192.168.2.2",
+					IPFamily:        ipfamily.IPv4,
+					EBGPMultiHop:    true,
+					EBGPMultiHopTTL: ptr.To[uint8](4),
+				},
+			},
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestDynamicPeers(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN: 64512,
+			EVPN: &UnderlayEvpn{
+				VTEP: "100.64.0.1/32",
+			},
 			RouterID: "10.0.0.1",
 			Neighbors: []NeighborConfig{
 				{
@@ -307,15 +1527,30 @@ func TestPassthroughNoEVPN(t *testing.T) {
 				},
 			},
 		},
+		VNIs: []L3VNIConfig{
+			{
+				VRF:      "red",
+				ASN:      64512,
+				VNI:      100,
+				RouterID: "10.0.0.1",
+				LocalNeighbor: &NeighborConfig{
+					ASN:         64515,
+					Addr:        "hosts-ipv4",
+					ListenRange: "192.168.10.0/24",
+				},
+				ToAdvertiseIPv4: []string{
+					"192.168.10.2/32",
+				},
+			},
+		},
 		Passthrough: &PassthroughConfig{
 			LocalNeighborV4: &NeighborConfig{
-				ASN:      64512,
-				Addr:     "192.168.1.3",
-				IPFamily: ipfamily.IPv4,
+				ASN:         64515,
+				Addr:        "hosts-ipv4",
+				ListenRange: "192.168.20.0/24",
 			},
 			ToAdvertiseIPv4: []string{
-				"192.169.20.0/24",
-				"192.169.21.0/24",
+				"192.168.20.2/32",
 			},
 		},
 	}
@@ -326,7 +1561,7 @@ func TestPassthroughNoEVPN(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestPassthroughV4(t *testing.T) {
+func TestL2GatewaySuppressRA(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
@@ -345,15 +1580,14 @@ func TestPassthroughV4(t *testing.T) {
 				},
 			},
 		},
-		Passthrough: &PassthroughConfig{
-			LocalNeighborV4: &NeighborConfig{
-				ASN:      64512,
-				Addr:     "192.168.1.3",
-				IPFamily: ipfamily.IPv4,
+		L2Gateways: []L2GatewayConfig{
+			{
+				Interface:  "br-pe-110",
+				SuppressRA: true,
 			},
-			ToAdvertiseIPv4: []string{
-				"192.169.20.0/24",
-				"192.169.21.0/24",
+			{
+				Interface:  "br-pe-120",
+				SuppressRA: false,
 			},
 		},
 	}
@@ -364,7 +1598,7 @@ func TestPassthroughV4(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
-func TestPassthroughDual(t *testing.T) {
+func TestL2VNIEthernetSegment(t *testing.T) {
 	configFile := testSetup(t)
 	updater := testUpdater(configFile)
 
@@ -383,27 +1617,49 @@ func TestPassthroughDual(t *testing.T) {
 				},
 			},
 		},
-		Passthrough: &PassthroughConfig{
-			LocalNeighborV4: &NeighborConfig{
-				ASN:      64512,
-				Addr:     "192.168.1.3",
-				IPFamily: ipfamily.IPv4,
+		EthernetSegments: []EthernetSegmentConfig{
+			{
+				Interface:    "pe-110",
+				ID:           1,
+				SysMAC:       "44:38:39:ff:ff:01",
+				DFPreference: 50000,
 			},
-			LocalNeighborV6: &NeighborConfig{
-				ASN:      64512,
-				Addr:     "2001:db8:20::2",
-				IPFamily: ipfamily.IPv6,
+			{
+				Interface: "pe-120",
+				ID:        2,
+				SysMAC:    "44:38:39:ff:ff:01",
 			},
-			ToAdvertiseIPv4: []string{
-				"192.169.20.0/24",
-				"192.169.21.0/24",
+			{
+				Interface:    "pe-130",
+				ESI:          "00:44:38:39:ff:ff:01:00:00:03",
+				DFPreference: 100,
 			},
-			ToAdvertiseIPv6: []string{
-				"2001:db8:20::/64",
-				"2001:db8:21::/64",
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+
+	testCheckConfigFile(t)
+}
+
+func TestDebuggingLogLevel(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Loglevel: "debugging",
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+			Neighbors: []NeighborConfig{
+				{
+					ASN:      64513,
+					Addr:     "192.168.1.2",
+					IPFamily: ipfamily.IPv4,
+				},
 			},
 		},
-		VNIs: []L3VNIConfig{},
 	}
 	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
 		t.Fatalf("Failed to apply config: %s", err)
@@ -412,6 +1668,30 @@ func TestPassthroughDual(t *testing.T) {
 	testCheckConfigFile(t)
 }
 
+func TestValidateLogLevel(t *testing.T) {
+	for _, level := range LogLevels {
+		if err := ValidateLogLevel(level); err != nil {
+			t.Errorf("ValidateLogLevel(%s) unexpected error: %v", level, err)
+		}
+	}
+	if err := ValidateLogLevel("verbose"); err == nil {
+		t.Errorf("ValidateLogLevel(verbose) expected error, got none")
+	}
+}
+
+func TestValidateBGPListenLimit(t *testing.T) {
+	for _, limit := range []int{0, 1, MaxBGPListenLimit} {
+		if err := ValidateBGPListenLimit(limit); err != nil {
+			t.Errorf("ValidateBGPListenLimit(%d) unexpected error: %v", limit, err)
+		}
+	}
+	for _, limit := range []int{-1, MaxBGPListenLimit + 1} {
+		if err := ValidateBGPListenLimit(limit); err == nil {
+			t.Errorf("ValidateBGPListenLimit(%d) expected error, got none", limit)
+		}
+	}
+}
+
 func testCompareFiles(t *testing.T, configFile, goldenFile string) {
 	var lastError error
 
@@ -487,3 +1767,63 @@ func testUpdater(configFile string) func(context.Context, string) error {
 		return nil
 	}
 }
+
+func TestConfigHeader(t *testing.T) {
+	configFile := testSetup(t)
+	updater := testUpdater(configFile)
+
+	config := Config{
+		Underlay: UnderlayConfig{
+			MyASN:    64512,
+			RouterID: "10.0.0.1",
+		},
+	}
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+	generated, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+
+	lines := strings.Split(string(generated), "\n")
+	wantPrefixes := []string{
+		headerPrefix + "version v0.0.0-test",
+		headerPrefix + "hash " + ConfigHash(string(generated)),
+		headerPrefix + "generated 2025-01-01T00:00:00Z",
+	}
+	for i, prefix := range wantPrefixes {
+		if lines[i] != prefix {
+			t.Errorf("header line %d = %q, want %q", i, lines[i], prefix)
+		}
+	}
+
+	oldTimeNow := timeNow
+	timeNow = func() time.Time { return time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { timeNow = oldTimeNow })
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+	regenerated, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+	if string(regenerated) == string(generated) {
+		t.Fatalf("expected the header timestamp to change")
+	}
+	if ConfigHash(string(regenerated)) != ConfigHash(string(generated)) {
+		t.Errorf("expected the hash to ignore the header")
+	}
+
+	config.Underlay.RouterID = "10.0.0.2"
+	if err := ApplyConfig(context.TODO(), &config, updater); err != nil {
+		t.Fatalf("Failed to apply config: %s", err)
+	}
+	changed, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+	if ConfigHash(string(changed)) == ConfigHash(string(generated)) {
+		t.Errorf("expected the hash to change with the configuration")
+	}
+}
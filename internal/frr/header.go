@@ -0,0 +1,64 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// headerPrefix is the prefix of the comment lines added on top
+// of the generated configuration.
+const headerPrefix = "! openperouter "
+
+// Variables overridden in unit tests in order to get a stable header.
+var (
+	operatorVersion = buildVersion
+	timeNow         = time.Now
+)
+
+// configHeader returns the comment lines to be prepended to the given
+// configuration, carrying the operator version, the hash of the
+// configuration and the generation timestamp.
+func configHeader(config string) string {
+	return fmt.Sprintf("%sversion %s\n%shash %s\n%sgenerated %s\n",
+		headerPrefix, operatorVersion(),
+		headerPrefix, ConfigHash(config),
+		headerPrefix, timeNow().UTC().Format(time.RFC3339))
+}
+
+// ConfigHash returns the hash of the given FRR configuration. The header
+// lines are not taken into account, so two configurations differing only
+// by their header have the same hash.
+func ConfigHash(config string) string {
+	h := sha256.New()
+	for _, line := range strings.SplitAfter(config, "\n") {
+		if strings.HasPrefix(line, headerPrefix) {
+			continue
+		}
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// buildVersion returns the version of the running binary, falling
+// back to the vcs revision it was built from for development builds.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return "unknown"
+}
@@ -121,27 +121,7 @@ func ParseNeighbour(vtyshRes string) (*Neighbor, error) {
 		if ip == nil {
 			return nil, fmt.Errorf("failed to parse %s as ip", ip)
 		}
-		connected := true
-		if n.BgpState != bgpConnected {
-			connected = false
-		}
-		prefixSent := 0
-		prefixReceived := 0
-		for _, s := range n.AddressFamilyInfo {
-			prefixSent += s.SentPrefixCounter
-			prefixReceived += s.AcceptedPrefixCounter
-		}
-		return &Neighbor{
-			IP:             ip,
-			Connected:      connected,
-			LocalAS:        strconv.Itoa(n.LocalAs),
-			RemoteAS:       strconv.Itoa(n.RemoteAs),
-			PrefixSent:     prefixSent,
-			PrefixReceived: prefixReceived,
-			Port:           n.PortForeign,
-			RemoteRouterID: n.RemoteRouterID,
-			MsgStats:       n.MsgStats,
-		}, nil
+		return toNeighbor(ip, n), nil
 	}
 	return nil, errors.New("no peers were returned")
 }
@@ -161,31 +141,35 @@ func ParseNeighbours(vtyshRes string) ([]*Neighbor, error) {
 		if ip == nil {
 			return nil, fmt.Errorf("failed to parse %s as ip", ip)
 		}
-		connected := true
-		if n.BgpState != bgpConnected {
-			connected = false
-		}
-		prefixSent := 0
-		prefixReceived := 0
-		for _, s := range n.AddressFamilyInfo {
-			prefixSent += s.SentPrefixCounter
-			prefixReceived += s.AcceptedPrefixCounter
-		}
-		res = append(res, &Neighbor{
-			IP:             ip,
-			Connected:      connected,
-			LocalAS:        strconv.Itoa(n.LocalAs),
-			RemoteAS:       strconv.Itoa(n.RemoteAs),
-			PrefixSent:     prefixSent,
-			PrefixReceived: prefixReceived,
-			Port:           n.PortForeign,
-			RemoteRouterID: n.RemoteRouterID,
-			MsgStats:       n.MsgStats,
-		})
+		res = append(res, toNeighbor(ip, n))
 	}
 	return res, nil
 }
 
+func toNeighbor(ip net.IP, n FRRNeighbor) *Neighbor {
+	connected := true
+	if n.BgpState != bgpConnected {
+		connected = false
+	}
+	prefixSent := 0
+	prefixReceived := 0
+	for _, s := range n.AddressFamilyInfo {
+		prefixSent += s.SentPrefixCounter
+		prefixReceived += s.AcceptedPrefixCounter
+	}
+	return &Neighbor{
+		IP:             ip,
+		Connected:      connected,
+		LocalAS:        strconv.Itoa(n.LocalAs),
+		RemoteAS:       strconv.Itoa(n.RemoteAs),
+		PrefixSent:     prefixSent,
+		PrefixReceived: prefixReceived,
+		Port:           n.PortForeign,
+		RemoteRouterID: n.RemoteRouterID,
+		MsgStats:       n.MsgStats,
+	}
+}
+
 // parseRoute takes the result of a show bgp ipv4 / ipv6
 // and parses the informations related to all the routes.
 func ParseRoutes(vtyshRes string) (map[string]Route, error) {
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ConfigModeIntegrated is the mode where all the daemons
+	// share the same integrated configuration file.
+	ConfigModeIntegrated = "integrated"
+	// ConfigModeSplit is the mode where each daemon
+	// has its own configuration file.
+	ConfigModeSplit = "split"
+)
+
+// ConfigModes are the supported configuration modes.
+var ConfigModes = []string{ConfigModeIntegrated, ConfigModeSplit}
+
+// ValidateConfigMode returns an error if the given mode is not a supported configuration mode.
+func ValidateConfigMode(mode string) error {
+	if !slices.Contains(ConfigModes, mode) {
+		return fmt.Errorf("invalid frr config mode %s: possible values are %v", mode, ConfigModes)
+	}
+	return nil
+}
+
+const (
+	zebra = "zebra"
+	bgpd  = "bgpd"
+	bfdd  = "bfdd"
+)
+
+// SplitDaemons are the daemons the configuration is split across in split mode.
+var SplitDaemons = []string{zebra, bgpd, bfdd}
+
+// SplitConfig splits the given integrated configuration into the
+// configuration of each daemon of SplitDaemons. A stanza is made of a
+// line starting at the first column, followed by the indented and empty
+// lines and by the closing exit line, if any. The global stanzas, as the
+// header and the logging ones, are copied to all the daemons.
+func SplitConfig(config string) (map[string]string, error) {
+	res := map[string]*strings.Builder{}
+	for _, d := range SplitDaemons {
+		res[d] = &strings.Builder{}
+	}
+
+	var current []string
+	for _, line := range strings.SplitAfter(config, "\n") {
+		if line == "" {
+			continue
+		}
+		empty := strings.TrimSpace(line) == ""
+		if current == nil && empty {
+			continue
+		}
+		continuation := strings.HasPrefix(line, " ") || empty
+		closing := strings.TrimSpace(line) == "exit" || strings.TrimSpace(line) == "exit-vrf"
+		if current == nil && (continuation || closing) {
+			return nil, fmt.Errorf("line %q is not part of any stanza", strings.TrimSpace(line))
+		}
+		if !continuation && !closing {
+			var err error
+			current, err = stanzaDaemons(line)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, d := range current {
+			res[d].WriteString(line)
+		}
+	}
+
+	configs := map[string]string{}
+	for d, b := range res {
+		configs[d] = b.String()
+	}
+	return configs, nil
+}
+
+// stanzaDaemons returns the daemons the stanza starting
+// with the given line is part of the configuration of.
+func stanzaDaemons(line string) ([]string, error) {
+	fields := strings.Fields(line)
+	second := ""
+	if len(fields) > 1 {
+		second = fields[1]
+	}
+	switch fields[0] {
+	case "!", "log", "hostname":
+		return SplitDaemons, nil
+	case "vrf", "interface":
+		return []string{zebra}, nil
+	case "bfd":
+		return []string{bfdd}, nil
+	case "route-map", "router":
+		return []string{bgpd}, nil
+	case "ip", "ipv6":
+		switch second {
+		case "nht":
+			return []string{zebra}, nil
+		case "prefix-list":
+			return []string{bgpd}, nil
+		}
+	case "debug":
+		switch second {
+		case "zebra":
+			return []string{zebra}, nil
+		case "bgp":
+			return []string{bgpd}, nil
+		case "bfd":
+			return []string{bfdd}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown daemon for the stanza starting with %q", strings.TrimSpace(line))
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "all daemons",
+			config: `! header
+log stdout debugging
+hostname router
+debug zebra events
+debug bgp updates
+vrf red
+ vni 100
+exit-vrf
+
+ip nht resolve-via-default
+ip prefix-list allowed seq 1 permit 10.0.0.0/8
+route-map allowed permit 1
+ match ip address prefix-list allowed
+exit
+router bgp 64512
+ bgp router-id 10.0.0.1
+exit
+bfd
+ profile fast
+ exit
+exit
+`,
+			want: map[string]string{
+				zebra: `! header
+log stdout debugging
+hostname router
+debug zebra events
+vrf red
+ vni 100
+exit-vrf
+
+ip nht resolve-via-default
+`,
+				bgpd: `! header
+log stdout debugging
+hostname router
+debug bgp updates
+ip prefix-list allowed seq 1 permit 10.0.0.0/8
+route-map allowed permit 1
+ match ip address prefix-list allowed
+exit
+router bgp 64512
+ bgp router-id 10.0.0.1
+exit
+`,
+				bfdd: `! header
+log stdout debugging
+hostname router
+bfd
+ profile fast
+ exit
+exit
+`,
+			},
+		},
+		{
+			name:    "unknown stanza",
+			config:  "foo bar\n",
+			wantErr: true,
+		},
+		{
+			name:    "indented line outside of a stanza",
+			config:  " bgp router-id 10.0.0.1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("SplitConfig() diff %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestValidateConfigMode(t *testing.T) {
+	for _, mode := range ConfigModes {
+		if err := ValidateConfigMode(mode); err != nil {
+			t.Errorf("ValidateConfigMode(%q) unexpected error: %v", mode, err)
+		}
+	}
+	if err := ValidateConfigMode("foo"); err == nil {
+		t.Errorf("ValidateConfigMode(\"foo\") expected error")
+	}
+}
+
+// TestSplitGoldenConfigs ensures all the stanzas the template
+// generates are assigned to a daemon.
+func TestSplitGoldenConfigs(t *testing.T) {
+	goldens, err := filepath.Glob(filepath.Join(testData, "*.golden"))
+	if err != nil {
+		t.Fatalf("failed to list the golden files: %v", err)
+	}
+	for _, g := range goldens {
+		// The file is invalid on purpose, to test the validation.
+		if filepath.Base(g) == "TestDockerTestfails.golden" {
+			continue
+		}
+		config, err := os.ReadFile(g)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", g, err)
+		}
+		if _, err := SplitConfig(string(config)); err != nil {
+			t.Errorf("failed to split %s: %v", g, err)
+		}
+	}
+}
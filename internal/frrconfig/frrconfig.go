@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
+
+	"github.com/openperouter/openperouter/internal/frr"
 )
 
 type Action string
@@ -19,27 +22,54 @@ const (
 // Update reloads the frr configuration at the given path.
 func Update(path string) error {
 	slog.Info("config update", "path", path)
-	err := reloadAction(path, Test)
+	err := reloadAction(path, Test, "")
 	if err != nil {
 		return err
 	}
-	err = reloadAction(path, Reload)
+	err = reloadAction(path, Reload, "")
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// UpdateSplit reloads the configurations of the daemons in the given
+// directory, written in split mode. All the configurations are tested
+// before reloading any of them.
+func UpdateSplit(dir string) error {
+	slog.Info("config update", "dir", dir, "mode", frr.ConfigModeSplit)
+	for _, action := range []Action{Test, Reload} {
+		for _, daemon := range frr.SplitDaemons {
+			if err := reloadAction(SplitConfigPath(dir, daemon), action, daemon); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SplitConfigPath returns the path of the configuration
+// of the given daemon in split mode.
+func SplitConfigPath(dir, daemon string) string {
+	return filepath.Join(dir, daemon+".conf")
+}
+
 var execCommand = exec.Command
 
-func reloadAction(path string, action Action) error {
-	reloadParameter := "--" + string(action)
-	cmd := execCommand("python3", reloaderPath, reloadParameter, path)
+// reloadAction runs the given action of frr-reload against the given path. If
+// daemon is set, the action is restricted to the configuration of that daemon.
+func reloadAction(path string, action Action, daemon string) error {
+	args := []string{reloaderPath, "--" + string(action)}
+	if daemon != "" {
+		args = append(args, "--daemon", daemon)
+	}
+	args = append(args, path)
+	cmd := execCommand("python3", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error("frr update failed", "action", action, "error", err, "output", string(output))
+		slog.Error("frr update failed", "action", action, "daemon", daemon, "error", err, "output", string(output))
 		return fmt.Errorf("frr update %s failed: %w", action, err)
 	}
-	slog.Debug("frr update succeeded", "action", action, "output", string(output))
+	slog.Debug("frr update succeeded", "action", action, "daemon", daemon, "output", string(output))
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -52,6 +53,29 @@ func TestReload(t *testing.T) {
 	}
 }
 
+func TestReloadSplit(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	for tc, params := range tests {
+		t.Run(fmt.Sprintf("reload split %s", tc), func(t *testing.T) {
+			err := UpdateSplit(tc)
+			if (params.failReload || params.failValidate) && err == nil {
+				t.Fatalf("expecting failure, got no error")
+			}
+			if params.failReload && !strings.Contains(err.Error(), "reload") {
+				t.Fatalf("expecting reload error, got %v", err)
+			}
+			if params.failValidate && !strings.Contains(err.Error(), "test") {
+				t.Fatalf("expecting test error, got %v", err)
+			}
+			if !params.failReload && !params.failValidate && err != nil {
+				t.Fatalf("expecting no error, got %v", err)
+			}
+		})
+	}
+}
+
 // helper function that redirects the execution to a mock process implemented by
 // TestHelperProcess
 func fakeExecCommand(name string, args ...string) *exec.Cmd {
@@ -82,6 +106,11 @@ func TestFakeReloadHelper(t *testing.T) {
 		}
 		args = args[1:]
 	}
+	daemon := ""
+	if len(args) == 5 && args[2] == "--daemon" {
+		daemon = args[3]
+		args = append(args[:2], args[4])
+	}
 	if len(args) != 3 {
 		fmt.Printf("expecting 3 args, got %v", args)
 		os.Exit(1)
@@ -93,6 +122,13 @@ func TestFakeReloadHelper(t *testing.T) {
 	}
 	action, _ := strings.CutPrefix(args[1], "--")
 	path := args[2]
+	if daemon != "" {
+		if path != SplitConfigPath(filepath.Dir(path), daemon) {
+			fmt.Println("daemon", daemon, "does not match path", path)
+			os.Exit(1)
+		}
+		path = filepath.Dir(path)
+	}
 
 	params, ok := tests[path]
 	if !ok {
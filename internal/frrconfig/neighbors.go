@@ -0,0 +1,56 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frrconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/openperouter/openperouter/internal/frr"
+)
+
+// NeighborsPath is the path the reloader serves the
+// state of the BGP neighbors of the router at.
+const NeighborsPath = "/neighbors"
+
+// ShowNeighbors returns the json state of the BGP neighbors
+// of the default vrf, as reported by vtysh.
+func ShowNeighbors() (string, error) {
+	cmd := execCommand("vtysh", "-c", "show bgp neighbors json")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to show the bgp neighbors: %w", err)
+	}
+	return string(output), nil
+}
+
+// Neighbors asks the reloader listening on the given socket
+// for the BGP neighbors of the default vrf of the router.
+func Neighbors(ctx context.Context, socketPath string) ([]*frr.Neighbor, error) {
+	client := socketClient(socketPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix"+NeighborsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the neighbors request: %w", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the neighbors against socket %s: %w", socketPath, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.ErrorContext(ctx, "failed to close res body", "error", err)
+		}
+	}()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the neighbors against socket %s, status %d", socketPath, res.StatusCode)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the neighbors: %w", err)
+	}
+	return frr.ParseNeighbours(string(body))
+}
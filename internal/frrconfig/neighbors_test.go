@@ -0,0 +1,61 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package frrconfig
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestNeighbors(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "reload.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create unix socket: %v", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != NeighborsPath {
+			t.Errorf("expected path %s, got %s", NeighborsPath, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{
+			"192.168.11.2": {"remoteAs": 64512, "localAs": 64514, "bgpState": "Established"},
+			"192.168.12.2": {"remoteAs": 64512, "localAs": 64514, "bgpState": "Active"}
+		}`))
+	})
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	neighbors, err := Neighbors(context.Background(), socketPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	connected := map[string]bool{}
+	for _, n := range neighbors {
+		connected[n.IP.String()] = n.Connected
+	}
+	want := map[string]bool{"192.168.11.2": true, "192.168.12.2": false}
+	if len(connected) != len(want) {
+		t.Fatalf("expected neighbors %v, got %v", want, connected)
+	}
+	for ip, c := range want {
+		if connected[ip] != c {
+			t.Errorf("expected neighbor %s connected %t, got %t", ip, c, connected[ip])
+		}
+	}
+}
@@ -4,47 +4,178 @@ package frrconfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/openperouter/openperouter/internal/frr"
 )
 
 func UpdaterForSocket(socketPath, configFile string) func(context.Context, string) error {
 	return func(ctx context.Context, config string) error {
-		updaterClient := func(ctx context.Context) error {
-			client := &http.Client{
-				Transport: &http.Transport{
-					DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-						return net.Dial("unix", socketPath)
-					},
-				},
-			}
+		return writeAndReload(ctx, socketPath, frr.ConfigModeIntegrated, map[string]string{configFile: config})
+	}
+}
 
-			slog.InfoContext(ctx, "updater requesting update", "socket", socketPath)
-			defer slog.InfoContext(ctx, "updater update requested")
+// UpdaterForMode returns the updater writing the configuration in the given
+// mode. In split mode, the configurations of the daemons are written in the
+// directory of configFile.
+func UpdaterForMode(mode, socketPath, configFile string) func(context.Context, string) error {
+	if mode == frr.ConfigModeSplit {
+		return SplitUpdaterForSocket(socketPath, filepath.Dir(configFile))
+	}
+	return UpdaterForSocket(socketPath, configFile)
+}
 
-			res, err := client.Post("http://unix/", "", nil)
-			if err != nil {
-				return fmt.Errorf("failed to reload against socket %s: %w", socketPath, err)
-			}
-			defer func() {
-				if err := res.Body.Close(); err != nil {
-					slog.ErrorContext(ctx, "failed to close res body", "error", err)
-				}
-			}()
-			if res.StatusCode != http.StatusOK {
-				return fmt.Errorf("failed to reload against socket %s, status %d", socketPath, res.StatusCode)
-			}
-			return nil
+// SplitUpdaterForSocket returns an updater that splits the configuration
+// across the files of the daemons in the given directory, and requests
+// the reload in split mode.
+func SplitUpdaterForSocket(socketPath, configDir string) func(context.Context, string) error {
+	return func(ctx context.Context, config string) error {
+		daemonConfigs, err := frr.SplitConfig(config)
+		if err != nil {
+			return fmt.Errorf("failed to split the frr configuration: %w", err)
+		}
+		files := map[string]string{}
+		for daemon, c := range daemonConfigs {
+			files[SplitConfigPath(configDir, daemon)] = c
 		}
+		return writeAndReload(ctx, socketPath, frr.ConfigModeSplit, files)
+	}
+}
+
+// requestReload asks the reloader listening on the given socket to reload
+// the configuration, written in the given mode. The reloader refuses the
+// request if it runs in a different mode.
+func requestReload(ctx context.Context, socketPath, mode string) error {
+	client := socketClient(socketPath)
+
+	slog.InfoContext(ctx, "updater requesting update", "socket", socketPath, "mode", mode)
+	defer slog.InfoContext(ctx, "updater update requested")
+
+	res, err := client.Post("http://unix/?"+url.Values{"mode": {mode}}.Encode(), "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to reload against socket %s: %w", socketPath, err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.ErrorContext(ctx, "failed to close res body", "error", err)
+		}
+	}()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to reload against socket %s, status %d", socketPath, res.StatusCode)
+	}
+	return nil
+}
+
+// socketClient returns an http client sending the requests
+// to the reloader listening on the given socket.
+func socketClient(socketPath string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return net.Dial("unix", socketPath)
+			},
+		},
+	}
+}
 
-		slog.InfoContext(ctx, "updater writing frr file", "file", configFile)
-		err := os.WriteFile(configFile, []byte(config), 0600)
+// writeAndReload writes the given configuration files and requests the
+// reload, restoring and reloading the previous files if the reload fails.
+// The files already containing their configuration are not written again,
+// but the reload is always requested: the files outlive the restarts of
+// FRR, which boots from its startup configuration, so their content does
+// not tell what FRR is running.
+func writeAndReload(ctx context.Context, socketPath, mode string, files map[string]string) error {
+	paths := slices.DeleteFunc(slices.Sorted(maps.Keys(files)), func(p string) bool {
+		if configUnchanged(p, files[p]) {
+			slog.InfoContext(ctx, "updater skipping unchanged frr file", "file", p)
+			return true
+		}
+		return false
+	})
+
+	restores := []func() error{}
+	restore := func() error {
+		errs := []error{}
+		for _, r := range restores {
+			errs = append(errs, r())
+		}
+		return errors.Join(errs...)
+	}
+	for _, p := range paths {
+		r, err := snapshotConfig(p)
 		if err != nil {
-			return fmt.Errorf("failed to write the config to %s", configFile)
+			return err
+		}
+		restores = append(restores, r)
+	}
+
+	var err error
+	for _, p := range paths {
+		slog.InfoContext(ctx, "updater writing frr file", "file", p)
+		if err = os.WriteFile(p, []byte(files[p]), 0600); err != nil {
+			err = fmt.Errorf("failed to write the config to %s", p)
+			break
 		}
-		return updaterClient(ctx)
 	}
+	if err == nil {
+		err = requestReload(ctx, socketPath, mode)
+	}
+	if err == nil {
+		return nil
+	}
+
+	slog.ErrorContext(ctx, "updater reload failed, restoring previous frr files", "files", paths, "error", err)
+	if restoreErr := restore(); restoreErr != nil {
+		return errors.Join(err, restoreErr)
+	}
+	// A failed reload may have applied part of the new configuration,
+	// so the restored one is reloaded to bring the router back to it.
+	if reloadErr := requestReload(ctx, socketPath, mode); reloadErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to reload the restored frr files: %w", reloadErr))
+	}
+	return err
+}
+
+// snapshotConfig saves the current content of the given config file and
+// returns a function that restores it. If the file does not exist, the
+// restore function removes it.
+func snapshotConfig(configFile string) (func() error, error) {
+	previous, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return func() error {
+			if err := os.Remove(configFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", configFile, err)
+			}
+			return nil
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the current config %s: %w", configFile, err)
+	}
+
+	return func() error {
+		if err := os.WriteFile(configFile, previous, 0600); err != nil {
+			return fmt.Errorf("failed to restore the config to %s: %w", configFile, err)
+		}
+		return nil
+	}, nil
+}
+
+// configUnchanged tells whether the given config file already contains the
+// given configuration, ignoring the generated header.
+func configUnchanged(configFile, config string) bool {
+	current, err := os.ReadFile(configFile)
+	if err != nil {
+		return false
+	}
+	return frr.ConfigHash(string(current)) == frr.ConfigHash(config)
 }
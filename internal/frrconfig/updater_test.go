@@ -7,7 +7,12 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/openperouter/openperouter/internal/frr"
 )
 
 func TestUpdaterForSocket(t *testing.T) {
@@ -40,6 +45,9 @@ func TestUpdaterForSocket(t *testing.T) {
 		if r.Method != http.MethodPost {
 			t.Errorf("expected POST request, got %s", r.Method)
 		}
+		if mode := r.URL.Query().Get("mode"); mode != frr.ConfigModeIntegrated {
+			t.Errorf("expected integrated mode, got %q", mode)
+		}
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -67,3 +75,195 @@ func TestUpdaterForSocket(t *testing.T) {
 		t.Errorf("expected content %q, got %q", expectedContent, string(content))
 	}
 }
+
+func TestUpdaterForSocketRestoresOnFailure(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "frr.conf")
+	previousContent := "previous config"
+	if err := os.WriteFile(configFile, []byte(previousContent), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "reloader.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create unix socket: %v", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	// The reload of the new config fails, the one of the restored config
+	// succeeds only when restoredReloadFails is false.
+	reloads := 0
+	restoredReloadFails := false
+	reloadedContents := []string{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reloads++
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			t.Errorf("failed to read config file: %v", err)
+		}
+		reloadedContents = append(reloadedContents, string(content))
+		if reloads == 1 || restoredReloadFails {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	updater := UpdaterForSocket(socketPath, configFile)
+
+	err = updater(context.Background(), "invalid config")
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	if string(content) != previousContent {
+		t.Errorf("expected content %q, got %q", previousContent, string(content))
+	}
+	wantReloaded := []string{"invalid config", previousContent}
+	if !slices.Equal(reloadedContents, wantReloaded) {
+		t.Errorf("expected the reloaded contents to be %q, got %q", wantReloaded, reloadedContents)
+	}
+
+	reloads = 0
+	restoredReloadFails = true
+	err = updater(context.Background(), "invalid config")
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if !strings.Contains(err.Error(), "failed to reload the restored frr files") {
+		t.Errorf("expected the error to report the failed reload of the restored files, got %v", err)
+	}
+	if reloads != 2 {
+		t.Errorf("expected the restored files to be reloaded, got %d reloads", reloads)
+	}
+}
+
+func TestUpdaterForSocketSkipsUnchangedWrite(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "frr.conf")
+	previousContent := "! openperouter generated 2025-01-01T00:00:00Z\nrouter bgp 64512\n"
+	if err := os.WriteFile(configFile, []byte(previousContent), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "reloader.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create unix socket: %v", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	reloads := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reloads++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	updater := UpdaterForSocket(socketPath, configFile)
+
+	err = updater(context.Background(), "! openperouter generated 2025-02-01T00:00:00Z\nrouter bgp 64512\n")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reloads != 1 {
+		t.Errorf("expected a reload for a config differing only by the header, got %d", reloads)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	if string(content) != previousContent {
+		t.Errorf("expected content %q, got %q", previousContent, string(content))
+	}
+
+	err = updater(context.Background(), "! openperouter generated 2025-02-01T00:00:00Z\nrouter bgp 64513\n")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reloads != 2 {
+		t.Errorf("expected a reload for a changed config, got %d", reloads)
+	}
+}
+
+func TestSplitUpdaterForSocket(t *testing.T) {
+	configDir := t.TempDir()
+	socketPath := filepath.Join(t.TempDir(), "reloader.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create unix socket: %v", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mode := r.URL.Query().Get("mode"); mode != frr.ConfigModeSplit {
+			t.Errorf("expected split mode, got %q", mode)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	updater := SplitUpdaterForSocket(socketPath, configDir)
+
+	err = updater(context.Background(), `log stdout
+vrf red
+ vni 100
+exit-vrf
+router bgp 64512
+exit
+bfd
+exit
+`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"zebra": "log stdout\nvrf red\n vni 100\nexit-vrf\n",
+		"bgpd":  "log stdout\nrouter bgp 64512\nexit\n",
+		"bfdd":  "log stdout\nbfd\nexit\n",
+	}
+	for daemon, want := range expected {
+		content, err := os.ReadFile(SplitConfigPath(configDir, daemon))
+		if err != nil {
+			t.Fatalf("failed to read the %s config file: %v", daemon, err)
+		}
+		if string(content) != want {
+			t.Errorf("expected %s content %q, got %q", daemon, want, string(content))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "frr.conf")); !os.IsNotExist(err) {
+		t.Errorf("expected no integrated config in split mode, got %v", err)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vishvananda/netlink"
 )
@@ -16,7 +18,7 @@ import (
 // setup bridge creates the bridge if not exists, and it enslaves it to the provided
 // vrf.
 func setupBridge(params VNIParams, vrf *netlink.Vrf) (*netlink.Bridge, error) {
-	name := bridgeName(params.VNI)
+	name := BridgeName(params.DeviceNamePrefix, params.VNI)
 	bridge, err := createBridge(name, vrf.Index)
 	if err != nil {
 		return nil, err
@@ -27,6 +29,11 @@ func setupBridge(params VNIParams, vrf *netlink.Vrf) (*netlink.Bridge, error) {
 		return nil, fmt.Errorf("failed to set addr_gen_mode to 1 for %s: %w", bridge.Name, err)
 	}
 
+	err = enableIPv6(bridge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable ipv6 for %s: %w", bridge.Name, err)
+	}
+
 	err = netlink.LinkSetUp(bridge)
 	if err != nil {
 		return nil, fmt.Errorf("could not set link up for bridge %s: %v", name, err)
@@ -72,6 +79,56 @@ func createBridge(name string, vrfIndex int) (*netlink.Bridge, error) {
 	return toCreate, nil
 }
 
+// defaultMACAgeingTime is the default ageing time of the kernel bridges.
+const defaultMACAgeingTime = 300 * time.Second
+
+// setBridgeMACAgeingTime sets the ageing time of the MAC addresses learned
+// by the given bridge, if not set already. A zero ageing time restores the
+// default of the kernel.
+func setBridgeMACAgeingTime(bridge netlink.Link, ageingTime time.Duration) error {
+	if ageingTime == 0 {
+		ageingTime = defaultMACAgeingTime
+	}
+	// the kernel expresses the ageing time in hundredths of a second.
+	centiseconds := uint32(ageingTime / (10 * time.Millisecond))
+	if br, ok := bridge.(*netlink.Bridge); ok && br.AgeingTime != nil && *br.AgeingTime == centiseconds {
+		return nil
+	}
+
+	attrs := netlink.NewLinkAttrs()
+	attrs.Name = bridge.Attrs().Name
+	attrs.Index = bridge.Attrs().Index
+	if err := netlink.LinkModify(&netlink.Bridge{LinkAttrs: attrs, AgeingTime: &centiseconds}); err != nil {
+		return fmt.Errorf("failed to set mac ageing time %s to bridge %s: %w", ageingTime, bridge.Attrs().Name, err)
+	}
+	return nil
+}
+
+// setBridgeHostRoutes makes the given bridge create neighbor entries from
+// the unsolicited ARP and NA messages it receives, so that the hosts
+// announcing themselves are advertised as MAC/IP routes, installed as host
+// routes in the VRF of the remote routers. Disabling it restores the
+// default of the kernel.
+func setBridgeHostRoutes(bridge netlink.Link, enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	if err := setIPv4Conf(bridge, "arp_accept", value); err != nil {
+		return fmt.Errorf("failed to set arp_accept to bridge %s: %w", bridge.Attrs().Name, err)
+	}
+	// accept_untracked_na is not available on the older kernels, where
+	// the unsolicited NA messages can't create neighbor entries anyway.
+	err := setIPv6Conf(bridge, "accept_untracked_na", value)
+	if errors.Is(err, os.ErrNotExist) && !enabled {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set accept_untracked_na to bridge %s: %w", bridge.Attrs().Name, err)
+	}
+	return nil
+}
+
 const (
 	macSize = 6
 )
@@ -88,24 +145,46 @@ func setBridgeFixedMacAddress(bridge netlink.Link, vni int) error {
 	}
 	copy(macAddress, macHeader)
 	copy(macAddress[2:], buf.Bytes())
+	if bytes.Equal(bridge.Attrs().HardwareAddr, macAddress) {
+		return nil
+	}
 	if err := netlink.LinkSetHardwareAddr(bridge, macAddress); err != nil {
 		return fmt.Errorf("failed to set mac address to bridge %s %x: %w", bridge.Attrs().Name, macAddress, err)
 	}
 	return nil
 }
 
+// setBridgeVTEPMac sets the vtep mac coming from the params
+// to the bridge, if any.
+func setBridgeVTEPMac(bridge netlink.Link, params VNIParams) error {
+	mac, err := vtepMAC(params)
+	if err != nil {
+		return err
+	}
+	if mac == nil || bytes.Equal(bridge.Attrs().HardwareAddr, mac) {
+		return nil
+	}
+	if err := netlink.LinkSetHardwareAddr(bridge, mac); err != nil {
+		return fmt.Errorf("failed to set mac address to bridge %s %s: %w", bridge.Attrs().Name, mac, err)
+	}
+	return nil
+}
+
 const bridgePrefix = "br-pe-"
 
-func bridgeName(vni int) string {
-	return fmt.Sprintf("%s%d", bridgePrefix, vni)
+// BridgeName returns the name of the bridge created for the
+// given vni inside the router namespace, with the given device
+// name prefix.
+func BridgeName(prefix string, vni int) string {
+	return fmt.Sprintf("%s%s%d", prefix, bridgePrefix, vni)
 }
 
-func vniFromBridgeName(name string) (int, error) {
-	if !strings.HasPrefix(name, bridgePrefix) {
+func vniFromBridgeName(prefix, name string) (int, error) {
+	if !strings.HasPrefix(name, prefix+bridgePrefix) {
 		return 0, NotRouterInterfaceError{Name: name}
 	}
 
-	vni := strings.TrimPrefix(name, bridgePrefix)
+	vni := strings.TrimPrefix(name, prefix+bridgePrefix)
 	res, err := strconv.Atoi(vni)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get vni for bridge %s", name)
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// conntrackBypassTable is the nftables table holding the rules
+// disabling the connection tracking in the target namespace.
+const conntrackBypassTable = "openperouter-notrack"
+
+// SetupConntrackBypass programs, in the target namespace, the nftables
+// rules disabling the connection tracking of the traffic from and to the
+// given CIDRs. The rules are replaced as a whole when the CIDRs change,
+// and removed when no CIDR is given. It returns true if the rules were
+// changed.
+func SetupConntrackBypass(namespace string, cidrs []string) (bool, error) {
+	ns, err := netns.GetFromPath(namespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to get network namespace %s: %w", namespace, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("SetupConntrackBypass: failed to close namespace", "error", err, "namespace", namespace)
+		}
+	}()
+
+	conn, err := nftables.New(nftables.WithNetNSFd(int(ns)))
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to nftables in namespace %s: %w", namespace, err)
+	}
+
+	// The table can't be listed when it doesn't exist, and neither on
+	// the hosts without nf_tables, where it can't have been programmed.
+	table, err := conn.ListTableOfFamily(conntrackBypassTable, nftables.TableFamilyINet)
+	if err != nil {
+		if len(cidrs) == 0 {
+			return false, nil
+		}
+		table = nil
+	}
+	if table != nil && slices.Equal(programmedConntrackBypassCIDRs(conn, table), cidrs) {
+		return false, nil
+	}
+
+	table = &nftables.Table{Name: conntrackBypassTable, Family: nftables.TableFamilyINet}
+	// Adding the table before deleting it makes the deletion succeed
+	// when the table does not exist yet.
+	conn.AddTable(table)
+	conn.DelTable(table)
+
+	if len(cidrs) > 0 {
+		table = conn.AddTable(table)
+		for _, hook := range []*nftables.ChainHook{nftables.ChainHookPrerouting, nftables.ChainHookOutput} {
+			chain := conn.AddChain(&nftables.Chain{
+				Name:     conntrackBypassChainName(hook),
+				Table:    table,
+				Type:     nftables.ChainTypeFilter,
+				Hooknum:  hook,
+				Priority: nftables.ChainPriorityRaw,
+			})
+			for _, cidr := range cidrs {
+				exprs, err := notrackExprs(cidr)
+				if err != nil {
+					return false, err
+				}
+				for _, e := range exprs {
+					conn.AddRule(&nftables.Rule{Table: table, Chain: chain, Exprs: e, UserData: []byte(cidr)})
+				}
+			}
+		}
+	}
+
+	if err := conn.Flush(); err != nil {
+		return false, fmt.Errorf("failed to program the conntrack bypass rules in namespace %s: %w", namespace, err)
+	}
+	return true, nil
+}
+
+// programmedConntrackBypassCIDRs returns the CIDRs of the notrack rules
+// programmed in the given table, in the order they were given in. Each
+// rule carries its CIDR as user data.
+func programmedConntrackBypassCIDRs(conn *nftables.Conn, table *nftables.Table) []string {
+	chain := &nftables.Chain{Name: conntrackBypassChainName(nftables.ChainHookPrerouting), Table: table}
+	rules, err := conn.GetRules(table, chain)
+	if err != nil {
+		return nil
+	}
+	res := []string{}
+	for _, r := range rules {
+		cidr := string(r.UserData)
+		if len(res) > 0 && res[len(res)-1] == cidr {
+			continue
+		}
+		res = append(res, cidr)
+	}
+	return res
+}
+
+func conntrackBypassChainName(hook *nftables.ChainHook) string {
+	if *hook == *nftables.ChainHookOutput {
+		return "output"
+	}
+	return "prerouting"
+}
+
+// notrackExprs returns the expressions of the rules disabling the
+// connection tracking of the traffic with the given CIDR as source
+// and as destination.
+func notrackExprs(cidr string) ([][]expr.Any, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conntrack bypass cidr %s: %w", cidr, err)
+	}
+
+	proto := byte(unix.NFPROTO_IPV4)
+	srcOffset, dstOffset := uint32(12), uint32(16)
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		proto = unix.NFPROTO_IPV6
+		srcOffset, dstOffset = 8, 24
+		ip = ipNet.IP.To16()
+	}
+	mask := []byte(ipNet.Mask)
+
+	res := [][]expr.Any{}
+	for _, offset := range []uint32{srcOffset, dstOffset} {
+		res = append(res, []expr.Any{
+			&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{proto}},
+			&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: uint32(len(ip))},
+			&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: uint32(len(ip)), Mask: mask, Xor: make([]byte, len(ip))},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ip},
+			&expr.Notrack{},
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netns"
+)
+
+const testConntrackNSName = "conntracktestns"
+
+func testConntrackNSPath() string {
+	return fmt.Sprintf("/var/run/netns/%s", testConntrackNSName)
+}
+
+var _ = Describe("Conntrack bypass configuration", func() {
+	var testNS netns.NsHandle
+
+	BeforeEach(func() {
+		cleanTest(testConntrackNSName)
+		testNS = createTestNS(testConntrackNSName)
+	})
+	AfterEach(func() {
+		cleanTest(testConntrackNSName)
+	})
+
+	It("programs the notrack rules for the overlay subnets and removes them", func() {
+		cidrs := []string{"192.170.1.0/24", "2001:db8:1::/64"}
+		changed, err := SetupConntrackBypass(testConntrackNSPath(), cidrs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		validateConntrackBypass(testNS, cidrs)
+
+		By("applying the same subnets again")
+		changed, err = SetupConntrackBypass(testConntrackNSPath(), cidrs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		validateConntrackBypass(testNS, cidrs)
+
+		By("updating the subnets")
+		cidrs = []string{"192.170.2.0/24"}
+		changed, err = SetupConntrackBypass(testConntrackNSPath(), cidrs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		validateConntrackBypass(testNS, cidrs)
+
+		By("removing the subnets")
+		changed, err = SetupConntrackBypass(testConntrackNSPath(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		conn, err := nftables.New(nftables.WithNetNSFd(int(testNS)))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = conn.ListTableOfFamily(conntrackBypassTable, nftables.TableFamilyINet)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not fail when removing the rules that were never programmed", func() {
+		changed, err := SetupConntrackBypass(testConntrackNSPath(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+})
+
+func validateConntrackBypass(ns netns.NsHandle, cidrs []string) {
+	conn, err := nftables.New(nftables.WithNetNSFd(int(ns)))
+	Expect(err).NotTo(HaveOccurred())
+	table, err := conn.ListTableOfFamily(conntrackBypassTable, nftables.TableFamilyINet)
+	Expect(err).NotTo(HaveOccurred())
+
+	for _, hook := range []*nftables.ChainHook{nftables.ChainHookPrerouting, nftables.ChainHookOutput} {
+		chain := &nftables.Chain{Name: conntrackBypassChainName(hook), Table: table}
+		rules, err := conn.GetRules(table, chain)
+		Expect(err).NotTo(HaveOccurred())
+		// one rule for the source and one for the destination of each cidr
+		Expect(rules).To(HaveLen(2 * len(cidrs)))
+
+		matched := []string{}
+		for _, r := range rules {
+			var network net.IP
+			notrack := false
+			for _, e := range r.Exprs {
+				switch e := e.(type) {
+				case *expr.Cmp:
+					if len(e.Data) == net.IPv4len || len(e.Data) == net.IPv6len {
+						network = net.IP(e.Data)
+					}
+				case *expr.Notrack:
+					notrack = true
+				}
+			}
+			Expect(notrack).To(BeTrue(), "rule %v has no notrack statement", r.Exprs)
+			matched = append(matched, network.String())
+		}
+		for _, cidr := range cidrs {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matched).To(ContainElement(ipNet.IP.String()))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// maxInterfaceNameLength is the maximum length of the name of a
+// network interface on linux.
+const maxInterfaceNameLength = 15
+
+// maxDeviceNamePrefixLength is the maximum length of the device name
+// prefix, leaving room for the longest fixed part of the names and for
+// a single digit vni. The names of the larger vnis are checked by
+// ValidateDeviceNames.
+const maxDeviceNamePrefixLength = maxInterfaceNameLength - len(bridgePrefix) - 1
+
+var deviceNamePrefixRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]*$`)
+
+// ValidateDeviceNamePrefix checks that the given prefix can be prepended
+// to the names of the vxlan and bridge devices created for each vni. The
+// vrfs are named after the resources and are not prefixed.
+func ValidateDeviceNamePrefix(prefix string) error {
+	if len(prefix) > maxDeviceNamePrefixLength {
+		return fmt.Errorf("device name prefix %s can't be longer than %d characters", prefix, maxDeviceNamePrefixLength)
+	}
+	if !deviceNamePrefixRegexp.MatchString(prefix) {
+		return fmt.Errorf("device name prefix %s contains invalid characters", prefix)
+	}
+	return nil
+}
+
+// ValidateDeviceNames checks that the names of the devices created for
+// the given vni with the given prefix fit in the length limit of the
+// interface names.
+func ValidateDeviceNames(prefix string, vni int) error {
+	for _, name := range []string{vxLanNameFromVNI(prefix, vni), BridgeName(prefix, vni), hostBridgeName(prefix, vni)} {
+		if len(name) > maxInterfaceNameLength {
+			return fmt.Errorf("device name %s for vni %d can't be longer than %d characters, use a shorter device name prefix",
+				name, vni, maxInterfaceNameLength)
+		}
+	}
+	return nil
+}
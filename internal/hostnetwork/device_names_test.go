@@ -0,0 +1,103 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import "testing"
+
+func TestDeviceNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefix       string
+		vni          int
+		wantPrefixOK bool
+		wantNamesOK  bool
+	}{
+		{
+			name:         "no prefix, largest vni",
+			vni:          maxVNI,
+			wantPrefixOK: true,
+			wantNamesOK:  true,
+		},
+		{
+			name:         "short prefix, largest vni",
+			prefix:       "o",
+			vni:          maxVNI,
+			wantPrefixOK: true,
+			wantNamesOK:  true,
+		},
+		{
+			name:         "prefix too long for the vni",
+			prefix:       "opr-",
+			vni:          maxVNI,
+			wantPrefixOK: true,
+		},
+		{
+			name:         "prefix fitting the vni width",
+			prefix:       "opr-",
+			vni:          12345,
+			wantPrefixOK: true,
+			wantNamesOK:  true,
+		},
+		{
+			name:         "prefix not fitting the vni width",
+			prefix:       "opr-",
+			vni:          123456,
+			wantPrefixOK: true,
+		},
+		{
+			name:         "longest prefix, single digit vni",
+			prefix:       "operator",
+			vni:          5,
+			wantPrefixOK: true,
+			wantNamesOK:  true,
+		},
+		{
+			name:   "prefix too long",
+			prefix: "operators",
+			vni:    5,
+		},
+		{
+			name:   "invalid characters",
+			prefix: "op/",
+			vni:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceNamePrefix(tt.prefix)
+			if (err == nil) != tt.wantPrefixOK {
+				t.Fatalf("ValidateDeviceNamePrefix(%q) error = %v, want ok %v", tt.prefix, err, tt.wantPrefixOK)
+			}
+			if err != nil {
+				return
+			}
+			err = ValidateDeviceNames(tt.prefix, tt.vni)
+			if (err == nil) != tt.wantNamesOK {
+				t.Fatalf("ValidateDeviceNames(%q, %d) error = %v, want ok %v", tt.prefix, tt.vni, err, tt.wantNamesOK)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, name := range []string{vxLanNameFromVNI(tt.prefix, tt.vni), BridgeName(tt.prefix, tt.vni), hostBridgeName(tt.prefix, tt.vni)} {
+				if len(name) > maxInterfaceNameLength {
+					t.Errorf("device name %s is longer than %d characters", name, maxInterfaceNameLength)
+				}
+			}
+			for name, parse := range map[string]func(string, string) (int, error){
+				vxLanNameFromVNI(tt.prefix, tt.vni): vniFromVXLanName,
+				BridgeName(tt.prefix, tt.vni):       vniFromBridgeName,
+				hostBridgeName(tt.prefix, tt.vni):   vniFromHostBridgeName,
+			} {
+				vni, err := parse(tt.prefix, name)
+				if err != nil {
+					t.Fatalf("failed to get the vni from %s: %v", name, err)
+				}
+				if vni != tt.vni {
+					t.Errorf("expected vni %d from %s, got %d", tt.vni, name, vni)
+				}
+			}
+		})
+	}
+}
@@ -14,8 +14,8 @@ import (
 
 // createHostBridge creates a bridge on the host namespace named after the
 // provided vni. If the bridge already exists, it will return the existing one.
-func createHostBridge(vni int) (netlink.Link, error) {
-	name := hostBridgeName(vni)
+func createHostBridge(prefix string, vni int) (netlink.Link, error) {
+	name := hostBridgeName(prefix, vni)
 	_, err := netlink.LinkByName(name)
 	// link does not exist, let's create it
 	if errors.As(err, &netlink.LinkNotFoundError{}) {
@@ -38,16 +38,16 @@ func createHostBridge(vni int) (netlink.Link, error) {
 
 const hostBridgePrefix = "br-hs-"
 
-func hostBridgeName(vni int) string {
-	return fmt.Sprintf("%s%d", hostBridgePrefix, vni)
+func hostBridgeName(prefix string, vni int) string {
+	return fmt.Sprintf("%s%s%d", prefix, hostBridgePrefix, vni)
 }
 
 // vniFromHostBridgeName extracts the VNI from a host bridge name.
-func vniFromHostBridgeName(name string) (int, error) {
-	if !strings.HasPrefix(name, hostBridgePrefix) {
+func vniFromHostBridgeName(prefix, name string) (int, error) {
+	if !strings.HasPrefix(name, prefix+hostBridgePrefix) {
 		return 0, NotRouterInterfaceError{Name: name}
 	}
-	vni := strings.TrimPrefix(name, hostBridgePrefix)
+	vni := strings.TrimPrefix(name, prefix+hostBridgePrefix)
 	res, err := strconv.Atoi(vni)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get vni for host bridge %s: %w", name, err)
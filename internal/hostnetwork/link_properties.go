@@ -18,6 +18,14 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// setMaster sets the given master to the link, if not set already.
+func setMaster(link, master netlink.Link) error {
+	if link.Attrs().MasterIndex == master.Attrs().Index {
+		return nil
+	}
+	return netlink.LinkSetMaster(link, master)
+}
+
 // assingIPToInterface assignes the given address to the link.
 func assignIPToInterface(link netlink.Link, address string) error {
 	addr, err := netlink.ParseAddr(address)
@@ -57,6 +65,24 @@ func interfaceHasIP(link netlink.Link, address string) (bool, error) {
 	return false, nil
 }
 
+// removeOtherAddresses removes from the given link all the global addresses
+// but the provided one, if any. The link local addresses are kept.
+func removeOtherAddresses(link netlink.Link, keep string) error {
+	addresses, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("removeOtherAddresses: failed to list addresses for interface %s: %w", link.Attrs().Name, err)
+	}
+	for _, a := range addresses {
+		if a.IPNet.String() == keep || a.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if err := netlink.AddrDel(link, &a); err != nil {
+			return fmt.Errorf("removeOtherAddresses: failed to remove address %s from interface %s: %w", a.IPNet, link.Attrs().Name, err)
+		}
+	}
+	return nil
+}
+
 // interfaceHasNoIP tells if the given link does not have
 // ips of the given family.
 func interfaceHasNoIP(link netlink.Link, family int) (bool, error) {
@@ -73,14 +99,42 @@ func interfaceHasNoIP(link netlink.Link, family int) (bool, error) {
 
 // setAddrGenModeNone sets addr_gen_mode to none to the given link.
 func setAddrGenModeNone(l netlink.Link) error {
-	fileName := fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/addr_gen_mode", l.Attrs().Name)
+	if err := setIPv6Conf(l, "addr_gen_mode", "1"); err != nil {
+		return fmt.Errorf("addrGenModeNone: %w", err)
+	}
+	return nil
+}
+
+// enableIPv6 makes sure IPv6 is not disabled on the given link. The IPv6
+// routes received via EVPN are installed through the bridge of the VNI with
+// an IPv4 mapped next hop, which the kernel accepts only if IPv6 is enabled
+// on the bridge, even if it has no link local address.
+func enableIPv6(l netlink.Link) error {
+	if err := setIPv6Conf(l, "disable_ipv6", "0"); err != nil {
+		return fmt.Errorf("enableIPv6: %w", err)
+	}
+	return nil
+}
+
+// setIPv6Conf sets the given ipv6 sysctl of the given link.
+func setIPv6Conf(l netlink.Link, key, value string) error {
+	return setIPConf(l, "ipv6", key, value)
+}
+
+// setIPv4Conf sets the given ipv4 sysctl of the given link.
+func setIPv4Conf(l netlink.Link, key, value string) error {
+	return setIPConf(l, "ipv4", key, value)
+}
+
+func setIPConf(l netlink.Link, family, key, value string) error {
+	fileName := fmt.Sprintf("/proc/sys/net/%s/conf/%s/%s", family, l.Attrs().Name, key)
 	fileName = filepath.Clean(fileName)
 	if !strings.HasPrefix(fileName, "/proc/sys/") {
 		panic(fmt.Errorf("attempt to escape")) // TODO: replace with os.Root when Go 1.24 is out
 	}
 	file, err := os.OpenFile(fileName, os.O_WRONLY, 0)
 	if err != nil {
-		return fmt.Errorf("addrGenModeNone: error opening file: %w", err)
+		return fmt.Errorf("error opening file: %w", err)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -88,18 +142,23 @@ func setAddrGenModeNone(l netlink.Link) error {
 		}
 	}()
 
-	if _, err := fmt.Fprintf(file, "%s\n", "1"); err != nil {
-		return fmt.Errorf("addrGenModeNone: error writing to file: %w", err)
+	if _, err := fmt.Fprintf(file, "%s\n", value); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
 	}
 	return nil
 }
 
-// setNeighSuppression sets neighbor suppression to the given link.
+// setNeighSuppression sets neighbor suppression to the given link,
+// if not set already.
 func setNeighSuppression(link netlink.Link) error {
+	protinfo, err := netlink.LinkGetProtinfo(link)
+	if err == nil && protinfo.NeighSuppress {
+		return nil
+	}
+
 	req := nl.NewNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
 
 	msg := nl.NewIfInfomsg(unix.AF_BRIDGE)
-	var err error
 	msg.Index, err = intToInt32(link.Attrs().Index)
 	if err != nil {
 		return fmt.Errorf("invalid index for %s", link.Attrs().Name)
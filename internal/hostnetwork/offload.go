@@ -0,0 +1,80 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ethtoolValue is the struct ethtool_value of the kernel, used
+// by the ethtool commands getting and setting a single feature.
+type ethtoolValue struct {
+	cmd  uint32
+	data uint32
+}
+
+// ethtoolIfreq is the struct ifreq of the kernel, with the ifr_data
+// member pointing to the ethtool command.
+type ethtoolIfreq struct {
+	name [unix.IFNAMSIZ]byte
+	data uintptr
+	_    [16]byte
+}
+
+// setOffloads enables or disables the GRO and GSO offloads of the given
+// link, as done by `ethtool -K <link> gro on|off gso on|off`. The
+// offloads that are not set are left untouched.
+func setOffloads(linkName string, gro, gso *bool) error {
+	if gro != nil {
+		if err := setFeature(linkName, unix.ETHTOOL_SGRO, *gro); err != nil {
+			return fmt.Errorf("failed to set gro on %s: %w", linkName, err)
+		}
+	}
+	if gso != nil {
+		if err := setFeature(linkName, unix.ETHTOOL_SGSO, *gso); err != nil {
+			return fmt.Errorf("failed to set gso on %s: %w", linkName, err)
+		}
+	}
+	return nil
+}
+
+// offloadEnabled tells if the offload read by the given ethtool
+// command is enabled on the given link.
+func offloadEnabled(linkName string, cmd uint32) (bool, error) {
+	value := ethtoolValue{cmd: cmd}
+	if err := ethtoolIoctl(linkName, &value); err != nil {
+		return false, err
+	}
+	return value.data != 0, nil
+}
+
+func setFeature(linkName string, cmd uint32, enabled bool) error {
+	value := ethtoolValue{cmd: cmd}
+	if enabled {
+		value.data = 1
+	}
+	return ethtoolIoctl(linkName, &value)
+}
+
+// ethtoolIoctl runs the given ethtool command against the given link, in
+// the network namespace of the calling thread.
+func ethtoolIoctl(linkName string, value *ethtoolValue) error {
+	if len(linkName) >= unix.IFNAMSIZ {
+		return fmt.Errorf("invalid link name %s", linkName)
+	}
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open the ethtool socket: %w", err)
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	req := ethtoolIfreq{data: uintptr(unsafe.Pointer(value))}
+	copy(req.name[:], linkName)
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return fmt.Errorf("ethtool command %#x failed: %w", value.cmd, errno)
+	}
+	return nil
+}
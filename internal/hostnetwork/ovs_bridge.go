@@ -23,10 +23,11 @@ var OVSSocketPath = defaultOVSSocketPath
 
 // Bridge model represents a row in the Bridge table
 type Bridge struct {
-	UUID        string            `ovsdb:"_uuid"`
-	Name        string            `ovsdb:"name"`
-	Ports       []string          `ovsdb:"ports"`
-	ExternalIds map[string]string `ovsdb:"external_ids"`
+	UUID         string            `ovsdb:"_uuid"`
+	Name         string            `ovsdb:"name"`
+	Ports        []string          `ovsdb:"ports"`
+	ExternalIds  map[string]string `ovsdb:"external_ids"`
+	DatapathType string            `ovsdb:"datapath_type"`
 }
 
 // Port model represents a row in the Port table
@@ -75,11 +76,19 @@ func NewOVSClient(ctx context.Context) (libovsclient.Client, error) {
 	return ovs, nil
 }
 
-// EnsureBridge ensures an OVS bridge exists, creating it if necessary
-func EnsureBridge(ctx context.Context, ovs libovsclient.Client, bridgeName string) (string, error) {
+// EnsureBridge ensures an OVS bridge exists, creating it if necessary.
+// When datapathType is not empty, it is set as the datapath type of the
+// bridge, changing it if the bridge exists already.
+func EnsureBridge(ctx context.Context, ovs libovsclient.Client, bridgeName, datapathType string) (string, error) {
 	br := &Bridge{Name: bridgeName}
 	err := ovs.Get(ctx, br)
 	if err == nil {
+		if datapathType == "" || br.DatapathType == datapathType {
+			return br.UUID, nil
+		}
+		if err := setBridgeDatapathType(ctx, ovs, br, datapathType); err != nil {
+			return "", err
+		}
 		return br.UUID, nil
 	}
 	if !errors.Is(err, libovsclient.ErrNotFound) {
@@ -88,9 +97,10 @@ func EnsureBridge(ctx context.Context, ovs libovsclient.Client, bridgeName strin
 
 	namedUUID := "new_bridge"
 	br = &Bridge{
-		UUID:        namedUUID,
-		Name:        bridgeName,
-		ExternalIds: map[string]string{"created-by": "openperouter"},
+		UUID:         namedUUID,
+		Name:         bridgeName,
+		ExternalIds:  map[string]string{"created-by": "openperouter"},
+		DatapathType: datapathType,
 	}
 
 	insertOp, err := ovs.Create(br)
@@ -126,8 +136,26 @@ func EnsureBridge(ctx context.Context, ovs libovsclient.Client, bridgeName strin
 	return realUUID, nil
 }
 
-func ensureOVSBridgeAndAttach(ctx context.Context, bridgeName, ifaceName string) error {
-	slog.Info("ensureOVSBridgeAndAttach", "bridge", bridgeName, "interface", ifaceName)
+// setBridgeDatapathType changes the datapath type of the given bridge.
+func setBridgeDatapathType(ctx context.Context, ovs libovsclient.Client, br *Bridge, datapathType string) error {
+	slog.Info("setting the datapath type of OVS bridge", "bridge", br.Name, "from", br.DatapathType, "to", datapathType)
+	br.DatapathType = datapathType
+	operations, err := ovs.Where(br).Update(br, &br.DatapathType)
+	if err != nil {
+		return fmt.Errorf("failed to create update operation for bridge %q: %w", br.Name, err)
+	}
+	reply, err := ovs.Transact(ctx, operations...)
+	if err != nil {
+		return fmt.Errorf("failed to set the datapath type of bridge %q: %w", br.Name, err)
+	}
+	if _, err := ovsdb.CheckOperationResults(reply, operations); err != nil {
+		return fmt.Errorf("failed to set the datapath type of bridge %q: %w", br.Name, err)
+	}
+	return nil
+}
+
+func ensureOVSBridgeAndAttach(ctx context.Context, bridgeName, ifaceName, datapathType string) error {
+	slog.Info("ensureOVSBridgeAndAttach", "bridge", bridgeName, "interface", ifaceName, "datapathType", datapathType)
 
 	// Verify the interface exists before trying to attach to OVS
 	link, err := netlink.LinkByName(ifaceName)
@@ -142,12 +170,12 @@ func ensureOVSBridgeAndAttach(ctx context.Context, bridgeName, ifaceName string)
 	}
 	defer ovs.Close()
 
-	return ensureOVSBridgeAndAttachWithClient(ctx, ovs, bridgeName, ifaceName)
+	return ensureOVSBridgeAndAttachWithClient(ctx, ovs, bridgeName, ifaceName, datapathType)
 }
 
 // ensureOVSBridgeAndAttachWithClient ensures an OVS bridge exists and attaches ifaceName as a port.
 // This version accepts a client parameter for testing.
-func ensureOVSBridgeAndAttachWithClient(ctx context.Context, ovs libovsclient.Client, bridgeName, ifaceName string) error {
+func ensureOVSBridgeAndAttachWithClient(ctx context.Context, ovs libovsclient.Client, bridgeName, ifaceName, datapathType string) error {
 	// Cache for indexed operations
 	if _, err := ovs.Monitor(ctx,
 		ovs.NewMonitor(
@@ -160,7 +188,7 @@ func ensureOVSBridgeAndAttachWithClient(ctx context.Context, ovs libovsclient.Cl
 		return fmt.Errorf("failed to setup monitor: %w", err)
 	}
 
-	bridgeUUID, err := EnsureBridge(ctx, ovs, bridgeName)
+	bridgeUUID, err := EnsureBridge(ctx, ovs, bridgeName, datapathType)
 	if err != nil {
 		return fmt.Errorf("failed to ensure OVS bridge %q exists: %w", bridgeName, err)
 	}
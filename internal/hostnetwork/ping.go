@@ -0,0 +1,117 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netns"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"golang.org/x/sys/unix"
+)
+
+// pingTimeout is the time to wait for the echo reply when
+// no deadline is set on the context.
+const pingTimeout = 2 * time.Second
+
+// Ping sends an ICMP echo request to the given ip from the given vrf of the
+// given namespace, and waits for the matching echo reply. The socket is bound
+// to the vrf device, as the ip is reachable only through the table of the vrf.
+func Ping(ctx context.Context, targetNS, vrf, ip string) error {
+	dst := net.ParseIP(ip)
+	if dst == nil {
+		return fmt.Errorf("failed to parse ip %s", ip)
+	}
+	ns, err := netns.GetFromPath(targetNS)
+	if err != nil {
+		return fmt.Errorf("ping: failed to find network namespace %s: %w", targetNS, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", targetNS, "error", err)
+		}
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(pingTimeout)
+	}
+
+	return inNamespace(ns, func() error {
+		network, address := "ip4:icmp", "0.0.0.0"
+		var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+		protocol := 1
+		if dst.To4() == nil {
+			network, address = "ip6:ipv6-icmp", "::"
+			echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+			protocol = 58
+		}
+
+		listenConfig := net.ListenConfig{Control: bindToDevice(vrf)}
+		conn, err := listenConfig.ListenPacket(ctx, network, address)
+		if err != nil {
+			return fmt.Errorf("ping: failed to open icmp socket in vrf %s: %w", vrf, err)
+		}
+		defer conn.Close()
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("ping: failed to set deadline: %w", err)
+		}
+
+		id := os.Getpid() & 0xffff
+		request := icmp.Message{
+			Type: echoType,
+			Body: &icmp.Echo{ID: id, Seq: 1, Data: []byte("openperouter")},
+		}
+		toSend, err := request.Marshal(nil)
+		if err != nil {
+			return fmt.Errorf("ping: failed to marshal echo request: %w", err)
+		}
+		if _, err := conn.WriteTo(toSend, &net.IPAddr{IP: dst}); err != nil {
+			return fmt.Errorf("ping: failed to send echo request to %s: %w", ip, err)
+		}
+
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return fmt.Errorf("ping: no echo reply from %s: %w", ip, err)
+			}
+			if addr, ok := from.(*net.IPAddr); !ok || !addr.IP.Equal(dst) {
+				continue
+			}
+			reply, err := icmp.ParseMessage(protocol, buf[:n])
+			if err != nil || reply.Type != replyType {
+				continue
+			}
+			if echo, ok := reply.Body.(*icmp.Echo); ok && echo.ID == id {
+				return nil
+			}
+		}
+	})
+}
+
+// bindToDevice returns a socket control function binding
+// the socket to the given device, as ip vrf exec does.
+func bindToDevice(device string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var bindErr error
+		err := c.Control(func(fd uintptr) {
+			bindErr = unix.BindToDevice(int(fd), device)
+		})
+		if err != nil {
+			return err
+		}
+		if bindErr != nil {
+			return fmt.Errorf("failed to bind to device %s: %w", device, bindErr)
+		}
+		return nil
+	}
+}
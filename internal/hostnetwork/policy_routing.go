@@ -0,0 +1,326 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+const (
+	// policyRoutingTableBase is the first routing table reserved to the
+	// L2 VNIs overlays. The table of a given VNI is the base + the VNI.
+	policyRoutingTableBase = 0x10000000
+	maxVNI                 = 1<<24 - 1
+	// policyRoutingPriority is the priority of the source based rules,
+	// which must be evaluated before the main table (32766).
+	policyRoutingPriority = 1000
+)
+
+// podNetNSDir is the directory the network namespaces of the
+// pods running on the node are pinned in.
+var podNetNSDir = "/run/netns"
+
+func policyRoutingTable(vni int) int {
+	return policyRoutingTableBase + vni
+}
+
+// vniFromPolicyRoutingTable returns the VNI the given table belongs to,
+// and false if the table is not one of the L2 VNIs tables.
+func vniFromPolicyRoutingTable(table int) (int, bool) {
+	if table < policyRoutingTableBase || table > policyRoutingTableBase+maxVNI {
+		return 0, false
+	}
+	return table - policyRoutingTableBase, true
+}
+
+// setupPolicyRouting installs in the current namespace a set of source based rules
+// that steer the traffic originated from the overlay subnets to a dedicated table,
+// where the default route points to the L2 gateway via the given link. This way
+// the default route of the main table is preserved for the rest of the traffic.
+func setupPolicyRouting(vni int, link netlink.Link, gatewayIPs []string) error {
+	table := policyRoutingTable(vni)
+	rules, err := policyRoutingRules(table)
+	if err != nil {
+		return err
+	}
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL,
+		&netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return fmt.Errorf("failed to list routes for table %d: %w", table, err)
+	}
+
+	for _, ip := range gatewayIPs {
+		gateway, subnet, err := net.ParseCIDR(ip)
+		if err != nil {
+			return fmt.Errorf("failed to parse gateway ip %s: %w", ip, err)
+		}
+		family := netlink.FAMILY_V4
+		if gateway.To4() == nil {
+			family = netlink.FAMILY_V6
+		}
+
+		subnetRoute := &netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       subnet,
+			Scope:     netlink.SCOPE_LINK,
+			Table:     table,
+		}
+		if err := ensureRoute(routes, subnetRoute); err != nil {
+			return fmt.Errorf("failed to add route to %s in table %d: %w", subnet, table, err)
+		}
+
+		defaultRoute := &netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       defaultDestination(family),
+			Gw:        gateway,
+			Table:     table,
+		}
+		if err := ensureRoute(routes, defaultRoute); err != nil {
+			return fmt.Errorf("failed to add default route via %s in table %d: %w", gateway, table, err)
+		}
+
+		if hasRuleFrom(rules, subnet) {
+			continue
+		}
+		rule := netlink.NewRule()
+		rule.Family = family
+		rule.Src = subnet
+		rule.Table = table
+		rule.Priority = policyRoutingPriority
+		if err := netlink.RuleAdd(rule); err != nil && !os.IsExist(err) {
+			return fmt.Errorf("failed to add rule from %s to table %d: %w", subnet, table, err)
+		}
+		slog.Debug("added policy routing rule", "vni", vni, "from", subnet.String(), "table", table)
+	}
+	return nil
+}
+
+// setupPodsPolicyRouting installs the policy routing of the given vni in the
+// namespaces of the pods attached to the given host master, either via a
+// macvlan on top of it or via a veth enslaved to it. This way the pods keep
+// their default route, while the traffic they source from the overlay subnets
+// is routed via the L2 gateway. The router, attached to the master via the
+// given veth, is skipped.
+func setupPodsPolicyRouting(targetNS string, vni int, master, routerVeth netlink.Link, gatewayIPs []string) error {
+	attachments, err := hostMasterAttachments(master, routerVeth)
+	if err != nil {
+		return err
+	}
+	return forEachPodNamespace(targetNS, func(hostNS netns.NsHandle) error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("failed to list links: %w", err)
+		}
+		// the id is read after listing the links, which assigns it to the
+		// host namespace if a link refers to it. Without an id, none does.
+		hostNSID, err := netlink.GetNetNsIdByFd(int(hostNS))
+		if err != nil {
+			return fmt.Errorf("failed to get the id of the host namespace: %w", err)
+		}
+		if hostNSID < 0 {
+			return nil
+		}
+		for _, l := range links {
+			if l.Attrs().NetNsID != hostNSID || !attachments[l.Attrs().ParentIndex] {
+				continue
+			}
+			if err := setupPolicyRouting(vni, l, gatewayIPs); err != nil {
+				return fmt.Errorf("failed to setup policy routing via %s: %w", l.Attrs().Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// hostMasterAttachments returns the indexes of the host links the pods can
+// be attached to the given master through: the master itself, for the
+// macvlans, and the veths enslaved to it but the one of the router.
+func hostMasterAttachments(master, routerVeth netlink.Link) (map[int]bool, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	res := map[int]bool{master.Attrs().Index: true}
+	for _, l := range links {
+		if l.Attrs().MasterIndex != master.Attrs().Index || l.Attrs().Index == routerVeth.Attrs().Index {
+			continue
+		}
+		res[l.Attrs().Index] = true
+	}
+	return res, nil
+}
+
+// forEachPodNamespace runs the given function in each of the namespaces
+// pinned in podNetNSDir, but the host and the target ones, passing it
+// the host namespace.
+func forEachPodNamespace(targetNS string, f func(hostNS netns.NsHandle) error) error {
+	entries, err := os.ReadDir(podNetNSDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the namespaces in %s: %w", podNetNSDir, err)
+	}
+	hostNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get the host namespace: %w", err)
+	}
+	defer func() {
+		if err := hostNS.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", "host", "error", err)
+		}
+	}()
+	routerNS, err := netns.GetFromPath(targetNS)
+	if err != nil {
+		return fmt.Errorf("failed to get network namespace %s: %w", targetNS, err)
+	}
+	defer func() {
+		if err := routerNS.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", targetNS, "error", err)
+		}
+	}()
+
+	errs := []error{}
+	for _, e := range entries {
+		path := filepath.Join(podNetNSDir, e.Name())
+		ns, err := netns.GetFromPath(path)
+		if err != nil {
+			// the pod may be gone in the meantime
+			slog.Debug("skipping namespace", "namespace", path, "error", err)
+			continue
+		}
+		if ns.Equal(hostNS) || ns.Equal(routerNS) {
+			_ = ns.Close()
+			continue
+		}
+		err = inNamespace(ns, func() error {
+			return f(hostNS)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", path, err))
+		}
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", path, "error", err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeAllPolicyRouting removes the policy routing of the given vni from
+// the host and, if it is installed there, from the namespaces of the pods.
+// The pods go first, as the rules of the host tell if they must be cleaned.
+func removeAllPolicyRouting(targetNS string, vni int) error {
+	rules, err := policyRoutingRules(policyRoutingTable(vni))
+	if err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		if err := forEachPodNamespace(targetNS, func(netns.NsHandle) error {
+			return removePolicyRouting(vni)
+		}); err != nil {
+			return err
+		}
+	}
+	return removePolicyRouting(vni)
+}
+
+// removePolicyRouting removes the rules and the routes installed by
+// setupPolicyRouting for the given vni in the current namespace, if any.
+func removePolicyRouting(vni int) error {
+	table := policyRoutingTable(vni)
+	rules, err := policyRoutingRules(table)
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, r := range rules {
+		if err := netlink.RuleDel(&r); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete rule %s: %w", r.String(), err))
+		}
+	}
+
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL,
+		&netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return fmt.Errorf("failed to list routes for table %d: %w", table, err)
+	}
+	for _, r := range routes {
+		if err := netlink.RouteDel(&r); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete route %s: %w", r.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeNonConfiguredPolicyRouting removes the policy routing of all
+// the VNIs not contained in the given set, from the host and the pods.
+func removeNonConfiguredPolicyRouting(targetNS string, vnis map[int]bool) error {
+	rules, err := netlink.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("failed to list rules: %w", err)
+	}
+	toRemove := map[int]bool{}
+	for _, r := range rules {
+		vni, ok := vniFromPolicyRoutingTable(r.Table)
+		if !ok || vnis[vni] {
+			continue
+		}
+		toRemove[vni] = true
+	}
+	errs := []error{}
+	for vni := range toRemove {
+		if err := removeAllPolicyRouting(targetNS, vni); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove policy routing for vni %d: %w", vni, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func policyRoutingRules(table int) ([]netlink.Rule, error) {
+	rules, err := netlink.RuleListFiltered(netlink.FAMILY_ALL,
+		&netlink.Rule{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules for table %d: %w", table, err)
+	}
+	return rules, nil
+}
+
+// ensureRoute replaces the given route, unless the given routes
+// already contain one to the same destination, via the same link
+// and gateway.
+func ensureRoute(routes []netlink.Route, route *netlink.Route) error {
+	for _, r := range routes {
+		dst := r.Dst
+		if dst == nil {
+			dst = defaultDestination(r.Family)
+		}
+		if r.LinkIndex == route.LinkIndex && dst.String() == route.Dst.String() && r.Gw.Equal(route.Gw) {
+			return nil
+		}
+	}
+	return netlink.RouteReplace(route)
+}
+
+func hasRuleFrom(rules []netlink.Rule, subnet *net.IPNet) bool {
+	for _, r := range rules {
+		if r.Src != nil && r.Src.String() == subnet.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultDestination(family int) *net.IPNet {
+	if family == netlink.FAMILY_V6 {
+		return &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+	}
+	return &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+}
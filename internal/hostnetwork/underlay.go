@@ -28,8 +28,15 @@ type UnderlayParams struct {
 
 type UnderlayEVPNParams struct {
 	VtepIP string `json:"vtep_ip"`
+	// VtepIPv6 is the additional ipv6 address of
+	// the VTEP, for dual stack VTEPs.
+	VtepIPv6 string `json:"vtep_ipv6,omitempty"`
 }
 
+// SetupUnderlay moves the underlay interface to the target namespace
+// and configures the VTEP on it, when EVPN is enabled.
+// Calling it again with the same params does not change anything
+// on the host.
 func SetupUnderlay(ctx context.Context, params UnderlayParams) error {
 	slog.DebugContext(ctx, "setup underlay", "params", params)
 	defer slog.DebugContext(ctx, "setup underlay done")
@@ -52,20 +59,76 @@ func SetupUnderlay(ctx context.Context, params UnderlayParams) error {
 	if params.EVPN == nil {
 		return nil
 	}
-	if err := createLoopback(ctx, ns, params.EVPN.VtepIP); err != nil {
+	vtepIPs := []string{params.EVPN.VtepIP}
+	if params.EVPN.VtepIPv6 != "" {
+		vtepIPs = append(vtepIPs, params.EVPN.VtepIPv6)
+	}
+	if err := createLoopback(ctx, ns, vtepIPs...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// RemoveUnderlay removes the underlay configuration from the target
+// namespace: the loopback holding the VTEP is deleted, and the underlay
+// interface, if any, is moved back to the current namespace.
+func RemoveUnderlay(ctx context.Context, targetNS string) error {
+	slog.DebugContext(ctx, "remove underlay", "namespace", targetNS)
+	defer slog.DebugContext(ctx, "remove underlay done")
+	ns, err := netns.GetFromPath(targetNS)
+	if err != nil {
+		return fmt.Errorf("RemoveUnderlay: failed to find network namespace %s: %w", targetNS, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", targetNS, "error", err)
+		}
+	}()
+	hostNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("RemoveUnderlay: failed to get the current network namespace: %w", err)
+	}
+	defer func() {
+		if err := hostNS.Close(); err != nil {
+			slog.Error("failed to close the current namespace", "error", err)
+		}
+	}()
+
+	underlayInterface, err := findInterfaceWithIP(ns, underlayInterfaceSpecialAddr)
+	if err != nil {
+		return fmt.Errorf("RemoveUnderlay: failed to get the underlay interface: %w", err)
+	}
+
+	return inNamespace(ns, func() error {
+		if err := removeLinkByName(UnderlayLoopback); err != nil {
+			return fmt.Errorf("RemoveUnderlay: failed to remove the loopback: %w", err)
+		}
+		if underlayInterface == "" {
+			return nil
+		}
+		underlay, err := netlink.LinkByName(underlayInterface)
+		if err != nil {
+			return fmt.Errorf("RemoveUnderlay: failed to get underlay nic by name %s: %w", underlayInterface, err)
+		}
+		addr, err := netlink.ParseAddr(underlayInterfaceSpecialAddr)
+		if err != nil {
+			return fmt.Errorf("RemoveUnderlay: failed to parse address %s: %w", underlayInterfaceSpecialAddr, err)
+		}
+		if err := netlink.AddrDel(underlay, addr); err != nil {
+			return fmt.Errorf("RemoveUnderlay: failed to remove address %s from %s: %w", underlayInterfaceSpecialAddr, underlayInterface, err)
+		}
+		return moveInterfaceToNamespace(ctx, underlayInterface, hostNS)
+	})
+}
+
 type UnderlayExistsError string
 
 func (e UnderlayExistsError) Error() string {
 	return string(e)
 }
 
-func createLoopback(ctx context.Context, ns netns.NsHandle, vtepIP string) error {
+func createLoopback(ctx context.Context, ns netns.NsHandle, vtepIPs ...string) error {
 	slog.DebugContext(ctx, "setup underlay", "step", "creating loopback interface")
 	defer slog.DebugContext(ctx, "setup underlay", "step", "loopback interface created")
 
@@ -79,9 +142,10 @@ func createLoopback(ctx context.Context, ns netns.NsHandle, vtepIP string) error
 			}
 		}
 
-		err = assignIPToInterface(loopback, vtepIP)
-		if err != nil {
-			return err
+		for _, ip := range vtepIPs {
+			if err := assignIPToInterface(loopback, ip); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -112,6 +176,10 @@ func moveUnderlayInterface(ctx context.Context, underlayInterface string, ns net
 		return UnderlayExistsError(fmt.Sprintf("existing underlay found: %s, new is %s", currentUnderlayInterface, underlayInterface))
 	}
 
+	if err := ensureVLANInterface(ctx, underlayInterface); err != nil {
+		return fmt.Errorf("failed to ensure underlay interface %s: %w", underlayInterface, err)
+	}
+
 	err = moveInterfaceToNamespace(ctx, underlayInterface, ns)
 	if err != nil {
 		return err
@@ -162,21 +230,12 @@ func HasUnderlayInterface(namespace string) (bool, error) {
 func findInterfaceWithIP(ns netns.NsHandle, ip string) (string, error) {
 	res := ""
 	err := inNamespace(ns, func() error {
-		links, err := netlink.LinkList()
+		l, err := linkWithIP(ip)
 		if err != nil {
-			return fmt.Errorf("failed to list links: %w", err)
+			return err
 		}
-		for _, l := range links {
-			addr, _ := netlink.AddrList(l, netlink.FAMILY_ALL)
-			slog.Debug("find underlay", "checking link", l.Attrs().Name, "addresses", addr)
-			hasIP, err := interfaceHasIP(l, ip)
-			if err != nil {
-				return err
-			}
-			if hasIP {
-				res = l.Attrs().Name
-				return nil
-			}
+		if l != nil {
+			res = l.Attrs().Name
 		}
 		return nil
 	})
@@ -190,3 +249,24 @@ func findInterfaceWithIP(ns netns.NsHandle, ip string) (string, error) {
 	slog.Debug("returning not found")
 	return "", nil
 }
+
+// linkWithIP returns the link of the current network ns assigned
+// to the given ip, or nil if none is.
+func linkWithIP(ip string) (netlink.Link, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	for _, l := range links {
+		addr, _ := netlink.AddrList(l, netlink.FAMILY_ALL)
+		slog.Debug("find underlay", "checking link", l.Attrs().Name, "addresses", addr)
+		hasIP, err := interfaceHasIP(l, ip)
+		if err != nil {
+			return nil, err
+		}
+		if hasIP {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
@@ -98,6 +98,27 @@ var _ = Describe("Underlay configuration should work when", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
+	It("creating the same underlay twice should not change anything", func() {
+		params := UnderlayParams{
+			UnderlayInterface: underlayTestInterface,
+			EVPN: &UnderlayEVPNParams{
+				VtepIP: "192.168.1.1/32",
+			},
+			TargetNS: underlayTestNSPath(),
+		}
+		err := SetupUnderlay(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validateUnderlayInNS(g, testNs, params)
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		expectNoNetlinkChanges(testNs, func() {
+			err = SetupUnderlay(context.Background(), params)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	It("changing the underlay interface should error", func() {
 		params := UnderlayParams{
 			UnderlayInterface: underlayTestInterface,
@@ -144,6 +165,23 @@ var _ = Describe("Underlay configuration should work when", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
+	It("should assign both the vtep ips with a dual stack vtep", func() {
+		params := UnderlayParams{
+			UnderlayInterface: underlayTestInterface,
+			EVPN: &UnderlayEVPNParams{
+				VtepIP:   "192.168.1.1/32",
+				VtepIPv6: "fd00:1::1/128",
+			},
+			TargetNS: underlayTestNSPath(),
+		}
+		err := SetupUnderlay(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validateUnderlayInNS(g, testNs, params)
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
 	It("should work without EVPN set", func() {
 		params := UnderlayParams{
 			UnderlayInterface: underlayTestInterface,
@@ -157,6 +195,39 @@ var _ = Describe("Underlay configuration should work when", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
+	It("should create the vlan sub-interface on the parent nic", func() {
+		const vlanParent = "testvlanpar"
+		parent := &netlink.Dummy{
+			LinkAttrs: netlink.LinkAttrs{
+				Name: vlanParent,
+			},
+		}
+		err := netlink.LinkAdd(parent)
+		Expect(err).NotTo(HaveOccurred())
+
+		params := UnderlayParams{
+			UnderlayInterface: vlanParent + ".161",
+			EVPN: &UnderlayEVPNParams{
+				VtepIP: "192.168.1.1/32",
+			},
+			TargetNS: underlayTestNSPath(),
+		}
+		err = SetupUnderlay(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNs, func() error {
+				validateUnderlay(g, params)
+				link, err := netlink.LinkByName(params.UnderlayInterface)
+				g.Expect(err).NotTo(HaveOccurred())
+				vlan, ok := link.(*netlink.Vlan)
+				g.Expect(ok).To(BeTrue(), "underlay interface %s is not a vlan", params.UnderlayInterface)
+				g.Expect(vlan.VlanId).To(Equal(161))
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
 	It("should work without NIC set, assuming Multus is used", func() {
 		params := UnderlayParams{
 			UnderlayInterface: "",
@@ -188,6 +259,9 @@ func validateUnderlay(g Gomega, params UnderlayParams, interfaceIPs ...string) {
 			loopbackFound = true
 			if params.EVPN != nil {
 				validateIP(g, l, params.EVPN.VtepIP)
+				if params.EVPN.VtepIPv6 != "" {
+					validateIP(g, l, params.EVPN.VtepIPv6)
+				}
 			}
 		}
 		if params.UnderlayInterface != "" && l.Attrs().Name == params.UnderlayInterface {
@@ -265,3 +339,30 @@ func createTestNS(testNs string) netns.NsHandle {
 	Expect(err).NotTo(HaveOccurred())
 	return newNs
 }
+
+// expectNoNetlinkChanges runs the given function and expects it not to
+// change any link, address or route, neither in the current namespace
+// nor in the given one. The kernel notifications are used instead of
+// mocking netlink, so any mutating call on an unchanged object is caught.
+func expectNoNetlinkChanges(ns netns.NsHandle, f func()) {
+	done := make(chan struct{})
+	defer close(done)
+
+	links := make(chan netlink.LinkUpdate, 100)
+	addrs := make(chan netlink.AddrUpdate, 100)
+	routes := make(chan netlink.RouteUpdate, 100)
+	for _, handle := range []*netns.NsHandle{nil, &ns} {
+		err := netlink.LinkSubscribeWithOptions(links, done, netlink.LinkSubscribeOptions{Namespace: handle})
+		Expect(err).NotTo(HaveOccurred())
+		err = netlink.AddrSubscribeWithOptions(addrs, done, netlink.AddrSubscribeOptions{Namespace: handle})
+		Expect(err).NotTo(HaveOccurred())
+		err = netlink.RouteSubscribeWithOptions(routes, done, netlink.RouteSubscribeOptions{Namespace: handle})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	f()
+
+	Consistently(links, 2*time.Second).ShouldNot(Receive(), "unexpected link change")
+	Expect(addrs).NotTo(Receive(), "unexpected address change")
+	Expect(routes).NotTo(Receive(), "unexpected route change")
+}
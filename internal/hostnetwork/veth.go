@@ -143,8 +143,13 @@ const PEVethPrefix = "pe-"
 // corresponding to the default namespace and the target namespace, based on VNI.
 func vethNamesFromVNI(vni int) VethNames {
 	hostSide := fmt.Sprintf("%s%d", HostVethPrefix, vni)
-	peSide := fmt.Sprintf("%s%d", PEVethPrefix, vni)
-	return VethNames{HostSide: hostSide, NamespaceSide: peSide}
+	return VethNames{HostSide: hostSide, NamespaceSide: PEVethName(vni)}
+}
+
+// PEVethName returns the name of the veth leg in
+// the target namespace corresponding to the given VNI.
+func PEVethName(vni int) string {
+	return fmt.Sprintf("%s%d", PEVethPrefix, vni)
 }
 
 // vniFromHostVeth extracts the VNI (as int) from a host veth name.
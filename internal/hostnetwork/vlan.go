@@ -0,0 +1,75 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	minVLANID = 1
+	maxVLANID = 4094
+)
+
+// ParseVLANInterface splits a dotted interface name in the form <parent>.<vlan>
+// into its parent interface and vlan id. It returns false if the name does not
+// describe a vlan sub-interface, and an error if the vlan id is out of range.
+func ParseVLANInterface(name string) (string, int, bool, error) {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 || i == len(name)-1 {
+		return "", 0, false, nil
+	}
+	parent, suffix := name[:i], name[i+1:]
+	vlanID, err := strconv.Atoi(suffix)
+	if err != nil {
+		return "", 0, false, nil
+	}
+	if vlanID < minVLANID || vlanID > maxVLANID {
+		return "", 0, false, fmt.Errorf("vlan id %d of interface %s must be between %d and %d", vlanID, name, minVLANID, maxVLANID)
+	}
+	return parent, vlanID, true, nil
+}
+
+// ensureVLANInterface creates the vlan sub-interface described by the given
+// dotted name on top of its parent, if the interface does not exist already.
+func ensureVLANInterface(ctx context.Context, name string) error {
+	_, err := netlink.LinkByName(name)
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &netlink.LinkNotFoundError{}) {
+		return fmt.Errorf("failed to get link %s: %w", name, err)
+	}
+
+	parentName, vlanID, isVLAN, err := ParseVLANInterface(name)
+	if err != nil {
+		return err
+	}
+	if !isVLAN {
+		return nil
+	}
+
+	parent, err := netlink.LinkByName(parentName)
+	if err != nil {
+		return fmt.Errorf("failed to get parent %s of vlan interface %s: %w", parentName, name, err)
+	}
+	vlan := &netlink.Vlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:        name,
+			ParentIndex: parent.Attrs().Index,
+		},
+		VlanId: vlanID,
+	}
+	if err := netlink.LinkAdd(vlan); err != nil {
+		return fmt.Errorf("failed to create vlan interface %s: %w", name, err)
+	}
+	slog.DebugContext(ctx, "created vlan interface", "name", name, "parent", parentName, "vlan", vlanID)
+	return nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package hostnetwork
+
+import "testing"
+
+func TestParseVLANInterface(t *testing.T) {
+	tests := []struct {
+		name       string
+		intf       string
+		wantParent string
+		wantVLAN   int
+		wantIsVLAN bool
+		wantErr    bool
+	}{
+		{
+			name:       "vlan sub-interface",
+			intf:       "eno2.161",
+			wantParent: "eno2",
+			wantVLAN:   161,
+			wantIsVLAN: true,
+		},
+		{
+			name:       "nested dots",
+			intf:       "eno2.10.161",
+			wantParent: "eno2.10",
+			wantVLAN:   161,
+			wantIsVLAN: true,
+		},
+		{
+			name: "plain interface",
+			intf: "eth0",
+		},
+		{
+			name: "non numeric suffix",
+			intf: "eth0.foo",
+		},
+		{
+			name: "trailing dot",
+			intf: "eth0.",
+		},
+		{
+			name: "leading dot",
+			intf: ".161",
+		},
+		{
+			name:    "vlan id zero",
+			intf:    "eno2.0",
+			wantErr: true,
+		},
+		{
+			name:    "vlan id too big",
+			intf:    "eno2.4095",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, vlan, isVLAN, err := ParseVLANInterface(tt.intf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVLANInterface(%s) error = %v, wantErr %v", tt.intf, err, tt.wantErr)
+			}
+			if parent != tt.wantParent || vlan != tt.wantVLAN || isVLAN != tt.wantIsVLAN {
+				t.Errorf("ParseVLANInterface(%s) = %s, %d, %v, want %s, %d, %v",
+					tt.intf, parent, vlan, isVLAN, tt.wantParent, tt.wantVLAN, tt.wantIsVLAN)
+			}
+		})
+	}
+}
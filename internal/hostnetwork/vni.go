@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	libovsclient "github.com/ovn-kubernetes/libovsdb/client"
 	"github.com/ovn-kubernetes/libovsdb/model"
@@ -17,16 +19,44 @@ import (
 )
 
 type VNIParams struct {
-	VRF       string `json:"vrf"`
-	TargetNS  string `json:"targetns"`
-	VTEPIP    string `json:"vtepip"`
+	VRF      string `json:"vrf"`
+	TargetNS string `json:"targetns"`
+	VTEPIP   string `json:"vtepip"`
+	// VTEPMAC is the MAC set on the vxlan interface and on the
+	// bridge of the VNI. If empty, the kernel assigns it.
+	VTEPMAC   string `json:"vtepmac,omitempty"`
 	VNI       int    `json:"vni"`
 	VXLanPort int    `json:"vxlanport"`
+	// DeviceNamePrefix is prepended to the names of the vxlan and
+	// bridge devices created for the vni.
+	DeviceNamePrefix string `json:"devicenameprefix,omitempty"`
+	// Learning enables MAC learning on the vxlan interface
+	// instead of relying on EVPN only.
+	Learning bool `json:"learning,omitempty"`
+	// MulticastGroup is the group the vxlan interface floods
+	// the BUM traffic to, used together with Learning.
+	MulticastGroup string `json:"multicastgroup,omitempty"`
+	// StaticVTEPs are the remote vteps the vxlan interface floods
+	// the BUM traffic to, used together with Learning.
+	StaticVTEPs []string `json:"staticvteps,omitempty"`
+	// DSCP is the DSCP value set on the outer header
+	// of the encapsulated packets.
+	DSCP int `json:"dscp,omitempty"`
+	// TXChecksum, when set, enables or disables the UDP checksum
+	// of the encapsulated packets sent by the vxlan interface.
+	TXChecksum *bool `json:"txchecksum,omitempty"`
+	// GRO and GSO, when set, enable or disable the generic receive
+	// and segmentation offloads of the vxlan interface.
+	GRO *bool `json:"gro,omitempty"`
+	GSO *bool `json:"gso,omitempty"`
 }
 
 type L3VNIParams struct {
 	VNIParams `json:",inline"`
 	HostVeth  *Veth `json:"veth"`
+	// RouterSVIAddress, when set, is assigned to the bridge of
+	// the VNI in the target namespace.
+	RouterSVIAddress string `json:"routersviaddress,omitempty"`
 }
 
 type L3PassthroughParams struct {
@@ -45,12 +75,25 @@ type L2VNIParams struct {
 	VNIParams    `json:",inline"`
 	L2GatewayIPs []string    `json:"l2gatewayips"`
 	HostMaster   *HostMaster `json:"hostmaster"`
+	// ManagePolicyRouting enables source based routing on the host and
+	// in the attached pods for the traffic originated from the L2 gateway
+	// subnets.
+	ManagePolicyRouting bool `json:"managepolicyrouting,omitempty"`
+	// MACAgeingTime is the ageing time of the MAC addresses learned
+	// by the bridge of the VNI. If zero, the kernel default is used.
+	MACAgeingTime time.Duration `json:"macageingtime,omitempty"`
+	// AdvertiseHostRoutes makes the bridge of the VNI learn the hosts
+	// announcing themselves, so that they are advertised as host routes.
+	AdvertiseHostRoutes bool `json:"advertisehostroutes,omitempty"`
 }
 
 type HostMaster struct {
 	Name       string `json:"name,omitempty"`
 	Type       string `json:"type,omitempty"`
 	AutoCreate bool   `json:"autocreate,omitempty"`
+	// DatapathType is the datapath type set on the OVS bridge
+	// when it is created automatically. If empty, it is not set.
+	DatapathType string `json:"datapathtype,omitempty"`
 }
 
 const (
@@ -72,13 +115,19 @@ func (e NotRouterInterfaceError) Error() string {
 // It uses setupVNI to create the necessary VRF, bridge, and
 // VXLan interface, and moves the veth to the VRF corresponding
 // to the L3 routing domain, exposing it to the default host namespace.
+// Calling it again with the same params does not change anything
+// on the host.
 func SetupL3VNI(ctx context.Context, params L3VNIParams) error {
-	if err := setupVNI(ctx, params.VNIParams); err != nil {
+	if err := setupVNI(ctx, params.VNIParams, true); err != nil {
 		return fmt.Errorf("SetupL3VNI: failed to setup VNI: %w", err)
 	}
 	slog.DebugContext(ctx, "setting up l3 VNI", "params", params)
 	defer slog.DebugContext(ctx, "end setting up l3 VNI", "params", params)
 
+	if err := setupRouterSVI(params); err != nil {
+		return fmt.Errorf("SetupL3VNI: %w", err)
+	}
+
 	if params.HostVeth == nil {
 		slog.DebugContext(ctx, "no host veth configured, skipping setup")
 		return nil
@@ -119,7 +168,7 @@ func SetupL3VNI(ctx context.Context, params L3VNIParams) error {
 			return fmt.Errorf("could not find vrf %s in namespace %s: %w", params.VRF, params.TargetNS, err)
 		}
 
-		err = netlink.LinkSetMaster(peVeth, vrf)
+		err = setMaster(peVeth, vrf)
 		if err != nil {
 			return fmt.Errorf("failed to set vrf %s as master of pe veth %s: %w", params.VRF, peVeth.Attrs().Name, err)
 		}
@@ -140,8 +189,13 @@ func SetupL3VNI(ctx context.Context, params L3VNIParams) error {
 // It uses setupVNI to create the necessary VRF, bridge, and
 // VXLan interface, and enslaves the veth leg to the bridge,
 // exposing the L2 domain to the default host namespace.
+// Calling it again with the same params does not change anything
+// on the host.
 func SetupL2VNI(ctx context.Context, params L2VNIParams) error {
-	if err := setupVNI(ctx, params.VNIParams); err != nil {
+	// the bridge of a distributed gateway gets the same fixed mac on all the nodes,
+	// set below, so it must not be overridden by the vtep mac.
+	withBridgeMAC := len(params.L2GatewayIPs) == 0
+	if err := setupVNI(ctx, params.VNIParams, withBridgeMAC); err != nil {
 		return fmt.Errorf("SetupL2VNI: failed to setup VNI: %w", err)
 	}
 	vethNames := vethNamesFromVNI(params.VNI)
@@ -171,25 +225,41 @@ func SetupL2VNI(ctx context.Context, params L2VNIParams) error {
 	}
 	slog.Info("SetupL2VNI: found host veth", "name", vethNames.HostSide, "index", hostVeth.Attrs().Index)
 
+	if !params.ManagePolicyRouting {
+		if err := removeAllPolicyRouting(params.TargetNS, params.VNI); err != nil {
+			return fmt.Errorf("SetupL2VNI: failed to remove policy routing for vni %d: %w", params.VNI, err)
+		}
+	}
+
 	if params.HostMaster != nil {
 		bridgeConfig := *params.HostMaster
 		switch bridgeConfig.Type {
 		case OVSBridgeLinkType:
 			lowerDeviceName := bridgeConfig.Name
+			datapathType := ""
 			if bridgeConfig.AutoCreate {
-				lowerDeviceName = hostBridgeName(params.VNI)
+				lowerDeviceName = hostBridgeName(params.DeviceNamePrefix, params.VNI)
+				datapathType = bridgeConfig.DatapathType
 			}
-			if err := ensureOVSBridgeAndAttach(ctx, lowerDeviceName, hostVeth.Attrs().Name); err != nil {
+			if err := ensureOVSBridgeAndAttach(ctx, lowerDeviceName, hostVeth.Attrs().Name, datapathType); err != nil {
 				return fmt.Errorf("failed to ensure OVS bridge %s and attach %s: %w", lowerDeviceName, hostVeth.Attrs().Name, err)
 			}
 		case BridgeLinkType:
-			master, err := hostMaster(params.VNI, bridgeConfig)
+			master, err := hostMaster(params.DeviceNamePrefix, params.VNI, bridgeConfig)
 			if err != nil {
 				return fmt.Errorf("SetupL2VNI: failed to get host master for VRF %s: %w", params.VRF, err)
 			}
-			if err := netlink.LinkSetMaster(hostVeth, master); err != nil {
+			if err := setMaster(hostVeth, master); err != nil {
 				return fmt.Errorf("failed to set host master %s as master of host veth %s: %w", master.Attrs().Name, hostVeth.Attrs().Name, err)
 			}
+			if params.ManagePolicyRouting {
+				if err := setupPolicyRouting(params.VNI, master, params.L2GatewayIPs); err != nil {
+					return fmt.Errorf("SetupL2VNI: failed to setup policy routing for vni %d: %w", params.VNI, err)
+				}
+				if err := setupPodsPolicyRouting(params.TargetNS, params.VNI, master, hostVeth, params.L2GatewayIPs); err != nil {
+					return fmt.Errorf("SetupL2VNI: failed to setup the pods policy routing for vni %d: %w", params.VNI, err)
+				}
+			}
 		default:
 			return fmt.Errorf("provided hostmaster.Type %q is not supported", bridgeConfig.Type)
 		}
@@ -200,14 +270,20 @@ func SetupL2VNI(ctx context.Context, params L2VNIParams) error {
 		if err != nil {
 			return fmt.Errorf("could not find peer veth %s in namespace %s: %w", vethNames.NamespaceSide, params.TargetNS, err)
 		}
-		name := bridgeName(params.VNI)
+		name := BridgeName(params.DeviceNamePrefix, params.VNI)
 		bridge, err := netlink.LinkByName(name)
 		if err != nil {
 			return fmt.Errorf("could not find bridge %s in namespace %s: %w", name, params.TargetNS, err)
 		}
-		if err := netlink.LinkSetMaster(peVeth, bridge); err != nil {
+		if err := setMaster(peVeth, bridge); err != nil {
 			return fmt.Errorf("failed to set bridge %s as master of pe veth %s: %w", name, peVeth.Attrs().Name, err)
 		}
+		if err := setBridgeMACAgeingTime(bridge, params.MACAgeingTime); err != nil {
+			return err
+		}
+		if err := setBridgeHostRoutes(bridge, params.AdvertiseHostRoutes); err != nil {
+			return err
+		}
 		if len(params.L2GatewayIPs) > 0 {
 			for _, ip := range params.L2GatewayIPs {
 				if err := assignIPToInterface(bridge, ip); err != nil {
@@ -228,6 +304,39 @@ func SetupL2VNI(ctx context.Context, params L2VNIParams) error {
 	return nil
 }
 
+// setupRouterSVI assigns the router svi address to the bridge of the VNI,
+// removing any other address, so that a changed or removed router svi
+// address does not stay on the bridge.
+func setupRouterSVI(params L3VNIParams) error {
+	ns, err := netns.GetFromPath(params.TargetNS)
+	if err != nil {
+		return fmt.Errorf("failed to get network namespace %s: %w", params.TargetNS, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", params.TargetNS, "error", err)
+		}
+	}()
+
+	return inNamespace(ns, func() error {
+		name := BridgeName(params.DeviceNamePrefix, params.VNI)
+		bridge, err := netlink.LinkByName(name)
+		if err != nil {
+			return fmt.Errorf("could not find bridge %s in namespace %s: %w", name, params.TargetNS, err)
+		}
+		if err := removeOtherAddresses(bridge, params.RouterSVIAddress); err != nil {
+			return fmt.Errorf("failed to remove the stale router svi addresses from bridge %s: %w", name, err)
+		}
+		if params.RouterSVIAddress == "" {
+			return nil
+		}
+		if err := assignIPToInterface(bridge, params.RouterSVIAddress); err != nil {
+			return fmt.Errorf("failed to assign router svi address %s to bridge %s: %w", params.RouterSVIAddress, name, err)
+		}
+		return nil
+	})
+}
+
 // setupVNI sets up the configuration required by FRR to
 // serve a given VNI in the target namespace. This includes:
 // - a linux VRF
@@ -235,8 +344,8 @@ func SetupL2VNI(ctx context.Context, params L2VNIParams) error {
 // - a VXLan interface enslaved to the given VRF
 //
 // Additionally, it creates a veth pair and moves one leg in the target
-// namespace.
-func setupVNI(ctx context.Context, params VNIParams) error {
+// namespace. If withBridgeMAC is set, the vtep mac is set to the bridge too.
+func setupVNI(ctx context.Context, params VNIParams, withBridgeMAC bool) error {
 	slog.DebugContext(ctx, "setting up VNI", "params", params)
 	defer slog.DebugContext(ctx, "end setting up VNI", "params", params)
 	ns, err := netns.GetFromPath(params.TargetNS)
@@ -262,6 +371,11 @@ func setupVNI(ctx context.Context, params VNIParams) error {
 		if err != nil {
 			return err
 		}
+		if withBridgeMAC {
+			if err := setBridgeVTEPMac(bridge, params); err != nil {
+				return err
+			}
+		}
 
 		slog.DebugContext(ctx, "setting up vxlan")
 		err = setupVXLan(params, bridge)
@@ -277,28 +391,60 @@ func setupVNI(ctx context.Context, params VNIParams) error {
 	return nil
 }
 
+// WithdrawFunc is called with the VNIs about to be removed, before
+// any of their devices is deleted. It returns the ones whose routes
+// may still be being withdrawn, whose devices must be kept for now.
+type WithdrawFunc func(vnis []int) []int
+
 // RemoveNonConfiguredVNIs removes from the target namespace the
 // leftovers corresponding to VNIs that are not configured anymore.
-func RemoveNonConfiguredVNIs(targetNS string, params []VNIParams) error {
+// When withdraw is not nil, it is called with the VNIs being removed
+// before touching their devices, and the devices of the VNIs it
+// returns are kept, so that their routes are withdrawn before the
+// traffic towards them is blackholed. Only the devices named with the
+// given device name prefix are considered.
+func RemoveNonConfiguredVNIs(targetNS, prefix string, params []VNIParams, withdraw WithdrawFunc) error {
 	vrfs := map[string]bool{}
 	vnis := map[int]bool{}
 	for _, p := range params {
 		vrfs[p.VRF] = true
 		vnis[p.VNI] = true
 	}
+	if withdraw != nil {
+		removed, err := nonConfiguredVNIs(targetNS, prefix, vnis)
+		if err != nil {
+			return err
+		}
+		if kept := withdraw(removed); len(kept) > 0 {
+			keptVRFs, err := VRFsByVNI(targetNS, prefix)
+			if err != nil {
+				return fmt.Errorf("remove non configured vnis: failed to get the vrfs of the kept vnis: %w", err)
+			}
+			for _, vni := range kept {
+				vnis[vni] = true
+				if vrf, ok := keptVRFs[vni]; ok {
+					vrfs[vrf] = true
+				}
+			}
+		}
+	}
 	hostLinks, err := netlink.LinkList()
 	if err != nil {
 		return fmt.Errorf("remove non configured vnis: failed to list links: %w", err)
 	}
 	failedDeletes := []error{}
-	if err := deleteLinksForType(BridgeLinkType, vnis, hostLinks, vniFromHostBridgeName); err != nil {
+	if err := deleteLinksForType(BridgeLinkType, prefix, vnis, hostLinks, vniFromHostBridgeName); err != nil {
 		failedDeletes = append(failedDeletes, fmt.Errorf("remove bridge links: %w", err))
 	}
 
-	if err := removeOVSBridgesForVNIs(context.Background(), vnis); err != nil {
+	if err := removeOVSBridgesForVNIs(context.Background(), prefix, vnis); err != nil {
 		failedDeletes = append(failedDeletes, fmt.Errorf("remove OVS bridges: %w", err))
 	}
 
+	if err := removeNonConfiguredPolicyRouting(targetNS, vnis); err != nil {
+		failedDeletes = append(failedDeletes, fmt.Errorf("remove policy routing: %w", err))
+	}
+
 	for _, hl := range hostLinks {
 		if hl.Type() != VethLinkType {
 			continue
@@ -335,11 +481,11 @@ func RemoveNonConfiguredVNIs(targetNS string, params []VNIParams) error {
 			return fmt.Errorf("remove non configured vnis: failed to list links: %w", err)
 		}
 
-		if err := deleteLinksForType(VXLanLinkType, vnis, links, vniFromVXLanName); err != nil {
+		if err := deleteLinksForType(VXLanLinkType, prefix, vnis, links, vniFromVXLanName); err != nil {
 			failedDeletes = append(failedDeletes, fmt.Errorf("remove vlan links: %w", err))
 			return err
 		}
-		if err := deleteLinksForType(BridgeLinkType, vnis, links, vniFromBridgeName); err != nil {
+		if err := deleteLinksForType(BridgeLinkType, prefix, vnis, links, vniFromBridgeName); err != nil {
 			failedDeletes = append(failedDeletes, fmt.Errorf("remove bridge links: %w", err))
 			return err
 		}
@@ -363,15 +509,52 @@ func RemoveNonConfiguredVNIs(targetNS string, params []VNIParams) error {
 	return errors.Join(failedDeletes...)
 }
 
+// nonConfiguredVNIs returns, sorted, the VNIs having a vxlan
+// interface in the target namespace but not in the given ones.
+func nonConfiguredVNIs(targetNS, prefix string, vnis map[int]bool) ([]int, error) {
+	ns, err := netns.GetFromPath(targetNS)
+	if err != nil {
+		return nil, fmt.Errorf("nonConfiguredVNIs: Failed to get network namespace %s: %w", targetNS, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", targetNS, "error", err)
+		}
+	}()
+
+	res := []int{}
+	if err := inNamespace(ns, func() error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("nonConfiguredVNIs: failed to list links: %w", err)
+		}
+		for _, l := range links {
+			if l.Type() != netlinkTypeFor(VXLanLinkType) {
+				continue
+			}
+			vni, err := vniFromVXLanName(prefix, l.Attrs().Name)
+			if err != nil || vnis[vni] {
+				continue
+			}
+			res = append(res, vni)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	slices.Sort(res)
+	return res, nil
+}
+
 // deleteLinks deletes all the links of the given type that do not correspond to
 // any VNI.
-func deleteLinksForType(linkType string, vnis map[int]bool, links []netlink.Link, vniFromName func(string) (int, error)) error {
+func deleteLinksForType(linkType, prefix string, vnis map[int]bool, links []netlink.Link, vniFromName func(string, string) (int, error)) error {
 	deleteErrors := []error{}
 	for _, l := range links {
 		if l.Type() != netlinkTypeFor(linkType) {
 			continue
 		}
-		vni, err := vniFromName(l.Attrs().Name)
+		vni, err := vniFromName(prefix, l.Attrs().Name)
 		if errors.As(err, &NotRouterInterfaceError{}) {
 			// not a router interface, skip
 			continue
@@ -402,7 +585,7 @@ func netlinkTypeFor(linkType string) string {
 
 // removeOVSBridgesForVNIs removes auto-created OVS bridges that are not in the configured VNIs list.
 // Only deletes bridges with external_id "created-by: openperouter" (auto-created bridges).
-func removeOVSBridgesForVNIs(ctx context.Context, vnis map[int]bool) error {
+func removeOVSBridgesForVNIs(ctx context.Context, prefix string, vnis map[int]bool) error {
 	ovs, err := NewOVSClient(ctx)
 	if err != nil {
 		// OVS not available, skip cleanup gracefully
@@ -436,7 +619,7 @@ func removeOVSBridgesForVNIs(ctx context.Context, vnis map[int]bool) error {
 			continue // Not auto-created by us, skip
 		}
 
-		vni, err := vniFromHostBridgeName(bridge.Name)
+		vni, err := vniFromHostBridgeName(prefix, bridge.Name)
 		if errors.As(err, &NotRouterInterfaceError{}) {
 			slog.Debug("skipping bridge - not our naming pattern", "name", bridge.Name)
 			continue // Not our bridge naming pattern
@@ -517,7 +700,7 @@ func removeOVSBridgesForVNIs(ctx context.Context, vnis map[int]bool) error {
 	return errors.Join(deleteErrors...)
 }
 
-func hostMaster(vni int, m HostMaster) (netlink.Link, error) {
+func hostMaster(prefix string, vni int, m HostMaster) (netlink.Link, error) {
 	if !m.AutoCreate {
 		hostMaster, err := netlink.LinkByName(m.Name)
 		if err != nil {
@@ -525,7 +708,7 @@ func hostMaster(vni int, m HostMaster) (netlink.Link, error) {
 		}
 		return hostMaster, nil
 	}
-	bridge, err := createHostBridge(vni)
+	bridge, err := createHostBridge(prefix, vni)
 	if err != nil {
 		return nil, fmt.Errorf("getHostMaster: failed to create host bridge %d: %w", vni, err)
 	}
@@ -50,7 +50,7 @@ var _ = Describe("L2 VNI configuration with OVS bridges", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 
 		By("removing the VNI")
-		err = RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{})
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{}, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking the VNI and OVS bridge are removed")
@@ -87,7 +87,7 @@ var _ = Describe("L2 VNI configuration with OVS bridges", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 
 		By("removing the VNI")
-		err = RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{})
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{}, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking the bridge persists (user-managed)")
@@ -124,13 +124,13 @@ var _ = Describe("L2 VNI configuration with OVS bridges", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 
 		By("removing VNI 100, keeping VNI 101")
-		err = RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{params2.VNIParams})
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{params2.VNIParams}, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking VNI 100 removed, VNI 101 persists")
 		Eventually(func(g Gomega) {
 			checkOVSHostBridgeDeleted(g, params1)
-			checkOVSBridgeExists(g, hostBridgeName(params2.VNI))
+			checkOVSBridgeExists(g, hostBridgeName(params2.DeviceNamePrefix, params2.VNI))
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
@@ -155,6 +155,25 @@ var _ = Describe("L2 VNI configuration with OVS bridges", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
+	It("should set the datapath type of the auto-created OVS bridge", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF: "testred", TargetNS: testNSPath(),
+				VTEPIP: "192.170.0.9/32", VNI: 100, VXLanPort: 4789,
+			},
+			HostMaster: &HostMaster{Type: OVSBridgeLinkType, AutoCreate: true, DatapathType: "netdev"},
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			bridge, err := getOVSBridge(hostBridgeName(params.DeviceNamePrefix, params.VNI))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(bridge.DatapathType).To(Equal("netdev"))
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
 	It("should configure L2 gateway IP with OVS bridge", func() {
 		gwIP := "10.10.100.1/24"
 		params := L2VNIParams{
@@ -191,7 +210,7 @@ func checkOVSHostBridgeDeleted(g Gomega, params L2VNIParams) {
 	g.Expect(params.HostMaster.Type).To(Equal(OVSBridgeLinkType))
 	g.Expect(params.HostMaster.AutoCreate).To(BeTrue())
 
-	hostBridge := hostBridgeName(params.VNI)
+	hostBridge := hostBridgeName(params.DeviceNamePrefix, params.VNI)
 	checkOVSBridgeDeleted(g, hostBridge)
 }
 
@@ -226,7 +245,7 @@ func createOVSBridge(name string) error {
 		return err
 	}
 
-	bridgeUUID, err := EnsureBridge(ctx, ovs, name)
+	bridgeUUID, err := EnsureBridge(ctx, ovs, name, "")
 	if err != nil {
 		return err
 	}
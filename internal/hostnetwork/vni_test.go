@@ -15,6 +15,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+	"k8s.io/utils/ptr"
 )
 
 const testNSName = "vnitestns"
@@ -121,6 +123,35 @@ var _ = Describe("L3 VNI configuration", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 	})
 
+	It("should set the vtep mac to the vxlan and to the bridge", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VTEPMAC:   "02:00:00:00:00:09",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostVeth: &Veth{
+				HostIPv4: "192.168.9.1/32",
+				NSIPv4:   "192.168.9.0/32",
+			},
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validateL3HostLeg(g, params)
+
+			_ = inNamespace(testNS, func() error {
+				validateL3VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
 	It("should work with multiple L3VNIs + cleanup", func() {
 		params := []L3VNIParams{
 			{
@@ -166,8 +197,24 @@ var _ = Describe("L3 VNI configuration", func() {
 		remaining := params[0]
 		toDelete := params[1]
 
+		By("keeping the non configured L3VNIs while they are withdrawn")
+		withdrawn := []int{}
+		err := RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{remaining.VNIParams}, func(vnis []int) []int {
+			withdrawn = append(withdrawn, vnis...)
+			return vnis
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(withdrawn).To(Equal([]int{toDelete.VNI}))
+		validateL3HostLeg(Default, toDelete)
+		_ = inNamespace(testNS, func() error {
+			validateL3VNI(Default, toDelete)
+			return nil
+		})
+
 		By("removing non configured L3VNIs")
-		err := RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{remaining.VNIParams})
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{remaining.VNIParams}, func([]int) []int {
+			return nil
+		})
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking remaining L3VNIs")
@@ -222,6 +269,239 @@ var _ = Describe("L3 VNI configuration", func() {
 
 	})
 
+	It("should not change anything when applied twice", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostVeth: &Veth{
+				HostIPv4: "192.168.9.1/32",
+				NSIPv4:   "192.168.9.0/32",
+				HostIPv6: "2001:db8::1/128",
+				NSIPv6:   "2001:db8::/128",
+			},
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validateL3HostLeg(g, params)
+
+			_ = inNamespace(testNS, func() error {
+				validateL3VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		expectNoNetlinkChanges(testNS, func() {
+			err = SetupL3VNI(context.Background(), params)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	It("should move the vni to a new vrf before removing the old one", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostVeth: &Veth{
+				HostIPv4: "192.168.9.1/32",
+				NSIPv4:   "192.168.9.0/32",
+			},
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("setting up the vni in the new vrf, keeping the old one")
+		old := params.VNIParams
+		params.VRF = "testblue"
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{params.VNIParams, old}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		err = SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		vrfs, err := VRFsByVNI(testNSPath(), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vrfs).To(Equal(map[int]string{100: "testblue"}))
+
+		By("removing the old vrf")
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{params.VNIParams}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validateL3HostLeg(g, params)
+
+			_ = inNamespace(testNS, func() error {
+				validateL3VNI(g, params)
+				checkLinkdeleted(g, "testred")
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should set the tos of the vxlan from the dscp", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+				DSCP:      46,
+			},
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				vxlan, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(vxlan.(*netlink.Vxlan).TOS).To(Equal(0xb8))
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("changing the dscp")
+		params.DSCP = 10
+		err = SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				vxlan, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(vxlan.(*netlink.Vxlan).TOS).To(Equal(0x28))
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should set the checksum and the offloads of the vxlan", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:        "testred",
+				TargetNS:   testNSPath(),
+				VTEPIP:     "192.170.0.9/32",
+				VNI:        100,
+				VXLanPort:  4789,
+				TXChecksum: ptr.To(true),
+				GRO:        ptr.To(false),
+				GSO:        ptr.To(false),
+			},
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateVXLanChecksumAndOffloads(g, params.VNIParams)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("disabling the checksum and enabling the offloads")
+		params.TXChecksum = ptr.To(false)
+		params.GRO = ptr.To(true)
+		params.GSO = ptr.To(true)
+		err = SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateVXLanChecksumAndOffloads(g, params.VNIParams)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should assign the router svi address to the bridge", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostVeth: &Veth{
+				HostIPv4: "192.168.9.1/32",
+				NSIPv4:   "192.168.9.0/32",
+			},
+			RouterSVIAddress: "10.250.0.1/32",
+		}
+
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL3VNI(g, params)
+				bridge, err := netlink.LinkByName(BridgeName(params.DeviceNamePrefix, params.VNI))
+				g.Expect(err).NotTo(HaveOccurred())
+				hasIP, err := interfaceHasIP(bridge, params.RouterSVIAddress)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(hasIP).To(BeTrue(), "bridge does not have the router svi address", params.RouterSVIAddress)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should replace and remove the router svi address of the bridge", func() {
+		params := L3VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			RouterSVIAddress: "10.250.0.1/32",
+		}
+		err := SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		checkBridgeAddresses := func(expected ...string) {
+			Eventually(func(g Gomega) {
+				_ = inNamespace(testNS, func() error {
+					bridge, err := netlink.LinkByName(BridgeName(params.DeviceNamePrefix, params.VNI))
+					g.Expect(err).NotTo(HaveOccurred())
+					addresses, err := netlink.AddrList(bridge, netlink.FAMILY_ALL)
+					g.Expect(err).NotTo(HaveOccurred())
+					got := []string{}
+					for _, a := range addresses {
+						if !a.IP.IsLinkLocalUnicast() {
+							got = append(got, a.IPNet.String())
+						}
+					}
+					g.Expect(got).To(ConsistOf(expected))
+					return nil
+				})
+			}, 30*time.Second, 1*time.Second).Should(Succeed())
+		}
+		checkBridgeAddresses("10.250.0.1/32")
+
+		By("changing the router svi address")
+		params.RouterSVIAddress = "10.250.0.2/32"
+		err = SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		checkBridgeAddresses("10.250.0.2/32")
+
+		By("removing the router svi address")
+		params.RouterSVIAddress = ""
+		err = SetupL3VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		checkBridgeAddresses()
+	})
+
 	It("should configure VXLAN and VRF when HostVeth is nil", func() {
 		params := L3VNIParams{
 			VNIParams: VNIParams{
@@ -294,7 +574,7 @@ var _ = Describe("L2 VNI configuration", func() {
 		}, 30*time.Second, 1*time.Second).Should(Succeed())
 
 		By("removing the VNI")
-		err = RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{})
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{}, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking the VNI is removed")
@@ -311,6 +591,271 @@ var _ = Describe("L2 VNI configuration", func() {
 
 	})
 
+	It("should manage policy routing for the L2VNI subnets", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			L2GatewayIPs: []string{"192.168.1.1/24", "2001:db8::1/64"},
+			HostMaster: &HostMaster{
+				Type:       BridgeLinkType,
+				AutoCreate: true,
+			},
+			ManagePolicyRouting: true,
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			validatePolicyRouting(g, params)
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("disabling policy routing")
+		params.ManagePolicyRouting = false
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			checkPolicyRoutingRemoved(g, params.VNI)
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("enabling it again and removing the VNI")
+		params.ManagePolicyRouting = true
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		err = RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			checkPolicyRoutingRemoved(g, params.VNI)
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should manage policy routing in the pods attached to the host master", func() {
+		const podNSName = "vnitestpodns"
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			L2GatewayIPs: []string{"192.168.1.1/24"},
+			HostMaster: &HostMaster{
+				Type:       BridgeLinkType,
+				AutoCreate: true,
+			},
+			ManagePolicyRouting: true,
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("attaching a pod to the host master via a macvlan")
+		cleanTest(podNSName)
+		podNS := createTestNS(podNSName)
+		DeferCleanup(func() {
+			cleanTest(podNSName)
+		})
+		master, err := netlink.LinkByName(hostBridgeName("", params.VNI))
+		Expect(err).NotTo(HaveOccurred())
+		macvlan := &netlink.Macvlan{
+			LinkAttrs: netlink.LinkAttrs{
+				Name:        "testpodnet1",
+				ParentIndex: master.Attrs().Index,
+				Namespace:   netlink.NsFd(podNS),
+			},
+			Mode: netlink.MACVLAN_MODE_BRIDGE,
+		}
+		Expect(netlink.LinkAdd(macvlan)).To(Succeed())
+		err = inNamespace(podNS, func() error {
+			link, err := netlink.LinkByName(macvlan.Name)
+			if err != nil {
+				return err
+			}
+			return netlink.LinkSetUp(link)
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(podNS, func() error {
+				validatePolicyRouting(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("disabling policy routing")
+		params.ManagePolicyRouting = false
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(podNS, func() error {
+				checkPolicyRoutingRemoved(g, params.VNI)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should toggle the vxlan learning mode", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:            "testred",
+				TargetNS:       testNSPath(),
+				VTEPIP:         "192.170.0.9/32",
+				VNI:            100,
+				VXLanPort:      4789,
+				Learning:       true,
+				MulticastGroup: "239.1.1.100",
+			},
+			HostMaster: &HostMaster{
+				Name: bridgeName,
+				Type: BridgeLinkType,
+			},
+		}
+		underlayIndex := setupUnderlayInterface(testNS)
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				vxlan, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(vxlan.(*netlink.Vxlan).VtepDevIndex).To(Equal(underlayIndex), "vxlan not bound to the underlay interface")
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("disabling learning")
+		params.Learning = false
+		params.MulticastGroup = ""
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should program the flooding entries of the static vteps", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:         "testred",
+				TargetNS:    testNSPath(),
+				VTEPIP:      "192.170.0.9/32",
+				VNI:         100,
+				VXLanPort:   4789,
+				Learning:    true,
+				StaticVTEPs: []string{"192.170.0.10", "192.170.0.11"},
+			},
+			HostMaster: &HostMaster{
+				Name: bridgeName,
+				Type: BridgeLinkType,
+			},
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				validateStaticVTEPs(g, params.VNIParams)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("changing the static vteps")
+		params.StaticVTEPs = []string{"192.170.0.11", "192.170.0.12"}
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				validateStaticVTEPs(g, params.VNIParams)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should set the mac ageing time of the bridge", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostMaster: &HostMaster{
+				Name: bridgeName,
+				Type: BridgeLinkType,
+			},
+			MACAgeingTime: 30 * time.Minute,
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("restoring the default ageing time")
+		params.MACAgeingTime = 0
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
+	It("should make the bridge learn the hosts to advertise host routes", func() {
+		params := L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			L2GatewayIPs:        []string{"192.168.1.0/24"},
+			AdvertiseHostRoutes: true,
+		}
+
+		err := SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+		By("disabling the host routes")
+		params.AdvertiseHostRoutes = false
+		err = SetupL2VNI(context.Background(), params)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_ = inNamespace(testNS, func() error {
+				validateL2VNI(g, params)
+				return nil
+			})
+		}, 30*time.Second, 1*time.Second).Should(Succeed())
+	})
+
 	It("should work with multiple L2VNIs + cleanup", func() {
 		params := []L2VNIParams{
 			{
@@ -359,7 +904,7 @@ var _ = Describe("L2 VNI configuration", func() {
 		toDelete := params[1]
 
 		By("removing non configured L2VNIs")
-		err := RemoveNonConfiguredVNIs(testNSPath(), []VNIParams{remaining.VNIParams})
+		err := RemoveNonConfiguredVNIs(testNSPath(), "", []VNIParams{remaining.VNIParams}, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		By("checking remaining L2VNIs")
@@ -445,6 +990,70 @@ var _ = Describe("L2 VNI configuration", func() {
 			},
 		}),
 	)
+
+	DescribeTable("should not change anything when applied twice",
+		func(params L2VNIParams) {
+			err := SetupL2VNI(context.Background(), params)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega) {
+				validateL2HostLeg(g, params)
+
+				_ = inNamespace(testNS, func() error {
+					validateL2VNI(g, params)
+					return nil
+				})
+			}, 30*time.Second, 1*time.Second).Should(Succeed())
+
+			expectNoNetlinkChanges(testNS, func() {
+				err = SetupL2VNI(context.Background(), params)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		},
+		Entry("without gateway", L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testred",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.9/32",
+				VNI:       100,
+				VXLanPort: 4789,
+			},
+			HostMaster: &HostMaster{
+				Name: bridgeName,
+				Type: BridgeLinkType,
+			},
+		}),
+		Entry("with dual-stack gateway", L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testgreen",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.11/32",
+				VNI:       300,
+				VXLanPort: 4789,
+			},
+			L2GatewayIPs: []string{"192.168.2.0/24", "2001:db8::1/64"},
+			HostMaster: &HostMaster{
+				Name: bridgeName,
+				Type: BridgeLinkType,
+			},
+			MACAgeingTime: 10 * time.Minute,
+		}),
+		Entry("with policy routing on an autocreated bridge", L2VNIParams{
+			VNIParams: VNIParams{
+				VRF:       "testblue",
+				TargetNS:  testNSPath(),
+				VTEPIP:    "192.170.0.12/32",
+				VNI:       400,
+				VXLanPort: 4789,
+			},
+			L2GatewayIPs: []string{"192.168.3.0/24", "2001:db8:1::1/64"},
+			HostMaster: &HostMaster{
+				Type:       BridgeLinkType,
+				AutoCreate: true,
+			},
+			ManagePolicyRouting: true,
+		}),
+	)
 })
 
 func validateL3HostLeg(g Gomega, params L3VNIParams) {
@@ -483,14 +1092,14 @@ func validateL2HostLeg(g Gomega, params L2VNIParams) {
 		case OVSBridgeLinkType:
 			hostMasterName := params.HostMaster.Name
 			if params.HostMaster.AutoCreate {
-				hostMasterName = hostBridgeName(params.VNI)
+				hostMasterName = hostBridgeName(params.DeviceNamePrefix, params.VNI)
 			}
 			checkOVSBridgeExists(g, hostMasterName)
 			checkVethAttachedToOVSBridge(g, hostMasterName, vethNames.HostSide)
 		case BridgeLinkType:
 			hostMasterName := params.HostMaster.Name
 			if params.HostMaster.AutoCreate {
-				hostMasterName = hostBridgeName(params.VNI)
+				hostMasterName = hostBridgeName(params.DeviceNamePrefix, params.VNI)
 			}
 			hostmaster, err := netlink.LinkByName(hostMasterName)
 			g.Expect(err).NotTo(HaveOccurred(), "host master not found", *params.HostMaster)
@@ -507,6 +1116,12 @@ func validateL2HostLeg(g Gomega, params L2VNIParams) {
 func validateL3VNI(g Gomega, params L3VNIParams) {
 	validateVNI(g, params.VNIParams)
 
+	if params.VTEPMAC != "" {
+		bridgeLink, err := netlink.LinkByName(BridgeName(params.DeviceNamePrefix, params.VNI))
+		g.Expect(err).NotTo(HaveOccurred(), "bridge not found", BridgeName(params.DeviceNamePrefix, params.VNI))
+		g.Expect(bridgeLink.Attrs().HardwareAddr.String()).To(Equal(params.VTEPMAC), "bridge mac is not the vtep mac")
+	}
+
 	if params.HostVeth == nil {
 		return
 	}
@@ -549,9 +1164,11 @@ func validateL2VNI(g Gomega, params L2VNIParams) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(hasNoIP).To(BeTrue(), "host leg does have ip")
 
-	bridgeLink, err := netlink.LinkByName(bridgeName(params.VNI))
-	g.Expect(err).NotTo(HaveOccurred(), "bridge not found", bridgeName(params.VNI))
+	bridgeLink, err := netlink.LinkByName(BridgeName(params.DeviceNamePrefix, params.VNI))
+	g.Expect(err).NotTo(HaveOccurred(), "bridge not found", BridgeName(params.DeviceNamePrefix, params.VNI))
 	g.Expect(peLegLink.Attrs().MasterIndex).To(Equal(bridgeLink.Attrs().Index))
+	validateBridgeMACAgeingTime(g, bridgeLink, params.MACAgeingTime)
+	validateBridgeHostRoutes(g, bridgeLink, params.AdvertiseHostRoutes)
 	if len(params.L2GatewayIPs) > 0 {
 		for _, ip := range params.L2GatewayIPs {
 			hasIP, err := interfaceHasIP(bridgeLink, ip)
@@ -569,11 +1186,11 @@ func validateL2VNI(g Gomega, params L2VNIParams) {
 }
 
 func validateVNI(g Gomega, params VNIParams) {
-	loopback, err := netlink.LinkByName(UnderlayLoopback)
-	g.Expect(err).NotTo(HaveOccurred(), "loopback not found", UnderlayLoopback)
+	vtepDev, err := vtepDevice(params)
+	g.Expect(err).NotTo(HaveOccurred(), "vtep device not found")
 
-	vxlanLink, err := netlink.LinkByName(vxLanNameFromVNI(params.VNI))
-	g.Expect(err).NotTo(HaveOccurred(), "vxlan link not found", vxLanNameFromVNI(params.VNI))
+	vxlanLink, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+	g.Expect(err).NotTo(HaveOccurred(), "vxlan link not found", vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
 
 	vxlan := vxlanLink.(*netlink.Vxlan)
 	g.Expect(vxlan.OperState).To(BeEquivalentTo(netlink.OperUnknown))
@@ -587,8 +1204,8 @@ func validateVNI(g Gomega, params VNIParams) {
 	vrf := vrfLink.(*netlink.Vrf)
 	g.Expect(vrf.OperState).To(BeEquivalentTo(netlink.OperUp))
 
-	bridgeLink, err := netlink.LinkByName(bridgeName(params.VNI))
-	g.Expect(err).NotTo(HaveOccurred(), "bridge not found", bridgeName(params.VNI))
+	bridgeLink, err := netlink.LinkByName(BridgeName(params.DeviceNamePrefix, params.VNI))
+	g.Expect(err).NotTo(HaveOccurred(), "bridge not found", BridgeName(params.DeviceNamePrefix, params.VNI))
 
 	bridge := bridgeLink.(*netlink.Bridge)
 	g.Expect(bridge.OperState).To(BeEquivalentTo(netlink.OperUp))
@@ -598,8 +1215,47 @@ func validateVNI(g Gomega, params VNIParams) {
 	addrGenModeNone = checkAddrGenModeNone(bridge)
 	g.Expect(addrGenModeNone).To(BeTrue())
 
-	err = checkVXLanConfigured(vxlan, bridge.Index, loopback.Attrs().Index, params)
+	disableIPv6, err := os.ReadFile(fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/disable_ipv6", bridge.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(strings.TrimSpace(string(disableIPv6))).To(Equal("0"), "ipv6 disabled on bridge", bridge.Name)
+
+	err = checkVXLanConfigured(vxlan, bridge.Index, vtepDev.Attrs().Index, params)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	if params.VTEPMAC != "" {
+		g.Expect(vxlan.HardwareAddr.String()).To(Equal(params.VTEPMAC), "vxlan mac is not the vtep mac")
+	}
+}
+
+func validateStaticVTEPs(g Gomega, params VNIParams) {
+	vxlan, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+	g.Expect(err).NotTo(HaveOccurred(), "vxlan link not found", vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+
+	entries, err := netlink.NeighList(vxlan.Attrs().Index, unix.AF_BRIDGE)
 	g.Expect(err).NotTo(HaveOccurred())
+	vteps := []string{}
+	for _, e := range entries {
+		if e.LinkIndex == vxlan.Attrs().Index && e.HardwareAddr.String() == "00:00:00:00:00:00" && e.IP != nil {
+			vteps = append(vteps, e.IP.String())
+		}
+	}
+	g.Expect(vteps).To(ConsistOf(params.StaticVTEPs))
+}
+
+func validateVXLanChecksumAndOffloads(g Gomega, params VNIParams) {
+	vxlanLink, err := netlink.LinkByName(vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+	g.Expect(err).NotTo(HaveOccurred(), "vxlan link not found", vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
+
+	vxlan := vxlanLink.(*netlink.Vxlan)
+	g.Expect(vxlan.UDPCSum).To(Equal(*params.TXChecksum))
+	g.Expect(vxlan.UDP6ZeroCSumTx).To(Equal(!*params.TXChecksum))
+
+	gro, err := offloadEnabled(vxlan.Name, unix.ETHTOOL_GGRO)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gro).To(Equal(*params.GRO))
+	gso, err := offloadEnabled(vxlan.Name, unix.ETHTOOL_GGSO)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gso).To(Equal(*params.GSO))
 }
 
 func validateVethForVNI(g Gomega, params VNIParams) {
@@ -613,7 +1269,7 @@ func checkHostBridgedeleted(g Gomega, params L2VNIParams) {
 	g.Expect(params.HostMaster).ToNot(BeNil())
 	g.Expect(params.HostMaster.AutoCreate).To(BeTrue())
 
-	hostBridge := hostBridgeName(params.VNI)
+	hostBridge := hostBridgeName(params.DeviceNamePrefix, params.VNI)
 	_, err := netlink.LinkByName(hostBridge)
 	g.Expect(errors.As(err, &netlink.LinkNotFoundError{})).To(BeTrue(), "host bridge not deleted", hostBridge, err)
 }
@@ -629,9 +1285,9 @@ func checkLinkExists(g Gomega, name string) {
 }
 
 func validateVNIIsNotConfigured(g Gomega, params VNIParams) {
-	checkLinkdeleted(g, vxLanNameFromVNI(params.VNI))
+	checkLinkdeleted(g, vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI))
 	checkLinkdeleted(g, params.VRF)
-	checkLinkdeleted(g, bridgeName(params.VNI))
+	checkLinkdeleted(g, BridgeName(params.DeviceNamePrefix, params.VNI))
 
 	vethNames := vethNamesFromVNI(params.VNI)
 	checkLinkdeleted(g, vethNames.NamespaceSide)
@@ -657,6 +1313,26 @@ func setupLoopback(ns netns.NsHandle) {
 	})
 }
 
+// setupUnderlayInterface creates in the given namespace an interface marked
+// as the underlay one, and returns its index.
+func setupUnderlayInterface(ns netns.NsHandle) int {
+	index := 0
+	_ = inNamespace(ns, func() error {
+		underlay := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "testunderlay"}}
+		err := netlink.LinkAdd(underlay)
+		Expect(err).NotTo(HaveOccurred(), "failed to create the underlay interface")
+		link, err := netlink.LinkByName("testunderlay")
+		Expect(err).NotTo(HaveOccurred())
+		err = assignIPToInterface(link, underlayInterfaceSpecialAddr)
+		Expect(err).NotTo(HaveOccurred())
+		err = netlink.LinkSetUp(link)
+		Expect(err).NotTo(HaveOccurred())
+		index = link.Attrs().Index
+		return nil
+	})
+	return index
+}
+
 func createLinuxBridge(name string) {
 	_, err := netlink.LinkByName(name)
 	if errors.As(err, &netlink.LinkNotFoundError{}) {
@@ -683,3 +1359,56 @@ func validateBridgeMacAddress(g Gomega, bridge netlink.Link, vni int) {
 	g.Expect(actualMac).NotTo(BeNil(), "bridge should have a MAC address")
 	g.Expect(actualMac).To(Equal(expectedMac), "bridge MAC address should be %v for VNI %d", expectedMac, vni)
 }
+
+func validateBridgeMACAgeingTime(g Gomega, bridgeLink netlink.Link, ageingTime time.Duration) {
+	if ageingTime == 0 {
+		ageingTime = defaultMACAgeingTime
+	}
+	bridge, ok := bridgeLink.(*netlink.Bridge)
+	g.Expect(ok).To(BeTrue(), "link is not a bridge", bridgeLink.Attrs().Name)
+	g.Expect(bridge.AgeingTime).NotTo(BeNil())
+	g.Expect(*bridge.AgeingTime).To(BeEquivalentTo(ageingTime.Milliseconds()/10), "unexpected ageing time for", bridge.Name)
+}
+
+func validateBridgeHostRoutes(g Gomega, bridgeLink netlink.Link, enabled bool) {
+	want := "0"
+	if enabled {
+		want = "1"
+	}
+	arpAccept, err := os.ReadFile(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/arp_accept", bridgeLink.Attrs().Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(strings.TrimSpace(string(arpAccept))).To(Equal(want), "unexpected arp_accept for", bridgeLink.Attrs().Name)
+}
+
+func validatePolicyRouting(g Gomega, params L2VNIParams) {
+	table := policyRoutingTable(params.VNI)
+	rules, err := netlink.RuleListFiltered(netlink.FAMILY_ALL, &netlink.Rule{Table: table}, netlink.RT_FILTER_TABLE)
+	g.Expect(err).NotTo(HaveOccurred())
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for _, ip := range params.L2GatewayIPs {
+		gateway, subnet, err := net.ParseCIDR(ip)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(hasRuleFrom(rules, subnet)).To(BeTrue(), "missing rule from %s in table %d", subnet, table)
+
+		foundDefault := false
+		for _, r := range routes {
+			if r.Gw != nil && r.Gw.Equal(gateway) {
+				foundDefault = true
+			}
+		}
+		g.Expect(foundDefault).To(BeTrue(), "missing default route via %s in table %d", gateway, table)
+	}
+}
+
+func checkPolicyRoutingRemoved(g Gomega, vni int) {
+	table := policyRoutingTable(vni)
+	rules, err := netlink.RuleListFiltered(netlink.FAMILY_ALL, &netlink.Rule{Table: table}, netlink.RT_FILTER_TABLE)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rules).To(BeEmpty())
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(routes).To(BeEmpty())
+}
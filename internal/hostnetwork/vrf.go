@@ -5,9 +5,11 @@ package hostnetwork
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
 )
 
 // setupVRF creates a new VRF and sets it up.
@@ -86,3 +88,48 @@ func findFreeRoutingTableID() (uint32, error) {
 	}
 	return 0, fmt.Errorf("findFreeRoutingTableID: Failed to find an available routing id")
 }
+
+// VRFsByVNI returns, for each VNI set up in the target namespace,
+// the name of the vrf its bridge is enslaved to. Only the bridges named
+// with the given device name prefix are considered.
+func VRFsByVNI(targetNS, prefix string) (map[int]string, error) {
+	ns, err := netns.GetFromPath(targetNS)
+	if err != nil {
+		return nil, fmt.Errorf("VRFsByVNI: Failed to get network namespace %s: %w", targetNS, err)
+	}
+	defer func() {
+		if err := ns.Close(); err != nil {
+			slog.Error("failed to close namespace", "namespace", targetNS, "error", err)
+		}
+	}()
+
+	res := map[int]string{}
+	if err := inNamespace(ns, func() error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("VRFsByVNI: failed to list links: %w", err)
+		}
+		vrfs := map[int]string{}
+		for _, l := range links {
+			if l.Type() == VRFLinkType {
+				vrfs[l.Attrs().Index] = l.Attrs().Name
+			}
+		}
+		for _, l := range links {
+			if l.Type() != netlinkTypeFor(BridgeLinkType) {
+				continue
+			}
+			vni, err := vniFromBridgeName(prefix, l.Attrs().Name)
+			if err != nil {
+				continue
+			}
+			if vrf, ok := vrfs[l.Attrs().MasterIndex]; ok {
+				res[vni] = vrf
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
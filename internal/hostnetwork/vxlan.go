@@ -3,6 +3,7 @@
 package hostnetwork
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 // setupVXLan sets up a vxlan interface corresponding to the provided
@@ -20,23 +22,90 @@ func setupVXLan(params VNIParams, bridge *netlink.Bridge) error {
 		return err
 	}
 
+	if err := setOffloads(vxlan.Name, params.GRO, params.GSO); err != nil {
+		return err
+	}
+
 	if err := setAddrGenModeNone(vxlan); err != nil {
 		return fmt.Errorf("failed to set addr_gen_mode to 1 for %s: %w", vxlan.Name, err)
 	}
-	if err := setNeighSuppression(vxlan); err != nil {
-		return fmt.Errorf("failed to set neigh suppression for %s: %w", vxlan.Name, err)
+	if !params.Learning {
+		if err := setNeighSuppression(vxlan); err != nil {
+			return fmt.Errorf("failed to set neigh suppression for %s: %w", vxlan.Name, err)
+		}
 	}
 
 	if err = netlink.LinkSetUp(vxlan); err != nil {
 		return fmt.Errorf("could not set link up for vxlan %s: %v", vxlan.Name, err)
 	}
 
+	// in EVPN mode the flooding entries are programmed by FRR.
+	if params.Learning {
+		if err := setupStaticVTEPs(vxlan, params); err != nil {
+			return fmt.Errorf("failed to set static vteps for %s: %w", vxlan.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// floodMAC is the all zeros MAC of the fdb entries used to flood
+// the BUM traffic of a vxlan interface to the remote vteps.
+var floodMAC = net.HardwareAddr{0, 0, 0, 0, 0, 0}
+
+// setupStaticVTEPs makes the flooding fdb entries of the given vxlan
+// match the static vteps of the params, as done by
+// `bridge fdb append 00:00:00:00:00:00 dev <vxlan> dst <vtep>`.
+// The entry of the multicast group, added by the kernel, is preserved.
+func setupStaticVTEPs(vxlan *netlink.Vxlan, params VNIParams) error {
+	toAdd := map[string]net.IP{}
+	for _, v := range params.StaticVTEPs {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return fmt.Errorf("failed to parse static vtep %s", v)
+		}
+		toAdd[ip.String()] = ip
+	}
+
+	entries, err := netlink.NeighList(vxlan.Index, unix.AF_BRIDGE)
+	if err != nil {
+		return fmt.Errorf("failed to list fdb entries: %w", err)
+	}
+	for _, e := range entries {
+		if e.LinkIndex != vxlan.Index || !bytes.Equal(e.HardwareAddr, floodMAC) || e.IP == nil {
+			continue
+		}
+		if _, ok := toAdd[e.IP.String()]; ok {
+			delete(toAdd, e.IP.String())
+			continue
+		}
+		if params.MulticastGroup != "" && e.IP.Equal(net.ParseIP(params.MulticastGroup)) {
+			continue
+		}
+		if err := netlink.NeighDel(&e); err != nil {
+			return fmt.Errorf("failed to delete fdb entry for %s: %w", e.IP, err)
+		}
+	}
+
+	for _, ip := range toAdd {
+		err := netlink.NeighAppend(&netlink.Neigh{
+			LinkIndex:    vxlan.Index,
+			Family:       unix.AF_BRIDGE,
+			State:        netlink.NUD_NOARP | netlink.NUD_PERMANENT,
+			Flags:        netlink.NTF_SELF,
+			IP:           ip,
+			HardwareAddr: floodMAC,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add fdb entry for %s: %w", ip, err)
+		}
+	}
 	return nil
 }
 
 // checkVXLanConfigured checks if the given VXLan has the required properties
 // passed as parameters.
-func checkVXLanConfigured(vxLan *netlink.Vxlan, bridgeIndex, loopbackIndex int, params VNIParams) error {
+func checkVXLanConfigured(vxLan *netlink.Vxlan, bridgeIndex, vtepDevIndex int, params VNIParams) error {
 	if vxLan.MasterIndex != bridgeIndex {
 		return fmt.Errorf("master index is not bridge index: %d, %d", vxLan.MasterIndex, bridgeIndex)
 	}
@@ -49,8 +118,32 @@ func checkVXLanConfigured(vxLan *netlink.Vxlan, bridgeIndex, loopbackIndex int,
 		return fmt.Errorf("port is not one coming from params: %d, %d", vxLan.Port, params.VXLanPort)
 	}
 
-	if vxLan.Learning {
-		return fmt.Errorf("learning is enabled")
+	if vxLan.Learning != params.Learning {
+		return fmt.Errorf("learning is not the one coming from params: %t, %t", vxLan.Learning, params.Learning)
+	}
+
+	group, err := multicastGroup(params)
+	if err != nil {
+		return err
+	}
+	if !vxLan.Group.Equal(group) {
+		return fmt.Errorf("group is not the one coming from params: %v, %v", vxLan.Group, params.MulticastGroup)
+	}
+
+	if vxLan.TOS != tosFromDSCP(params.DSCP) {
+		return fmt.Errorf("tos is not the one coming from params: %d, %d", vxLan.TOS, tosFromDSCP(params.DSCP))
+	}
+
+	if params.TXChecksum != nil && vxLan.UDPCSum != *params.TXChecksum {
+		return fmt.Errorf("udp checksum is not the one coming from params: %t, %t", vxLan.UDPCSum, *params.TXChecksum)
+	}
+
+	mac, err := vtepMAC(params)
+	if err != nil {
+		return err
+	}
+	if mac != nil && !bytes.Equal(vxLan.HardwareAddr, mac) {
+		return fmt.Errorf("mac is not the one coming from params: %v, %v", vxLan.HardwareAddr, params.VTEPMAC)
 	}
 
 	vtepIP, _, err := net.ParseCIDR(params.VTEPIP)
@@ -61,34 +154,51 @@ func checkVXLanConfigured(vxLan *netlink.Vxlan, bridgeIndex, loopbackIndex int,
 		return fmt.Errorf("src addr is not one coming from params: %v, %v", vxLan.SrcAddr, params.VTEPIP)
 	}
 
-	if vxLan.VtepDevIndex != loopbackIndex {
-		return fmt.Errorf("vtep dev index is not loopback index: %d %d", vxLan.VtepDevIndex, loopbackIndex)
+	if vxLan.VtepDevIndex != vtepDevIndex {
+		return fmt.Errorf("vtep dev index is not the expected one: %d %d", vxLan.VtepDevIndex, vtepDevIndex)
 	}
 	return nil
 }
 
 func createVXLan(params VNIParams, bridge *netlink.Bridge) (*netlink.Vxlan, error) {
-	loopback, err := netlink.LinkByName(UnderlayLoopback)
+	vtepDev, err := vtepDevice(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get loopback by name: %w", err)
+		return nil, err
 	}
 
-	vxlanName := vxLanNameFromVNI(params.VNI)
+	vxlanName := vxLanNameFromVNI(params.DeviceNamePrefix, params.VNI)
 
 	vtepIP, _, err := net.ParseCIDR(params.VTEPIP)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse vtep ip %v: %w", params.VTEPIP, err)
 	}
 
+	group, err := multicastGroup(params)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := vtepMAC(params)
+	if err != nil {
+		return nil, err
+	}
+
 	toCreate := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{
-		Name:        vxlanName,
-		MasterIndex: bridge.Index,
+		Name:         vxlanName,
+		MasterIndex:  bridge.Index,
+		HardwareAddr: mac,
 	},
 		VxlanId:      params.VNI,
 		Port:         params.VXLanPort,
-		Learning:     false,
+		Learning:     params.Learning,
+		Group:        group,
+		TOS:          tosFromDSCP(params.DSCP),
 		SrcAddr:      vtepIP,
-		VtepDevIndex: loopback.Attrs().Index,
+		VtepDevIndex: vtepDev.Attrs().Index,
+	}
+	if params.TXChecksum != nil {
+		toCreate.UDPCSum = *params.TXChecksum
+		toCreate.UDP6ZeroCSumTx = !*params.TXChecksum
 	}
 
 	link, err := netlink.LinkByName(vxlanName)
@@ -102,7 +212,7 @@ func createVXLan(params VNIParams, bridge *netlink.Bridge) (*netlink.Vxlan, erro
 		return nil, fmt.Errorf("failed to get vxlan link by name %s: %w", vxlanName, err)
 	}
 	vxlan, ok := link.(*netlink.Vxlan)
-	if ok && checkVXLanConfigured(vxlan, bridge.Index, loopback.Attrs().Index, params) == nil {
+	if ok && checkVXLanConfigured(vxlan, bridge.Index, vtepDev.Attrs().Index, params) == nil {
 		return vxlan, nil
 	}
 	if err := netlink.LinkDel(link); err != nil {
@@ -115,17 +225,69 @@ func createVXLan(params VNIParams, bridge *netlink.Bridge) (*netlink.Vxlan, erro
 	return toCreate, nil
 }
 
+// vtepDevice returns the device the vxlan interface of the given vni is
+// bound to. It is the loopback holding the vtep ip, unless the interface
+// floods to a multicast group: the group must be joined on the underlay
+// interface, where the multicast traffic of the fabric is received.
+func vtepDevice(params VNIParams) (netlink.Link, error) {
+	if params.MulticastGroup == "" {
+		loopback, err := netlink.LinkByName(UnderlayLoopback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loopback by name: %w", err)
+		}
+		return loopback, nil
+	}
+	underlay, err := linkWithIP(underlayInterfaceSpecialAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the underlay interface: %w", err)
+	}
+	if underlay == nil {
+		return nil, fmt.Errorf("no underlay interface to join multicast group %s on", params.MulticastGroup)
+	}
+	return underlay, nil
+}
+
+// vtepMAC returns the MAC set in the params, if any.
+func vtepMAC(params VNIParams) (net.HardwareAddr, error) {
+	if params.VTEPMAC == "" {
+		return nil, nil
+	}
+	mac, err := net.ParseMAC(params.VTEPMAC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vtep mac %s: %w", params.VTEPMAC, err)
+	}
+	return mac, nil
+}
+
+// multicastGroup returns the multicast group set in the params, if any.
+func multicastGroup(params VNIParams) (net.IP, error) {
+	if params.MulticastGroup == "" {
+		return nil, nil
+	}
+	group := net.ParseIP(params.MulticastGroup)
+	if group == nil {
+		return nil, fmt.Errorf("failed to parse multicast group %s", params.MulticastGroup)
+	}
+	return group, nil
+}
+
+// tosFromDSCP returns the value of the tos field carrying the given
+// DSCP, which takes its six most significant bits.
+func tosFromDSCP(dscp int) int {
+	return dscp << 2
+}
+
 const vniPrefix = "vni"
 
-func vxLanNameFromVNI(vni int) string {
-	return fmt.Sprintf("%s%d", vniPrefix, vni)
+func vxLanNameFromVNI(prefix string, vni int) string {
+	return fmt.Sprintf("%s%s%d", prefix, vniPrefix, vni)
 }
 
-func vniFromVXLanName(name string) (int, error) {
-	if !strings.HasPrefix(name, vniPrefix) {
+func vniFromVXLanName(prefix, name string) (int, error) {
+	if !strings.HasPrefix(name, prefix+vniPrefix) {
 		return 0, NotRouterInterfaceError{Name: name}
 	}
-	vni := strings.TrimPrefix(name, vniPrefix)
+	vni := strings.TrimPrefix(name, prefix+vniPrefix)
 	res, err := strconv.Atoi(vni)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get vni for vxlan %s", name)
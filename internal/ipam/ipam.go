@@ -72,6 +72,33 @@ func VTEPIp(pool string, index int) (net.IPNet, error) {
 	return res, nil
 }
 
+// maxMACOffset is the highest value of the device specific
+// part of a MAC address, the one the node index is added to.
+const maxMACOffset = 1<<24 - 1
+
+// VTEPMAC returns the MAC address to be used for the local VTEP on the
+// ith node, derived by adding the index to the given base MAC.
+func VTEPMAC(base string, index int) (net.HardwareAddr, error) {
+	mac, err := net.ParseMAC(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base mac %s: %w", base, err)
+	}
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("base mac %s is not a 48 bit mac", base)
+	}
+	offset := int(mac[3])<<16 | int(mac[4])<<8 | int(mac[5])
+	offset += index
+	if index < 0 || offset > maxMACOffset {
+		return nil, fmt.Errorf("failed to get mac for node %d from base mac %s: out of range", index, base)
+	}
+	res := make(net.HardwareAddr, 6)
+	copy(res, mac[:3])
+	res[3] = byte(offset >> 16)
+	res[4] = byte(offset >> 8)
+	res[5] = byte(offset)
+	return res, nil
+}
+
 // RouterID returns the IP to be used for the router ID on the ith node.
 func RouterID(pool string, index int) (string, error) {
 	_, cidr, err := net.ParseCIDR(pool)
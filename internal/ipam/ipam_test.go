@@ -343,3 +343,60 @@ func TestVTEPIP(t *testing.T) {
 	}
 
 }
+
+func TestVTEPMAC(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		index       int
+		expectedMAC string
+		shouldFail  bool
+	}{
+		{
+			"first",
+			"02:00:00:00:00:00",
+			0,
+			"02:00:00:00:00:00",
+			false,
+		}, {
+			"second",
+			"02:00:00:00:00:00",
+			1,
+			"02:00:00:00:00:01",
+			false,
+		}, {
+			"carry",
+			"02:00:00:00:00:ff",
+			2,
+			"02:00:00:00:01:01",
+			false,
+		}, {
+			"overflow",
+			"02:00:00:ff:ff:ff",
+			1,
+			"",
+			true,
+		}, {
+			"invalid",
+			"hellothisisnotamac",
+			0,
+			"",
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := VTEPMAC(tc.base, tc.index)
+			if err != nil && !tc.shouldFail {
+				t.Fatalf("got error %v while should not fail", err)
+			}
+			if err == nil && tc.shouldFail {
+				t.Fatalf("was expecting error, didn't fail")
+			}
+
+			if !tc.shouldFail && res.String() != tc.expectedMAC {
+				t.Fatalf("was expecting %s, got %s on the VTEP MAC", tc.expectedMAC, res.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextAttrsKey struct{}
+
+// WithAttrs returns a copy of ctx carrying the given attributes, which
+// are added to every record logged with the returned context.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := attrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, contextAttrsKey{}, merged)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextHandler is a slog.Handler adding the attributes carried
+// by the context to each record before passing it to the wrapped handler.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps the given handler with a ContextHandler.
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(attrsFromContext(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
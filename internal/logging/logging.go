@@ -23,7 +23,7 @@ func New(level string) (*slog.Logger, error) {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logLevel,
 	})
-	logger := slog.New(handler)
+	logger := slog.New(NewContextHandler(handler))
 	slog.SetDefault(logger)
 	return logger, nil
 }
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package metrics holds the metrics exposed by the controllers. They are
+// registered to the controller-runtime registry, served by the metrics
+// server or, in host mode, by the probes server.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "openpe"
+
+var (
+	// ReconcileCacheHits counts the reconciliations whose configuration
+	// matched the one applied by the previous reconciliation.
+	ReconcileCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_cache_hits_total",
+		Help:      "Number of reconciliations whose configuration matched the last applied one.",
+	})
+
+	// ReconcileCacheMisses counts the reconciliations whose configuration
+	// differed from the one applied by the previous reconciliation.
+	ReconcileCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_cache_misses_total",
+		Help:      "Number of reconciliations whose configuration differed from the last applied one.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ReconcileCacheHits, ReconcileCacheMisses)
+}
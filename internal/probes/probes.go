@@ -0,0 +1,105 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package probes serves the health probes, on a socket of the family of
+// the configured address, and optionally the metrics, for the deployments
+// where the metrics server is not running, such as host mode.
+package probes
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	healthPath  = "/healthz"
+	readyPath   = "/readyz"
+	metricsPath = "/metrics"
+)
+
+// Handler returns an http handler serving the liveness probe, the readiness
+// probe running the given checks and, in the OpenMetrics text format, the
+// metrics collected by the given gatherer. The metrics are not served if
+// the gatherer is nil.
+func Handler(gatherer prometheus.Gatherer, readyChecks map[string]healthz.Checker) http.Handler {
+	health := &healthz.Handler{Checks: map[string]healthz.Checker{"ping": healthz.Ping}}
+	ready := &healthz.Handler{Checks: map[string]healthz.Checker{"ping": healthz.Ping}}
+	for name, check := range readyChecks {
+		ready.Checks[name] = check
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(healthPath, http.StripPrefix(healthPath, health))
+	mux.Handle(healthPath+"/", http.StripPrefix(healthPath, health))
+	mux.Handle(readyPath, http.StripPrefix(readyPath, ready))
+	mux.Handle(readyPath+"/", http.StripPrefix(readyPath, ready))
+	if gatherer != nil {
+		mux.Handle(metricsPath, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}))
+	}
+	return mux
+}
+
+// ListenNetwork returns the network the given address must be listened on
+// for its family to be honored: an unspecified ipv4 address such as
+// 0.0.0.0 binds only ipv4, and an ipv6 one such as [::] only ipv6, while
+// an address without host binds both the families.
+func ListenNetwork(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid port in address %s: %w", addr, err)
+	}
+	if host == "" {
+		return "tcp", nil
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host in address %s, must be an ip: %w", addr, err)
+	}
+	if ip.Is4() || ip.Is4In6() {
+		return "tcp4", nil
+	}
+	return "tcp6", nil
+}
+
+// Listen listens on the given address, honoring its family.
+func Listen(addr string) (net.Listener, error) {
+	network, err := ListenNetwork(addr)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// NewServer returns a runnable to be added to the manager, serving
+// Handler on the given address.
+func NewServer(addr string, gatherer prometheus.Gatherer, readyChecks map[string]healthz.Checker) (*manager.Server, error) {
+	listener, err := Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &manager.Server{
+		Name: "probes",
+		Server: &http.Server{
+			Handler:           Handler(gatherer, readyChecks),
+			ReadHeaderTimeout: 32 * time.Second,
+		},
+		Listener: listener,
+	}, nil
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package probes
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+func TestHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openperouter_test_gauge",
+		Help: "A gauge used in tests.",
+	})
+	registry.MustRegister(gauge)
+	gauge.Set(3)
+
+	server := httptest.NewServer(Handler(registry, nil))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		contains []string
+	}{
+		{
+			name:     "liveness",
+			path:     "/healthz",
+			contains: []string{"ok"},
+		},
+		{
+			name:     "readiness",
+			path:     "/readyz",
+			contains: []string{"ok"},
+		},
+		{
+			name: "metrics",
+			path: "/metrics",
+			contains: []string{
+				"# TYPE openperouter_test_gauge gauge",
+				"openperouter_test_gauge 3",
+			},
+		},
+		{
+			name:   "openmetrics",
+			path:   "/metrics",
+			header: "application/openmetrics-text",
+			contains: []string{
+				"# TYPE openperouter_test_gauge gauge",
+				"openperouter_test_gauge 3",
+				"# EOF",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Accept", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("failed to get %s: %v", tt.path, err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200 for %s, got %d", tt.path, resp.StatusCode)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(string(body), c) {
+					t.Errorf("expected %q in the body of %s, got %s", c, tt.path, body)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerReadyChecks(t *testing.T) {
+	server := httptest.NewServer(Handler(nil, map[string]healthz.Checker{
+		"failing": func(_ *http.Request) error { return errors.New("not ready") },
+	}))
+	defer server.Close()
+
+	for path, want := range map[string]int{
+		"/healthz": http.StatusOK,
+		"/readyz":  http.StatusInternalServerError,
+		"/metrics": http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("expected status %d for %s, got %d", want, path, resp.StatusCode)
+		}
+	}
+}
+
+func TestListenNetwork(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{addr: ":9081", want: "tcp"},
+		{addr: "0.0.0.0:9081", want: "tcp4"},
+		{addr: "192.168.1.1:9081", want: "tcp4"},
+		{addr: "[::]:9081", want: "tcp6"},
+		{addr: "[2001:db8::1]:9081", want: "tcp6"},
+		{addr: "9081", wantErr: true},
+		{addr: "localhost:9081", wantErr: true},
+		{addr: "0.0.0.0:70000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := ListenNetwork(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected network %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestListenFamily checks that an unspecified ipv4 address binds only
+// ipv4, as listening on tcp binds both the families.
+func TestListenFamily(t *testing.T) {
+	listener, err := Listen("0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	if addr.IP.To4() == nil {
+		t.Fatalf("expected an ipv4 listener, got %s", addr)
+	}
+	if conn, err := net.Dial("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(addr.Port))); err != nil {
+		t.Errorf("failed to connect over ipv4: %v", err)
+	} else {
+		_ = conn.Close()
+	}
+
+	ipv6, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("ipv6 not available: %v", err)
+	}
+	_ = ipv6.Close()
+	if conn, err := net.Dial("tcp6", net.JoinHostPort("::1", strconv.Itoa(addr.Port))); err == nil {
+		_ = conn.Close()
+		t.Errorf("expected the ipv4 listener not to accept ipv6 connections")
+	}
+}
@@ -3,13 +3,19 @@
 package staticconfiguration
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/openperouter/openperouter/api/static"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
+// ConfigMapKey is the key of the ConfigMap holding the static configuration.
+const ConfigMapKey = "config.yaml"
+
 // ReadFromFile reads a PERouterConfig from a YAML file.
 func ReadFromFile(path string) (*static.PERouterConfig, error) {
 	data, err := os.ReadFile(path)
@@ -17,6 +23,26 @@ func ReadFromFile(path string) (*static.PERouterConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return parse(data)
+}
+
+// ReadFromConfigMap reads a PERouterConfig from the ConfigMapKey entry
+// of the given ConfigMap.
+func ReadFromConfigMap(ctx context.Context, cli client.Reader, namespace, name string) (*static.PERouterConfig, error) {
+	var cm corev1.ConfigMap
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &cm); err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, err)
+	}
+
+	data, ok := cm.Data[ConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s/%s has no %s key", namespace, name, ConfigMapKey)
+	}
+
+	return parse([]byte(data))
+}
+
+func parse(data []byte) (*static.PERouterConfig, error) {
 	var config static.PERouterConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
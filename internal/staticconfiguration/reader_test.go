@@ -3,11 +3,15 @@
 package staticconfiguration
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/openperouter/openperouter/api/static"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestReadFromFile(t *testing.T) {
@@ -69,3 +73,68 @@ func TestReadFromFile_NonExistentFile(t *testing.T) {
 		t.Error("expected error when reading non-existent file")
 	}
 }
+
+func TestReadFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		configMap   *corev1.ConfigMap
+		expected    *static.PERouterConfig
+		expectError bool
+	}{
+		{
+			name: "valid config",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "perouter-config", Namespace: "openperouter-system"},
+				Data:       map[string]string{ConfigMapKey: "nodeIndex: 42\n"},
+			},
+			expected: &static.PERouterConfig{NodeIndex: 42},
+		},
+		{
+			name: "missing key",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "perouter-config", Namespace: "openperouter-system"},
+				Data:       map[string]string{"other.yaml": "nodeIndex: 42\n"},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid yaml",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "perouter-config", Namespace: "openperouter-system"},
+				Data:       map[string]string{ConfigMapKey: "invalid: [unclosed\n"},
+			},
+			expectError: true,
+		},
+		{
+			name: "configmap in another namespace",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "perouter-config", Namespace: "default"},
+				Data:       map[string]string{ConfigMapKey: "nodeIndex: 42\n"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().WithObjects(tt.configMap).Build()
+
+			config, err := ReadFromConfigMap(context.Background(), cli, "openperouter-system", "perouter-config")
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.NodeIndex != tt.expected.NodeIndex {
+				t.Errorf("expected NodeIndex %d, got %d", tt.expected.NodeIndex, config.NodeIndex)
+			}
+		})
+	}
+}
@@ -70,6 +70,28 @@ func (c *Client) IsActive(unitName string) (bool, error) {
 	return strings.TrimSpace(string(output)) == "active", nil
 }
 
+// Exists checks if a systemd unit is known to systemd
+func (c *Client) Exists(ctx context.Context, unitName string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	cmdArgs := []string{
+		"-t", c.hostPID,
+		"-m", "-u", "-i", "-n",
+		"systemctl",
+		"show", "--property=LoadState", "--value",
+		unitName,
+	}
+
+	cmd := exec.CommandContext(ctx, "nsenter", cmdArgs...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("systemctl show %s failed: %w: %s", unitName, err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)) != "not-found", nil
+}
+
 // runSystemctl executes a systemctl command in the host's namespaces
 func (c *Client) runSystemctl(ctx context.Context, args ...string) error {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier:Apache-2.0
+
+// Package verify checks the consistency of the configuration of the whole
+// cluster, as a preflight before rolling it out to the nodes.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/controller/nodeindex"
+	"github.com/openperouter/openperouter/internal/conversion"
+)
+
+// Report is the outcome of the verification of the cluster.
+type Report struct {
+	Nodes          int
+	Underlays      int
+	L3VNIs         int
+	L2VNIs         int
+	L3Passthroughs int
+	// Problems lists the inconsistencies found, empty if none.
+	Problems []string
+}
+
+// OK tells if no inconsistency was found.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Print writes the report in a human readable form.
+func (r Report) Print(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "nodes: %d, underlays: %d, l3vnis: %d, l2vnis: %d, l3passthroughs: %d\n",
+		r.Nodes, r.Underlays, r.L3VNIs, r.L2VNIs, r.L3Passthroughs)
+	if err != nil {
+		return err
+	}
+	if r.OK() {
+		_, err = fmt.Fprintln(w, "the configuration is consistent")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "found %d problems:\n", len(r.Problems)); err != nil {
+		return err
+	}
+	for _, p := range r.Problems {
+		if _, err := fmt.Fprintf(w, "- %s\n", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cluster lists the openperouter resources and the nodes of the cluster
+// and checks them without changing anything: the resources must pass the
+// validation the controller runs, an underlay must exist and every node
+// must have a unique index. An error is returned only if the cluster
+// can't be read.
+func Cluster(ctx context.Context, cli client.Reader) (Report, error) {
+	var underlays v1alpha1.UnderlayList
+	if err := cli.List(ctx, &underlays); err != nil {
+		return Report{}, fmt.Errorf("failed to list underlays: %w", err)
+	}
+	var l3vnis v1alpha1.L3VNIList
+	if err := cli.List(ctx, &l3vnis); err != nil {
+		return Report{}, fmt.Errorf("failed to list l3vnis: %w", err)
+	}
+	var l2vnis v1alpha1.L2VNIList
+	if err := cli.List(ctx, &l2vnis); err != nil {
+		return Report{}, fmt.Errorf("failed to list l2vnis: %w", err)
+	}
+	var l3passthroughs v1alpha1.L3PassthroughList
+	if err := cli.List(ctx, &l3passthroughs); err != nil {
+		return Report{}, fmt.Errorf("failed to list l3passthroughs: %w", err)
+	}
+	var nodes corev1.NodeList
+	if err := cli.List(ctx, &nodes); err != nil {
+		return Report{}, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	report := Report{
+		Nodes:          len(nodes.Items),
+		Underlays:      len(underlays.Items),
+		L3VNIs:         len(l3vnis.Items),
+		L2VNIs:         len(l2vnis.Items),
+		L3Passthroughs: len(l3passthroughs.Items),
+	}
+	if len(underlays.Items) == 0 {
+		report.Problems = append(report.Problems, "no underlay found")
+	}
+	err := conversion.ValidateAllJoined(underlays.Items, l3vnis.Items, l2vnis.Items, l3passthroughs.Items)
+	report.Problems = append(report.Problems, validationProblems(err)...)
+	report.Problems = append(report.Problems, nodeIndexProblems(nodes.Items)...)
+	return report, nil
+}
+
+func validationProblems(err error) []string {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	res := []string{}
+	for _, e := range joined.Unwrap() {
+		res = append(res, e.Error())
+	}
+	return res
+}
+
+// nodeIndexProblems tells the nodes with a missing or invalid index, and
+// the indexes shared by more than one node.
+func nodeIndexProblems(nodes []corev1.Node) []string {
+	res := []string{}
+	byIndex := map[int][]string{}
+	for _, n := range nodes {
+		value, ok := n.Annotations[nodeindex.OpenpeNodeIndex]
+		if !ok {
+			res = append(res, fmt.Sprintf("node %s has no index", n.Name))
+			continue
+		}
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 {
+			res = append(res, fmt.Sprintf("node %s has an invalid index %q", n.Name, value))
+			continue
+		}
+		byIndex[index] = append(byIndex[index], n.Name)
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		names := byIndex[index]
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		res = append(res, fmt.Sprintf("nodes %s share the index %d", strings.Join(names, ", "), index))
+	}
+	return res
+}
+
+// ErrInconsistent is returned by Run when the cluster is inconsistent.
+var ErrInconsistent = errors.New("the configuration of the cluster is inconsistent")
+
+// Run verifies the cluster and prints the report to the given writer,
+// returning ErrInconsistent if any problem was found.
+func Run(ctx context.Context, cli client.Reader, w io.Writer) error {
+	report, err := Cluster(ctx, cli)
+	if err != nil {
+		return err
+	}
+	if err := report.Print(w); err != nil {
+		return fmt.Errorf("failed to print the report: %w", err)
+	}
+	if !report.OK() {
+		return ErrInconsistent
+	}
+	return nil
+}
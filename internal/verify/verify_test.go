@@ -0,0 +1,114 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/controller/nodeindex"
+)
+
+func TestRun(t *testing.T) {
+	underlay := &v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth0"},
+			EVPN: &v1alpha1.EVPNConfig{VTEPCIDR: "100.65.0.0/24"},
+		},
+	}
+	l3vni := &v1alpha1.L3VNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+		Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100},
+	}
+	l2vni := func(vni uint32) *v1alpha1.L2VNI {
+		return &v1alpha1.L2VNI{
+			ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"},
+			Spec:       v1alpha1.L2VNISpec{VNI: vni},
+		}
+	}
+	node := func(name, index string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if index != "" {
+			n.Annotations = map[string]string{nodeindex.OpenpeNodeIndex: index}
+		}
+		return n
+	}
+
+	tests := []struct {
+		name         string
+		objects      []client.Object
+		wantProblems []string
+	}{
+		{
+			name:    "consistent",
+			objects: []client.Object{underlay, l3vni, l2vni(200), node("node1", "0"), node("node2", "1")},
+		},
+		{
+			name:         "no underlay",
+			objects:      []client.Object{l3vni, node("node1", "0")},
+			wantProblems: []string{"no underlay found"},
+		},
+		{
+			name:    "vni collision and duplicate index",
+			objects: []client.Object{underlay, l3vni, l2vni(100), node("node1", "0"), node("node2", "0"), node("node3", "1")},
+			wantProblems: []string{
+				"failed to validate vnis: vni 100 is used by both l3vni red and l2vni blue",
+				"nodes node1, node2 share the index 0",
+			},
+		},
+		{
+			name:    "missing and invalid index",
+			objects: []client.Object{underlay, node("node1", ""), node("node2", "foo")},
+			wantProblems: []string{
+				"node node1 has no index",
+				`node node2 has an invalid index "foo"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add client-go to scheme: %v", err)
+			}
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+
+			out := &bytes.Buffer{}
+			err := Run(context.Background(), cli, out)
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Fatalf("Run() unexpected error: %v, report:\n%s", err, out)
+				}
+				if !strings.Contains(out.String(), "the configuration is consistent") {
+					t.Errorf("expected a consistent report, got:\n%s", out)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInconsistent) {
+				t.Fatalf("Run() error = %v, want ErrInconsistent", err)
+			}
+			for _, p := range tt.wantProblems {
+				if !strings.Contains(out.String(), "- "+p+"\n") {
+					t.Errorf("expected problem %q in the report, got:\n%s", p, out)
+				}
+			}
+		})
+	}
+}
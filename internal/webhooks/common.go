@@ -3,8 +3,10 @@
 package webhooks
 
 import (
+	"fmt"
 	"log/slog"
 
+	"github.com/openperouter/openperouter/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -12,3 +14,70 @@ var (
 	Logger        *slog.Logger
 	WebhookClient client.Reader
 )
+
+// ValidateAll validates the whole set of resources, and is shared by all the webhooks.
+var ValidateAll func(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI, l3passthroughs []v1alpha1.L3Passthrough) error
+
+// resources holds the existing resources of all the kinds validated together.
+type resources struct {
+	underlays      []v1alpha1.Underlay
+	l3vnis         []v1alpha1.L3VNI
+	l2vnis         []v1alpha1.L2VNI
+	l3passthroughs []v1alpha1.L3Passthrough
+}
+
+func getResources() (resources, error) {
+	underlays, err := getUnderlays()
+	if err != nil {
+		return resources{}, err
+	}
+	l3vnis, err := getL3VNIs()
+	if err != nil {
+		return resources{}, err
+	}
+	l2vnis, err := getL2VNIs()
+	if err != nil {
+		return resources{}, err
+	}
+	l3passthroughs, err := getL3Passthroughs()
+	if err != nil {
+		return resources{}, err
+	}
+	return resources{
+		underlays:      underlays.Items,
+		l3vnis:         l3vnis.Items,
+		l2vnis:         l2vnis.Items,
+		l3passthroughs: l3passthroughs.Items,
+	}, nil
+}
+
+// ofKinds returns the resources of the given kinds only, or all
+// of them if kinds is nil.
+func (r resources) ofKinds(kinds map[string]bool) resources {
+	if kinds == nil {
+		return r
+	}
+	res := resources{}
+	if kinds[KindUnderlay] {
+		res.underlays = r.underlays
+	}
+	if kinds[KindL3VNI] {
+		res.l3vnis = r.l3vnis
+	}
+	if kinds[KindL2VNI] {
+		res.l2vnis = r.l2vnis
+	}
+	if kinds[KindL3Passthrough] {
+		res.l3passthroughs = r.l3passthroughs
+	}
+	return res
+}
+
+// validate validates the resources of the kinds whose webhook is enabled.
+func (r resources) validate() error {
+	r = r.ofKinds(enabledKinds)
+	if err := ValidateAll(r.underlays, r.l3vnis, r.l2vnis, r.l3passthroughs); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
@@ -3,9 +3,13 @@
 package webhooks
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 )
 
 const (
@@ -13,6 +17,7 @@ const (
 	readyPath  = "/readyz"
 )
 
+// SetupHealth registers the health and readiness endpoints on the webhook server.
 func SetupHealth(mgr ctrl.Manager) {
 	mgr.GetWebhookServer().Register(
 		healthPath,
@@ -23,6 +28,36 @@ func SetupHealth(mgr ctrl.Manager) {
 		&healthHandler{})
 }
 
+// SetupHealthProbes registers the webhook health and readiness checks on the
+// manager health probe server, for when the probes must be served on an address
+// other than the webhook one. It must be called before the manager is started.
+func SetupHealthProbes(mgr ctrl.Manager) error {
+	if err := mgr.AddHealthzCheck("webhook", healthz.Ping); err != nil {
+		return fmt.Errorf("failed to add the webhook health check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		return fmt.Errorf("failed to add the webhook readiness check: %w", err)
+	}
+	return nil
+}
+
+// ValidateBindAddress checks that the given address is in the host:port form,
+// where the host is either empty or an ip and the port is a valid tcp port.
+func ValidateBindAddress(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid bind address %q: %w", address, err)
+	}
+	if host != "" && net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid bind address %q: host %q is not an ip", address, host)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid bind address %q: port %q must be between 1 and 65535", address, port)
+	}
+	return nil
+}
+
 type healthHandler struct{}
 
 func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import "testing"
+
+func TestValidateBindAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "port only", address: ":8081"},
+		{name: "ipv4 and port", address: "192.168.1.10:8081"},
+		{name: "ipv6 and port", address: "[fd00::1]:8081"},
+		{name: "empty", address: "", wantErr: true},
+		{name: "missing port", address: "192.168.1.10", wantErr: true},
+		{name: "hostname", address: "localhost:8081", wantErr: true},
+		{name: "non numeric port", address: ":http", wantErr: true},
+		{name: "port zero", address: ":0", wantErr: true},
+		{name: "port out of range", address: ":65536", wantErr: true},
+		{name: "unbracketed ipv6", address: "fd00::1:8081", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBindAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBindAddress(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The kinds of resource a validating webhook can be enabled for.
+const (
+	KindUnderlay      = "underlay"
+	KindL3VNI         = "l3vni"
+	KindL2VNI         = "l2vni"
+	KindL3Passthrough = "l3passthrough"
+)
+
+// AllKinds are all the kinds of resource with a validating webhook.
+var AllKinds = []string{KindUnderlay, KindL3VNI, KindL2VNI, KindL3Passthrough}
+
+type kindWebhook struct {
+	path  string
+	setup func(ctrl.Manager) error
+}
+
+// enabledKinds are the kinds whose webhook is enabled, set by Setup.
+// The resources of the other kinds are not admitted through validation,
+// so they are left out of it not to reject the enabled ones because of
+// them. A nil map enables all the kinds.
+var enabledKinds map[string]bool
+
+// kindWebhooks maps each kind to the path and setup function of its webhook.
+var kindWebhooks = map[string]kindWebhook{
+	KindUnderlay:      {path: underlayValidationWebhookPath, setup: SetupUnderlay},
+	KindL3VNI:         {path: l3vniValidationWebhookPath, setup: SetupL3VNI},
+	KindL2VNI:         {path: l2vniValidationWebhookPath, setup: SetupL2VNI},
+	KindL3Passthrough: {path: l3passthroughValidationWebhookPath, setup: SetupL3Passthrough},
+}
+
+// ParseKinds parses a comma separated list of kinds, returning all
+// the kinds if the list is empty.
+func ParseKinds(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return AllKinds, nil
+	}
+	res := []string{}
+	seen := map[string]bool{}
+	for _, k := range strings.Split(list, ",") {
+		kind := strings.ToLower(strings.TrimSpace(k))
+		if _, ok := kindWebhooks[kind]; !ok {
+			return nil, fmt.Errorf("unsupported webhook kind %q, must be one of %s", k, strings.Join(AllKinds, ", "))
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("webhook kind %q specified more than once", kind)
+		}
+		seen[kind] = true
+		res = append(res, kind)
+	}
+	return res, nil
+}
+
+// Setup registers the validating webhooks of the given kinds. The
+// webhooks of the other kinds are served by a handler admitting every
+// request, so that the resources are not rejected by the api server
+// when the webhook configuration still references them.
+func Setup(mgr ctrl.Manager, kinds []string) error {
+	enabled := map[string]bool{}
+	for _, k := range kinds {
+		enabled[k] = true
+	}
+	enabledKinds = enabled
+	for _, kind := range AllKinds {
+		w := kindWebhooks[kind]
+		if !enabled[kind] {
+			Logger.Info("webhook disabled, admitting all the requests", "kind", kind)
+			registerAllowAll(mgr, w.path)
+			continue
+		}
+		if err := w.setup(mgr); err != nil {
+			return fmt.Errorf("failed to setup the %s webhook: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// registerAllowAll registers a handler admitting all the requests on the given path.
+var registerAllowAll = func(mgr ctrl.Manager, path string) {
+	mgr.GetWebhookServer().Register(path, &webhook.Admission{Handler: allowAll{}})
+}
+
+type allowAll struct{}
+
+func (allowAll) Handle(context.Context, admission.Request) admission.Response {
+	return admission.Allowed("")
+}
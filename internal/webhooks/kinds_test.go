@@ -0,0 +1,127 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestParseKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", list: "", want: AllKinds},
+		{name: "single kind", list: "underlay", want: []string{KindUnderlay}},
+		{name: "multiple kinds", list: "underlay, L3VNI", want: []string{KindUnderlay, KindL3VNI}},
+		{name: "unknown kind", list: "underlay,foo", wantErr: true},
+		{name: "duplicate kind", list: "l2vni,l2vni", wantErr: true},
+		{name: "empty kind", list: "underlay,", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKinds(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKinds(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("ParseKinds(%q) diff %s", tt.list, cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestSetupRegistersRequestedKinds(t *testing.T) {
+	oldWebhooks := kindWebhooks
+	oldRegisterAllowAll := registerAllowAll
+	oldLogger := Logger
+	oldEnabledKinds := enabledKinds
+	t.Cleanup(func() {
+		kindWebhooks = oldWebhooks
+		registerAllowAll = oldRegisterAllowAll
+		Logger = oldLogger
+		enabledKinds = oldEnabledKinds
+	})
+	Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	validators := []string{}
+	kindWebhooks = map[string]kindWebhook{}
+	for kind, w := range oldWebhooks {
+		kindWebhooks[kind] = kindWebhook{
+			path: w.path,
+			setup: func(ctrl.Manager) error {
+				validators = append(validators, kind)
+				return nil
+			},
+		}
+	}
+	allowed := []string{}
+	registerAllowAll = func(_ ctrl.Manager, path string) {
+		allowed = append(allowed, path)
+	}
+
+	if err := Setup(nil, []string{KindUnderlay, KindL3Passthrough}); err != nil {
+		t.Fatalf("Setup() unexpected error: %v", err)
+	}
+
+	wantValidators := []string{KindUnderlay, KindL3Passthrough}
+	if !cmp.Equal(validators, wantValidators) {
+		t.Errorf("registered validators diff %s", cmp.Diff(wantValidators, validators))
+	}
+	wantAllowed := []string{l3vniValidationWebhookPath, l2vniValidationWebhookPath}
+	if !cmp.Equal(allowed, wantAllowed) {
+		t.Errorf("registered allow all handlers diff %s", cmp.Diff(wantAllowed, allowed))
+	}
+	wantEnabled := map[string]bool{KindUnderlay: true, KindL3Passthrough: true}
+	if !cmp.Equal(enabledKinds, wantEnabled) {
+		t.Errorf("enabled kinds diff %s", cmp.Diff(wantEnabled, enabledKinds))
+	}
+}
+
+func TestValidateOnlyEnabledKinds(t *testing.T) {
+	oldValidateAll := ValidateAll
+	oldEnabledKinds := enabledKinds
+	t.Cleanup(func() {
+		ValidateAll = oldValidateAll
+		enabledKinds = oldEnabledKinds
+	})
+
+	var got resources
+	ValidateAll = func(underlays []v1alpha1.Underlay, l3vnis []v1alpha1.L3VNI, l2vnis []v1alpha1.L2VNI, l3passthroughs []v1alpha1.L3Passthrough) error {
+		got = resources{underlays: underlays, l3vnis: l3vnis, l2vnis: l2vnis, l3passthroughs: l3passthroughs}
+		return nil
+	}
+	existing := resources{
+		underlays:      []v1alpha1.Underlay{{ObjectMeta: metav1.ObjectMeta{Name: "underlay"}}},
+		l3vnis:         []v1alpha1.L3VNI{{ObjectMeta: metav1.ObjectMeta{Name: "red"}}},
+		l2vnis:         []v1alpha1.L2VNI{{ObjectMeta: metav1.ObjectMeta{Name: "blue"}}},
+		l3passthroughs: []v1alpha1.L3Passthrough{{ObjectMeta: metav1.ObjectMeta{Name: "passthrough"}}},
+	}
+
+	enabledKinds = map[string]bool{KindUnderlay: true, KindL3VNI: true}
+	if err := existing.validate(); err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+	want := resources{underlays: existing.underlays, l3vnis: existing.l3vnis}
+	if !cmp.Equal(got, want, cmp.AllowUnexported(resources{})) {
+		t.Errorf("validated resources diff %s", cmp.Diff(want, got, cmp.AllowUnexported(resources{})))
+	}
+
+	enabledKinds = nil
+	if err := existing.validate(); err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+	if !cmp.Equal(got, existing, cmp.AllowUnexported(resources{})) {
+		t.Errorf("validated resources diff %s", cmp.Diff(existing, got, cmp.AllowUnexported(resources{})))
+	}
+}
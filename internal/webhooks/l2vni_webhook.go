@@ -17,8 +17,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-var ValidateL2VNIs func(l2vnis []v1alpha1.L2VNI) error
-
 const (
 	l2vniValidationWebhookPath = "/validate-openperouter-io-v1alpha1-l2vni"
 )
@@ -102,14 +100,14 @@ func validateL2VNIDelete(_ *v1alpha1.L2VNI) error {
 }
 
 func validateL2VNI(l2vni *v1alpha1.L2VNI) error {
-	existingL2VNIs, err := getL2VNIs()
+	existing, err := getResources()
 	if err != nil {
 		return err
 	}
 
-	toValidate := make([]v1alpha1.L2VNI, 0, len(existingL2VNIs.Items))
+	toValidate := make([]v1alpha1.L2VNI, 0, len(existing.l2vnis))
 	found := false
-	for _, existingL2VNI := range existingL2VNIs.Items {
+	for _, existingL2VNI := range existing.l2vnis {
 		if existingL2VNI.Name == l2vni.Name && existingL2VNI.Namespace == l2vni.Namespace {
 			toValidate = append(toValidate, *l2vni.DeepCopy())
 			found = true
@@ -120,9 +118,15 @@ func validateL2VNI(l2vni *v1alpha1.L2VNI) error {
 	if !found {
 		toValidate = append(toValidate, *l2vni.DeepCopy())
 	}
+	existing.l2vnis = toValidate
 
-	if err := ValidateL2VNIs(toValidate); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+	if err := existing.validate(); err != nil {
+		return err
+	}
+	if ValidateNADSubnets != nil {
+		if err := ValidateNADSubnets([]v1alpha1.L2VNI{*l2vni}); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
 	}
 	return nil
 }
@@ -8,7 +8,6 @@ import (
 	"net/http"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
-	"github.com/openperouter/openperouter/internal/conversion"
 	v1 "k8s.io/api/admission/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -16,8 +15,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-var ValidateL3Passthroughs func(l3passthroughs []v1alpha1.L3Passthrough) error
-
 const (
 	l3passthroughValidationWebhookPath = "/validate-openperouter-io-v1alpha1-l3passthrough"
 )
@@ -97,14 +94,14 @@ func validateL3PassthroughDelete(_ *v1alpha1.L3Passthrough) error {
 }
 
 func validateL3Passthrough(l3passthrough *v1alpha1.L3Passthrough) error {
-	existingL3Passthroughs, err := getL3Passthroughs()
+	existing, err := getResources()
 	if err != nil {
 		return err
 	}
 
-	toValidate := make([]v1alpha1.L3Passthrough, 0, len(existingL3Passthroughs.Items))
+	toValidate := make([]v1alpha1.L3Passthrough, 0, len(existing.l3passthroughs))
 	found := false
-	for _, existingL3Passthrough := range existingL3Passthroughs.Items {
+	for _, existingL3Passthrough := range existing.l3passthroughs {
 		if existingL3Passthrough.Name == l3passthrough.Name && existingL3Passthrough.Namespace == l3passthrough.Namespace {
 			toValidate = append(toValidate, *l3passthrough.DeepCopy())
 			found = true
@@ -115,19 +112,9 @@ func validateL3Passthrough(l3passthrough *v1alpha1.L3Passthrough) error {
 	if !found {
 		toValidate = append(toValidate, *l3passthrough.DeepCopy())
 	}
+	existing.l3passthroughs = toValidate
 
-	if err := ValidateL3Passthroughs(toValidate); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-
-	l3vnis, err := getL3VNIs()
-	if err != nil {
-		return err
-	}
-	if err := conversion.ValidateHostSessions(l3vnis.Items, toValidate); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-	return nil
+	return existing.validate()
 }
 
 var getL3Passthroughs = func() (*v1alpha1.L3PassthroughList, error) {
@@ -7,9 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
-	"github.com/openperouter/openperouter/internal/conversion"
 	v1 "k8s.io/api/admission/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,8 +17,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-var ValidateL3VNIs func(l3vnis []v1alpha1.L3VNI) error
-
 const (
 	l3vniValidationWebhookPath = "/validate-openperouter-io-v1alpha1-l3vni"
 )
@@ -75,8 +73,19 @@ func (v *L3VNIValidator) Handle(ctx context.Context, req admission.Request) (res
 		if err := validateL3VNIDelete(&l3vni); err != nil {
 			return admission.Denied(err.Error())
 		}
+		return admission.Allowed("")
 	}
-	return admission.Allowed("")
+	return admission.Allowed("").WithWarnings(l3vniWarnings(&l3vni)...)
+}
+
+// l3vniWarnings returns the warnings about the security implications
+// of the given L3VNI to be returned to the user.
+func l3vniWarnings(l3vni *v1alpha1.L3VNI) []string {
+	if len(l3vni.Spec.LeakToDefault) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("l3vni %s leaks %v into the default vrf: the leaked prefixes are reachable from outside vrf %s",
+		l3vni.Name, l3vni.Spec.LeakToDefault, l3vni.Spec.VRF)}
 }
 
 func validateL3VNICreate(l3vni *v1alpha1.L3VNI) error {
@@ -104,19 +113,38 @@ func localCIDR(hostSession *v1alpha1.HostSession) v1alpha1.LocalCIDRConfig {
 	return hostSession.LocalCIDR
 }
 
-func validateL3VNIDelete(_ *v1alpha1.L3VNI) error {
+// validateL3VNIDelete denies the deletion of the L3VNI while any L2VNI is
+// still attached to its VRF, as it would be left with a dangling SVI.
+func validateL3VNIDelete(l3vni *v1alpha1.L3VNI) error {
+	Logger.Debug("webhook l3vni", "action", "delete", "name", l3vni.Name, "namespace", l3vni.Namespace)
+	defer Logger.Debug("webhook l3vni", "action", "end delete", "name", l3vni.Name, "namespace", l3vni.Namespace)
+
+	existing, err := getResources()
+	if err != nil {
+		return err
+	}
+
+	dependents := []string{}
+	for _, vni := range existing.l2vnis {
+		if vni.Spec.VRF != nil && *vni.Spec.VRF == l3vni.Spec.VRF {
+			dependents = append(dependents, fmt.Sprintf("l2vni %s/%s", vni.Namespace, vni.Name))
+		}
+	}
+	if len(dependents) > 0 {
+		return fmt.Errorf("l3vni %s/%s can't be deleted while %s still use vrf %s", l3vni.Namespace, l3vni.Name, strings.Join(dependents, ", "), l3vni.Spec.VRF)
+	}
 	return nil
 }
 
 func validateL3VNI(l3vni *v1alpha1.L3VNI) error {
-	existingL3VNIs, err := getL3VNIs()
+	existing, err := getResources()
 	if err != nil {
 		return err
 	}
 
-	toValidate := make([]v1alpha1.L3VNI, 0, len(existingL3VNIs.Items))
+	toValidate := make([]v1alpha1.L3VNI, 0, len(existing.l3vnis))
 	found := false
-	for _, existingL3VNI := range existingL3VNIs.Items {
+	for _, existingL3VNI := range existing.l3vnis {
 		if existingL3VNI.Name == l3vni.Name && existingL3VNI.Namespace == l3vni.Namespace {
 			toValidate = append(toValidate, *l3vni.DeepCopy())
 			found = true
@@ -127,19 +155,9 @@ func validateL3VNI(l3vni *v1alpha1.L3VNI) error {
 	if !found {
 		toValidate = append(toValidate, *l3vni.DeepCopy())
 	}
+	existing.l3vnis = toValidate
 
-	if err := ValidateL3VNIs(toValidate); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-
-	l3passthroughs, err := getL3Passthroughs()
-	if err != nil {
-		return err
-	}
-	if err := conversion.ValidateHostSessions(toValidate, l3passthroughs.Items); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-	return nil
+	return existing.validate()
 }
 
 var getL3VNIs = func() (*v1alpha1.L3VNIList, error) {
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateL3VNIDelete(t *testing.T) {
+	l3vni := &v1alpha1.L3VNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"},
+		Spec:       v1alpha1.L3VNISpec{VRF: "red", VNI: 100},
+	}
+	tests := []struct {
+		name           string
+		objects        []client.Object
+		wantDependents []string
+	}{
+		{
+			name:    "no dependents",
+			objects: []client.Object{l3vni.DeepCopy()},
+		},
+		{
+			name: "l2vnis attached to other vrfs or to none",
+			objects: []client.Object{
+				l3vni.DeepCopy(),
+				&v1alpha1.L2VNI{
+					ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 200, VRF: ptr.To("blue")},
+				},
+				&v1alpha1.L2VNI{
+					ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "openperouter-system"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 201},
+				},
+			},
+		},
+		{
+			name: "l2vnis attached to the vrf",
+			objects: []client.Object{
+				l3vni.DeepCopy(),
+				&v1alpha1.L2VNI{
+					ObjectMeta: metav1.ObjectMeta{Name: "red-1", Namespace: "openperouter-system"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 200, VRF: ptr.To("red")},
+				},
+				&v1alpha1.L2VNI{
+					ObjectMeta: metav1.ObjectMeta{Name: "red-2", Namespace: "openperouter-system"},
+					Spec:       v1alpha1.L2VNISpec{VNI: 201, VRF: ptr.To("red")},
+				},
+			},
+			wantDependents: []string{
+				"l2vni openperouter-system/red-1",
+				"l2vni openperouter-system/red-2",
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WebhookClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+
+			err := validateL3VNIDelete(l3vni)
+			if len(tt.wantDependents) == 0 {
+				if err != nil {
+					t.Fatalf("validateL3VNIDelete() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateL3VNIDelete() expected error, got nil")
+			}
+			for _, d := range tt.wantDependents {
+				if !strings.Contains(err.Error(), d) {
+					t.Errorf("expected error %q to contain %q", err.Error(), d)
+				}
+			}
+		})
+	}
+}
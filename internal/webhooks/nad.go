@@ -0,0 +1,137 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateNADSubnets, when set, checks the gateway ips of the given L2VNIs
+// against the subnets of the NetworkAttachmentDefinitions the pods are
+// attached to them with. The NetworkAttachmentDefinitions are not always
+// known to the cluster validating the L2VNIs, so the check is optional.
+var ValidateNADSubnets func(l2vnis []v1alpha1.L2VNI) error
+
+var nadListGVK = schema.GroupVersionKind{
+	Group:   "k8s.cni.cncf.io",
+	Version: "v1",
+	Kind:    "NetworkAttachmentDefinitionList",
+}
+
+// +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list
+
+// NADSubnetLister returns the subnets the pods attached to an L2VNI get
+// their addresses from, reading them from the ipam configuration of the
+// NetworkAttachmentDefinitions attached to the host bridge of the L2VNI.
+type NADSubnetLister struct {
+	Client client.Reader
+}
+
+// SubnetsFor returns the subnets of the NetworkAttachmentDefinitions
+// attached to the host bridge of the given L2VNI.
+func (l NADSubnetLister) SubnetsFor(l2vni v1alpha1.L2VNI) ([]string, error) {
+	if l2vni.Spec.HostMaster == nil {
+		return nil, nil
+	}
+	nads := &unstructured.UnstructuredList{}
+	nads.SetGroupVersionKind(nadListGVK)
+	if err := l.Client.List(context.Background(), nads); err != nil {
+		return nil, errors.Join(err, errors.New("failed to get existing NetworkAttachmentDefinition objects"))
+	}
+	res := []string{}
+	for _, nad := range nads.Items {
+		config, _, err := unstructured.NestedString(nad.Object, "spec", "config")
+		if err != nil || config == "" {
+			continue
+		}
+		subnets, err := nadSubnets(config, func(master string) bool {
+			return attachedToHostMaster(l2vni, master)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid config of NetworkAttachmentDefinition %s/%s: %w", nad.GetNamespace(), nad.GetName(), err)
+		}
+		res = append(res, subnets...)
+	}
+	return res, nil
+}
+
+// attachedToHostMaster tells if the given interface is the host bridge of
+// the L2VNI. The name of the bridges created automatically is prefixed
+// with the device name prefix of the router, which is not known here.
+func attachedToHostMaster(l2vni v1alpha1.L2VNI, master string) bool {
+	hostMaster := l2vni.Spec.HostMaster
+	if hostMaster.Name != "" {
+		return master == hostMaster.Name
+	}
+	return hostMaster.AutoCreate && strings.HasSuffix(master, fmt.Sprintf("br-hs-%d", l2vni.Spec.VNI))
+}
+
+// cniConfig holds the fields of a CNI configuration, or of a plugin of a
+// configuration list, telling which interface it attaches the pods to and
+// which subnets it assigns their addresses from.
+type cniConfig struct {
+	Bridge  string      `json:"bridge"`
+	Master  string      `json:"master"`
+	IPAM    ipamConfig  `json:"ipam"`
+	Plugins []cniConfig `json:"plugins"`
+}
+
+type ipamConfig struct {
+	Range  string `json:"range"`
+	Subnet string `json:"subnet"`
+	Ranges [][]struct {
+		Subnet string `json:"subnet"`
+	} `json:"ranges"`
+	Addresses []struct {
+		Address string `json:"address"`
+	} `json:"addresses"`
+}
+
+// nadSubnets returns the subnets assigned by the plugins of the given
+// CNI configuration attaching the pods to an interface matching attached.
+func nadSubnets(config string, attached func(master string) bool) ([]string, error) {
+	var parsed cniConfig
+	if err := json.Unmarshal([]byte(config), &parsed); err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for _, plugin := range append([]cniConfig{parsed}, parsed.Plugins...) {
+		master := plugin.Bridge
+		if master == "" {
+			master = plugin.Master
+		}
+		if master == "" || !attached(master) {
+			continue
+		}
+		cidrs := []string{plugin.IPAM.Range, plugin.IPAM.Subnet}
+		for _, set := range plugin.IPAM.Ranges {
+			for _, r := range set {
+				cidrs = append(cidrs, r.Subnet)
+			}
+		}
+		for _, a := range plugin.IPAM.Addresses {
+			cidrs = append(cidrs, a.Address)
+		}
+		for _, cidr := range cidrs {
+			if cidr == "" {
+				continue
+			}
+			_, subnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ipam subnet %s: %w", cidr, err)
+			}
+			res = append(res, subnet.String())
+		}
+	}
+	return res, nil
+}
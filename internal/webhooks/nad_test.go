@@ -0,0 +1,111 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openperouter/openperouter/api/v1alpha1"
+)
+
+func TestNADSubnets(t *testing.T) {
+	l2vni := v1alpha1.L2VNI{
+		Spec: v1alpha1.L2VNISpec{
+			VNI:        110,
+			HostMaster: &v1alpha1.HostMaster{Type: "linux-bridge", AutoCreate: true},
+		},
+	}
+	attached := func(master string) bool {
+		return attachedToHostMaster(l2vni, master)
+	}
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "host-local subnet of the autocreated bridge",
+			config: `{"cniVersion":"0.3.1","type":"bridge","bridge":"br-hs-110","ipam":{"type":"host-local","subnet":"192.171.24.0/24"}}`,
+			want:   []string{"192.171.24.0/24"},
+		},
+		{
+			name:   "other bridge",
+			config: `{"cniVersion":"0.3.1","type":"bridge","bridge":"br-hs-111","ipam":{"type":"host-local","subnet":"192.171.25.0/24"}}`,
+			want:   []string{},
+		},
+		{
+			name: "plugins of a configuration list",
+			config: `{"cniVersion":"0.3.1","plugins":[
+				{"type":"bridge","bridge":"br-hs-110","ipam":{"type":"host-local","ranges":[[{"subnet":"192.171.24.0/24"}],[{"subnet":"fd00:24::/64"}]]}},
+				{"type":"tuning"}]}`,
+			want: []string{"192.171.24.0/24", "fd00:24::/64"},
+		},
+		{
+			name:   "whereabouts range of a macvlan",
+			config: `{"cniVersion":"0.3.1","type":"macvlan","master":"br-hs-110","ipam":{"type":"whereabouts","range":"192.171.24.10/24"}}`,
+			want:   []string{"192.171.24.0/24"},
+		},
+		{
+			name:   "static addresses",
+			config: `{"cniVersion":"0.3.1","type":"bridge","bridge":"br-hs-110","ipam":{"type":"static","addresses":[{"address":"192.171.24.5/24"}]}}`,
+			want:   []string{"192.171.24.0/24"},
+		},
+		{
+			name:    "invalid subnet",
+			config:  `{"cniVersion":"0.3.1","type":"bridge","bridge":"br-hs-110","ipam":{"type":"host-local","subnet":"192.171.24.0"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			config:  `{"cniVersion"`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := nadSubnets(tc.config, attached)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got subnets %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected subnets (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAttachedToHostMaster(t *testing.T) {
+	named := v1alpha1.L2VNI{
+		Spec: v1alpha1.L2VNISpec{
+			VNI:        110,
+			HostMaster: &v1alpha1.HostMaster{Type: "linux-bridge", Name: "br-pods"},
+		},
+	}
+	if !attachedToHostMaster(named, "br-pods") {
+		t.Fatal("expected the named host master to match")
+	}
+	if attachedToHostMaster(named, "br-hs-110") {
+		t.Fatal("expected the autocreated bridge name not to match a named host master")
+	}
+
+	autocreated := v1alpha1.L2VNI{
+		Spec: v1alpha1.L2VNISpec{
+			VNI:        110,
+			HostMaster: &v1alpha1.HostMaster{Type: "linux-bridge", AutoCreate: true},
+		},
+	}
+	if !attachedToHostMaster(autocreated, "pebr-hs-110") {
+		t.Fatal("expected the autocreated bridge to match regardless of the prefix")
+	}
+	if attachedToHostMaster(autocreated, "br-hs-1100") {
+		t.Fatal("expected the bridge of another vni not to match")
+	}
+}
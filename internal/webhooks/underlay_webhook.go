@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/openperouter/openperouter/api/v1alpha1"
 	v1 "k8s.io/api/admission/v1"
@@ -20,8 +22,6 @@ const (
 	underlayValidationWebhookPath = "/validate-openperouter-io-v1alpha1-underlay"
 )
 
-var ValidateUnderlays func(underlays []v1alpha1.Underlay) error
-
 type UnderlayValidator struct {
 	client  client.Client
 	decoder admission.Decoder
@@ -95,19 +95,45 @@ func validateUnderlayUpdate(underlay *v1alpha1.Underlay) error {
 	return validateUnderlay(underlay)
 }
 
-func validateUnderlayDelete(_ *v1alpha1.Underlay) error {
+// validateUnderlayDelete denies the deletion of the underlay while any VNI or
+// passthrough still exists, as they can't be configured without an underlay.
+func validateUnderlayDelete(underlay *v1alpha1.Underlay) error {
+	Logger.Debug("webhook underlay", "action", "delete", "name", underlay.Name, "namespace", underlay.Namespace)
+	defer Logger.Debug("webhook underlay", "action", "end delete", "name", underlay.Name, "namespace", underlay.Namespace)
+
+	existing, err := getResources()
+	if err != nil {
+		return err
+	}
+
+	dependents := []string{}
+	for _, vni := range existing.l3vnis {
+		dependents = append(dependents, fmt.Sprintf("l3vni %s/%s", vni.Namespace, vni.Name))
+	}
+	for _, vni := range existing.l2vnis {
+		dependents = append(dependents, fmt.Sprintf("l2vni %s/%s", vni.Namespace, vni.Name))
+	}
+	for _, passthrough := range existing.l3passthroughs {
+		dependents = append(dependents, fmt.Sprintf("l3passthrough %s/%s", passthrough.Namespace, passthrough.Name))
+	}
+	if len(dependents) > 0 {
+		return fmt.Errorf("underlay %s/%s can't be deleted while %s still exist", underlay.Namespace, underlay.Name, strings.Join(dependents, ", "))
+	}
 	return nil
 }
 
 func validateUnderlay(underlay *v1alpha1.Underlay) error {
-
-	existingUnderlays, err := getUnderlays()
+	existing, err := getResources()
 	if err != nil {
 		return err
 	}
-	toValidate := make([]v1alpha1.Underlay, 0, len(existingUnderlays.Items))
+	if err := validateNicsNotInUse(underlay, existing.underlays); err != nil {
+		return err
+	}
+
+	toValidate := make([]v1alpha1.Underlay, 0, len(existing.underlays))
 	found := false
-	for _, existingUnderlay := range existingUnderlays.Items {
+	for _, existingUnderlay := range existing.underlays {
 		if existingUnderlay.Name == underlay.Name && existingUnderlay.Namespace == underlay.Namespace {
 			toValidate = append(toValidate, *underlay.DeepCopy())
 			found = true
@@ -118,9 +144,24 @@ func validateUnderlay(underlay *v1alpha1.Underlay) error {
 	if !found {
 		toValidate = append(toValidate, *underlay.DeepCopy())
 	}
+	existing.underlays = toValidate
+
+	return existing.validate()
+}
 
-	if err := ValidateUnderlays(toValidate); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+// validateNicsNotInUse checks that none of the nics of the underlay
+// is already used by another one of the existing underlays.
+func validateNicsNotInUse(underlay *v1alpha1.Underlay, existing []v1alpha1.Underlay) error {
+	for _, other := range existing {
+		if other.Name == underlay.Name && other.Namespace == underlay.Namespace {
+			continue
+		}
+		for _, nic := range underlay.Spec.Nics {
+			if slices.Contains(other.Spec.Nics, nic) {
+				return fmt.Errorf("nic %s of underlay %s/%s is already used by underlay %s/%s",
+					nic, underlay.Namespace, underlay.Name, other.Namespace, other.Name)
+			}
+		}
 	}
 	return nil
 }
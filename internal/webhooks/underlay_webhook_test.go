@@ -0,0 +1,159 @@
+// SPDX-License-Identifier:Apache-2.0
+
+package webhooks
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/openperouter/openperouter/api/v1alpha1"
+	"github.com/openperouter/openperouter/internal/conversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateUnderlayDelete(t *testing.T) {
+	underlay := &v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+	}
+	tests := []struct {
+		name           string
+		objects        []client.Object
+		wantDependents []string
+	}{
+		{
+			name:    "no dependents",
+			objects: []client.Object{underlay.DeepCopy()},
+		},
+		{
+			name: "vnis and passthrough still exist",
+			objects: []client.Object{
+				underlay.DeepCopy(),
+				&v1alpha1.L3VNI{ObjectMeta: metav1.ObjectMeta{Name: "red", Namespace: "openperouter-system"}},
+				&v1alpha1.L2VNI{ObjectMeta: metav1.ObjectMeta{Name: "blue", Namespace: "openperouter-system"}},
+				&v1alpha1.L3Passthrough{ObjectMeta: metav1.ObjectMeta{Name: "passthrough", Namespace: "openperouter-system"}},
+			},
+			wantDependents: []string{
+				"l3vni openperouter-system/red",
+				"l2vni openperouter-system/blue",
+				"l3passthrough openperouter-system/passthrough",
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WebhookClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+
+			err := validateUnderlayDelete(underlay)
+			if len(tt.wantDependents) == 0 {
+				if err != nil {
+					t.Fatalf("validateUnderlayDelete() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateUnderlayDelete() expected error, got nil")
+			}
+			for _, d := range tt.wantDependents {
+				if !strings.Contains(err.Error(), d) {
+					t.Errorf("expected error %q to contain %q", err.Error(), d)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUnderlayNics(t *testing.T) {
+	existing := &v1alpha1.Underlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+		Spec: v1alpha1.UnderlaySpec{
+			ASN:  65000,
+			Nics: []string{"eth1"},
+		},
+	}
+	tests := []struct {
+		name     string
+		objects  []client.Object
+		underlay *v1alpha1.Underlay
+		wantErr  string
+	}{
+		{
+			name: "valid nic",
+			underlay: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"eth1"}},
+			},
+		},
+		{
+			name: "invalid nic name",
+			underlay: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"eth1;reboot"}},
+			},
+			wantErr: "contains invalid characters",
+		},
+		{
+			name: "nic name too long",
+			underlay: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"averyveryverylongnic"}},
+			},
+			wantErr: "can't be longer than 15 characters",
+		},
+		{
+			name:    "updating the nic of an existing underlay",
+			objects: []client.Object{existing.DeepCopy()},
+			underlay: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "underlay", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"eth2"}},
+			},
+		},
+		{
+			name:    "nic used by another underlay",
+			objects: []client.Object{existing.DeepCopy()},
+			underlay: &v1alpha1.Underlay{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "openperouter-system"},
+				Spec:       v1alpha1.UnderlaySpec{ASN: 65000, Nics: []string{"eth1"}},
+			},
+			wantErr: "nic eth1 of underlay openperouter-system/other is already used by underlay openperouter-system/underlay",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	ValidateAll = conversion.ValidateAllJoined
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WebhookClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+
+			err := validateUnderlayCreate(tt.underlay)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateUnderlayCreate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateUnderlayCreate() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}